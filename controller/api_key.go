@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"errors"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/repository"
+	"school-notification-backend/security"
+	"school-notification-backend/util"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ApiKeyController interface {
+	CreateApiKey(c *fiber.Ctx) error
+	GetApiKeyAll(c *fiber.Ctx) error
+	GetScopeAll(c *fiber.Ctx) error
+	RevokeApiKey(c *fiber.Ctx) error
+}
+
+type apiKeyController struct {
+	apiKeyRepo repository.ApiKeyRepository
+	classRepo  repository.ClassRepository
+	courseRepo repository.CourseRepository
+	logger     logger.Logger
+}
+
+func NewApiKeyController(apiKeyRepo repository.ApiKeyRepository, classRepo repository.ClassRepository, courseRepo repository.CourseRepository, logger logger.Logger) ApiKeyController {
+	return &apiKeyController{apiKeyRepo: apiKeyRepo, classRepo: classRepo, courseRepo: courseRepo, logger: logger}
+}
+
+// api key is return only once, keep only hash of secret
+func (a *apiKeyController) CreateApiKey(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	req := models.ApiKeyRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "create api key", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	name, err := util.CheckStringData(req.Name, "name")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "create api key", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	a.logger.Debug(c.UserContext(), "api key name", "name", name)
+
+	if len(req.Scopes) == 0 {
+		a.logger.Warn(c.UserContext(), "scopes", "error", util.ErrRequireParameter)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"scopes")
+	}
+	for _, v := range req.Scopes {
+		if !security.IsScope(v) {
+			a.logger.Warn(c.UserContext(), "scope", "error", util.ErrValueInvalid)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "scope "+v+util.ErrValueInvalid.Error())
+		}
+	}
+	a.logger.Debug(c.UserContext(), "scopes", "scopes", req.Scopes)
+
+	if req.ClassId != nil {
+		_, err := a.classRepo.GetClassById(c.UserContext(), *req.ClassId)
+		if err != nil {
+			a.logger.Error(c.UserContext(), "create api key", "error", err)
+			if errors.Is(err, util.ErrNotFound) {
+				return util.ResponseNotSuccess(c, fiber.StatusNotFound, "class_id "+util.ErrNotFound.Error())
+			}
+			return err
+		}
+		a.logger.Debug(c.UserContext(), "class id", "class_id", *req.ClassId)
+	}
+
+	if req.CourseId != nil {
+		_, err := a.courseRepo.GetCourseById(c.UserContext(), *req.CourseId)
+		if err != nil {
+			a.logger.Error(c.UserContext(), "create api key", "error", err)
+			if errors.Is(err, util.ErrNotFound) {
+				return util.ResponseNotSuccess(c, fiber.StatusNotFound, "course_id "+util.ErrNotFound.Error())
+			}
+			return err
+		}
+		a.logger.Debug(c.UserContext(), "course id", "course_id", *req.CourseId)
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil {
+		t, err := time.Parse(time.RFC3339, *req.ExpiresAt)
+		if err != nil || !t.After(time.Now()) {
+			a.logger.Warn(c.UserContext(), "expires at", "error", util.ErrValueInvalid)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "expires_at"+util.ErrValueInvalid.Error())
+		}
+		a.logger.Debug(c.UserContext(), "expires at", "expires_at", *req.ExpiresAt)
+		expiresAt = &t
+	}
+
+	apiKey := &models.ApiKey{
+		Id:        primitive.NewObjectID(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name:      name,
+		Scopes:    req.Scopes,
+		ClassId:   req.ClassId,
+		CourseId:  req.CourseId,
+		ExpiresAt: expiresAt,
+		CreatedBy: user.Id.Hex(),
+	}
+
+	key, hash, err := security.NewApiKey(apiKey.Id.Hex())
+	if err != nil {
+		a.logger.Error(c.UserContext(), "create api key", "error", err)
+		return util.ResponseError(c, err)
+	}
+	apiKey.KeyHash = hash
+
+	_, err = a.apiKeyRepo.Insert(c.UserContext(), apiKey)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "create api key", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.ApiKeyCollection, apiKey.Id.Hex(), apiKey)
+
+	return util.ResponseSuccess(c, fiber.StatusCreated, "create api key success", map[string]interface{}{
+		"api_key_id": apiKey.Id,
+		"api_key":    key,
+	})
+}
+
+func (a *apiKeyController) GetApiKeyAll(c *fiber.Ctx) error {
+	apiKeys, err := a.apiKeyRepo.GetAll(c.UserContext())
+	if err != nil {
+		a.logger.Error(c.UserContext(), "get api key all", "error", err)
+		return err
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"api_keys": apiKeys,
+	})
+}
+
+func (a *apiKeyController) GetScopeAll(c *fiber.Ctx) error {
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"scopes": security.ScopeList(),
+	})
+}
+
+func (a *apiKeyController) RevokeApiKey(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	req := models.ApiKeyRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "revoke api key", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	id, err := util.CheckStringData(req.Id, "id")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "revoke api key", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	a.logger.Debug(c.UserContext(), "revoke api key id", "id", id)
+
+	apiKey, err := a.apiKeyRepo.GetById(c.UserContext(), id)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "revoke api key", "error", err)
+		return err
+	}
+
+	if apiKey.Revoked {
+		a.logger.Warn(c.UserContext(), "api key already revoked")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "api key already revoked")
+	}
+
+	security.AuditBefore(c, repository.ApiKeyCollection, apiKey.Id.Hex(), apiKey)
+	t := time.Now()
+	revokedBy := user.Id.Hex()
+	apiKey.Revoked = true
+	apiKey.RevokedAt = &t
+	apiKey.RevokedBy = &revokedBy
+	apiKey.UpdatedAt = t
+
+	result, err := a.apiKeyRepo.Update(c.UserContext(), apiKey)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "revoke api key", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.ApiKeyCollection, apiKey.Id.Hex(), apiKey)
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "revoke api key success", map[string]interface{}{
+		"api_key_id":   apiKey.Id,
+		"update_count": result.ModifiedCount,
+	})
+}
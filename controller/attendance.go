@@ -0,0 +1,363 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/realtime"
+	"school-notification-backend/repository"
+	"school-notification-backend/security"
+	"school-notification-backend/util"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var errNoCurrentTerm = errors.New("current year and term not found")
+var errThresholdNotSet = errors.New("attendance threshold is not configured")
+
+type AttendanceController interface {
+	GetChronicAbsenceReport(c *fiber.Ctx) error
+	NotifyChronicAbsence(c *fiber.Ctx) error
+	RunChronicAbsenceJob(interval time.Duration)
+}
+
+type attendanceController struct {
+	schoolDataRepository repository.SchoolDataRepository
+	courseRepo           repository.CourseRepository
+	checkNameRepository  repository.CheckNameRepository
+	profileRepo          repository.ProfileRepository
+	classRepo            repository.ClassRepository
+	notificationRepo     repository.NotificationRepository
+	noticeRepo           repository.ChronicAbsenceNoticeRepository
+	hub                  realtime.Hub
+	logger               logger.Logger
+}
+
+func NewAttendanceController(schoolDataRepository repository.SchoolDataRepository, courseRepo repository.CourseRepository, checkNameRepository repository.CheckNameRepository, profileRepo repository.ProfileRepository, classRepo repository.ClassRepository, notificationRepo repository.NotificationRepository, noticeRepo repository.ChronicAbsenceNoticeRepository, hub realtime.Hub, logger logger.Logger) AttendanceController {
+	return &attendanceController{schoolDataRepository: schoolDataRepository, courseRepo: courseRepo, checkNameRepository: checkNameRepository, profileRepo: profileRepo, classRepo: classRepo, notificationRepo: notificationRepo, noticeRepo: noticeRepo, hub: hub, logger: logger}
+}
+
+func (a *attendanceController) GetChronicAbsenceReport(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	classId := c.Query("class_id")
+	a.logger.Debug(c.UserContext(), "find chronic absence class id", "class_id", classId)
+
+	reports, err := a.chronicAbsenceReport(c.UserContext())
+	if err != nil {
+		a.logger.Error(c.UserContext(), "get chronic absence report", "error", err)
+		if errors.Is(err, errNoCurrentTerm) || errors.Is(err, errThresholdNotSet) {
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+		}
+		return util.ResponseError(c, err)
+	}
+
+	// teacher see only student in class that is advisor
+	reportList := []*models.ChronicAbsenceReport{}
+	for _, v := range reports {
+		if user.Role == "teacher" && v.AdvisorId != user.ProfileId {
+			continue
+		}
+		if classId != "" && v.ClassId != classId {
+			continue
+		}
+		reportList = append(reportList, v)
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"report_list": reportList,
+	})
+}
+
+func (a *attendanceController) NotifyChronicAbsence(c *fiber.Ctx) error {
+	reports, err := a.chronicAbsenceReport(c.UserContext())
+	if err != nil {
+		a.logger.Error(c.UserContext(), "notify chronic absence", "error", err)
+		if errors.Is(err, errNoCurrentTerm) || errors.Is(err, errThresholdNotSet) {
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+		}
+		return util.ResponseError(c, err)
+	}
+
+	count := a.notifyChronicAbsence(c.UserContext(), reports)
+
+	return util.ResponseSuccess(c, fiber.StatusCreated, "notify chronic absence success", map[string]interface{}{
+		"report_count":       len(reports),
+		"notification_count": count,
+	})
+}
+
+// run in background, the first check is after one interval
+func (a *attendanceController) RunChronicAbsenceJob(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ctx := context.Background()
+	for range ticker.C {
+		a.logger.Debug(ctx, "chronic absence job start")
+		reports, err := a.chronicAbsenceReport(ctx)
+		if err != nil {
+			a.logger.Warn(ctx, "chronic absence job failed", "error", err)
+			continue
+		}
+
+		count := a.notifyChronicAbsence(ctx, reports)
+		a.logger.Debug(ctx, "chronic absence job end", "report_count", len(reports), "notification_count", count)
+	}
+}
+
+// one notification for each advisor, student that advisor is already notified at the same last absent and late is skip
+func (a *attendanceController) notifyChronicAbsence(ctx context.Context, reports []*models.ChronicAbsenceReport) int {
+	advisorReports := map[string][]*models.ChronicAbsenceReport{}
+	advisorIdList := []string{}
+	for _, v := range reports {
+		if v.AdvisorId == "" {
+			continue
+		}
+		if !a.addChronicAbsenceNotice(ctx, v) {
+			continue
+		}
+		if _, ok := advisorReports[v.AdvisorId]; !ok {
+			advisorIdList = append(advisorIdList, v.AdvisorId)
+		}
+		advisorReports[v.AdvisorId] = append(advisorReports[v.AdvisorId], v)
+	}
+
+	notifications := []*models.Notification{}
+	for _, advisorId := range advisorIdList {
+		studentIdList := []string{}
+		for _, v := range advisorReports[advisorId] {
+			check := true
+			for _, id := range studentIdList {
+				if id == v.StudentId {
+					check = false
+					break
+				}
+			}
+			if check {
+				studentIdList = append(studentIdList, v.StudentId)
+			}
+		}
+
+		message := fmt.Sprintf("%d students in your class are over the attendance threshold", len(studentIdList))
+		notifications = append(notifications, newNotification(advisorId, "teacher", "attendance_report", "chronic absence", message, ""))
+	}
+	sendNotification(ctx, a.logger, a.notificationRepo, a.hub, notifications)
+
+	return len(notifications)
+}
+
+// notice is insert before notification, so two run at the same time notify once by unique index
+// false when the student is already notified or notice is failed
+func (a *attendanceController) addChronicAbsenceNotice(ctx context.Context, report *models.ChronicAbsenceReport) bool {
+	_, err := a.noticeRepo.Insert(ctx, &models.ChronicAbsenceNotice{
+		Id:           primitive.NewObjectID(),
+		CreatedAt:    time.Now(),
+		StudentId:    report.StudentId,
+		AdvisorId:    report.AdvisorId,
+		LastAbsentAt: report.LastAbsentAt,
+		LastLateAt:   report.LastLateAt,
+	})
+	if err != nil {
+		if !errors.Is(err, util.ErrConflict) {
+			a.logger.Error(ctx, "add chronic absence notice", "student_id", report.StudentId, "error", err)
+		}
+		return false
+	}
+
+	return true
+}
+
+// no course in progress is empty report
+func (a *attendanceController) chronicAbsenceReport(ctx context.Context) ([]*models.ChronicAbsenceReport, error) {
+	threshold, err := a.schoolDataRepository.GetByFilter(ctx, bson.M{"type": "AttendanceThreshold"})
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return nil, errThresholdNotSet
+		}
+		return nil, err
+	}
+	if threshold.AttendanceThreshold == nil {
+		return nil, errThresholdNotSet
+	}
+
+	data, err := getCurrentYearAndTerm(ctx, a.schoolDataRepository)
+	if err != nil {
+		return nil, err
+	}
+
+	courses, err := a.courseRepo.GetCourseAllByFilter(ctx, bson.M{
+		"status": "progress",
+		"year":   *data.Year,
+		"term":   *data.Term,
+	})
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return []*models.ChronicAbsenceReport{}, nil
+		}
+		return nil, err
+	}
+
+	// check of every course of each student
+	studentIdList := []string{}
+	records := map[string][]attendanceRecord{}
+	for _, course := range courses {
+		checkNameList, err := a.checkNameRepository.GetByFilterAll(ctx, bson.M{"course_id": course.Id.Hex(), "status": "end"})
+		if err != nil {
+			if errors.Is(err, util.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, checkName := range checkNameList {
+			for _, d := range checkName.CheckNameData {
+				if !isStudentInCourse(course, d.StudentId) {
+					continue
+				}
+				if _, ok := records[d.StudentId]; !ok {
+					studentIdList = append(studentIdList, d.StudentId)
+				}
+				records[d.StudentId] = append(records[d.StudentId], attendanceRecord{course: course, timeStart: checkName.TimeStart, status: d.Status})
+			}
+		}
+	}
+
+	students := map[string]*models.ProfileStudent{}
+	classes := map[string]*models.ClassData{}
+	reports := []*models.ChronicAbsenceReport{}
+	for _, studentId := range studentIdList {
+		report := newChronicAbsenceReport(studentId, records[studentId], threshold.AttendanceThreshold)
+		if report == nil {
+			continue
+		}
+
+		student, ok := students[studentId]
+		if !ok {
+			p, err := a.profileRepo.GetProfileById(ctx, bson.M{"profile_id": studentId, "role": "student"}, "student")
+			if err != nil {
+				a.logger.Error(ctx, "chronic absence report", "error", err)
+			} else {
+				profile := p.(models.ProfileStudent)
+				student = &profile
+			}
+			students[studentId] = student
+		}
+		if student == nil {
+			reports = append(reports, report)
+			continue
+		}
+		report.StudentName = student.Name
+		report.ClassId = student.ClassId
+
+		class, ok := classes[student.ClassId]
+		if !ok {
+			class, err = a.classRepo.GetClassById(ctx, student.ClassId)
+			if err != nil {
+				a.logger.Error(ctx, "chronic absence report", "error", err)
+			}
+			classes[student.ClassId] = class
+		}
+		if class != nil {
+			report.AdvisorId = class.AdvisorId
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// status of the student in one ended check name
+type attendanceRecord struct {
+	course    *models.Course
+	timeStart time.Time
+	status    string
+}
+
+// threshold is check with count of all course, return nil when the student is not over any threshold
+func newChronicAbsenceReport(studentId string, records []attendanceRecord, threshold *models.AttendanceThreshold) *models.ChronicAbsenceReport {
+	if threshold == nil || len(records) == 0 {
+		return nil
+	}
+
+	// consecutive absence is count in order of lesson of every course
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].timeStart.Before(records[j].timeStart)
+	})
+
+	report := &models.ChronicAbsenceReport{
+		StudentId:  studentId,
+		Reason:     []string{},
+		CourseList: []models.ChronicAbsenceCourse{},
+	}
+
+	courseIndex := map[string]int{}
+	consecutive := 0
+	for i, v := range records {
+		ci, ok := courseIndex[v.course.Id.Hex()]
+		if !ok {
+			ci = len(report.CourseList)
+			courseIndex[v.course.Id.Hex()] = ci
+			report.CourseList = append(report.CourseList, models.ChronicAbsenceCourse{CourseId: v.course.Id.Hex(), CourseName: v.course.Name})
+		}
+		course := &report.CourseList[ci]
+
+		report.AllDateCount++
+		course.AllDateCount++
+		switch v.status {
+		case "absent":
+			report.AbsentCount++
+			course.AbsentCount++
+			report.LastAbsentAt = &records[i].timeStart
+			consecutive++
+			if consecutive > report.ConsecutiveAbsence {
+				report.ConsecutiveAbsence = consecutive
+			}
+		case "leave":
+			// leave is approved, it does not break or extend consecutive absence
+			report.LeaveCount++
+			course.LeaveCount++
+		case "attend":
+			report.AttendCount++
+			course.AttendCount++
+			consecutive = 0
+		case "late":
+			report.LateCount++
+			course.LateCount++
+			report.LastLateAt = &records[i].timeStart
+			consecutive = 0
+		default:
+			consecutive = 0
+		}
+	}
+
+	report.AbsenceRate = float64(report.AbsentCount) * 100 / float64(report.AllDateCount)
+	report.LateRate = float64(report.LateCount) * 100 / float64(report.AllDateCount)
+	for i, v := range report.CourseList {
+		report.CourseList[i].AbsenceRate = float64(v.AbsentCount) * 100 / float64(v.AllDateCount)
+		report.CourseList[i].LateRate = float64(v.LateCount) * 100 / float64(v.AllDateCount)
+	}
+
+	if threshold.AbsenceRate > 0 && report.AbsenceRate >= threshold.AbsenceRate {
+		report.Reason = append(report.Reason, "absence_rate")
+	}
+	if threshold.LateRate > 0 && report.LateRate >= threshold.LateRate {
+		report.Reason = append(report.Reason, "late_rate")
+	}
+	if threshold.ConsecutiveAbsence > 0 && report.ConsecutiveAbsence >= threshold.ConsecutiveAbsence {
+		report.Reason = append(report.Reason, "consecutive_absence")
+	}
+
+	if len(report.Reason) == 0 {
+		return nil
+	}
+
+	return report
+}
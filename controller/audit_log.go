@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"school-notification-backend/logger"
+	"school-notification-backend/repository"
+	"school-notification-backend/security"
+	"school-notification-backend/util"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type AuditLogController interface {
+	GetAuditLogAll(c *fiber.Ctx) error
+}
+
+type auditLogController struct {
+	auditLogRepo repository.AuditLogRepository
+	logger       logger.Logger
+}
+
+func NewAuditLogController(auditLogRepo repository.AuditLogRepository, logger logger.Logger) AuditLogController {
+	return &auditLogController{auditLogRepo: auditLogRepo, logger: logger}
+}
+
+// filter by actor, action, entity and date range, from and to is RFC3339 or date, default limit is 100
+func (a *auditLogController) GetAuditLogAll(c *fiber.Ctx) error {
+	filter := bson.M{}
+	if c.Query("actor_id") != "" {
+		filter["actor_id"] = c.Query("actor_id")
+	}
+	if c.Query("actor_username") != "" {
+		filter["actor_username"] = c.Query("actor_username")
+	}
+	if c.Query("action") != "" {
+		filter["action"] = c.Query("action")
+	}
+
+	target := bson.M{}
+	if c.Query("collection") != "" {
+		target["collection"] = c.Query("collection")
+	}
+	if c.Query("target_id") != "" {
+		target["target_id"] = c.Query("target_id")
+	}
+	if len(target) > 0 {
+		filter["targets"] = bson.M{"$elemMatch": target}
+	}
+
+	createdAt := bson.M{}
+	for _, v := range [][2]string{{"from", "$gte"}, {"to", "$lte"}} {
+		if c.Query(v[0]) == "" {
+			continue
+		}
+
+		t, err := parseAuditDate(c.Query(v[0]), v[0] == "to")
+		if err != nil {
+			a.logger.Warn(c.UserContext(), "get audit log all", "error", err)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, v[0]+util.ErrValueInvalid.Error())
+		}
+		createdAt[v[1]] = t
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	limit := int64(100)
+	if c.Query("limit") != "" {
+		n, err := strconv.ParseInt(c.Query("limit"), 10, 64)
+		if err != nil || n <= 0 {
+			a.logger.Warn(c.UserContext(), "limit", "error", util.ErrValueInvalid)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "limit"+util.ErrValueInvalid.Error())
+		}
+		limit = n
+	}
+	a.logger.Debug(c.UserContext(), "find audit log limit", "filter", filter, "limit", limit)
+
+	auditLogs, err := a.auditLogRepo.GetByFilterAll(c.UserContext(), filter, limit)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "get audit log all", "error", err)
+		return err
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"audit_logs": auditLogs,
+	})
+}
+
+// date only is start of day, or end of day when endOfDay
+func parseAuditDate(s string, endOfDay bool) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err == nil {
+		return t, nil
+	}
+
+	t, err = util.ParseDate(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+
+	return t, nil
+}
+
+// update many is record as one target, before is filter and after is filter with update
+func auditUpdateMany(c *fiber.Ctx, collection string, filter bson.M, update bson.M, modifiedCount int64) {
+	if modifiedCount == 0 {
+		return
+	}
+
+	after := bson.M{"modified_count": modifiedCount}
+	for k, v := range filter {
+		after[k] = v
+	}
+	for k, v := range update {
+		after[k] = v
+	}
+
+	security.AuditBefore(c, collection, "", filter)
+	security.AuditAfter(c, collection, "", after)
+}
@@ -3,7 +3,7 @@ package controller
 import (
 	"errors"
 	"fmt"
-	"log"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/repository"
 	"school-notification-backend/security"
@@ -13,22 +13,48 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
+var errInvalidCredentials = errors.New("invalid credentials")
+
 type AuthController interface {
 	SignUp(c *fiber.Ctx) error
 	SignIn(c *fiber.Ctx) error
 	GetUserWithId(c *fiber.Ctx) error
+	Refresh(c *fiber.Ctx) error
+	Logout(c *fiber.Ctx) error
+	LogoutAll(c *fiber.Ctx) error
+	GetSessionList(c *fiber.Ctx) error
+	RevokeSession(c *fiber.Ctx) error
+	RevokeUserSession(c *fiber.Ctx) error
+	GetJwks(c *fiber.Ctx) error
+	ChangePassword(c *fiber.Ctx) error
+	ResetPassword(c *fiber.Ctx) error
+	SignInTwoFactor(c *fiber.Ctx) error
+	EnrollTwoFactor(c *fiber.Ctx) error
+	ConfirmTwoFactor(c *fiber.Ctx) error
+	DisableTwoFactor(c *fiber.Ctx) error
+	RegenerateRecoveryCodes(c *fiber.Ctx) error
+	ResetTwoFactor(c *fiber.Ctx) error
+	GetTwoFactorPolicyAll(c *fiber.Ctx) error
+	SetTwoFactorPolicy(c *fiber.Ctx) error
 }
 
 type authController struct {
-	userRepo    repository.UsersRepository
-	profileRepo repository.ProfileRepository
+	userRepo         repository.UsersRepository
+	profileRepo      repository.ProfileRepository
+	sessionRepo      repository.SessionRepository
+	loginAttemptRepo repository.LoginAttemptRepository
+	loginLockRepo    repository.LoginLockRepository
+	twoFactorRepo    repository.TwoFactorPolicyRepository
+	logger           logger.Logger
 }
 
-func NewAuthController(userRepo repository.UsersRepository, profileRepo repository.ProfileRepository) AuthController {
-	return &authController{userRepo: userRepo, profileRepo: profileRepo}
+func NewAuthController(userRepo repository.UsersRepository, profileRepo repository.ProfileRepository, sessionRepo repository.SessionRepository, loginAttemptRepo repository.LoginAttemptRepository, loginLockRepo repository.LoginLockRepository, twoFactorRepo repository.TwoFactorPolicyRepository, logger logger.Logger) AuthController {
+	return &authController{userRepo: userRepo, profileRepo: profileRepo, sessionRepo: sessionRepo, loginAttemptRepo: loginAttemptRepo, loginLockRepo: loginLockRepo, twoFactorRepo: twoFactorRepo, logger: logger}
 }
 
 func (a *authController) SignUp(c *fiber.Ctx) error {
@@ -36,7 +62,7 @@ func (a *authController) SignUp(c *fiber.Ctx) error {
 	var input models.UserRequest
 	err := c.BodyParser(&input)
 	if err != nil {
-		log.Println(err)
+		a.logger.Warn(c.UserContext(), "sign up", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -46,57 +72,56 @@ func (a *authController) SignUp(c *fiber.Ctx) error {
 	}
 
 	if len(strings.TrimSpace(input.Username)) == 0 {
-		log.Println("do not have parameter username")
+		a.logger.Warn(c.UserContext(), "do not have parameter username")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"username")
 	}
 	input.Username = strings.TrimSpace(input.Username)
-	log.Println("username:", input.Username)
+	a.logger.Debug(c.UserContext(), "username", "username", input.Username)
 
-	_, err = a.userRepo.GetByUsername(input.Username)
+	_, err = a.userRepo.GetByUsername(c.UserContext(), input.Username)
 	if err == nil {
-		log.Println("username already exists")
+		a.logger.Warn(c.UserContext(), "username already exists")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "username"+util.ErrValueAlreadyExists.Error())
 	}
-	if err.Error() != "mongo: no documents in result" {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+	if !errors.Is(err, util.ErrNotFound) {
+		a.logger.Error(c.UserContext(), "sign up", "error", err)
+		return util.ResponseError(c, err)
 	}
 
 	if len(strings.TrimSpace(input.Password)) == 0 {
-		log.Println("do not have parameter password")
+		a.logger.Warn(c.UserContext(), "do not have parameter password")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"password")
 	}
 	input.Password = strings.TrimSpace(input.Password)
-	log.Println("password:", input.Password)
 
 	input.Password, err = security.EncryptPassword(input.Password)
 	if err != nil {
-		log.Println(err)
+		a.logger.Warn(c.UserContext(), "sign up", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
 
 	if len(strings.TrimSpace(input.Role)) == 0 {
-		log.Println("do not have parameter role")
+		a.logger.Warn(c.UserContext(), "do not have parameter role")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"role")
 	}
 	input.Role = strings.TrimSpace(input.Role)
-	log.Println("role:", input.Role)
+	a.logger.Debug(c.UserContext(), "role", "role", input.Role)
 
 	if len(strings.TrimSpace(input.ProfileId)) == 0 {
-		log.Println("do not have parameter profile id")
+		a.logger.Warn(c.UserContext(), "do not have parameter profile id")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"profile_id")
 	}
 	input.ProfileId = strings.TrimSpace(input.ProfileId)
-	log.Println("profile id:", input.ProfileId)
+	a.logger.Debug(c.UserContext(), "profile id", "profile_id", input.ProfileId)
 
 	if len(strings.TrimSpace(input.UserId)) == 0 {
-		log.Println("do not have parameter user id")
+		a.logger.Warn(c.UserContext(), "do not have parameter user id")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"user_id")
 	}
 	input.UserId = strings.TrimSpace(input.UserId)
-	log.Println("user id:", input.UserId)
+	a.logger.Debug(c.UserContext(), "user id", "user_id", input.UserId)
 
-	// err = a.profileRepo.GetProfileByFilterForCheckExists(bson.M{"profile_id": input.ProfileId, "role": input.Role})
+	// err = a.profileRepo.GetProfileByFilterForCheckExists(c.UserContext(), bson.M{"profile_id": input.ProfileId, "role": input.Role})
 	// if err != nil {
 	// 	log.Println(err)
 	// 	if err.Error() == "mongo: no documents in result" {
@@ -107,7 +132,7 @@ func (a *authController) SignUp(c *fiber.Ctx) error {
 
 	user := models.User{
 		Id:        primitive.NewObjectID(),
-		CreatedAt: time.Now().Format(time.RFC3339),
+		CreatedAt: time.Now(),
 		Username:  input.Username,
 		Password:  input.Password,
 		ProfileId: input.ProfileId,
@@ -115,13 +140,13 @@ func (a *authController) SignUp(c *fiber.Ctx) error {
 		UserId:    input.UserId,
 	}
 
-	result, err := a.userRepo.InsertUser(&user)
+	result, err := a.userRepo.InsertUser(c.UserContext(), &user)
 	if err != nil {
-		log.Println(err)
+		a.logger.Warn(c.UserContext(), "sign up", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
 
-	log.Println("result:", result)
+	a.logger.Debug(c.UserContext(), "result", "result", result)
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "create user success", map[string]interface{}{
 		"user_id":  result.InsertedID,
@@ -133,7 +158,7 @@ func (a *authController) SignIn(c *fiber.Ctx) error {
 	var input models.UserRequest
 	err := c.BodyParser(&input)
 	if err != nil {
-		log.Println(err)
+		a.logger.Warn(c.UserContext(), "sign in", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -143,60 +168,134 @@ func (a *authController) SignIn(c *fiber.Ctx) error {
 	}
 
 	if len(strings.TrimSpace(input.Username)) == 0 {
-		log.Println("do not have parameter username")
+		a.logger.Warn(c.UserContext(), "do not have parameter username")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"username")
 	}
 	input.Username = strings.TrimSpace(input.Username)
-	log.Println("username:", input.Username)
-
-	exists, err := a.userRepo.GetByUsername(input.Username)
-	if err != nil {
-		log.Println(input.Username, "signin failed")
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, errors.New("invalid credentials").Error())
-	}
+	a.logger.Debug(c.UserContext(), "username", "username", input.Username)
 
 	if len(strings.TrimSpace(input.Password)) == 0 {
-		log.Println("do not have parameter password")
+		a.logger.Warn(c.UserContext(), "do not have parameter password")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"password")
 	}
 	input.Password = strings.TrimSpace(input.Password)
-	log.Println("password:", input.Password)
+
+	// username or ip that fail too many time must wait
+	wait, err := loginRetryAfter(c.UserContext(), a.loginLockRepo, input.Username, c.IP())
+	if err != nil {
+		a.logger.Error(c.UserContext(), "sign in", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if wait > 0 {
+		a.logger.Warn(c.UserContext(), "signin locked", "username", input.Username, "ip", c.IP())
+		recordLoginAttempt(c, a.logger, a.loginAttemptRepo, input.Username, "", false, "locked")
+		return responseLoginLocked(c, wait)
+	}
+
+	exists, err := a.userRepo.GetByUsername(c.UserContext(), input.Username)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "signin failed", "username", input.Username, "error", err)
+		if !errors.Is(err, util.ErrNotFound) {
+			return util.ResponseError(c, err)
+		}
+		// same time, status and message as wrong password, so username can not be guess
+		security.VerifyDummyPassword(input.Password)
+		addLoginFailure(c.UserContext(), a.logger, a.loginLockRepo, input.Username, c.IP())
+		recordLoginAttempt(c, a.logger, a.loginAttemptRepo, input.Username, "", false, "unknown username")
+		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, errInvalidCredentials.Error())
+	}
 
 	err = security.VerifyPassword(exists.Password, input.Password)
 	if err != nil {
-		log.Println(input.Username, "signin failed")
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, errors.New("invalid credentials").Error())
+		a.logger.Warn(c.UserContext(), "signin failed", "username", input.Username, "error", err)
+		addLoginFailure(c.UserContext(), a.logger, a.loginLockRepo, input.Username, c.IP())
+		recordLoginAttempt(c, a.logger, a.loginAttemptRepo, input.Username, exists.Id.Hex(), false, "invalid password")
+		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, errInvalidCredentials.Error())
+	}
+
+	// user with two factor exchange challenge token and totp code for token
+	if exists.TotpEnabled {
+		challengeToken, err := security.NewChallengeToken(exists.Id.Hex())
+		if err != nil {
+			a.logger.Error(c.UserContext(), "signin failed", "username", input.Username, "error", err)
+			return util.ResponseError(c, err)
+		}
+
+		return util.ResponseSuccess(c, fiber.StatusOK, "two factor required", map[string]interface{}{
+			"two_factor_required": true,
+			"challenge_token":     challengeToken,
+		})
 	}
 
-	tokenStr, err := security.NewToken(exists.Id.Hex())
+	return a.signInSuccess(c, exists)
+}
+
+// create session and token of user that pass all sign in step
+func (a *authController) signInSuccess(c *fiber.Ctx, user *models.User) error {
+	session := &models.Session{
+		Id:        primitive.NewObjectID(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserId:    user.Id.Hex(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		Ip:        c.IP(),
+		ExpiresAt: time.Now().Add(security.RefreshTokenTTL()),
+	}
+
+	refreshToken, hash, err := security.NewRefreshToken(session.Id.Hex())
 	if err != nil {
-		log.Println(input.Username, "signin failed")
-		log.Println(err)
+		a.logger.Error(c.UserContext(), "signin failed", "username", user.Username, "error", err)
+		return util.ResponseError(c, err)
+	}
+	session.RefreshTokenHash = hash
+
+	_, err = a.sessionRepo.Insert(c.UserContext(), session)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "signin failed", "username", user.Username, "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	tokenStr, err := security.NewToken(user.Id.Hex(), session.Id.Hex())
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "signin failed", "username", user.Username, "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, err.Error())
 	}
 
+	// success reset fail count of username, fail count of ip is keep
+	_, err = a.loginLockRepo.Delete(c.UserContext(), security.LoginLockUsername, user.Username)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "sign in success", "error", err)
+	}
+	recordLoginAttempt(c, a.logger, a.loginAttemptRepo, user.Username, user.Id.Hex(), true, "")
+
+	twoFactorRequired, err := security.IsTwoFactorRequired(c.UserContext(), user.Role)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "sign in success", "error", err)
+	}
+
 	return util.ResponseSuccess(c, fiber.StatusOK, "signin success", map[string]interface{}{
-		"token":      fmt.Sprintf("Bearer %s", tokenStr),
-		"user_id":    exists.UserId,
-		"profile_id": exists.ProfileId,
-		"role":       exists.Role,
+		"token":         fmt.Sprintf("Bearer %s", tokenStr),
+		"refresh_token": refreshToken,
+		"session_id":    session.Id,
+		"user_id":       user.UserId,
+		"profile_id":    user.ProfileId,
+		"role":          user.Role,
+
+		"must_change_password":       user.MustChangePassword,
+		"two_factor_enroll_required": twoFactorRequired && !user.TotpEnabled,
 	})
 }
 
 func (a *authController) GetUserWithId(c *fiber.Ctx) error {
-	log.Println(c.GetReqHeaders()["Authorization"])
-
 	payload, err := security.ParseToken(c.GetReqHeaders()["Authorization"])
 	if err != nil {
-		log.Println(err)
+		a.logger.Warn(c.UserContext(), "get user with id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, err.Error())
 	}
 
-	log.Println("payload Id:", payload.Id)
+	a.logger.Debug(c.UserContext(), "payload Id", "id", payload.Id)
 
-	// user, err := a.userRepo.GetById(payload.Id)
+	// user, err := a.userRepo.GetById(c.UserContext(), payload.Id)
 	// if err != nil {
 	// 	log.Println(err)
 	// 	return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, err.Error())
@@ -206,3 +305,287 @@ func (a *authController) GetUserWithId(c *fiber.Ctx) error {
 		// "user": user,
 	})
 }
+
+// rotate refresh token, old refresh token use again will revoke the session
+func (a *authController) Refresh(c *fiber.Ctx) error {
+	req := models.SessionRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "refresh", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	refreshToken, err := util.CheckStringData(req.RefreshToken, "refresh_token")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "refresh", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	sessionId, secret, err := security.SplitRefreshToken(refreshToken)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "refresh", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, err.Error())
+	}
+	a.logger.Debug(c.UserContext(), "refresh session id", "session_id", sessionId)
+
+	session, err := a.sessionRepo.GetById(c.UserContext(), sessionId)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "refresh", "error", err)
+		if errors.Is(err, util.ErrNotFound) || errors.Is(err, util.ErrInvalidID) {
+			return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, security.ErrRefreshTokenInvalid.Error())
+		}
+		return util.ResponseError(c, err)
+	}
+
+	if !security.IsSessionActive(session) {
+		a.logger.Warn(c.UserContext(), "refresh", "error", security.ErrSessionRevoked)
+		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, security.ErrSessionRevoked.Error())
+	}
+
+	if session.RefreshTokenHash != security.HashRefreshToken(secret) {
+		a.logger.Warn(c.UserContext(), "refresh token reuse, revoke session", "session_id", sessionId)
+		revokeSession(session, "", revokeReasonRefreshTokenReuse)
+		_, err = a.sessionRepo.Update(c.UserContext(), session)
+		if err != nil {
+			a.logger.Error(c.UserContext(), "refresh", "error", err)
+		}
+		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, security.ErrRefreshTokenInvalid.Error())
+	}
+
+	user, err := a.userRepo.GetById(c.UserContext(), session.UserId)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "refresh", "error", err)
+		if errors.Is(err, util.ErrNotFound) {
+			return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, security.ErrSessionRevoked.Error())
+		}
+		return util.ResponseError(c, err)
+	}
+
+	refreshToken, hash, err := security.NewRefreshToken(session.Id.Hex())
+	if err != nil {
+		a.logger.Error(c.UserContext(), "refresh", "error", err)
+		return util.ResponseError(c, err)
+	}
+	session.RefreshTokenHash = hash
+	session.UpdatedAt = time.Now()
+
+	_, err = a.sessionRepo.Update(c.UserContext(), session)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "refresh", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	tokenStr, err := security.NewToken(user.Id.Hex(), session.Id.Hex())
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "refresh", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "refresh success", map[string]interface{}{
+		"token":         fmt.Sprintf("Bearer %s", tokenStr),
+		"refresh_token": refreshToken,
+		"session_id":    session.Id,
+	})
+}
+
+func (a *authController) Logout(c *fiber.Ctx) error {
+	payload, err := security.ParseToken(c.GetReqHeaders()["Authorization"])
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "logout", "error", err)
+		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
+	}
+
+	session, err := a.sessionRepo.GetById(c.UserContext(), payload.Subject)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "logout", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	security.AuditBefore(c, repository.SessionCollection, session.Id.Hex(), session)
+	revokeSession(session, payload.Id, revokeReasonLogout)
+	result, err := a.sessionRepo.Update(c.UserContext(), session)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "logout", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.SessionCollection, session.Id.Hex(), session)
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "logout success", map[string]interface{}{
+		"session_id":   session.Id,
+		"update_count": result.ModifiedCount,
+	})
+}
+
+func (a *authController) LogoutAll(c *fiber.Ctx) error {
+	payload, err := security.ParseToken(c.GetReqHeaders()["Authorization"])
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "logout all", "error", err)
+		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
+	}
+
+	result, err := a.revokeUserSession(c, payload.Id, payload.Id, revokeReasonLogoutAll)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "logout all", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "logout all success", map[string]interface{}{
+		"update_count": result.ModifiedCount,
+	})
+}
+
+// own session list, admin can find with user_id
+func (a *authController) GetSessionList(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	userId := user.Id.Hex()
+	if c.Query("user_id") != "" {
+		if user.Role != "admin" {
+			a.logger.Warn(c.UserContext(), "not permiistion")
+			return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
+		}
+		userId = c.Query("user_id")
+	}
+	a.logger.Debug(c.UserContext(), "find session of user id", "user_id", userId)
+
+	sessions, err := a.sessionRepo.GetByFilterAll(c.UserContext(), bson.M{"user_id": userId, "revoked": false})
+	if err != nil {
+		a.logger.Error(c.UserContext(), "get session list", "error", err)
+		return err
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"sessions": sessions,
+	})
+}
+
+func (a *authController) RevokeSession(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	req := models.SessionRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "revoke session", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	id, err := util.CheckStringData(req.Id, "id")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "revoke session", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	a.logger.Debug(c.UserContext(), "revoke session id", "id", id)
+
+	session, err := a.sessionRepo.GetById(c.UserContext(), id)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "revoke session", "error", err)
+		return err
+	}
+
+	security.AuditBefore(c, repository.SessionCollection, session.Id.Hex(), session)
+	revokeSession(session, user.Id.Hex(), revokeReasonRevoke)
+	result, err := a.sessionRepo.Update(c.UserContext(), session)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "revoke session", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.SessionCollection, session.Id.Hex(), session)
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "revoke session success", map[string]interface{}{
+		"session_id":   session.Id,
+		"update_count": result.ModifiedCount,
+	})
+}
+
+func (a *authController) RevokeUserSession(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	req := models.SessionRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "revoke user session", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	userId, err := util.CheckStringData(req.UserId, "user_id")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "revoke user session", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	a.logger.Debug(c.UserContext(), "revoke session of user id", "user_id", userId)
+
+	result, err := a.revokeUserSession(c, userId, user.Id.Hex(), revokeReasonRevoke)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "revoke user session", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "revoke session success", map[string]interface{}{
+		"update_count": result.ModifiedCount,
+	})
+}
+
+// reason that session is revoke
+const (
+	revokeReasonLogout            = "logout"
+	revokeReasonLogoutAll         = "logout_all"
+	revokeReasonRevoke            = "revoke"
+	revokeReasonPasswordChange    = "password_change"
+	revokeReasonPasswordReset     = "password_reset"
+	revokeReasonTwoFactorReset    = "two_factor_reset"
+	revokeReasonRefreshTokenReuse = "refresh_token_reuse"
+)
+
+func (a *authController) revokeUserSession(c *fiber.Ctx, userId string, revokedBy string, reason string) (*mongo.UpdateResult, error) {
+	now := time.Now()
+	filter := bson.M{"user_id": userId, "revoked": false}
+	update := bson.M{
+		"revoked":       true,
+		"revoked_at":    now,
+		"revoked_by":    revokedBy,
+		"revoke_reason": reason,
+		"updated_at":    now,
+	}
+
+	result, err := a.sessionRepo.UpdateMany(c.UserContext(), filter, update)
+	if err != nil {
+		return nil, err
+	}
+	auditUpdateMany(c, repository.SessionCollection, filter, update, result.ModifiedCount)
+
+	return result, nil
+}
+
+// revokedBy is empty when server revoke the session
+func revokeSession(session *models.Session, revokedBy string, reason string) {
+	now := time.Now()
+	session.Revoked = true
+	session.RevokedAt = &now
+	if revokedBy != "" {
+		session.RevokedBy = &revokedBy
+	}
+	session.RevokeReason = reason
+	session.UpdatedAt = now
+}
+
+// public key set in jwks format for other service to verify token
+func (a *authController) GetJwks(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(map[string]interface{}{
+		"keys": security.JWKS(),
+	})
+}
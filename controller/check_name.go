@@ -1,8 +1,13 @@
 package controller
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
+	"school-notification-backend/notifier"
+	"school-notification-backend/realtime"
 	"school-notification-backend/repository"
 	"school-notification-backend/security"
 	"school-notification-backend/util"
@@ -15,35 +20,41 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// time of day in check name message
+const checkTimeLayout = "15:04:05"
+
 type CheckNameController interface {
 	AddDateForCheck(c *fiber.Ctx) error
 	GetDateByCourseId(c *fiber.Ctx) error
 	CheckNameStudent(c *fiber.Ctx) error
 	GetCheckNameDataByCourseIdAndDate(c *fiber.Ctx) error
 	EndDateCheckName(c *fiber.Ctx) error
+	OverrideCheckName(c *fiber.Ctx) error
+	RunCheckNameScheduler(interval time.Duration, timeLate time.Duration, endAfter time.Duration)
 }
 
 type checkNameController struct {
 	checkNameRepository repository.CheckNameRepository
 	courseRepo          repository.CourseRepository
-	userRepo            repository.UsersRepository
+	schoolDataRepo      repository.SchoolDataRepository
+	profileRepo         repository.ProfileRepository
+	classRepo           repository.ClassRepository
+	leaveRequestRepo    repository.LeaveRequestRepository
+	notificationRepo    repository.NotificationRepository
+	hub                 realtime.Hub
+	alertNotifier       notifier.Notifier
+	logger              logger.Logger
 }
 
-func NewCheckNameController(checkNameRepository repository.CheckNameRepository, courseRepo repository.CourseRepository, userRepo repository.UsersRepository) CheckNameController {
-	return &checkNameController{checkNameRepository: checkNameRepository, courseRepo: courseRepo, userRepo: userRepo}
+func NewCheckNameController(checkNameRepository repository.CheckNameRepository, courseRepo repository.CourseRepository, schoolDataRepo repository.SchoolDataRepository, profileRepo repository.ProfileRepository, classRepo repository.ClassRepository, leaveRequestRepo repository.LeaveRequestRepository, notificationRepo repository.NotificationRepository, hub realtime.Hub, alertNotifier notifier.Notifier, logger logger.Logger) CheckNameController {
+	return &checkNameController{checkNameRepository: checkNameRepository, courseRepo: courseRepo, schoolDataRepo: schoolDataRepo, profileRepo: profileRepo, classRepo: classRepo, leaveRequestRepo: leaveRequestRepo, notificationRepo: notificationRepo, hub: hub, alertNotifier: alertNotifier, logger: logger}
 }
 
 func (cn *checkNameController) AddDateForCheck(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cn.userRepo, []string{"admin", "teacher"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.CheckNameRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		cn.logger.Warn(c.UserContext(), "add date for check", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -54,48 +65,52 @@ func (cn *checkNameController) AddDateForCheck(c *fiber.Ctx) error {
 
 	courseId, err := util.CheckStringData(req.CourseId, "course_id")
 	if err != nil {
-		log.Println(err)
+		cn.logger.Warn(c.UserContext(), "add date for check", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("course id:", courseId)
+	cn.logger.Debug(c.UserContext(), "course id", "course_id", courseId)
 
-	course, err := cn.courseRepo.GetCourseById(courseId)
+	course, err := cn.courseRepo.GetCourseById(c.UserContext(), courseId)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cn.logger.Error(c.UserContext(), "add date for check", "error", err)
+		return err
+	}
+
+	canManage, err := canManageCourse(c, course)
+	if err != nil {
+		cn.logger.Error(c.UserContext(), "can manage course", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if !canManage {
+		cn.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, errNotOwnerOfCourse.Error())
 	}
 
 	if course.Status != "progress" {
-		log.Println("course status does not progress")
+		cn.logger.Warn(c.UserContext(), "course status does not progress")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "course status does not progress")
 	}
 
 	date, err := util.CheckStringData(req.Date, "date")
 	if err != nil {
-		log.Println(err)
+		cn.logger.Warn(c.UserContext(), "add date for check", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("check name date:", date)
+	cn.logger.Debug(c.UserContext(), "check name date", "date", date)
 	timeLate, err := util.CheckIntegerData(req.TimeLate, "time_late")
 	if err != nil {
-		log.Println(err)
+		cn.logger.Warn(c.UserContext(), "add date for check", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("check name time late:", timeLate)
+	cn.logger.Debug(c.UserContext(), "check name time late", "time_late", timeLate)
 
-	tDate, err := time.Parse("2006-01-02", date)
+	tDate, err := util.ParseDate(date)
 	if err != nil {
-		log.Println(err)
+		cn.logger.Warn(c.UserContext(), "add date for check", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
 	weekDay := strings.ToLower(tDate.Weekday().String())
-	log.Println(weekDay)
+	cn.logger.Debug(c.UserContext(), "add date for check", "week_day", weekDay)
 
 	checkDay := true
 	for _, dt := range course.DateTime {
@@ -105,37 +120,79 @@ func (cn *checkNameController) AddDateForCheck(c *fiber.Ctx) error {
 		}
 	}
 	if checkDay {
-		log.Println("day not found in course")
+		cn.logger.Warn(c.UserContext(), "day not found in course")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "day not found in course")
 	}
 
-	_, err = cn.checkNameRepository.GetByFilter(bson.M{"course_id": courseId, "date": date})
+	err = checkSchoolDay(c.UserContext(), cn.schoolDataRepo, course.Year, course.Term, date)
+	if err != nil {
+		cn.logger.Error(c.UserContext(), "add date for check", "error", err)
+		if err == errHolidayDate || err == errDateOutOfTerm {
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+		}
+		return util.ResponseError(c, err)
+	}
+
+	var timeStart time.Time
+	if req.TimeStart != "" {
+		timeStart, err = time.ParseInLocation("2006-01-02 15:04", date+" "+req.TimeStart, time.Local)
+		if err != nil {
+			cn.logger.Warn(c.UserContext(), "add date for check", "error", err)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "time_start"+util.ErrValueInvalid.Error())
+		}
+	} else {
+		start, ok := lessonStartTime(course, date)
+		if !ok {
+			cn.logger.Warn(c.UserContext(), "lesson start time not found in course")
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"time_start")
+		}
+		timeStart = start
+	}
+	cn.logger.Debug(c.UserContext(), "check name time start", "check_name_time_start", timeStart.Format(time.RFC3339))
+
+	// course with many lesson in a day has one check name per lesson
+	_, err = cn.checkNameRepository.GetByFilter(c.UserContext(), bson.M{"course_id": courseId, "date": tDate, "time_start": timeStart})
 	if err == nil {
-		log.Println("check name date already exists")
+		cn.logger.Warn(c.UserContext(), "check name date already exists")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "check name date"+util.ErrValueAlreadyExists.Error())
 	}
-	if err.Error() != "mongo: no documents in result" {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+	if !errors.Is(err, util.ErrNotFound) {
+		cn.logger.Error(c.UserContext(), "add date for check", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	closeAction := ""
+	if req.TimeClose != nil {
+		if *req.TimeClose < timeLate {
+			cn.logger.Warn(c.UserContext(), "time close is before time late")
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "time_close"+util.ErrValueInvalid.Error())
+		}
+
+		closeAction = req.CloseAction
+		if closeAction == "" {
+			closeAction = "reject"
+		}
+		if closeAction != "reject" && closeAction != "absent" {
+			cn.logger.Warn(c.UserContext(), "close action invalid")
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ReturnErrorStatusInvalid("close_action", "reject,absent").Error())
+		}
+		cn.logger.Debug(c.UserContext(), "check name time close close action", "time_close", *req.TimeClose, "close_action", closeAction)
 	}
 
 	t := time.Now()
-	checkNameNew := &models.CheckName{
-		Id:            primitive.NewObjectID(),
-		CreatedAt:     t.Format(time.RFC3339),
-		UpdatedAt:     t.Format(time.RFC3339),
-		CourseId:      course.Id.Hex(),
-		Date:          date,
-		TimeLate:      t.Add(time.Minute * time.Duration(timeLate)).Format(time.RFC3339),
-		Status:        "progress",
-		CheckNameData: createCheckNameData(course.StudentIdList, t.Format(time.RFC3339)),
+	checkNameNew := newCheckName(course, tDate, timeStart, timeStart.Add(time.Minute*time.Duration(timeLate)), t)
+	if req.TimeClose != nil {
+		timeClose := timeStart.Add(time.Minute * time.Duration(*req.TimeClose))
+		checkNameNew.TimeClose = &timeClose
+		checkNameNew.CloseAction = closeAction
 	}
 
-	result, err := cn.checkNameRepository.Insert(checkNameNew)
+	result, err := cn.checkNameRepository.Insert(c.UserContext(), checkNameNew)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cn.logger.Error(c.UserContext(), "add date for check", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.CheckNameCollection, checkNameNew.Id.Hex(), checkNameNew)
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "create date check name success", map[string]interface{}{
 		"id": result.InsertedID,
@@ -143,78 +200,46 @@ func (cn *checkNameController) AddDateForCheck(c *fiber.Ctx) error {
 }
 
 func (cn *checkNameController) GetDateByCourseId(c *fiber.Ctx) error {
-	user, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cn.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-	role := user.Role
-	profileId := user.ProfileId
-
 	courseId, err := util.CheckStringData(c.Query("course_id"), "course_id")
 	if err != nil {
-		log.Println(err)
+		cn.logger.Warn(c.UserContext(), "get date by course id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find score of course id:", courseId)
-	course, err := cn.courseRepo.GetCourseById(courseId)
+	cn.logger.Debug(c.UserContext(), "find score of course id", "course_id", courseId)
+	course, err := cn.courseRepo.GetCourseById(c.UserContext(), courseId)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cn.logger.Error(c.UserContext(), "get date by course id", "error", err)
+		return err
 	}
 
-	checkNameList, err := cn.checkNameRepository.GetByFilterAll(bson.M{"course_id": courseId})
+	studentIdList, err := readableStudentIdList(c, cn.profileRepo, cn.classRepo, course)
 	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cn.logger.Error(c.UserContext(), "get date by course id", "error", err)
+		return util.ResponseError(c, err)
 	}
-
-	if len(checkNameList) == 0 {
-		log.Println("check name not found")
-		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
+	if studentIdList != nil && len(studentIdList) == 0 {
+		cn.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, errNotOwnerOfCourse.Error())
 	}
 
-	if role == "teacher" {
-		if profileId != course.InstructorId {
-			log.Println("not permiistion")
-			return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "not permission")
-		}
+	checkNameList, err := cn.checkNameRepository.GetByFilterAll(c.UserContext(), bson.M{"course_id": courseId})
+	if err != nil {
+		cn.logger.Error(c.UserContext(), "get date by course id", "error", err)
+		return err
+	}
 
-	} else if role == "student" {
-		check := true
-		for _, v := range course.StudentIdList {
-			if v == profileId {
-				check = false
-				break
-			}
-		}
-		if check {
-			log.Println("not permiistion")
-			return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "not permission")
-		}
-	} else {
-		if role != "server" {
-			log.Println("role", util.ErrValueInvalid)
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "role"+util.ErrValueInvalid.Error())
-		}
+	if len(checkNameList) == 0 {
+		cn.logger.Warn(c.UserContext(), "check name not found")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
 	checkNamel := []string{}
 	for _, v := range checkNameList {
-		checkNamel = append(checkNamel, v.Date)
+		checkNamel = append(checkNamel, util.FormatDate(v.Date))
 	}
 
 	if len(checkNamel) == 0 {
-		log.Println("date ", util.ErrNotFound)
+		cn.logger.Warn(c.UserContext(), "date", "error", util.ErrNotFound)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "date "+util.ErrNotFound.Error())
 	}
 
@@ -224,16 +249,10 @@ func (cn *checkNameController) GetDateByCourseId(c *fiber.Ctx) error {
 }
 
 func (cn *checkNameController) CheckNameStudent(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cn.userRepo, []string{"admin", "teacher"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.CheckNameRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		cn.logger.Warn(c.UserContext(), "check name student", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -244,60 +263,87 @@ func (cn *checkNameController) CheckNameStudent(c *fiber.Ctx) error {
 
 	courseId, err := util.CheckStringData(req.CourseId, "course_id")
 	if err != nil {
-		log.Println(err)
+		cn.logger.Warn(c.UserContext(), "check name student", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("course id:", courseId)
+	cn.logger.Debug(c.UserContext(), "course id", "course_id", courseId)
 
-	course, err := cn.courseRepo.GetCourseById(courseId)
+	course, err := cn.courseRepo.GetCourseById(c.UserContext(), courseId)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cn.logger.Error(c.UserContext(), "check name student", "error", err)
+		return err
+	}
+
+	canManage, err := canManageCourse(c, course)
+	if err != nil {
+		cn.logger.Error(c.UserContext(), "can manage course", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if !canManage {
+		cn.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, errNotOwnerOfCourse.Error())
 	}
 
 	if course.Status != "progress" {
-		log.Println("course status does not progress")
+		cn.logger.Warn(c.UserContext(), "course status does not progress")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "course status does not progress")
 	}
 
 	date, err := util.CheckStringData(req.Date, "date")
 	if err != nil {
-		log.Println(err)
+		cn.logger.Warn(c.UserContext(), "check name student", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("check name date:", date)
+	cn.logger.Debug(c.UserContext(), "check name date", "date", date)
+	tDate, err := util.ParseDate(date)
+	if err != nil {
+		cn.logger.Warn(c.UserContext(), "check name student", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "date"+util.ErrValueInvalid.Error())
+	}
 
-	chcekName, err := cn.checkNameRepository.GetByFilter(bson.M{"course_id": courseId, "date": date})
+	timeStart, err := slotTimeStart(date, req.TimeStart)
 	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
+		cn.logger.Warn(c.UserContext(), "check name student", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "time_start"+util.ErrValueInvalid.Error())
+	}
+
+	chcekName, err := getCheckNameOfSlot(c.UserContext(), cn.checkNameRepository, courseId, tDate, timeStart)
+	if err != nil {
+		cn.logger.Error(c.UserContext(), "check name student", "error", err)
+		if errors.Is(err, util.ErrNotFound) {
 			return util.ResponseNotSuccess(c, fiber.StatusNotFound, "date "+util.ErrNotFound.Error())
 		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		return util.ResponseError(c, err)
+	}
+
+	// check name that is end is change by override only, so change has note and history
+	if chcekName.Status != "progress" {
+		cn.logger.Warn(c.UserContext(), "check name status does not progress", "status", chcekName.Status)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "check name status does not progress")
 	}
 
 	studentId, err := util.CheckStringData(req.StudentId, "student_id")
 	if err != nil {
-		log.Println(err)
+		cn.logger.Warn(c.UserContext(), "check name student", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("student id:", studentId)
+	cn.logger.Debug(c.UserContext(), "student id", "student_id", studentId)
 
 	checkBy, err := util.CheckStringData(req.CheckBy, "check_by")
 	if err != nil {
-		log.Println(err)
+		cn.logger.Warn(c.UserContext(), "check name student", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("check by:", checkBy)
+	cn.logger.Debug(c.UserContext(), "check by", "check_by", checkBy)
 
 	if checkBy != "teacher" && checkBy != "server" {
-		log.Println("check by", util.ErrValueInvalid)
+		cn.logger.Warn(c.UserContext(), "check by", "error", util.ErrValueInvalid)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "check by"+util.ErrValueInvalid.Error())
+	}
+
+	// machine client check by server only
+	if security.GetApiKey(c) != nil && checkBy != "server" {
+		cn.logger.Warn(c.UserContext(), "check by", "error", util.ErrValueInvalid)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "check by"+util.ErrValueInvalid.Error())
 	}
 
@@ -310,35 +356,100 @@ func (cn *checkNameController) CheckNameStudent(c *fiber.Ctx) error {
 	}
 
 	if check {
-		log.Println("student id not found in course")
+		cn.logger.Warn(c.UserContext(), "student id not found in course")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "student id not found in course")
 	}
 
 	t := time.Now()
+	// teacher or admin can enter check in time of backfill date, check of today and later use server time
+	if req.Time != "" {
+		user := security.GetUser(c)
+		if security.GetApiKey(c) != nil || (user.Role != "teacher" && user.Role != "admin") {
+			cn.logger.Warn(c.UserContext(), "time can enter by teacher only", "role", user.Role)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "time"+util.ErrValueInvalid.Error())
+		}
+
+		today, err := util.ParseDate(util.FormatDate(t))
+		if err != nil {
+			cn.logger.Error(c.UserContext(), "check name student", "error", err)
+			return util.ResponseError(c, err)
+		}
+		if !tDate.Before(today) {
+			cn.logger.Warn(c.UserContext(), "time can enter for past date only")
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "time"+util.ErrValueInvalid.Error())
+		}
+
+		t, err = time.ParseInLocation("2006-01-02 15:04", date+" "+req.Time, time.Local)
+		if err != nil {
+			cn.logger.Warn(c.UserContext(), "check name student", "error", err)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "time"+util.ErrValueInvalid.Error())
+		}
+		cn.logger.Debug(c.UserContext(), "check in time", "check_time", req.Time)
+	}
+
+	closed := false
+	if chcekName.TimeClose != nil {
+		closed = t.After(*chcekName.TimeClose)
+	}
+	if closed && chcekName.CloseAction != "absent" {
+		cn.logger.Warn(c.UserContext(), "check name is closed")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "check name is closed")
+	}
+
+	// approved leave is not overwrite by check in
+	for _, v := range chcekName.CheckNameData {
+		if v.StudentId == studentId && v.Status == "leave" {
+			cn.logger.Warn(c.UserContext(), "student is on leave", "student_id", studentId)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "student is on leave")
+		}
+	}
+
+	security.AuditBefore(c, repository.CheckNameCollection, chcekName.Id.Hex(), chcekName)
+	status := ""
+	checkTime := ""
+	var checked models.CheckNameData
 	for i, v := range chcekName.CheckNameData {
 		if v.StudentId == studentId {
-			chcekName.CheckNameData[i].UpdatedAt = t.Format(time.RFC3339)
-			chcekName.CheckNameData[i].Time = strings.Split(strings.Split(t.Format(time.RFC3339), "T")[1], "+")[0]
+			chcekName.CheckNameData[i].UpdatedAt = time.Now()
+			chcekName.CheckNameData[i].Time = &t
 			chcekName.CheckNameData[i].CheckBy = checkBy
 
-			tl, err := time.Parse(time.RFC3339, chcekName.TimeLate)
-			if err != nil {
-				log.Println(err)
-				return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, err.Error())
-			}
-
-			if !(t.After(tl)) {
+			if closed {
+				chcekName.CheckNameData[i].Status = "absent"
+			} else if !(t.After(chcekName.TimeLate)) {
 				chcekName.CheckNameData[i].Status = "attend"
 			} else {
 				chcekName.CheckNameData[i].Status = "late"
 			}
+			status = chcekName.CheckNameData[i].Status
+			checkTime = t.Format(checkTimeLayout)
+			checked = chcekName.CheckNameData[i]
 		}
 	}
 
-	result, err := cn.checkNameRepository.Update(chcekName)
+	result, err := cn.checkNameRepository.Update(c.UserContext(), chcekName)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cn.logger.Error(c.UserContext(), "check name student", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.CheckNameCollection, chcekName.Id.Hex(), chcekName)
+
+	if status != "" {
+		publishCheckName(cn.hub, course, chcekName, checked)
+	}
+	if status == "late" {
+		alerts := newAttendanceAlerts(c.UserContext(), cn.logger, cn.profileRepo, cn.classRepo, studentId, "late", course.Name, date, checkTime, chcekName.Id.Hex())
+		sendNotification(c.UserContext(), cn.logger, cn.notificationRepo, cn.hub, append([]*models.Notification{
+			newNotification(studentId, "student", "check_name", "late", "late for "+course.Name+" on "+date+" at "+checkTime, chcekName.Id.Hex()),
+		}, newAlertNotifications(alerts)...))
+		sendAlert(c.UserContext(), cn.logger, cn.alertNotifier, alerts)
+	}
+	if status == "absent" {
+		alerts := newAttendanceAlerts(c.UserContext(), cn.logger, cn.profileRepo, cn.classRepo, studentId, "absent", course.Name, date, checkTime, chcekName.Id.Hex())
+		sendNotification(c.UserContext(), cn.logger, cn.notificationRepo, cn.hub, append([]*models.Notification{
+			newNotification(studentId, "student", "check_name", "absent", "absent from "+course.Name+" on "+date, chcekName.Id.Hex()),
+		}, newAlertNotifications(alerts)...))
+		sendAlert(c.UserContext(), cn.logger, cn.alertNotifier, alerts)
 	}
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "check name success", map[string]interface{}{
@@ -347,105 +458,81 @@ func (cn *checkNameController) CheckNameStudent(c *fiber.Ctx) error {
 	})
 }
 
+// instructor and admin read all student, student read own data, advisor and parent read data of their student
 func (cn *checkNameController) GetCheckNameDataByCourseIdAndDate(c *fiber.Ctx) error {
-	user, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cn.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-	// role := c.Query("role")
-	role := user.Role
-	// profileId := c.Query("id")
-	profileId := user.ProfileId
+	user := security.GetUser(c)
+
 	courseId, err := util.CheckStringData(c.Query("course_id"), "course_id")
 	if err != nil {
-		log.Println(err)
+		cn.logger.Warn(c.UserContext(), "get check name data by course id and date", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find check name of course id:", courseId)
-	course, err := cn.courseRepo.GetCourseById(courseId)
+	cn.logger.Debug(c.UserContext(), "find check name of course id", "course_id", courseId)
+	course, err := cn.courseRepo.GetCourseById(c.UserContext(), courseId)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cn.logger.Error(c.UserContext(), "get check name data by course id and date", "error", err)
+		return err
+	}
+
+	studentIdList, err := readableStudentIdList(c, cn.profileRepo, cn.classRepo, course)
+	if err != nil {
+		cn.logger.Error(c.UserContext(), "get check name data by course id and date", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if studentIdList != nil && len(studentIdList) == 0 {
+		cn.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, errNotOwnerOfCourse.Error())
 	}
 
 	var dataRes interface{}
-	if role == "teacher" {
+	if user.Role == "student" {
+		checkNameList, err := cn.checkNameRepository.GetByFilterAll(c.UserContext(), bson.M{"course_id": courseId})
+		if err != nil {
+			cn.logger.Error(c.UserContext(), "get check name data by course id and date", "error", err)
+			return err
+		}
+
+		checkNameListRes := newCheckNameStudentResList(checkNameList, user.ProfileId)
+		if len(checkNameListRes) == 0 {
+			cn.logger.Warn(c.UserContext(), "student id not have checked")
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "student id not have checked")
+		}
+		dataRes = checkNameListRes
+	} else {
 		date, err := util.CheckStringData(c.Query("date"), "date")
 		if err != nil {
-			log.Println(err)
+			cn.logger.Warn(c.UserContext(), "get check name data by course id and date", "error", err)
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 		}
-		log.Println("check name date:", date)
-
-		if profileId != course.InstructorId {
-			log.Println("not permiistion")
-			return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "not permission")
+		cn.logger.Debug(c.UserContext(), "check name date", "date", date)
+		tDate, err := util.ParseDate(date)
+		if err != nil {
+			cn.logger.Warn(c.UserContext(), "get check name data by course id and date", "error", err)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "date"+util.ErrValueInvalid.Error())
 		}
 
-		data, err := cn.checkNameRepository.GetByFilter(bson.M{"course_id": courseId, "date": date})
+		timeStart, err := slotTimeStart(date, c.Query("time_start"))
 		if err != nil {
-			log.Println(err)
-			if err == mongo.ErrNoDocuments {
-				return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-			}
-			return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+			cn.logger.Warn(c.UserContext(), "get check name data by course id and date", "error", err)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "time_start"+util.ErrValueInvalid.Error())
 		}
-		dataRes = data
-	} else if role == "student" {
 
-		check := true
-		for _, v := range course.StudentIdList {
-			if v == profileId {
-				check = false
-				break
-			}
-		}
-		if check {
-			log.Println("not permiistion")
-			return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "not permission")
+		data, err := getCheckNameOfSlot(c.UserContext(), cn.checkNameRepository, courseId, tDate, timeStart)
+		if err != nil {
+			cn.logger.Error(c.UserContext(), "get check name data by course id and date", "error", err)
+			return err
 		}
 
-		checkNameList, err := cn.checkNameRepository.GetByFilterAll(bson.M{"course_id": courseId})
-		if err != nil {
-			log.Println(err)
-			if err == mongo.ErrNoDocuments {
-				return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-			}
-			return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
-		}
-
-		checkNameListRes := []models.CheckNameStudentRes{}
-		for _, v := range checkNameList {
-			for _, check := range v.CheckNameData {
-				if check.StudentId == profileId {
-					checkNameListRes = append(checkNameListRes, models.CheckNameStudentRes{
-						Date:      v.Date,
-						UpdatedAt: check.UpdatedAt,
-						Time:      check.Time,
-						Status:    check.Status,
-						CheckBy:   check.CheckBy,
-					})
-					break
+		if studentIdList != nil {
+			checkNameData := []models.CheckNameData{}
+			for _, v := range data.CheckNameData {
+				if isStudentInList(studentIdList, v.StudentId) {
+					checkNameData = append(checkNameData, v)
 				}
 			}
+			data.CheckNameData = checkNameData
 		}
-		if len(checkNameListRes) == 0 {
-			log.Println("student id not have checked")
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "student id not have checked")
-		}
-		dataRes = checkNameListRes
-	} else {
-		if role != "server" {
-			log.Println("role", util.ErrValueInvalid)
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "role"+util.ErrValueInvalid.Error())
-		}
+		dataRes = data
 	}
 
 	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
@@ -454,16 +541,97 @@ func (cn *checkNameController) GetCheckNameDataByCourseIdAndDate(c *fiber.Ctx) e
 }
 
 func (cn *checkNameController) EndDateCheckName(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cn.userRepo, []string{"admin", "teacher"})
+	req := models.CheckNameRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		cn.logger.Warn(c.UserContext(), "end date check name", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	courseId, err := util.CheckStringData(req.CourseId, "course_id")
+	if err != nil {
+		cn.logger.Warn(c.UserContext(), "end date check name", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	cn.logger.Debug(c.UserContext(), "course id", "course_id", courseId)
+
+	course, err := cn.courseRepo.GetCourseById(c.UserContext(), courseId)
+	if err != nil {
+		cn.logger.Error(c.UserContext(), "end date check name", "error", err)
+		return err
+	}
+
+	canManage, err := canManageCourse(c, course)
+	if err != nil {
+		cn.logger.Error(c.UserContext(), "can manage course", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if !canManage {
+		cn.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, errNotOwnerOfCourse.Error())
+	}
+
+	if course.Status != "progress" {
+		cn.logger.Warn(c.UserContext(), "course status does not progress")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "course status does not progress")
+	}
+
+	date, err := util.CheckStringData(req.Date, "date")
+	if err != nil {
+		cn.logger.Warn(c.UserContext(), "end date check name", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	cn.logger.Debug(c.UserContext(), "check name date", "date", date)
+	tDate, err := util.ParseDate(date)
+	if err != nil {
+		cn.logger.Warn(c.UserContext(), "end date check name", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "date"+util.ErrValueInvalid.Error())
+	}
+
+	timeStart, err := slotTimeStart(date, req.TimeStart)
+	if err != nil {
+		cn.logger.Warn(c.UserContext(), "end date check name", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "time_start"+util.ErrValueInvalid.Error())
+	}
+
+	chcekName, err := getCheckNameOfSlot(c.UserContext(), cn.checkNameRepository, courseId, tDate, timeStart)
+	if err != nil {
+		cn.logger.Error(c.UserContext(), "end date check name", "error", err)
+		return err
+	}
+
+	if chcekName.Status != "progress" {
+		cn.logger.Warn(c.UserContext(), "this date not in progress")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "this date not in progress")
+	}
+
+	security.AuditBefore(c, repository.CheckNameCollection, chcekName.Id.Hex(), chcekName)
+	result, err := cn.endCheckName(c.UserContext(), course, chcekName)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
+		cn.logger.Error(c.UserContext(), "end date check name", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.CheckNameCollection, chcekName.Id.Hex(), chcekName)
+
+	return util.ResponseSuccess(c, fiber.StatusCreated, "check name success", map[string]interface{}{
+		"check_name_id": chcekName.Id,
+		"update_count":  result.ModifiedCount,
+	})
+}
+
+// set any status of student with note, allow after end until course summary
+func (cn *checkNameController) OverrideCheckName(c *fiber.Ctx) error {
+	user := security.GetUser(c)
 
 	req := models.CheckNameRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		cn.logger.Warn(c.UserContext(), "override check name", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -474,58 +642,175 @@ func (cn *checkNameController) EndDateCheckName(c *fiber.Ctx) error {
 
 	courseId, err := util.CheckStringData(req.CourseId, "course_id")
 	if err != nil {
-		log.Println(err)
+		cn.logger.Warn(c.UserContext(), "override check name", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("course id:", courseId)
+	cn.logger.Debug(c.UserContext(), "course id", "course_id", courseId)
 
-	course, err := cn.courseRepo.GetCourseById(courseId)
+	course, err := cn.courseRepo.GetCourseById(c.UserContext(), courseId)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cn.logger.Error(c.UserContext(), "override check name", "error", err)
+		return err
+	}
+
+	canManage, err := canManageCourse(c, course)
+	if err != nil {
+		cn.logger.Error(c.UserContext(), "can manage course", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if !canManage {
+		cn.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, errNotOwnerOfCourse.Error())
 	}
 
 	if course.Status != "progress" {
-		log.Println("course status does not progress")
+		cn.logger.Warn(c.UserContext(), "course status does not progress")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "course status does not progress")
 	}
 
 	date, err := util.CheckStringData(req.Date, "date")
 	if err != nil {
-		log.Println(err)
+		cn.logger.Warn(c.UserContext(), "override check name", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	cn.logger.Debug(c.UserContext(), "check name date", "date", date)
+	tDate, err := util.ParseDate(date)
+	if err != nil {
+		cn.logger.Warn(c.UserContext(), "override check name", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "date"+util.ErrValueInvalid.Error())
+	}
+
+	studentId, err := util.CheckStringData(req.StudentId, "student_id")
+	if err != nil {
+		cn.logger.Warn(c.UserContext(), "override check name", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	cn.logger.Debug(c.UserContext(), "student id", "student_id", studentId)
+
+	status, err := util.CheckStringData(req.Status, "status")
+	if err != nil {
+		cn.logger.Warn(c.UserContext(), "override check name", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	cn.logger.Debug(c.UserContext(), "status", "status", status)
+
+	if status != "attend" && status != "late" && status != "absent" && status != "leave" {
+		cn.logger.Warn(c.UserContext(), "status invalid")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ReturnErrorStatusInvalid("status", "attend,late,absent,leave").Error())
+	}
+
+	note, err := util.CheckStringData(req.Note, "note")
+	if err != nil {
+		cn.logger.Warn(c.UserContext(), "override check name", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("check name date:", date)
+	cn.logger.Debug(c.UserContext(), "note", "note", note)
 
-	chcekName, err := cn.checkNameRepository.GetByFilter(bson.M{"course_id": courseId, "date": date})
+	timeStart, err := slotTimeStart(date, req.TimeStart)
 	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
+		cn.logger.Warn(c.UserContext(), "override check name", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "time_start"+util.ErrValueInvalid.Error())
+	}
+
+	chcekName, err := getCheckNameOfSlot(c.UserContext(), cn.checkNameRepository, courseId, tDate, timeStart)
+	if err != nil {
+		cn.logger.Error(c.UserContext(), "override check name", "error", err)
+		if errors.Is(err, util.ErrNotFound) {
+			return util.ResponseNotSuccess(c, fiber.StatusNotFound, "date "+util.ErrNotFound.Error())
 		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		return util.ResponseError(c, err)
 	}
 
-	if chcekName.Status != "progress" {
-		log.Println("this date not in progress")
-		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "this date not in progress")
+	security.AuditBefore(c, repository.CheckNameCollection, chcekName.Id.Hex(), chcekName)
+	t := time.Now()
+	previous := ""
+	var overridden models.CheckNameData
+	check := true
+	for i, v := range chcekName.CheckNameData {
+		if v.StudentId == studentId {
+			chcekName.CheckNameData[i].History = append(chcekName.CheckNameData[i].History, models.CheckNameHistory{
+				UpdatedAt: v.UpdatedAt,
+				Time:      v.Time,
+				Status:    v.Status,
+				CheckBy:   v.CheckBy,
+				Note:      v.Note,
+				EditBy:    user.ProfileId,
+				EditRole:  user.Role,
+				EditedAt:  t,
+			})
+			chcekName.CheckNameData[i].UpdatedAt = t
+			if v.Time == nil {
+				chcekName.CheckNameData[i].Time = &t
+			}
+			chcekName.CheckNameData[i].Status = status
+			chcekName.CheckNameData[i].CheckBy = user.Role
+			chcekName.CheckNameData[i].Note = &note
+			previous = v.Status
+			overridden = chcekName.CheckNameData[i]
+			check = false
+			break
+		}
+	}
+
+	if check {
+		cn.logger.Warn(c.UserContext(), "student id not found in check name")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "student id not found in check name")
+	}
+
+	result, err := cn.checkNameRepository.Update(c.UserContext(), chcekName)
+	if err != nil {
+		cn.logger.Error(c.UserContext(), "override check name", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.CheckNameCollection, chcekName.Id.Hex(), chcekName)
+
+	if previous != status {
+		publishCheckName(cn.hub, course, chcekName, overridden)
+		sendNotification(c.UserContext(), cn.logger, cn.notificationRepo, cn.hub, newStudentNotification(c.UserContext(), cn.logger, cn.profileRepo, studentId, "check_name", "attendance updated", course.Name+" on "+date+" changed to "+status+": "+note, chcekName.Id.Hex()))
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "override check name success", map[string]interface{}{
+		"check_name_id": chcekName.Id,
+		"update_count":  result.ModifiedCount,
+	})
+}
+
+// mark student that does not check as absent or leave and end the check name
+func (cn *checkNameController) endCheckName(ctx context.Context, course *models.Course, chcekName *models.CheckName) (*mongo.UpdateResult, error) {
+	// student with approved leave on this date is leave instead of absent
+	leaveBy := map[string]string{}
+	leaveRequests, err := cn.leaveRequestRepo.GetByFilterAll(ctx, bson.M{
+		"student_id": bson.M{"$in": course.StudentIdList},
+		"status":     "approved",
+		"date_start": bson.M{"$lte": util.FormatDate(chcekName.Date)},
+		"date_end":   bson.M{"$gte": util.FormatDate(chcekName.Date)},
+	})
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		return nil, err
+	}
+	for _, v := range leaveRequests {
+		leaveBy[v.StudentId] = v.ApproveBy
 	}
 
 	t := time.Now()
+	absentIdList := []string{}
+	absentTime := t.Format(checkTimeLayout)
+	ended := map[string]struct{}{}
 	// for _, v := range course.StudentIdList {
 	// 	check := true
 	for i, d := range chcekName.CheckNameData {
 		if d.Status == "" {
-			chcekName.CheckNameData[i].UpdatedAt = t.Format(time.RFC3339)
-			chcekName.CheckNameData[i].Time = strings.Split(strings.Split(t.Format(time.RFC3339), "T")[1], "+")[0]
+			ended[d.StudentId] = struct{}{}
+			chcekName.CheckNameData[i].UpdatedAt = t
+			chcekName.CheckNameData[i].Time = &t
+			if approveBy, ok := leaveBy[d.StudentId]; ok {
+				chcekName.CheckNameData[i].Status = "leave"
+				chcekName.CheckNameData[i].CheckBy = approveBy
+				continue
+			}
 			chcekName.CheckNameData[i].Status = "absent"
 			chcekName.CheckNameData[i].CheckBy = "server"
+			absentIdList = append(absentIdList, d.StudentId)
 		}
 		// if v == d.StudentId {
 		// 	check = false
@@ -535,7 +820,7 @@ func (cn *checkNameController) EndDateCheckName(c *fiber.Ctx) error {
 	// if check {
 	// 	chcekName.CheckNameData = append(chcekName.CheckNameData, models.CheckNameData{
 	// 		StudentId: v,
-	// 		UpdatedAt: t.Format(time.RFC3339),
+	// 		UpdatedAt: t,
 	// 		Time:      strings.Split(strings.Split(t.Format(time.RFC3339), "T")[1], "+")[0],
 	// 		Status:    "absent",
 	// 		CheckBy:   "server",
@@ -545,19 +830,100 @@ func (cn *checkNameController) EndDateCheckName(c *fiber.Ctx) error {
 
 	chcekName.Status = "end"
 
-	result, err := cn.checkNameRepository.Update(chcekName)
+	result, err := cn.checkNameRepository.Update(ctx, chcekName)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		return nil, err
 	}
 
-	return util.ResponseSuccess(c, fiber.StatusCreated, "check name success", map[string]interface{}{
+	for _, d := range chcekName.CheckNameData {
+		if _, ok := ended[d.StudentId]; ok {
+			publishCheckName(cn.hub, course, chcekName, d)
+		}
+	}
+
+	date := util.FormatDate(chcekName.Date)
+	notifications := []*models.Notification{
+		newNotification(course.InstructorId, "teacher", "check_name", "check name ended", fmt.Sprintf("%s on %s ended with %d absent", course.Name, date, len(absentIdList)), chcekName.Id.Hex()),
+	}
+	alerts := []*notifier.Alert{}
+	for _, studentId := range absentIdList {
+		notifications = append(notifications, newNotification(studentId, "student", "check_name", "absent", "absent from "+course.Name+" on "+date, chcekName.Id.Hex()))
+		alerts = append(alerts, newAttendanceAlerts(ctx, cn.logger, cn.profileRepo, cn.classRepo, studentId, "absent", course.Name, date, absentTime, chcekName.Id.Hex())...)
+	}
+	sendNotification(ctx, cn.logger, cn.notificationRepo, cn.hub, append(notifications, newAlertNotifications(alerts)...))
+	sendAlert(ctx, cn.logger, cn.alertNotifier, alerts)
+
+	return result, nil
+}
+
+// every status change is push, attend has no notification but client still show it live
+func publishCheckName(hub realtime.Hub, course *models.Course, chcekName *models.CheckName, data models.CheckNameData) {
+	event := map[string]interface{}{
 		"check_name_id": chcekName.Id,
-		"update_count":  result.ModifiedCount,
-	})
+		"course_id":     course.Id,
+		"date":          util.FormatDate(chcekName.Date),
+		"student_id":    data.StudentId,
+		"status":        data.Status,
+		"time":          data.Time,
+		"check_by":      data.CheckBy,
+	}
+	hub.PublishToProfile("student", data.StudentId, "check_name", event)
+	hub.PublishToProfile("teacher", course.InstructorId, "check_name", event)
 }
 
-func createCheckNameData(studentIdList []string, t string) []models.CheckNameData {
+// time start "15:04" of lesson at date, nil when it is not set
+func slotTimeStart(date string, timeStart string) (*time.Time, error) {
+	if timeStart == "" {
+		return nil, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02 15:04", date+" "+timeStart, time.Local)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// check name of lesson that start at timeStart, without timeStart it is the one in progress of the date
+// or the last lesson when every check name of the date is end
+func getCheckNameOfSlot(ctx context.Context, checkNameRepo repository.CheckNameRepository, courseId string, tDate time.Time, timeStart *time.Time) (*models.CheckName, error) {
+	if timeStart != nil {
+		return checkNameRepo.GetByFilter(ctx, bson.M{"course_id": courseId, "date": tDate, "time_start": *timeStart})
+	}
+
+	checkNameList, err := checkNameRepo.GetByFilterAll(ctx, bson.M{"course_id": courseId, "date": tDate})
+	if err != nil {
+		return nil, err
+	}
+
+	var found *models.CheckName
+	for _, v := range checkNameList {
+		if found == nil ||
+			(v.Status == "progress" && found.Status != "progress") ||
+			((v.Status == "progress") == (found.Status == "progress") && v.TimeStart.After(found.TimeStart)) {
+			found = v
+		}
+	}
+
+	return found, nil
+}
+
+func newCheckName(course *models.Course, date time.Time, timeStart time.Time, timeLate time.Time, t time.Time) *models.CheckName {
+	return &models.CheckName{
+		Id:            primitive.NewObjectID(),
+		CreatedAt:     t,
+		UpdatedAt:     t,
+		CourseId:      course.Id.Hex(),
+		Date:          date,
+		TimeStart:     timeStart,
+		TimeLate:      timeLate,
+		Status:        "progress",
+		CheckNameData: createCheckNameData(course.StudentIdList, t),
+	}
+}
+
+func createCheckNameData(studentIdList []string, t time.Time) []models.CheckNameData {
 
 	var res []models.CheckNameData
 	for _, s := range studentIdList {
@@ -569,3 +935,100 @@ func createCheckNameData(studentIdList []string, t string) []models.CheckNameDat
 
 	return res
 }
+
+func newCheckNameStudentResList(checkNameList []*models.CheckName, studentId string) []models.CheckNameStudentRes {
+	checkNameListRes := []models.CheckNameStudentRes{}
+	for _, v := range checkNameList {
+		for _, check := range v.CheckNameData {
+			if check.StudentId == studentId {
+				checkNameListRes = append(checkNameListRes, models.CheckNameStudentRes{
+					Date:      v.Date,
+					UpdatedAt: check.UpdatedAt,
+					Time:      check.Time,
+					Status:    check.Status,
+					CheckBy:   check.CheckBy,
+				})
+				break
+			}
+		}
+	}
+
+	return checkNameListRes
+}
+
+// alert for parent and class advisor of the student
+func newAttendanceAlerts(ctx context.Context, logger logger.Logger, profileRepo repository.ProfileRepository, classRepo repository.ClassRepository, studentId string, alertType string, courseName string, date string, checkTime string, refId string) []*notifier.Alert {
+	alerts := []*notifier.Alert{}
+
+	p, err := profileRepo.GetProfileById(ctx, bson.M{"profile_id": studentId, "role": "student"}, "student")
+	if err != nil {
+		logger.Warn(ctx, "new attendance alerts", "error", err)
+		return alerts
+	}
+	student := p.(models.ProfileStudent)
+
+	newAlert := func(profileId string, role string, email string, phone string) *notifier.Alert {
+		return &notifier.Alert{
+			Type:        alertType,
+			ProfileId:   profileId,
+			Role:        role,
+			Email:       email,
+			Phone:       phone,
+			StudentId:   studentId,
+			StudentName: student.Name,
+			CourseName:  courseName,
+			Date:        date,
+			Time:        checkTime,
+			RefId:       refId,
+		}
+	}
+
+	if student.ParentId != "" {
+		pp, err := profileRepo.GetProfileById(ctx, bson.M{"profile_id": student.ParentId, "role": "parent"}, "parent")
+		if err != nil {
+			logger.Error(ctx, "new attendance alerts", "error", err)
+		} else {
+			parent := pp.(models.ProfileParent)
+			alerts = append(alerts, newAlert(parent.ProfileId, parent.Role, parent.Email, parent.Phone))
+		}
+	}
+
+	class, err := classRepo.GetClassById(ctx, student.ClassId)
+	if err != nil {
+		logger.Warn(ctx, "new attendance alerts", "error", err)
+		return alerts
+	}
+
+	if class.AdvisorId != "" {
+		pt, err := profileRepo.GetProfileById(ctx, bson.M{"profile_id": class.AdvisorId, "role": "teacher"}, "teacher")
+		if err != nil {
+			logger.Error(ctx, "new attendance alerts", "error", err)
+		} else {
+			advisor := pt.(models.ProfileTeacher)
+			alerts = append(alerts, newAlert(advisor.ProfileId, advisor.Role, advisor.Email, advisor.Phone))
+		}
+	}
+
+	return alerts
+}
+
+// alert is send in background by notifier, alert that can not be queue is logged and does not fail the caller
+// alert wait for free space of queue until ctx is done, alert that can not be queue is log with its detail
+func sendAlert(ctx context.Context, logger logger.Logger, n notifier.Notifier, alerts []*notifier.Alert) {
+	for _, alert := range alerts {
+		err := n.Notify(ctx, alert)
+		if err != nil {
+			logger.Error(ctx, "queue alert failed", "type", alert.Type, "profile_id", alert.ProfileId, "role", alert.Role, "student_id", alert.StudentId, "ref_id", alert.RefId, "error", err)
+		}
+	}
+}
+
+// in app notification of alert is send with other notification of the event, notifier send only outside of app
+func newAlertNotifications(alerts []*notifier.Alert) []*models.Notification {
+	notifications := []*models.Notification{}
+	for _, v := range alerts {
+		notifications = append(notifications, newNotification(v.ProfileId, v.Role, "check_name", v.Title(), v.Message(), v.RefId))
+	}
+
+	return notifications
+}
@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"school-notification-backend/models"
+	"school-notification-backend/util"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// source of check name that scheduler open, only this check name is end by scheduler
+const checkNameSourceScheduler = "scheduler"
+
+// create check name at each lesson time of the day and end it after endAfter
+func (cn *checkNameController) RunCheckNameScheduler(interval time.Duration, timeLate time.Duration, endAfter time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ctx := context.Background()
+	for t := range ticker.C {
+		cn.createScheduledCheckName(ctx, t, timeLate, endAfter)
+		cn.endScheduledCheckName(ctx, t, endAfter)
+	}
+}
+
+func (cn *checkNameController) createScheduledCheckName(ctx context.Context, now time.Time, timeLate time.Duration, endAfter time.Duration) {
+	date := util.FormatDate(now)
+
+	holiday, err := isHoliday(ctx, cn.schoolDataRepo, date)
+	if err != nil {
+		cn.logger.Warn(ctx, "check name scheduler", "error", err)
+		return
+	}
+	if holiday {
+		return
+	}
+
+	// check name date is midnight of the day
+	tDate, err := util.ParseDate(date)
+	if err != nil {
+		cn.logger.Error(ctx, "check name scheduler", "error", err)
+		return
+	}
+
+	weekDay := strings.ToLower(now.Weekday().String())
+	courses, err := cn.courseRepo.GetCourseAllByFilter(ctx, bson.M{"status": "progress", "date_time.day": weekDay})
+	if err != nil {
+		if !errors.Is(err, util.ErrNotFound) {
+			cn.logger.Error(ctx, "check name scheduler", "error", err)
+		}
+		return
+	}
+
+	for _, course := range courses {
+		schoolDay := false
+		for _, start := range lessonStartTimes(course, date) {
+			if now.Before(start) || !now.Before(start.Add(endAfter)) {
+				continue
+			}
+
+			if !schoolDay {
+				err = checkSchoolDay(ctx, cn.schoolDataRepo, course.Year, course.Term, date)
+				if err != nil {
+					if err != errDateOutOfTerm {
+						cn.logger.Error(ctx, "check name scheduler", "error", err)
+					}
+					break
+				}
+				schoolDay = true
+			}
+
+			// lesson that already has check name, by scheduler or teacher, is skip
+			_, err = cn.checkNameRepository.GetByFilter(ctx, bson.M{"course_id": course.Id.Hex(), "date": tDate, "time_start": start})
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, util.ErrNotFound) {
+				cn.logger.Warn(ctx, "check name scheduler", "error", err)
+				continue
+			}
+
+			checkName := newCheckName(course, tDate, start, start.Add(timeLate), now)
+			checkName.Source = checkNameSourceScheduler
+			_, err = cn.checkNameRepository.Insert(ctx, checkName)
+			if err != nil {
+				cn.logger.Warn(ctx, "check name scheduler", "error", err)
+				continue
+			}
+			cn.logger.Debug(ctx, "check name scheduler create", "course_id", course.Id.Hex(), "date", date, "time_start", start)
+		}
+	}
+}
+
+func (cn *checkNameController) endScheduledCheckName(ctx context.Context, now time.Time, endAfter time.Duration) {
+	// backfill check name of teacher is end by teacher
+	checkNameList, err := cn.checkNameRepository.GetByFilterAll(ctx, bson.M{"status": "progress", "source": checkNameSourceScheduler})
+	if err != nil {
+		if !errors.Is(err, util.ErrNotFound) {
+			cn.logger.Error(ctx, "check name scheduler", "error", err)
+		}
+		return
+	}
+
+	for _, checkName := range checkNameList {
+		course, err := cn.courseRepo.GetCourseById(ctx, checkName.CourseId)
+		if err != nil {
+			cn.logger.Warn(ctx, "check name scheduler", "error", err)
+			continue
+		}
+
+		if course.Status != "progress" {
+			continue
+		}
+
+		if now.Before(checkName.TimeStart.Add(endAfter)) {
+			continue
+		}
+
+		_, err = cn.endCheckName(ctx, course, checkName)
+		if err != nil {
+			cn.logger.Warn(ctx, "check name scheduler", "error", err)
+			continue
+		}
+		cn.logger.Debug(ctx, "check name scheduler end", "course_id", course.Id.Hex(), "date", checkName.Date)
+	}
+}
+
+// first time slot of the course in weekday of date
+func lessonStartTime(course *models.Course, date string) (time.Time, bool) {
+	starts := lessonStartTimes(course, date)
+	if len(starts) == 0 {
+		return time.Time{}, false
+	}
+
+	return starts[0], true
+}
+
+// every time slot of the course in weekday of date in order, same time is once
+func lessonStartTimes(course *models.Course, date string) []time.Time {
+	tDate, err := util.ParseDate(date)
+	if err != nil {
+		return nil
+	}
+	weekDay := strings.ToLower(tDate.Weekday().String())
+
+	starts := []time.Time{}
+	for _, dt := range course.DateTime {
+		if dt.Day != weekDay {
+			continue
+		}
+		for _, v := range dt.Time {
+			start, err := time.ParseInLocation("2006-01-02 15:04", date+" "+v, time.Local)
+			if err != nil {
+				continue
+			}
+
+			exists := false
+			for _, s := range starts {
+				if s.Equal(start) {
+					exists = true
+					break
+				}
+			}
+			if !exists {
+				starts = append(starts, start)
+			}
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	return starts
+}
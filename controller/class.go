@@ -1,8 +1,9 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/repository"
 	"school-notification-backend/security"
@@ -14,7 +15,6 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type ClassController interface {
@@ -30,40 +30,33 @@ type classController struct {
 	classRepo            repository.ClassRepository
 	schoolDataRepository repository.SchoolDataRepository
 	profileRepo          repository.ProfileRepository
-	userRepo             repository.UsersRepository
 	faceDetectionRepo    repository.FaceDetectionRepository
+	logger               logger.Logger
 }
 
-func NewClassController(classRepo repository.ClassRepository, schoolDataRepository repository.SchoolDataRepository, profileRepo repository.ProfileRepository, userRepo repository.UsersRepository, faceDetectionRepo repository.FaceDetectionRepository) ClassController {
-	return &classController{classRepo: classRepo, schoolDataRepository: schoolDataRepository, profileRepo: profileRepo, userRepo: userRepo, faceDetectionRepo: faceDetectionRepo}
+func NewClassController(classRepo repository.ClassRepository, schoolDataRepository repository.SchoolDataRepository, profileRepo repository.ProfileRepository, faceDetectionRepo repository.FaceDetectionRepository, logger logger.Logger) ClassController {
+	return &classController{classRepo: classRepo, schoolDataRepository: schoolDataRepository, profileRepo: profileRepo, faceDetectionRepo: faceDetectionRepo, logger: logger}
 }
 
 func (cl *classController) CreateClass(c *fiber.Ctx) error {
-
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cl.userRepo, []string{"admin"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
-	num, err := cl.classRepo.GetCountOfClassYear("1")
-	if err != nil && err.Error() != "mongo: no documents in result" {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+	num, err := cl.classRepo.GetCountOfClassYear(c.UserContext(), "1")
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		cl.logger.Error(c.UserContext(), "create class", "error", err)
+		return util.ResponseError(c, err)
 	}
 
-	dataList, err := cl.schoolDataRepository.GetByFilterAll(bson.M{"type": "YearAndTerm"})
+	dataList, err := cl.schoolDataRepository.GetByFilterAll(c.UserContext(), bson.M{"type": "YearAndTerm"})
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cl.logger.Error(c.UserContext(), "create class", "error", err)
+		return util.ResponseError(c, err)
 	}
 
 	sort.Slice(dataList, func(i, j int) bool {
-		return dataList[i].CreatedAt > dataList[j].CreatedAt
+		return dataList[i].CreatedAt.After(dataList[j].CreatedAt)
 	})
 
 	if *dataList[0].Status == true {
-		log.Println("school data invalid")
+		cl.logger.Error(c.UserContext(), "school data invalid")
 		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, "school data invalid")
 	}
 
@@ -72,8 +65,8 @@ func (cl *classController) CreateClass(c *fiber.Ctx) error {
 
 	classNew := &models.ClassData{
 		Id:        primitive.NewObjectID(),
-		CreatedAt: time.Now().Format(time.RFC3339),
-		UpdatedAt: time.Now().Format(time.RFC3339),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 		ClassYear: "1",
 		ClassRoom: fmt.Sprint(num + 1),
 		Status:    false,
@@ -82,16 +75,17 @@ func (cl *classController) CreateClass(c *fiber.Ctx) error {
 		Slot:      createTimeSlot(),
 	}
 
-	class, err := cl.classRepo.Insert(classNew)
+	class, err := cl.classRepo.Insert(c.UserContext(), classNew)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cl.logger.Error(c.UserContext(), "create class", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.ClassCollection, classNew.Id.Hex(), classNew)
 
 	dataNew := &models.FaceDetectData{
 		Id:                   primitive.NewObjectID(),
-		CreatedAt:            time.Now().Format(time.RFC3339),
-		UpdatedAt:            time.Now().Format(time.RFC3339),
+		CreatedAt:            time.Now(),
+		UpdatedAt:            time.Now(),
 		Status:               "not",
 		Name:                 classNew.ClassYear + "/" + classNew.ClassRoom,
 		ClassId:              classNew.Id.Hex(),
@@ -101,11 +95,12 @@ func (cl *classController) CreateClass(c *fiber.Ctx) error {
 		ImageStudentPathList: createEmptyImageList(classNew.NumberOfStudent),
 	}
 
-	_, err = cl.faceDetectionRepo.Insert(dataNew)
+	_, err = cl.faceDetectionRepo.Insert(c.UserContext(), dataNew)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cl.logger.Error(c.UserContext(), "create class", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.FaceDetectionCollection, dataNew.Id.Hex(), dataNew)
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "create class success", map[string]interface{}{
 		"class_id": class.InsertedID,
@@ -113,30 +108,21 @@ func (cl *classController) CreateClass(c *fiber.Ctx) error {
 }
 
 func (cl *classController) GetClassAllByClassYear(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cl.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	classYear, err := util.CheckStringData(c.Query("class_year"), "class_year")
 	if err != nil {
-		log.Println(err)
+		cl.logger.Warn(c.UserContext(), "get class all by class year", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("class year:", classYear)
+	cl.logger.Debug(c.UserContext(), "class year", "class_year", classYear)
 
-	classes, err := cl.classRepo.GetClassByFilterAll(bson.M{"class_year": classYear})
+	classes, err := cl.classRepo.GetClassByFilterAll(c.UserContext(), bson.M{"class_year": classYear})
 	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cl.logger.Error(c.UserContext(), "get class all by class year", "error", err)
+		return err
 	}
 
 	if len(classes) == 0 {
-		log.Println("class not found")
+		cl.logger.Warn(c.UserContext(), "class not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -147,33 +133,21 @@ func (cl *classController) GetClassAllByClassYear(c *fiber.Ctx) error {
 
 func (cl *classController) GetClassById(c *fiber.Ctx) error {
 
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cl.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	id, err := util.CheckStringData(c.Query("class_id"), "class_id")
 	if err != nil {
-		log.Println(err)
+		cl.logger.Warn(c.UserContext(), "get class by id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find class id:", id)
+	cl.logger.Debug(c.UserContext(), "find class id", "id", id)
 
-	class, err := cl.classRepo.GetClassById(id)
+	class, err := cl.classRepo.GetClassById(c.UserContext(), id)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cl.logger.Error(c.UserContext(), "get class by id", "error", err)
+		return err
 	}
 
 	if class == nil {
-		log.Println("class not found")
+		cl.logger.Warn(c.UserContext(), "class not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -183,40 +157,28 @@ func (cl *classController) GetClassById(c *fiber.Ctx) error {
 }
 
 func (cl *classController) GetClassByClassYearAndRoom(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cl.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	classYear, err := util.CheckStringData(c.Query("class_year"), "class_year")
 	if err != nil {
-		log.Println(err)
+		cl.logger.Warn(c.UserContext(), "get class by class year and room", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find class year:", classYear)
+	cl.logger.Debug(c.UserContext(), "find class year", "class_year", classYear)
 
 	classRoom, err := util.CheckStringData(c.Query("class_room"), "class_room")
 	if err != nil {
-		log.Println(err)
+		cl.logger.Warn(c.UserContext(), "get class by class year and room", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find class room:", classRoom)
+	cl.logger.Debug(c.UserContext(), "find class room", "class_room", classRoom)
 
-	class, err := cl.classRepo.GetClassByFilter(bson.M{"class_year": classYear, "class_room": classRoom, "status": false})
+	class, err := cl.classRepo.GetClassByFilter(c.UserContext(), bson.M{"class_year": classYear, "class_room": classRoom, "status": false})
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cl.logger.Error(c.UserContext(), "get class by class year and room", "error", err)
+		return err
 	}
 
 	if class == nil {
-		log.Println("class not found")
+		cl.logger.Warn(c.UserContext(), "class not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -226,16 +188,10 @@ func (cl *classController) GetClassByClassYearAndRoom(c *fiber.Ctx) error {
 }
 
 func (cl *classController) SetAdvisor(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cl.userRepo, []string{"admin"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.ClassRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		cl.logger.Warn(c.UserContext(), "set advisor", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -246,73 +202,65 @@ func (cl *classController) SetAdvisor(c *fiber.Ctx) error {
 
 	id, err := util.CheckStringData(req.ClassId, "class_id")
 	if err != nil {
-		log.Println(err)
+		cl.logger.Warn(c.UserContext(), "set advisor", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find class id:", id)
+	cl.logger.Debug(c.UserContext(), "find class id", "id", id)
 
-	class, err := cl.classRepo.GetClassById(id)
+	class, err := cl.classRepo.GetClassById(c.UserContext(), id)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cl.logger.Error(c.UserContext(), "set advisor", "error", err)
+		return err
 	}
 
 	if class.AdvisorId != "" {
-		log.Println("class has advisor")
+		cl.logger.Warn(c.UserContext(), "class has advisor")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "class has advisor")
 	}
 
 	advisorId, err := util.CheckStringData(req.AdvisorId, "advisor_id")
 	if err != nil {
-		log.Println(err)
+		cl.logger.Warn(c.UserContext(), "set advisor", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find advisord id:", advisorId)
+	cl.logger.Debug(c.UserContext(), "find advisord id", "advisor_id", advisorId)
 
 	filter := bson.M{
 		"profile_id": advisorId,
 		"role":       "teacher",
 	}
 
-	p, err := cl.profileRepo.GetProfileById(filter, "teacher")
+	p, err := cl.profileRepo.GetProfileById(c.UserContext(), filter, "teacher")
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cl.logger.Error(c.UserContext(), "set advisor", "error", err)
+		return err
 	}
 
 	profile, _ := p.(models.ProfileTeacher)
 
 	if profile.ClassInCounseling != "" {
-		log.Println("teacher has class in counseling")
+		cl.logger.Warn(c.UserContext(), "teacher has class in counseling")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "teacher has class in counseling")
 	}
 
+	security.AuditBefore(c, repository.ClassCollection, class.Id.Hex(), class)
+	security.AuditBefore(c, repository.ProfileCollection, profile.Id.Hex(), profile)
 	class.AdvisorId = advisorId
 	profile.ClassInCounseling = class.Id.Hex()
 
-	_, err = cl.profileRepo.Update(profile.Id, profile)
+	_, err = cl.profileRepo.Update(c.UserContext(), profile.Id, profile)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cl.logger.Error(c.UserContext(), "set advisor", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.ProfileCollection, profile.Id.Hex(), profile)
 
-	_, err = cl.classRepo.Update(class)
+	_, err = cl.classRepo.Update(c.UserContext(), class)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cl.logger.Error(c.UserContext(), "set advisor", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.ClassCollection, class.Id.Hex(), class)
 
 	return util.ResponseSuccess(c, fiber.StatusOK, "add success", map[string]interface{}{
 		"class_id":   class.Id,
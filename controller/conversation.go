@@ -1,7 +1,7 @@
 package controller
 
 import (
-	"log"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/repository"
 	"school-notification-backend/security"
@@ -21,24 +21,20 @@ type ConversationController interface {
 type conversationController struct {
 	conversationRepo repository.ConversationRepository
 	profileRepo      repository.ProfileRepository
-	userRepo         repository.UsersRepository
+	logger           logger.Logger
 }
 
-func NewConversationController(conversationRepo repository.ConversationRepository, profileRepo repository.ProfileRepository, userRepo repository.UsersRepository) ConversationController {
-	return &conversationController{conversationRepo: conversationRepo, profileRepo: profileRepo, userRepo: userRepo}
+func NewConversationController(conversationRepo repository.ConversationRepository, profileRepo repository.ProfileRepository, logger logger.Logger) ConversationController {
+	return &conversationController{conversationRepo: conversationRepo, profileRepo: profileRepo, logger: logger}
 }
 
 func (co *conversationController) CreateConversation(c *fiber.Ctx) error {
-	user, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], co.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
+	user := security.GetUser(c)
 
 	req := models.ConversationRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		co.logger.Warn(c.UserContext(), "create conversation", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -49,25 +45,25 @@ func (co *conversationController) CreateConversation(c *fiber.Ctx) error {
 
 	senderId, err := util.CheckStringData(req.SenderId, "sender_id")
 	if err != nil {
-		log.Println(err)
+		co.logger.Warn(c.UserContext(), "create conversation", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("sender id:", senderId)
+	co.logger.Debug(c.UserContext(), "sender id", "sender_id", senderId)
 
 	if user.UserId != senderId {
-		log.Println("not permiistion")
-		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "not permission")
+		co.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
 	}
 
 	receiverId, err := util.CheckStringData(req.ReceiverId, "receiver_id")
 	if err != nil {
-		log.Println(err)
+		co.logger.Warn(c.UserContext(), "create conversation", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("receiver id:", receiverId)
+	co.logger.Debug(c.UserContext(), "receiver id", "receiver_id", receiverId)
 
 	if ok := primitive.IsValidObjectID(senderId); ok == false {
-		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrIdIsNotPrimitiveObjectID.Error())
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrInvalidID.Error())
 	}
 
 	soID, err := primitive.ObjectIDFromHex(senderId)
@@ -75,20 +71,14 @@ func (co *conversationController) CreateConversation(c *fiber.Ctx) error {
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
 
-	err = co.profileRepo.GetProfileByFilterForCheckExists(bson.M{"_id": soID})
+	err = co.profileRepo.GetProfileByFilterForCheckExists(c.UserContext(), bson.M{"_id": soID})
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		co.logger.Error(c.UserContext(), "create conversation", "error", err)
+		return err
 	}
 
 	if ok := primitive.IsValidObjectID(receiverId); ok == false {
-		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrIdIsNotPrimitiveObjectID.Error())
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrInvalidID.Error())
 	}
 
 	roID, err := primitive.ObjectIDFromHex(receiverId)
@@ -96,19 +86,13 @@ func (co *conversationController) CreateConversation(c *fiber.Ctx) error {
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
 
-	err = co.profileRepo.GetProfileByFilterForCheckExists(bson.M{"_id": roID})
+	err = co.profileRepo.GetProfileByFilterForCheckExists(c.UserContext(), bson.M{"_id": roID})
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		co.logger.Error(c.UserContext(), "create conversation", "error", err)
+		return err
 	}
 
-	// _, err = co.conversationRepo.GetByFilter(bson.M{"members": bson.M{
+	// _, err = co.conversationRepo.GetByFilter(c.UserContext(), bson.M{"members": bson.M{
 	// 	"$in": bson.M{
 	// 		"$and": bson.A{
 	// 			senderId,
@@ -127,19 +111,20 @@ func (co *conversationController) CreateConversation(c *fiber.Ctx) error {
 
 	conversationNew := &models.Conversation{
 		Id:        primitive.NewObjectID(),
-		CreatedAt: time.Now().Format(time.RFC3339),
-		UpdatedAt: time.Now().Format(time.RFC3339),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 		Members: []string{
 			senderId,
 			receiverId,
 		},
 	}
 
-	re, err := co.conversationRepo.Insert(conversationNew)
+	re, err := co.conversationRepo.Insert(c.UserContext(), conversationNew)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		co.logger.Error(c.UserContext(), "create conversation", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.ConversationCollection, conversationNew.Id.Hex(), conversationNew)
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "create conversation success", map[string]interface{}{
 		"conversation_id": re.InsertedID,
@@ -147,42 +132,32 @@ func (co *conversationController) CreateConversation(c *fiber.Ctx) error {
 }
 
 func (co *conversationController) GetByUserId(c *fiber.Ctx) error {
-	user, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], co.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
+	user := security.GetUser(c)
 
 	userId, err := util.CheckStringData(c.Query("user_id"), "user_id")
 	if err != nil {
-		log.Println(err)
+		co.logger.Warn(c.UserContext(), "get by user id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find by user id:", userId)
+	co.logger.Debug(c.UserContext(), "find by user id", "user_id", userId)
 
 	if user.UserId != userId {
-		log.Println("not permiistion")
-		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "not permission")
+		co.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
 	}
 
-	conversations, err := co.conversationRepo.GetConversationAllByFilter(bson.M{"members": bson.M{
+	conversations, err := co.conversationRepo.GetConversationAllByFilter(c.UserContext(), bson.M{"members": bson.M{
 		"$in": bson.A{
 			userId,
 		},
 	}})
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		co.logger.Error(c.UserContext(), "get by user id", "error", err)
+		return err
 	}
 
 	if len(conversations) == 0 {
-		log.Println("conversation not found")
+		co.logger.Warn(c.UserContext(), "conversation not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
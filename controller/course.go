@@ -1,7 +1,9 @@
 package controller
 
 import (
-	"log"
+	"errors"
+	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/repository"
 	"school-notification-backend/security"
@@ -12,7 +14,6 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type CourseController interface {
@@ -21,6 +22,7 @@ type CourseController interface {
 	GetCourseByYearAndTerm(c *fiber.Ctx) error
 	FinishCourse(c *fiber.Ctx) error
 	GetCourseById(c *fiber.Ctx) error
+	GetLessonDates(c *fiber.Ctx) error
 }
 
 type courseController struct {
@@ -31,24 +33,19 @@ type courseController struct {
 	classRepo            repository.ClassRepository
 	profileRepo          repository.ProfileRepository
 	courseSummaryRepo    repository.CourseSummaryRepository
-	userRepo             repository.UsersRepository
+	unitOfWork           db.UnitOfWork
+	logger               logger.Logger
 }
 
-func NewCourseController(courseRepo repository.CourseRepository, subjectRepository repository.SubjectRepository, schoolDataRepository repository.SchoolDataRepository, locationRepo repository.LocationRepository, classRepo repository.ClassRepository, profileRepo repository.ProfileRepository, courseSummaryRepo repository.CourseSummaryRepository, userRepo repository.UsersRepository) CourseController {
-	return &courseController{courseRepo: courseRepo, subjectRepository: subjectRepository, schoolDataRepository: schoolDataRepository, locationRepo: locationRepo, classRepo: classRepo, profileRepo: profileRepo, courseSummaryRepo: courseSummaryRepo, userRepo: userRepo}
+func NewCourseController(courseRepo repository.CourseRepository, subjectRepository repository.SubjectRepository, schoolDataRepository repository.SchoolDataRepository, locationRepo repository.LocationRepository, classRepo repository.ClassRepository, profileRepo repository.ProfileRepository, courseSummaryRepo repository.CourseSummaryRepository, unitOfWork db.UnitOfWork, logger logger.Logger) CourseController {
+	return &courseController{courseRepo: courseRepo, subjectRepository: subjectRepository, schoolDataRepository: schoolDataRepository, locationRepo: locationRepo, classRepo: classRepo, profileRepo: profileRepo, courseSummaryRepo: courseSummaryRepo, unitOfWork: unitOfWork, logger: logger}
 }
 
 func (cc *courseController) CreateCourse(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cc.userRepo, []string{"admin"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.CourseRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		cc.logger.Warn(c.UserContext(), "create course", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -57,18 +54,18 @@ func (cc *courseController) CreateCourse(c *fiber.Ctx) error {
 		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
 	}
 
-	dataList, err := cc.schoolDataRepository.GetByFilterAll(bson.M{"type": "YearAndTerm"})
+	dataList, err := cc.schoolDataRepository.GetByFilterAll(c.UserContext(), bson.M{"type": "YearAndTerm"})
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cc.logger.Error(c.UserContext(), "create course", "error", err)
+		return util.ResponseError(c, err)
 	}
 
 	sort.Slice(dataList, func(i, j int) bool {
-		return dataList[i].CreatedAt > dataList[j].CreatedAt
+		return dataList[i].CreatedAt.After(dataList[j].CreatedAt)
 	})
 
 	if *dataList[0].Status == true {
-		log.Println("school data invalid")
+		cc.logger.Error(c.UserContext(), "school data invalid")
 		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, "school data invalid")
 	}
 
@@ -77,27 +74,24 @@ func (cc *courseController) CreateCourse(c *fiber.Ctx) error {
 
 	subjectId, err := util.CheckStringData(req.SubjectId, "subject_id")
 	if err != nil {
-		log.Println(err)
+		cc.logger.Warn(c.UserContext(), "create course", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("subject id:", subjectId)
+	cc.logger.Debug(c.UserContext(), "subject id", "subject_id", subjectId)
 	instructorId, err := util.CheckStringData(req.InstructorId, "instructor_id")
 	if err != nil {
-		log.Println(err)
+		cc.logger.Warn(c.UserContext(), "create course", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("instructor id:", instructorId)
+	cc.logger.Debug(c.UserContext(), "instructor id", "instructor_id", instructorId)
 
-	subject, err := cc.subjectRepository.GetSubjectByFilter(bson.M{"subject_id": subjectId})
+	subject, err := cc.subjectRepository.GetSubjectByFilter(c.UserContext(), bson.M{"subject_id": subjectId})
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
+		cc.logger.Error(c.UserContext(), "create course", "error", err)
+		if errors.Is(err, util.ErrNotFound) {
 			return util.ResponseNotSuccess(c, fiber.StatusNotFound, "subject_id "+util.ErrNotFound.Error())
 		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		return err
 	}
 
 	check := true
@@ -109,67 +103,55 @@ func (cc *courseController) CreateCourse(c *fiber.Ctx) error {
 	}
 
 	if check {
-		log.Println("instructor id not found in subject instructor")
+		cc.logger.Warn(c.UserContext(), "instructor id not found in subject instructor")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "instructor id not found in subject instructor")
 	}
 
 	classId, err := util.CheckStringData(req.ClassId, "class_id")
 	if err != nil {
-		log.Println(err)
+		cc.logger.Warn(c.UserContext(), "create course", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("create profile in class id:", classId)
+	cc.logger.Debug(c.UserContext(), "create profile in class id", "class_id", classId)
 
-	class, err := cc.classRepo.GetClassById(classId)
+	class, err := cc.classRepo.GetClassById(c.UserContext(), classId)
 	if err != nil {
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		return err
 	}
 
 	if class.Status == true {
-		log.Println("class did finish")
+		cc.logger.Warn(c.UserContext(), "class did finish")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "class did finish")
 	}
 
 	locationId, err := util.CheckStringData(req.LocationId, "location_id")
 	if err != nil {
-		log.Println(err)
+		cc.logger.Warn(c.UserContext(), "create course", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("location id:", locationId)
+	cc.logger.Debug(c.UserContext(), "location id", "location_id", locationId)
 
-	location, err := cc.locationRepo.GetLocationByFilter(bson.M{"location_id": req.LocationId})
+	location, err := cc.locationRepo.GetLocationByFilter(c.UserContext(), bson.M{"location_id": req.LocationId})
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cc.logger.Error(c.UserContext(), "create course", "error", err)
+		return err
 	}
 
 	// check create course again
-	_, err = cc.courseRepo.GetCourseByFilter(bson.M{"subject_id": subjectId, "class_id": classId})
+	_, err = cc.courseRepo.GetCourseByFilter(c.UserContext(), bson.M{"subject_id": subjectId, "class_id": classId})
 	if err == nil {
-		log.Println("course data subject and class already exists")
+		cc.logger.Warn(c.UserContext(), "course data subject and class already exists")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "course data subject and class"+util.ErrValueAlreadyExists.Error())
 	}
-	if err.Error() != "mongo: no documents in result" {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+	if !errors.Is(err, util.ErrNotFound) {
+		cc.logger.Error(c.UserContext(), "create course", "error", err)
+		return util.ResponseError(c, err)
 	}
 
 	courseNew := &models.Course{
 		Id:              primitive.NewObjectID(),
-		CreatedAt:       time.Now().Format(time.RFC3339),
-		UpdatedAt:       time.Now().Format(time.RFC3339),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 		Status:          "create",
 		SubjectId:       subjectId,
 		InstructorId:    instructorId,
@@ -186,7 +168,7 @@ func (cc *courseController) CreateCourse(c *fiber.Ctx) error {
 	}
 
 	if len(req.DateTime) == 0 {
-		log.Println(util.ErrRequireParameter.Error() + "date_time")
+		cc.logger.Warn(c.UserContext(), "create course", "error", util.ErrRequireParameter.Error()+"date_time")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"date_time")
 	}
 
@@ -195,19 +177,16 @@ func (cc *courseController) CreateCourse(c *fiber.Ctx) error {
 		"role":       "teacher",
 	}
 
-	p, err := cc.profileRepo.GetProfileById(filter, "teacher")
+	p, err := cc.profileRepo.GetProfileById(c.UserContext(), filter, "teacher")
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cc.logger.Error(c.UserContext(), "create course", "error", err)
+		return err
 	}
 
 	profile, _ := p.(models.ProfileTeacher)
+	security.AuditBefore(c, repository.ProfileCollection, profile.Id.Hex(), profile)
+	security.AuditBefore(c, repository.ClassCollection, class.Id.Hex(), class)
+	security.AuditBefore(c, repository.LocationCollection, location.Id.Hex(), location)
 
 	for i, v := range profile.CourseTeachesList {
 		if v.Term == courseNew.Term && v.Year == courseNew.Year {
@@ -220,31 +199,31 @@ func (cc *courseController) CreateCourse(c *fiber.Ctx) error {
 	for di, dt := range req.DateTime {
 		req.DateTime[di].Day, err = util.CheckStringData(dt.Day, "day")
 		if err != nil {
-			log.Println(err)
+			cc.logger.Warn(c.UserContext(), "create course", "error", err)
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 		}
 		dt.Day = req.DateTime[di].Day
-		log.Println("day:", dt.Day)
+		cc.logger.Debug(c.UserContext(), "day", "day", dt.Day)
 		checkDay := true
 		for i, slot := range class.Slot {
 			if dt.Day == slot.Day {
 				check := true
 				if len(dt.Time) == 0 {
-					log.Println(util.ErrRequireParameter.Error() + "time")
+					cc.logger.Warn(c.UserContext(), "create course", "error", util.ErrRequireParameter.Error()+"time")
 					return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"time")
 				}
 				for dti, t := range dt.Time {
 					for j, ts := range slot.TimeSlot {
 						req.DateTime[di].Time[dti], err = util.CheckStringData(t, "time")
 						if err != nil {
-							log.Println(err)
+							cc.logger.Warn(c.UserContext(), "create course", "error", err)
 							return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 						}
 						t = req.DateTime[di].Time[dti]
-						log.Println("time:", t)
+						cc.logger.Debug(c.UserContext(), "time", "t", t)
 						if ts.Time == t {
 							if ts.Status == true {
-								log.Println("date time is used in class")
+								cc.logger.Warn(c.UserContext(), "date time is used in class")
 								return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "date time is used in this class")
 							}
 							class.Slot[i].TimeSlot[j].Status = true
@@ -254,7 +233,7 @@ func (cc *courseController) CreateCourse(c *fiber.Ctx) error {
 						}
 					}
 					if check {
-						log.Println("time", dt.Time, "is in valid")
+						cc.logger.Warn(c.UserContext(), "time is in valid", "check_time", dt.Time)
 						return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "time "+t+" is in valid")
 					}
 				}
@@ -263,7 +242,7 @@ func (cc *courseController) CreateCourse(c *fiber.Ctx) error {
 			}
 		}
 		if checkDay {
-			log.Println("day", dt.Day, "is in valid")
+			cc.logger.Warn(c.UserContext(), "day is in valid", "day", dt.Day)
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "day "+dt.Day+" is in valid")
 		}
 	}
@@ -276,7 +255,7 @@ func (cc *courseController) CreateCourse(c *fiber.Ctx) error {
 					for j, ts := range slot.TimeSlot {
 						if ts.Time == t {
 							if ts.Status == true {
-								log.Println("date time is used in teacher time lot")
+								cc.logger.Warn(c.UserContext(), "date time is used in teacher time lot")
 								return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "date time is used in teacher time lot")
 							}
 							profile.Slot[i].TimeSlot[j].Status = true
@@ -316,7 +295,7 @@ func (cc *courseController) CreateCourse(c *fiber.Ctx) error {
 						// log.Println("time:", ti)
 						if ts.Time == t {
 							if ts.Status == true {
-								log.Println("date time is used in this location")
+								cc.logger.Warn(c.UserContext(), "date time is used in this location")
 								return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "date time is used in this location")
 							}
 							location.Slot[i].TimeSlot[j].Status = true
@@ -343,61 +322,85 @@ func (cc *courseController) CreateCourse(c *fiber.Ctx) error {
 	// update value
 	courseNew.DateTime = req.DateTime
 
-	_, err = cc.courseRepo.Insert(courseNew)
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
-	}
+	err = doUnitOfWork(c, cc.unitOfWork, func(tx db.Tx) error {
+		ctx := tx.Context()
 
-	_, err = cc.classRepo.Update(class)
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
-	}
+		err := tx.Track(repository.CourseCollection, courseNew.Id)
+		if err != nil {
+			return err
+		}
+		_, err = cc.courseRepo.Insert(ctx, courseNew)
+		if err != nil {
+			return err
+		}
+		security.AuditAfter(c, repository.CourseCollection, courseNew.Id.Hex(), courseNew)
 
-	_, err = cc.profileRepo.Update(profile.Id, profile)
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
-	}
+		err = tx.Track(repository.ClassCollection, class.Id)
+		if err != nil {
+			return err
+		}
+		_, err = cc.classRepo.Update(ctx, class)
+		if err != nil {
+			return err
+		}
+		security.AuditAfter(c, repository.ClassCollection, class.Id.Hex(), class)
 
-	for _, s := range class.StudentIdList {
-		filter := bson.M{
-			"profile_id": s,
-			"role":       "student",
+		err = tx.Track(repository.ProfileCollection, profile.Id)
+		if err != nil {
+			return err
 		}
-		p, err := cc.profileRepo.GetProfileById(filter, "student")
+		_, err = cc.profileRepo.Update(ctx, profile.Id, profile)
 		if err != nil {
-			log.Println(err)
-			if err.Error() == "mongo: no documents in result" {
-				return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
+			return err
+		}
+		security.AuditAfter(c, repository.ProfileCollection, profile.Id.Hex(), profile)
+
+		for _, s := range class.StudentIdList {
+			filter := bson.M{
+				"profile_id": s,
+				"role":       "student",
 			}
-			if err.Error() == "Id is not primitive objectID" {
-				return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+			p, err := cc.profileRepo.GetProfileById(ctx, filter, "student")
+			if err != nil {
+				return err
 			}
-			return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
-		}
-		profile, _ := p.(models.ProfileStudent)
-		for i, v := range profile.TermScore {
-			if v.Term == courseNew.Term && v.Year == courseNew.Year {
-				profile.TermScore[i].CourseList = append(profile.TermScore[i].CourseList, models.CourseList{
-					Id: courseNew.Id,
-				})
-				break
+			profile, _ := p.(models.ProfileStudent)
+			security.AuditBefore(c, repository.ProfileCollection, profile.Id.Hex(), profile)
+			for i, v := range profile.TermScore {
+				if v.Term == courseNew.Term && v.Year == courseNew.Year {
+					profile.TermScore[i].CourseList = append(profile.TermScore[i].CourseList, models.CourseList{
+						Id: courseNew.Id,
+					})
+					break
+				}
+			}
+
+			err = tx.Track(repository.ProfileCollection, profile.Id)
+			if err != nil {
+				return err
 			}
+			_, err = cc.profileRepo.Update(ctx, profile.Id, profile)
+			if err != nil {
+				return err
+			}
+			security.AuditAfter(c, repository.ProfileCollection, profile.Id.Hex(), profile)
 		}
 
-		_, err = cc.profileRepo.Update(profile.Id, profile)
+		err = tx.Track(repository.LocationCollection, location.Id)
 		if err != nil {
-			log.Println(err)
-			return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+			return err
 		}
-	}
+		_, err = cc.locationRepo.Update(ctx, location)
+		if err != nil {
+			return err
+		}
+		security.AuditAfter(c, repository.LocationCollection, location.Id.Hex(), location)
 
-	_, err = cc.locationRepo.Update(location)
+		return nil
+	})
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cc.logger.Error(c.UserContext(), "create course", "error", err)
+		return err
 	}
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "create course success", map[string]interface{}{
@@ -406,16 +409,12 @@ func (cc *courseController) CreateCourse(c *fiber.Ctx) error {
 }
 
 func (cc *courseController) ChangeCourseToProgress(c *fiber.Ctx) error {
-	user, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cc.userRepo, []string{"admin", "teacher"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
+	user := security.GetUser(c)
 
 	req := models.CourseRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		cc.logger.Warn(c.UserContext(), "change course to progress", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -426,70 +425,76 @@ func (cc *courseController) ChangeCourseToProgress(c *fiber.Ctx) error {
 
 	id, err := util.CheckStringData(req.Id, "id")
 	if err != nil {
-		log.Println(err)
+		cc.logger.Warn(c.UserContext(), "change course to progress", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("id:", id)
+	cc.logger.Debug(c.UserContext(), "id", "id", id)
 
-	course, err := cc.courseRepo.GetCourseById(id)
+	course, err := cc.courseRepo.GetCourseById(c.UserContext(), id)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cc.logger.Error(c.UserContext(), "change course to progress", "error", err)
+		return err
+	}
+
+	canManage, err := canManageCourse(c, course)
+	if err != nil {
+		cc.logger.Error(c.UserContext(), "can manage course", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if !canManage {
+		cc.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, errNotOwnerOfCourse.Error())
 	}
 
 	if course.Status != "create" && course.Status != "summary" {
-		log.Println("status invalid")
+		cc.logger.Warn(c.UserContext(), "status invalid")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ReturnErrorStatusInvalid("course", "create").Error())
 	}
+	security.AuditBefore(c, repository.CourseCollection, course.Id.Hex(), course)
 
 	if course.Status == "create" {
 		if user.Role != "admin" {
-			log.Println("role", util.ErrValueInvalid)
+			cc.logger.Warn(c.UserContext(), "role", "error", util.ErrValueInvalid)
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "role"+util.ErrValueInvalid.Error())
 		}
 
 		if course.SubjectId == "" {
-			log.Println("subject id is nil")
+			cc.logger.Warn(c.UserContext(), "subject id is nil")
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "subject id is nil")
 		}
 
 		if course.InstructorId == "" {
-			log.Println("instructor id is nil")
+			cc.logger.Warn(c.UserContext(), "instructor id is nil")
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "instructor id is nil")
 		}
 
 		if course.LocationId == nil {
-			log.Println("location id is nil")
+			cc.logger.Warn(c.UserContext(), "location id is nil")
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "location id is nil")
 		}
 
 		if course.ClassId == nil {
-			log.Println("class id is nil")
+			cc.logger.Warn(c.UserContext(), "class id is nil")
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "class id is nil")
 		}
 
 		course.Status = "progress"
 	} else if course.Status == "summary" && user.Role == "teacher" {
 		if user.Role != "teacher" {
-			log.Println("role", util.ErrValueInvalid)
+			cc.logger.Warn(c.UserContext(), "role", "error", util.ErrValueInvalid)
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "role"+util.ErrValueInvalid.Error())
 		}
 
 		course.Status = "progress"
 	}
 
-	course.UpdatedAt = time.Now().Format(time.RFC3339)
-	courseUpdate, err := cc.courseRepo.Update(course)
+	course.UpdatedAt = time.Now()
+	courseUpdate, err := cc.courseRepo.Update(c.UserContext(), course)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cc.logger.Error(c.UserContext(), "change course to progress", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.CourseCollection, course.Id.Hex(), course)
 
 	return util.ResponseSuccess(c, fiber.StatusOK, "update success", map[string]interface{}{
 		"course_id":           course.Id,
@@ -498,46 +503,36 @@ func (cc *courseController) ChangeCourseToProgress(c *fiber.Ctx) error {
 }
 
 func (cc *courseController) GetCourseByYearAndTerm(c *fiber.Ctx) error {
-	user, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cc.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
+	user := security.GetUser(c)
 
 	year, err := util.CheckStringData(c.Query("year"), "year")
 	if err != nil {
-		log.Println(err)
+		cc.logger.Warn(c.UserContext(), "get course by year and term", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find course of year", year)
+	cc.logger.Debug(c.UserContext(), "find course of year", "year", year)
 	term, err := util.CheckStringData(c.Query("term"), "term")
 	if err != nil {
-		log.Println(err)
+		cc.logger.Warn(c.UserContext(), "get course by year and term", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find course of term", term)
+	cc.logger.Debug(c.UserContext(), "find course of term", "term", term)
 
-	log.Println(user.Role)
+	cc.logger.Debug(c.UserContext(), "get course by year and term", "role", user.Role)
 	var coursesRes interface{}
 	if user.Role == "admin" || user.Role == "server" {
-		courses, err := cc.courseRepo.GetCourseAllByFilter(bson.M{"year": year, "term": term})
+		courses, err := cc.courseRepo.GetCourseAllByFilter(c.UserContext(), bson.M{"year": year, "term": term})
 		if err != nil {
-			log.Println(err)
+			cc.logger.Warn(c.UserContext(), "get course by year and term", "error", err)
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 		}
 
 		coursesRes = courses
 	} else if user.Role == "teacher" {
-		p, err := cc.profileRepo.GetProfileById(bson.M{"profile_id": user.ProfileId, "role": user.Role}, user.Role)
+		p, err := cc.profileRepo.GetProfileById(c.UserContext(), bson.M{"profile_id": user.ProfileId, "role": user.Role}, user.Role)
 		if err != nil {
-			log.Println(err)
-			if err.Error() == "mongo: no documents in result" {
-				return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-			}
-			if err.Error() == "Id is not primitive objectID" {
-				return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-			}
-			return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+			cc.logger.Error(c.UserContext(), "get course by year and term", "error", err)
+			return err
 		}
 
 		profile, _ := p.(models.ProfileTeacher)
@@ -551,15 +546,15 @@ func (cc *courseController) GetCourseByYearAndTerm(c *fiber.Ctx) error {
 		}
 
 		if index == -1 {
-			log.Println("year or term not found")
+			cc.logger.Warn(c.UserContext(), "year or term not found")
 			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
 		}
 
 		courses := []*models.Course{}
 		for _, v := range profile.CourseTeachesList[index].CourseIdList {
-			course, err := cc.courseRepo.GetCourseById(v.Hex())
+			course, err := cc.courseRepo.GetCourseById(c.UserContext(), v.Hex())
 			if err != nil {
-				log.Println(err)
+				cc.logger.Warn(c.UserContext(), "get course by year and term", "error", err)
 				return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 			}
 
@@ -571,16 +566,10 @@ func (cc *courseController) GetCourseByYearAndTerm(c *fiber.Ctx) error {
 
 		coursesRes = courses
 	} else if user.Role == "student" {
-		p, err := cc.profileRepo.GetProfileById(bson.M{"profile_id": user.ProfileId, "role": user.Role}, user.Role)
+		p, err := cc.profileRepo.GetProfileById(c.UserContext(), bson.M{"profile_id": user.ProfileId, "role": user.Role}, user.Role)
 		if err != nil {
-			log.Println(err)
-			if err.Error() == "mongo: no documents in result" {
-				return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-			}
-			if err.Error() == "Id is not primitive objectID" {
-				return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-			}
-			return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+			cc.logger.Error(c.UserContext(), "get course by year and term", "error", err)
+			return err
 		}
 
 		profile, _ := p.(models.ProfileStudent)
@@ -594,15 +583,15 @@ func (cc *courseController) GetCourseByYearAndTerm(c *fiber.Ctx) error {
 		}
 
 		if index == -1 {
-			log.Println("year or term not found")
+			cc.logger.Warn(c.UserContext(), "year or term not found")
 			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
 		}
 
 		courses := []*models.Course{}
 		for _, v := range profile.TermScore[index].CourseList {
-			course, err := cc.courseRepo.GetCourseById(v.Id.Hex())
+			course, err := cc.courseRepo.GetCourseById(c.UserContext(), v.Id.Hex())
 			if err != nil {
-				log.Println(err)
+				cc.logger.Warn(c.UserContext(), "get course by year and term", "error", err)
 				return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 			}
 
@@ -614,7 +603,7 @@ func (cc *courseController) GetCourseByYearAndTerm(c *fiber.Ctx) error {
 
 		coursesRes = courses
 	} else {
-		log.Println("role is invalid")
+		cc.logger.Warn(c.UserContext(), "role is invalid")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "role"+util.ErrValueInvalid.Error())
 	}
 
@@ -624,33 +613,21 @@ func (cc *courseController) GetCourseByYearAndTerm(c *fiber.Ctx) error {
 }
 
 func (cc *courseController) GetCourseById(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cc.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	id, err := util.CheckStringData(c.Query("course_id"), "course_id")
 	if err != nil {
-		log.Println(err)
+		cc.logger.Warn(c.UserContext(), "get course by id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find course id:", id)
+	cc.logger.Debug(c.UserContext(), "find course id", "id", id)
 
-	course, err := cc.courseRepo.GetCourseById(id)
+	course, err := cc.courseRepo.GetCourseById(c.UserContext(), id)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cc.logger.Error(c.UserContext(), "get course by id", "error", err)
+		return err
 	}
 
 	if course == nil {
-		log.Println("course not found")
+		cc.logger.Warn(c.UserContext(), "course not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -659,16 +636,40 @@ func (cc *courseController) GetCourseById(c *fiber.Ctx) error {
 	})
 }
 
-func (cc *courseController) FinishCourse(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cc.userRepo, []string{"admin"})
+func (cc *courseController) GetLessonDates(c *fiber.Ctx) error {
+	id, err := util.CheckStringData(c.Query("course_id"), "course_id")
+	if err != nil {
+		cc.logger.Warn(c.UserContext(), "get lesson dates", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	cc.logger.Debug(c.UserContext(), "find lesson dates of course id", "id", id)
+
+	course, err := cc.courseRepo.GetCourseById(c.UserContext(), id)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
+		cc.logger.Error(c.UserContext(), "get lesson dates", "error", err)
+		return err
+	}
+
+	lessonDateList, err := newLessonDateList(c.UserContext(), cc.schoolDataRepository, course)
+	if err != nil {
+		cc.logger.Error(c.UserContext(), "get lesson dates", "error", err)
+		if err == errTermDateNotSet {
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+		}
+		return err
 	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"course_id":    course.Id,
+		"lesson_dates": lessonDateList,
+	})
+}
+
+func (cc *courseController) FinishCourse(c *fiber.Ctx) error {
 	req := models.CourseRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		cc.logger.Warn(c.UserContext(), "finish course", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -679,52 +680,38 @@ func (cc *courseController) FinishCourse(c *fiber.Ctx) error {
 
 	id, err := util.CheckStringData(req.Id, "id")
 	if err != nil {
-		log.Println(err)
+		cc.logger.Warn(c.UserContext(), "finish course", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("id:", id)
+	cc.logger.Debug(c.UserContext(), "id", "id", id)
 
-	course, err := cc.courseRepo.GetCourseById(id)
+	course, err := cc.courseRepo.GetCourseById(c.UserContext(), id)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cc.logger.Error(c.UserContext(), "finish course", "error", err)
+		return err
 	}
 
 	if course.Status != "summary" {
-		log.Println("status invalid")
+		cc.logger.Warn(c.UserContext(), "status invalid")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ReturnErrorStatusInvalid("course", "summary").Error())
 	}
 
-	log.Println("get course summary")
-	courseSum, err := cc.courseSummaryRepo.GetByFilter(bson.M{"course_id": id})
-	if err != nil && err.Error() != "mongo: no documents in result" {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+	cc.logger.Debug(c.UserContext(), "get course summary")
+	courseSum, err := cc.courseSummaryRepo.GetByFilter(c.UserContext(), bson.M{"course_id": id})
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		cc.logger.Error(c.UserContext(), "finish course", "error", err)
+		return err
 	}
 
 	for _, sData := range courseSum.StudentData {
-		p, err := cc.profileRepo.GetProfileById(bson.M{"profile_id": sData.StudentId, "role": "student"}, "student")
+		p, err := cc.profileRepo.GetProfileById(c.UserContext(), bson.M{"profile_id": sData.StudentId, "role": "student"}, "student")
 		if err != nil {
-			log.Println(err)
-			if err.Error() == "mongo: no documents in result" {
-				return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-			}
-			if err.Error() == "Id is not primitive objectID" {
-				return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-			}
-			return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+			cc.logger.Error(c.UserContext(), "finish course", "error", err)
+			return err
 		}
 
 		profile, _ := p.(models.ProfileStudent)
+		security.AuditBefore(c, repository.ProfileCollection, profile.Id.Hex(), profile)
 
 		for i, t := range profile.TermScore {
 			if t.Year == course.Year && t.Term == course.Term {
@@ -735,7 +722,7 @@ func (cc *courseController) FinishCourse(c *fiber.Ctx) error {
 							profile.TermScore[i].TermCredit -= profile.TermScore[i].CourseList[j].Credit
 							profile.AllCredit -= profile.TermScore[i].CourseList[j].Credit
 						}
-						// profile.TermScore[i].CourseList[j].CreatedAt = time.Now().Format(time.RFC3339)
+						// profile.TermScore[i].CourseList[j].CreatedAt = time.Now()
 						profile.TermScore[i].CourseList[j].Grade = sData.Grade
 						profile.TermScore[i].CourseList[j].ScoreWorkGet = sData.ScoreWorkGet
 						profile.TermScore[i].CourseList[j].ScoreWorkFull = sData.ScoreWorkFull
@@ -747,6 +734,7 @@ func (cc *courseController) FinishCourse(c *fiber.Ctx) error {
 						profile.TermScore[i].CourseList[j].AllDateCount = sData.AllDateCount
 						profile.TermScore[i].CourseList[j].CheckNameAttendCount = sData.CheckNameAttendCount
 						profile.TermScore[i].CourseList[j].CheckNameAbsentCount = sData.CheckNameAbsentCount
+						profile.TermScore[i].CourseList[j].CheckNameLeaveCount = sData.CheckNameLeaveCount
 						profile.TermScore[i].CourseList[j].CheckNameLateCount = sData.CheckNameLateCount
 
 						profile.TermScore[i].TermCredit += course.Credit
@@ -769,38 +757,36 @@ func (cc *courseController) FinishCourse(c *fiber.Ctx) error {
 
 			profile.GPA = totalGrade / float64(profile.AllCredit)
 
-			_, err = cc.profileRepo.Update(profile.Id, profile)
+			_, err = cc.profileRepo.Update(c.UserContext(), profile.Id, profile)
 			if err != nil {
-				log.Println(err)
+				cc.logger.Warn(c.UserContext(), "finish course", "error", err)
 				return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 			}
+			security.AuditAfter(c, repository.ProfileCollection, profile.Id.Hex(), profile)
 
 			break
 		}
 	}
 
+	security.AuditBefore(c, repository.CourseCollection, course.Id.Hex(), course)
 	course.Status = "finish"
-	course.UpdatedAt = time.Now().Format(time.RFC3339)
-	courseUpdate, err := cc.courseRepo.Update(course)
+	course.UpdatedAt = time.Now()
+	courseUpdate, err := cc.courseRepo.Update(c.UserContext(), course)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cc.logger.Error(c.UserContext(), "finish course", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.CourseCollection, course.Id.Hex(), course)
 
 	// update location
-	log.Println("get location")
-	location, err := cc.locationRepo.GetLocationById(course.LocationId.Hex())
+	cc.logger.Debug(c.UserContext(), "get location")
+	location, err := cc.locationRepo.GetLocationById(c.UserContext(), course.LocationId.Hex())
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cc.logger.Error(c.UserContext(), "finish course", "error", err)
+		return err
 	}
 
+	security.AuditBefore(c, repository.LocationCollection, location.Id.Hex(), location)
 	for _, dt := range course.DateTime {
 		for i, slot := range location.Slot {
 			if slot.Day == dt.Day {
@@ -818,11 +804,12 @@ func (cc *courseController) FinishCourse(c *fiber.Ctx) error {
 		}
 	}
 
-	_, err = cc.locationRepo.Update(location)
+	_, err = cc.locationRepo.Update(c.UserContext(), location)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cc.logger.Error(c.UserContext(), "finish course", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.LocationCollection, location.Id.Hex(), location)
 
 	return util.ResponseSuccess(c, fiber.StatusOK, "update success", map[string]interface{}{
 		"course_id":           course.Id,
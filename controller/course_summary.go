@@ -1,8 +1,11 @@
 package controller
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
+	"school-notification-backend/realtime"
 	"school-notification-backend/repository"
 	"school-notification-backend/security"
 	"school-notification-backend/util"
@@ -11,7 +14,6 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type CourseSummaryController interface {
@@ -25,71 +27,74 @@ type courseSummaryController struct {
 	courseRepo          repository.CourseRepository
 	scoreRepository     repository.ScoreRepository
 	checkNameRepository repository.CheckNameRepository
-	userRepo            repository.UsersRepository
 	profileRepo         repository.ProfileRepository
+	classRepo           repository.ClassRepository
+	notificationRepo    repository.NotificationRepository
+	hub                 realtime.Hub
+	logger              logger.Logger
 }
 
-func NewCourseSummaryController(courseSummaryRepo repository.CourseSummaryRepository, courseRepo repository.CourseRepository, scoreRepository repository.ScoreRepository, checkNameRepository repository.CheckNameRepository, userRepo repository.UsersRepository, profileRepo repository.ProfileRepository) CourseSummaryController {
-	return &courseSummaryController{courseSummaryRepo: courseSummaryRepo, courseRepo: courseRepo, scoreRepository: scoreRepository, checkNameRepository: checkNameRepository, userRepo: userRepo, profileRepo: profileRepo}
+func NewCourseSummaryController(courseSummaryRepo repository.CourseSummaryRepository, courseRepo repository.CourseRepository, scoreRepository repository.ScoreRepository, checkNameRepository repository.CheckNameRepository, profileRepo repository.ProfileRepository, classRepo repository.ClassRepository, notificationRepo repository.NotificationRepository, hub realtime.Hub, logger logger.Logger) CourseSummaryController {
+	return &courseSummaryController{courseSummaryRepo: courseSummaryRepo, courseRepo: courseRepo, scoreRepository: scoreRepository, checkNameRepository: checkNameRepository, profileRepo: profileRepo, classRepo: classRepo, notificationRepo: notificationRepo, hub: hub, logger: logger}
 }
 
+// identity from token, student read own summary, advisor and parent read summary of their student
 func (cs *courseSummaryController) GetSummaryCourse(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cs.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
+	user := security.GetUser(c)
 
 	courseId, err := util.CheckStringData(c.Query("course_id"), "course_id")
 	if err != nil {
-		log.Println(err)
+		cs.logger.Warn(c.UserContext(), "get summary course", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find course summary by course id:", courseId)
+	cs.logger.Debug(c.UserContext(), "find course summary by course id", "course_id", courseId)
 
-	role, err := util.CheckStringData(c.Query("role"), "role")
+	course, err := cs.courseRepo.GetCourseById(c.UserContext(), courseId)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+		cs.logger.Error(c.UserContext(), "get summary course", "error", err)
+		return err
 	}
-	log.Println("role:", role)
 
-	courseSum, err := cs.courseSummaryRepo.GetByFilter(bson.M{"course_id": courseId})
+	studentIdList, err := readableStudentIdList(c, cs.profileRepo, cs.classRepo, course)
 	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cs.logger.Error(c.UserContext(), "get summary course", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if studentIdList != nil && len(studentIdList) == 0 {
+		cs.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, errNotOwnerOfCourse.Error())
+	}
+
+	courseSum, err := cs.courseSummaryRepo.GetByFilter(c.UserContext(), bson.M{"course_id": courseId})
+	if err != nil {
+		cs.logger.Error(c.UserContext(), "get summary course", "error", err)
+		return err
 	}
 
 	var res interface{}
-	if role == "teacher" {
+	if studentIdList == nil {
 		res = courseSum.StudentData
-	} else if role == "student" {
-		studentId, err := util.CheckStringData(c.Query("student_id"), "student_id")
-		if err != nil {
-			log.Println(err)
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		log.Println("find course summary by student id:", studentId)
-
+	} else if user.Role == "student" {
 		check := true
 		for _, v := range courseSum.StudentData {
-			if v.StudentId == studentId {
+			if v.StudentId == user.ProfileId {
 				res = v
 				check = false
 				break
 			}
 		}
 		if check {
-			log.Println("not found")
-			return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, util.ErrNotFound.Error())
+			cs.logger.Warn(c.UserContext(), "not found")
+			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
 		}
-
 	} else {
-		log.Println("role", util.ErrValueInvalid)
-		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "role"+util.ErrValueInvalid.Error())
+		studentData := []models.StudentData{}
+		for _, v := range courseSum.StudentData {
+			if isStudentInList(studentIdList, v.StudentId) {
+				studentData = append(studentData, v)
+			}
+		}
+		res = studentData
 	}
 
 	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
@@ -98,36 +103,26 @@ func (cs *courseSummaryController) GetSummaryCourse(c *fiber.Ctx) error {
 }
 
 func (cs *courseSummaryController) StudentGetSummaryCourse(c *fiber.Ctx) error {
-	user, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cs.userRepo, []string{"student"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
+	user := security.GetUser(c)
 
 	year, err := util.CheckStringData(c.Query("year"), "year")
 	if err != nil {
-		log.Println(err)
+		cs.logger.Warn(c.UserContext(), "student get summary course", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("year:", year)
+	cs.logger.Debug(c.UserContext(), "year", "year", year)
 
 	term, err := util.CheckStringData(c.Query("term"), "term")
 	if err != nil {
-		log.Println(err)
+		cs.logger.Warn(c.UserContext(), "student get summary course", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("term:", term)
+	cs.logger.Debug(c.UserContext(), "term", "term", term)
 
-	p, err := cs.profileRepo.GetProfileById(bson.M{"profile_id": user.ProfileId, "role": user.Role}, user.Role)
+	p, err := cs.profileRepo.GetProfileById(c.UserContext(), bson.M{"profile_id": user.ProfileId, "role": user.Role}, user.Role)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cs.logger.Error(c.UserContext(), "student get summary course", "error", err)
+		return err
 	}
 
 	profile, _ := p.(models.ProfileStudent)
@@ -141,15 +136,15 @@ func (cs *courseSummaryController) StudentGetSummaryCourse(c *fiber.Ctx) error {
 	}
 
 	if index == -1 {
-		log.Println("year or term not found")
+		cs.logger.Warn(c.UserContext(), "year or term not found")
 		return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
 	}
 
 	courses := []*models.Course{}
 	for _, v := range profile.TermScore[index].CourseList {
-		course, err := cs.courseRepo.GetCourseById(v.Id.Hex())
+		course, err := cs.courseRepo.GetCourseById(c.UserContext(), v.Id.Hex())
 		if err != nil {
-			log.Println(err)
+			cs.logger.Warn(c.UserContext(), "student get summary course", "error", err)
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 		}
 
@@ -158,9 +153,9 @@ func (cs *courseSummaryController) StudentGetSummaryCourse(c *fiber.Ctx) error {
 
 	var courseSumList []*models.CourseSummary
 	for _, v := range courses {
-		courseSum, err := cs.courseSummaryRepo.GetByFilter(bson.M{"course_id": v.Id.Hex()})
+		courseSum, err := cs.courseSummaryRepo.GetByFilter(c.UserContext(), bson.M{"course_id": v.Id.Hex()})
 		if err != nil {
-			log.Println(err)
+			cs.logger.Warn(c.UserContext(), "student get summary course", "error", err)
 			continue
 		}
 
@@ -172,36 +167,19 @@ func (cs *courseSummaryController) StudentGetSummaryCourse(c *fiber.Ctx) error {
 	for _, d := range courseSumList {
 		for _, data := range d.StudentData {
 			if data.StudentId == user.ProfileId {
-				course, err := cs.courseRepo.GetCourseById(d.CourseId)
+				course, err := cs.courseRepo.GetCourseById(c.UserContext(), d.CourseId)
 				if err != nil {
-					log.Println(err)
-					if err == mongo.ErrNoDocuments {
-						return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-					}
-					return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
-				}
-				dataRes := models.StudentDataRes{
-					CourseName:           course.Name,
-					ScoreWorkGet:         data.ScoreWorkGet,
-					ScoreWorkFull:        data.ScoreWorkFull,
-					ScoreMidGet:          data.ScoreMidGet,
-					ScoreMidFull:         data.ScoreMidFull,
-					ScoreFinalGet:        data.ScoreFinalGet,
-					ScoreFinaFull:        data.ScoreFinaFull,
-					Grade:                data.Grade,
-					AllDateCount:         data.AllDateCount,
-					CheckNameAttendCount: data.CheckNameAttendCount,
-					CheckNameAbsentCount: data.CheckNameAbsentCount,
-					CheckNameLateCount:   data.CheckNameLateCount,
+					cs.logger.Error(c.UserContext(), "student get summary course", "error", err)
+					return err
 				}
-				dataList = append(dataList, dataRes)
+				dataList = append(dataList, newStudentDataRes(course.Name, data))
 				break
 			}
 		}
 	}
 
 	if len(dataList) == 0 {
-		log.Println(util.ErrNotFound)
+		cs.logger.Warn(c.UserContext(), "student get summary course", "error", util.ErrNotFound)
 		return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
 	}
 
@@ -211,16 +189,10 @@ func (cs *courseSummaryController) StudentGetSummaryCourse(c *fiber.Ctx) error {
 }
 
 func (cs *courseSummaryController) SummaryCourse(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], cs.userRepo, []string{"admin", "teacher"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.CourseSummaryRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		cs.logger.Warn(c.UserContext(), "summary course", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -231,59 +203,66 @@ func (cs *courseSummaryController) SummaryCourse(c *fiber.Ctx) error {
 
 	courseId, err := util.CheckStringData(req.CourseId, "course_id")
 	if err != nil {
-		log.Println(err)
+		cs.logger.Warn(c.UserContext(), "summary course", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("course id:", courseId)
+	cs.logger.Debug(c.UserContext(), "course id", "course_id", courseId)
 
-	course, err := cs.courseRepo.GetCourseById(courseId)
+	course, err := cs.courseRepo.GetCourseById(c.UserContext(), courseId)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
+		cs.logger.Error(c.UserContext(), "summary course", "error", err)
+		if errors.Is(err, util.ErrNotFound) {
 			return util.ResponseNotSuccess(c, fiber.StatusNotFound, "course_id "+util.ErrNotFound.Error())
 		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		return err
+	}
+
+	canManage, err := canManageCourse(c, course)
+	if err != nil {
+		cs.logger.Error(c.UserContext(), "can manage course", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if !canManage {
+		cs.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, errNotOwnerOfCourse.Error())
 	}
 
 	if course.Status != "progress" {
-		log.Println("course status does not progress")
+		cs.logger.Warn(c.UserContext(), "course status does not progress")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "course status does not progress")
 	}
 
-	log.Println("get scores")
-	scores, err := cs.scoreRepository.GetByFilterAll(bson.M{"course_id": courseId})
-	if err != nil && err != mongo.ErrNoDocuments {
-		log.Println(err)
+	cs.logger.Debug(c.UserContext(), "get scores")
+	scores, err := cs.scoreRepository.GetByFilterAll(c.UserContext(), bson.M{"course_id": courseId})
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		cs.logger.Error(c.UserContext(), "summary course", "error", err)
 		// if err == mongo.ErrNoDocuments {
 		// 	return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
 		// }
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		return util.ResponseError(c, err)
 	}
 
-	log.Println("get check name list")
-	checkNameList, err := cs.checkNameRepository.GetByFilterAll(bson.M{"course_id": courseId})
-	if err != nil && err != mongo.ErrNoDocuments {
-		log.Println(err)
+	cs.logger.Debug(c.UserContext(), "get check name list")
+	checkNameList, err := cs.checkNameRepository.GetByFilterAll(c.UserContext(), bson.M{"course_id": courseId})
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		cs.logger.Error(c.UserContext(), "summary course", "error", err)
 		// if err == mongo.ErrNoDocuments {
 		// 	return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
 		// }
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		return util.ResponseError(c, err)
 	}
 
-	log.Println("check summary")
-	courseSum, err := cs.courseSummaryRepo.GetByFilter(bson.M{"course_id": courseId})
-	if err != nil && err.Error() != "mongo: no documents in result" {
-		log.Println(err)
+	cs.logger.Debug(c.UserContext(), "check summary")
+	courseSum, err := cs.courseSummaryRepo.GetByFilter(c.UserContext(), bson.M{"course_id": courseId})
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		cs.logger.Error(c.UserContext(), "summary course", "error", err)
 		// if err == mongo.ErrNoDocuments {
 		// 	return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
 		// }
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		return util.ResponseError(c, err)
 	}
 
-	t := time.Now().Format(time.RFC3339)
+	t := time.Now()
 	var courseSummary models.CourseSummary
 	if courseSum == nil {
 		courseSummary = models.CourseSummary{
@@ -314,7 +293,7 @@ func (cs *courseSummaryController) SummaryCourse(c *fiber.Ctx) error {
 			} else if s.Type == "final" {
 				scoreFinalFull += s.ScoreFull
 			} else {
-				log.Println(util.ErrTypeInvalid)
+				cs.logger.Warn(c.UserContext(), "summary course", "error", util.ErrTypeInvalid)
 				return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrTypeInvalid.Error())
 			}
 			for _, sData := range s.ScoreInformation {
@@ -335,7 +314,7 @@ func (cs *courseSummaryController) SummaryCourse(c *fiber.Ctx) error {
 						}
 						break
 					} else {
-						log.Println(util.ErrTypeInvalid)
+						cs.logger.Warn(c.UserContext(), "summary course", "error", util.ErrTypeInvalid)
 						return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrTypeInvalid.Error())
 					}
 				}
@@ -369,6 +348,7 @@ func (cs *courseSummaryController) SummaryCourse(c *fiber.Ctx) error {
 		totalDate := 0
 		totalDateAttend := 0
 		totalDateAbsent := 0
+		totalDateLeave := 0
 		totalDateLate := 0
 		for _, checkName := range checkNameList {
 			totalDate++
@@ -380,11 +360,14 @@ func (cs *courseSummaryController) SummaryCourse(c *fiber.Ctx) error {
 					} else if cData.Status == "absent" {
 						totalDateAbsent++
 						break
+					} else if cData.Status == "leave" {
+						totalDateLeave++
+						break
 					} else if cData.Status == "late" || cData.Status == "" { // ต้องไม่ได้
 						totalDateLate++
 						break
 					} else {
-						log.Println(util.ErrTypeInvalid)
+						cs.logger.Warn(c.UserContext(), "summary course", "error", util.ErrTypeInvalid)
 						return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrTypeInvalid.Error())
 					}
 				}
@@ -403,6 +386,7 @@ func (cs *courseSummaryController) SummaryCourse(c *fiber.Ctx) error {
 			AllDateCount:         totalDate,
 			CheckNameAttendCount: totalDateAttend,
 			CheckNameAbsentCount: totalDateAbsent,
+			CheckNameLeaveCount:  totalDateLeave,
 			CheckNameLateCount:   totalDateLate,
 		}
 
@@ -410,28 +394,56 @@ func (cs *courseSummaryController) SummaryCourse(c *fiber.Ctx) error {
 	}
 
 	if courseSum == nil {
-		_, err = cs.courseSummaryRepo.Insert(&courseSummary)
+		_, err = cs.courseSummaryRepo.Insert(c.UserContext(), &courseSummary)
 		if err != nil {
-			log.Println(err)
-			return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+			cs.logger.Error(c.UserContext(), "summary course", "error", err)
+			return util.ResponseError(c, err)
 		}
 	} else {
-		_, err = cs.courseSummaryRepo.Update(&courseSummary)
+		security.AuditBefore(c, repository.CourseSummaryCollection, courseSum.Id.Hex(), courseSum)
+		_, err = cs.courseSummaryRepo.Update(c.UserContext(), &courseSummary)
 		if err != nil {
-			log.Println(err)
-			return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+			cs.logger.Error(c.UserContext(), "summary course", "error", err)
+			return util.ResponseError(c, err)
 		}
 	}
+	security.AuditAfter(c, repository.CourseSummaryCollection, courseSummary.Id.Hex(), courseSummary)
 
+	security.AuditBefore(c, repository.CourseCollection, course.Id.Hex(), course)
 	course.Status = "summary"
-	course.UpdatedAt = time.Now().Format(time.RFC3339)
-	_, err = cs.courseRepo.Update(course)
+	course.UpdatedAt = time.Now()
+	_, err = cs.courseRepo.Update(c.UserContext(), course)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		cs.logger.Error(c.UserContext(), "summary course", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.CourseCollection, course.Id.Hex(), course)
+
+	notifications := []*models.Notification{}
+	for _, sData := range courseSummary.StudentData {
+		notifications = append(notifications, newStudentNotification(c.UserContext(), cs.logger, cs.profileRepo, sData.StudentId, "course_summary", "grade published", fmt.Sprintf("%s grade: %v", course.Name, sData.Grade), courseSummary.Id.Hex())...)
+	}
+	sendNotification(c.UserContext(), cs.logger, cs.notificationRepo, cs.hub, notifications)
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "create courseSummary success", map[string]interface{}{
 		"course_summary_id": courseSummary.Id,
 	})
 }
+
+func newStudentDataRes(courseName string, data models.StudentData) models.StudentDataRes {
+	return models.StudentDataRes{
+		CourseName:           courseName,
+		ScoreWorkGet:         data.ScoreWorkGet,
+		ScoreWorkFull:        data.ScoreWorkFull,
+		ScoreMidGet:          data.ScoreMidGet,
+		ScoreMidFull:         data.ScoreMidFull,
+		ScoreFinalGet:        data.ScoreFinalGet,
+		ScoreFinaFull:        data.ScoreFinaFull,
+		Grade:                data.Grade,
+		AllDateCount:         data.AllDateCount,
+		CheckNameAttendCount: data.CheckNameAttendCount,
+		CheckNameAbsentCount: data.CheckNameAbsentCount,
+		CheckNameLeaveCount:  data.CheckNameLeaveCount,
+		CheckNameLateCount:   data.CheckNameLateCount,
+	}
+}
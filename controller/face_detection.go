@@ -1,8 +1,10 @@
 package controller
 
 import (
-	"log"
+	"context"
+	"errors"
 	"net/http"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/repository"
 	"school-notification-backend/security"
@@ -12,7 +14,6 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type FaceDetectionController interface {
@@ -28,61 +29,51 @@ type FaceDetectionController interface {
 type faceDetectionController struct {
 	faceDetectionRepo repository.FaceDetectionRepository
 	classRepo         repository.ClassRepository
-	userRepo          repository.UsersRepository
+	logger            logger.Logger
 }
 
-func NewFaceDetectionController(faceDetectionRepo repository.FaceDetectionRepository, classRepo repository.ClassRepository, userRepo repository.UsersRepository) FaceDetectionController {
-	return &faceDetectionController{faceDetectionRepo: faceDetectionRepo, classRepo: classRepo, userRepo: userRepo}
+func NewFaceDetectionController(faceDetectionRepo repository.FaceDetectionRepository, classRepo repository.ClassRepository, logger logger.Logger) FaceDetectionController {
+	return &faceDetectionController{faceDetectionRepo: faceDetectionRepo, classRepo: classRepo, logger: logger}
 }
 
 func (f *faceDetectionController) OpenCamera(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], f.userRepo, []string{"admin", "teacher"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	classId, err := util.CheckStringData(c.Query("class_id"), "class_id")
 	if err != nil {
-		log.Println(err)
+		f.logger.Warn(c.UserContext(), "open camera", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("class id:", classId)
+	f.logger.Debug(c.UserContext(), "class id", "class_id", classId)
 
 	courseId, err := util.CheckStringData(c.Query("course_id"), "course_id")
 	if err != nil {
-		log.Println(err)
+		f.logger.Warn(c.UserContext(), "open camera", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("course id:", courseId)
+	f.logger.Debug(c.UserContext(), "course id", "course_id", courseId)
 
-	log.Println("run camera")
+	f.logger.Debug(c.UserContext(), "run camera")
+	// request context is cancel when handler return, keep only request id
+	ctx := logger.WithRequestId(context.Background(), logger.RequestId(c.UserContext()))
 	go func() {
-		log.Println("call")
+		f.logger.Debug(ctx, "call")
 		_, err := http.Get("http://localhost:8000/cv?class_id=" + classId + "&course_id=" + courseId)
 		if err != nil {
 			return
 		}
-		log.Println("close camera")
+		f.logger.Debug(ctx, "close camera")
 	}()
 
-	log.Println("call success")
+	f.logger.Debug(c.UserContext(), "call success")
 	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
 		"success": true,
 	})
 }
 
 func (f *faceDetectionController) CreatFaceDetectionData(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], f.userRepo, []string{"admin"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.FaceDetectDataRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		f.logger.Warn(c.UserContext(), "creat face detection data", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -93,37 +84,31 @@ func (f *faceDetectionController) CreatFaceDetectionData(c *fiber.Ctx) error {
 
 	classId, err := util.CheckStringData(req.ClassId, "class_id")
 	if err != nil {
-		log.Println(err)
+		f.logger.Warn(c.UserContext(), "creat face detection data", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("class id:", classId)
+	f.logger.Debug(c.UserContext(), "class id", "class_id", classId)
 
-	_, err = f.faceDetectionRepo.GetByFilter(bson.M{"class_id": classId})
+	_, err = f.faceDetectionRepo.GetByFilter(c.UserContext(), bson.M{"class_id": classId})
 	if err == nil {
-		log.Println("data for class id already exists")
+		f.logger.Warn(c.UserContext(), "data for class id already exists")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "data for class"+util.ErrValueAlreadyExists.Error())
 	}
-	if err.Error() != "mongo: no documents in result" {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+	if !errors.Is(err, util.ErrNotFound) {
+		f.logger.Error(c.UserContext(), "creat face detection data", "error", err)
+		return util.ResponseError(c, err)
 	}
 
-	class, err := f.classRepo.GetClassById(classId)
+	class, err := f.classRepo.GetClassById(c.UserContext(), classId)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		f.logger.Error(c.UserContext(), "creat face detection data", "error", err)
+		return err
 	}
 
 	dataNew := &models.FaceDetectData{
 		Id:                   primitive.NewObjectID(),
-		CreatedAt:            time.Now().Format(time.RFC3339),
-		UpdatedAt:            time.Now().Format(time.RFC3339),
+		CreatedAt:            time.Now(),
+		UpdatedAt:            time.Now(),
 		Status:               "not",
 		Name:                 class.ClassYear + "/" + class.ClassRoom,
 		ClassId:              classId,
@@ -133,11 +118,12 @@ func (f *faceDetectionController) CreatFaceDetectionData(c *fiber.Ctx) error {
 		ImageStudentPathList: createEmptyImageList(class.NumberOfStudent),
 	}
 
-	data, err := f.faceDetectionRepo.Insert(dataNew)
+	data, err := f.faceDetectionRepo.Insert(c.UserContext(), dataNew)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		f.logger.Error(c.UserContext(), "creat face detection data", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.FaceDetectionCollection, dataNew.Id.Hex(), dataNew)
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "create face detection data success", map[string]interface{}{
 		"data_id": data.InsertedID,
@@ -145,16 +131,10 @@ func (f *faceDetectionController) CreatFaceDetectionData(c *fiber.Ctx) error {
 }
 
 func (f *faceDetectionController) UploadImageData(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], f.userRepo, []string{"admin", "server"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.FaceDetectDataRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		f.logger.Warn(c.UserContext(), "upload image data", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -165,29 +145,28 @@ func (f *faceDetectionController) UploadImageData(c *fiber.Ctx) error {
 
 	id, err := util.CheckStringData(req.Id, "id")
 	if err != nil {
-		log.Println(err)
+		f.logger.Warn(c.UserContext(), "upload image data", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("id:", id)
+	f.logger.Debug(c.UserContext(), "id", "id", id)
 
-	data, err := f.faceDetectionRepo.GetById(id)
+	data, err := f.faceDetectionRepo.GetById(c.UserContext(), id)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		f.logger.Error(c.UserContext(), "upload image data", "error", err)
+		return err
+	}
+
+	if !security.ApiKeyAllowClass(security.GetApiKey(c), data.ClassId) {
+		f.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
 	}
 
 	studentId, err := util.CheckStringData(req.StudentId, "student_id")
 	if err != nil {
-		log.Println(err)
+		f.logger.Warn(c.UserContext(), "upload image data", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("student id:", studentId)
+	f.logger.Debug(c.UserContext(), "student id", "student_id", studentId)
 
 	index := -1
 	for i, v := range data.StudentIdList {
@@ -198,15 +177,16 @@ func (f *faceDetectionController) UploadImageData(c *fiber.Ctx) error {
 	}
 
 	if index == -1 {
-		log.Println("not found student")
+		f.logger.Warn(c.UserContext(), "not found student")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error()+" student")
 	}
 
 	if len(req.ImagePathList) == 0 {
-		log.Println(util.ReturnError(util.ErrRequireParameter.Error() + "image_path_list"))
+		f.logger.Warn(c.UserContext(), "upload image data", "error", util.ReturnError(util.ErrRequireParameter.Error()+"image_path_list"))
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"image_path_list")
 	}
 
+	security.AuditBefore(c, repository.FaceDetectionCollection, data.Id.Hex(), data)
 	for _, v := range req.ImagePathList {
 		data.ImageStudentPathList[index] = append(data.ImageStudentPathList[index], v)
 	}
@@ -214,11 +194,12 @@ func (f *faceDetectionController) UploadImageData(c *fiber.Ctx) error {
 	data.NumberOfImage += len(req.ImagePathList)
 	data.Status = "not"
 
-	_, err = f.faceDetectionRepo.Update(data)
+	_, err = f.faceDetectionRepo.Update(c.UserContext(), data)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		f.logger.Error(c.UserContext(), "upload image data", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.FaceDetectionCollection, data.Id.Hex(), data)
 
 	return util.ResponseSuccess(c, fiber.StatusOK, "update success", map[string]interface{}{
 		"data_id": data.Id,
@@ -226,16 +207,10 @@ func (f *faceDetectionController) UploadImageData(c *fiber.Ctx) error {
 }
 
 func (f *faceDetectionController) ModelTrained(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], f.userRepo, []string{"admin", "server"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.FaceDetectDataRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		f.logger.Warn(c.UserContext(), "model trained", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -246,59 +221,62 @@ func (f *faceDetectionController) ModelTrained(c *fiber.Ctx) error {
 
 	id, err := util.CheckStringData(req.Id, "id")
 	if err != nil {
-		log.Println(err)
+		f.logger.Warn(c.UserContext(), "model trained", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("id:", id)
+	f.logger.Debug(c.UserContext(), "id", "id", id)
 
-	data, err := f.faceDetectionRepo.GetById(id)
+	data, err := f.faceDetectionRepo.GetById(c.UserContext(), id)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		f.logger.Error(c.UserContext(), "model trained", "error", err)
+		return err
+	}
+
+	if !security.ApiKeyAllowClass(security.GetApiKey(c), data.ClassId) {
+		f.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
 	}
 
 	classId, err := util.CheckStringData(req.ClassId, "class_id")
 	if err != nil {
-		log.Println(err)
+		f.logger.Warn(c.UserContext(), "model trained", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("class id:", classId)
+	f.logger.Debug(c.UserContext(), "class id", "class_id", classId)
 
 	if classId != data.ClassId {
-		log.Println("class id not match")
+		f.logger.Warn(c.UserContext(), "class id not match")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "class id not match")
 	}
 
+	security.AuditBefore(c, repository.FaceDetectionCollection, data.Id.Hex(), data)
 	data.Status = "progress"
 
+	// request context is cancel when handler return, keep only request id
+	ctx := logger.WithRequestId(context.Background(), logger.RequestId(c.UserContext()))
 	go func() {
-		log.Println("call")
+		f.logger.Debug(ctx, "call")
 		_, err := http.Post("http://localhost:8000/train-model?class_id="+classId, "application/json", nil)
 		if err != nil {
 			return
 		}
 		data.Status = "yes"
-		data.UpdatedAt = time.Now().Format(time.RFC3339)
-		log.Println("finish")
-		_, err = f.faceDetectionRepo.Update(data)
+		data.UpdatedAt = time.Now()
+		f.logger.Debug(ctx, "finish")
+		_, err = f.faceDetectionRepo.Update(ctx, data)
 		if err != nil {
-			log.Println(err)
+			f.logger.Warn(ctx, "model trained", "error", err)
 			return
 		}
 	}()
 
-	data.UpdatedAt = time.Now().Format(time.RFC3339)
-	_, err = f.faceDetectionRepo.Update(data)
+	data.UpdatedAt = time.Now()
+	_, err = f.faceDetectionRepo.Update(c.UserContext(), data)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		f.logger.Error(c.UserContext(), "model trained", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.FaceDetectionCollection, data.Id.Hex(), data)
 
 	return util.ResponseSuccess(c, fiber.StatusOK, "update success", map[string]interface{}{
 		"data_id": data.Id,
@@ -306,23 +284,24 @@ func (f *faceDetectionController) ModelTrained(c *fiber.Ctx) error {
 }
 
 func (f *faceDetectionController) GetAll(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], f.userRepo, []string{"admin", "server"})
+	datas, err := f.faceDetectionRepo.GetAll(c.UserContext())
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
+		f.logger.Error(c.UserContext(), "get all", "error", err)
+		return err
 	}
 
-	datas, err := f.faceDetectionRepo.GetAll()
-	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
+	// api key with class restriction see only its class
+	apiKey := security.GetApiKey(c)
+	dataList := []*models.FaceDetectData{}
+	for _, v := range datas {
+		if security.ApiKeyAllowClass(apiKey, v.ClassId) {
+			dataList = append(dataList, v)
 		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
 	}
+	datas = dataList
 
 	if len(datas) == 0 {
-		log.Println("data not found")
+		f.logger.Warn(c.UserContext(), "data not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -332,34 +311,22 @@ func (f *faceDetectionController) GetAll(c *fiber.Ctx) error {
 }
 
 func (f *faceDetectionController) GetById(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], f.userRepo, []string{"admin", "server", "teacher"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	id, err := util.CheckStringData(c.Query("id"), "id")
 	if err != nil {
-		log.Println(err)
+		f.logger.Warn(c.UserContext(), "get by id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find id:", id)
+	f.logger.Debug(c.UserContext(), "find id", "id", id)
 
-	data, err := f.faceDetectionRepo.GetById(id)
+	data, err := f.faceDetectionRepo.GetById(c.UserContext(), id)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		f.logger.Error(c.UserContext(), "get by id", "error", err)
+		return err
 	}
 
-	if data == nil {
-		log.Println("data not found")
-		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
+	if !security.ApiKeyAllowClass(security.GetApiKey(c), data.ClassId) {
+		f.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
 	}
 
 	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
@@ -368,33 +335,26 @@ func (f *faceDetectionController) GetById(c *fiber.Ctx) error {
 }
 
 func (f *faceDetectionController) GetByClassId(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], f.userRepo, []string{"admin", "server", "teacher"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	classId, err := util.CheckStringData(c.Query("class_id"), "class_id")
 	if err != nil {
-		log.Println(err)
+		f.logger.Warn(c.UserContext(), "get by class id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("class id:", classId)
+	f.logger.Debug(c.UserContext(), "class id", "class_id", classId)
 
-	data, err := f.faceDetectionRepo.GetByFilter(bson.M{"class_id": classId})
+	if !security.ApiKeyAllowClass(security.GetApiKey(c), classId) {
+		f.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
+	}
+
+	data, err := f.faceDetectionRepo.GetByFilter(c.UserContext(), bson.M{"class_id": classId})
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		f.logger.Error(c.UserContext(), "get by class id", "error", err)
+		return err
 	}
 
 	if data == nil {
-		log.Println("data not found")
+		f.logger.Warn(c.UserContext(), "data not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -2,9 +2,10 @@ package controller
 
 import (
 	"fmt"
-	"log"
 	"os"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
+	"school-notification-backend/realtime"
 	"school-notification-backend/repository"
 	"school-notification-backend/security"
 	"school-notification-backend/util"
@@ -12,7 +13,6 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type InformationController interface {
@@ -23,53 +23,50 @@ type InformationController interface {
 }
 
 type informationController struct {
-	infoRepo repository.InformationRepository
-	userRepo repository.UsersRepository
+	infoRepo         repository.InformationRepository
+	userRepo         repository.UsersRepository
+	notificationRepo repository.NotificationRepository
+	hub              realtime.Hub
+	logger           logger.Logger
 }
 
-func NewInformationController(infoRepo repository.InformationRepository, userRepo repository.UsersRepository) InformationController {
-	return &informationController{infoRepo: infoRepo, userRepo: userRepo}
+func NewInformationController(infoRepo repository.InformationRepository, userRepo repository.UsersRepository, notificationRepo repository.NotificationRepository, hub realtime.Hub, logger logger.Logger) InformationController {
+	return &informationController{infoRepo: infoRepo, userRepo: userRepo, notificationRepo: notificationRepo, hub: hub, logger: logger}
 }
 
 func (i *informationController) CreateInformation(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], i.userRepo, []string{"admin"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	name, err := util.CheckStringData(c.FormValue("name"), "name")
 	if err != nil {
-		log.Println(err)
+		i.logger.Warn(c.UserContext(), "create information", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("name:", name)
+	i.logger.Debug(c.UserContext(), "name", "name", name)
 
 	description, err := util.CheckStringData(c.FormValue("description"), "description")
 	if err != nil {
-		log.Println(err)
+		i.logger.Warn(c.UserContext(), "create information", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("description:", description)
+	i.logger.Debug(c.UserContext(), "description", "description", description)
 
 	content, err := util.CheckStringData(c.FormValue("content"), "content")
 	if err != nil {
-		log.Println(err)
+		i.logger.Warn(c.UserContext(), "create information", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("content:", content)
+	i.logger.Debug(c.UserContext(), "content", "content", content)
 
 	category, err := util.CheckStringData(c.FormValue("category"), "category")
 	if err != nil {
-		log.Println(err)
+		i.logger.Warn(c.UserContext(), "create information", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("category:", category)
+	i.logger.Debug(c.UserContext(), "category", "category", category)
 
 	imageUrl := ""
 	file, err := c.FormFile("file")
 	if err == nil {
-		log.Println("file type:", file.Header.Get("Content-Type"))
+		i.logger.Debug(c.UserContext(), "file type", "file_type", file.Header.Get("Content-Type"))
 
 		if _, err := os.Stat("./storage"); os.IsNotExist(err) {
 			err = os.Mkdir("./storage", 0777)
@@ -83,7 +80,7 @@ func (i *informationController) CreateInformation(c *fiber.Ctx) error {
 
 		err = c.SaveFile(file, fmt.Sprintf("./storage/information/%s", filename))
 		if err != nil {
-			log.Println("file save error --> ", err)
+			i.logger.Error(c.UserContext(), "file save error", "error", err)
 			value, ok := err.(*fiber.Error)
 			if ok {
 				return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -97,8 +94,8 @@ func (i *informationController) CreateInformation(c *fiber.Ctx) error {
 
 	informationNew := &models.Information{
 		Id:          primitive.NewObjectID(),
-		CreatedAt:   time.Now().Format(time.RFC3339),
-		UpdatedAt:   time.Now().Format(time.RFC3339),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 		Name:        name,
 		Description: description,
 		Content:     content,
@@ -106,75 +103,78 @@ func (i *informationController) CreateInformation(c *fiber.Ctx) error {
 		FilePath:    imageUrl,
 	}
 
-	information, err := i.infoRepo.Insert(informationNew)
+	information, err := i.infoRepo.Insert(c.UserContext(), informationNew)
+	if err != nil {
+		i.logger.Error(c.UserContext(), "create information", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.InformationCollection, informationNew.Id.Hex(), informationNew)
+
+	users, err := i.userRepo.GetAll(c.UserContext())
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		i.logger.Error(c.UserContext(), "create information", "error", err)
 	}
 
+	notifications := []*models.Notification{}
+	for _, u := range users {
+		if u.Role == "admin" {
+			continue
+		}
+		notifications = append(notifications, newNotification(u.ProfileId, u.Role, "information", name, description, informationNew.Id.Hex()))
+	}
+	sendNotification(c.UserContext(), i.logger, i.notificationRepo, i.hub, notifications)
+
 	return util.ResponseSuccess(c, fiber.StatusCreated, "create information success", map[string]interface{}{
 		"information_id": information.InsertedID,
 	})
 }
 
 func (i *informationController) UpdateInformation(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], i.userRepo, []string{"admin"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	id, err := util.CheckStringData(c.FormValue("id"), "id")
 	if err != nil {
-		log.Println(err)
+		i.logger.Warn(c.UserContext(), "update information", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("id:", id)
+	i.logger.Debug(c.UserContext(), "id", "id", id)
 
-	information, err := i.infoRepo.GetInformationById(id)
+	information, err := i.infoRepo.GetInformationById(c.UserContext(), id)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		i.logger.Error(c.UserContext(), "update information", "error", err)
+		return err
 	}
 
 	name, err := util.CheckStringData(c.FormValue("name"), "name")
 	if err != nil {
-		log.Println(err)
+		i.logger.Warn(c.UserContext(), "update information", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("name:", name)
+	i.logger.Debug(c.UserContext(), "name", "name", name)
 
 	description, err := util.CheckStringData(c.FormValue("description"), "description")
 	if err != nil {
-		log.Println(err)
+		i.logger.Warn(c.UserContext(), "update information", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("description:", description)
+	i.logger.Debug(c.UserContext(), "description", "description", description)
 
 	content, err := util.CheckStringData(c.FormValue("content"), "content")
 	if err != nil {
-		log.Println(err)
+		i.logger.Warn(c.UserContext(), "update information", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("content:", content)
+	i.logger.Debug(c.UserContext(), "content", "content", content)
 
 	category, err := util.CheckStringData(c.FormValue("category"), "category")
 	if err != nil {
-		log.Println(err)
+		i.logger.Warn(c.UserContext(), "update information", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("category:", category)
+	i.logger.Debug(c.UserContext(), "category", "category", category)
 
 	imageUrl := ""
 	file, err := c.FormFile("file")
 	if err == nil {
-		log.Println("file type:", file.Header.Get("Content-Type"))
+		i.logger.Debug(c.UserContext(), "file type", "file_type", file.Header.Get("Content-Type"))
 
 		if _, err := os.Stat("./storage"); os.IsNotExist(err) {
 			err = os.Mkdir("./storage", 0777)
@@ -188,7 +188,7 @@ func (i *informationController) UpdateInformation(c *fiber.Ctx) error {
 
 		err = c.SaveFile(file, fmt.Sprintf("./storage/information/%s", filename))
 		if err != nil {
-			log.Println("file save error --> ", err)
+			i.logger.Error(c.UserContext(), "file save error", "error", err)
 			value, ok := err.(*fiber.Error)
 			if ok {
 				return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -200,7 +200,8 @@ func (i *informationController) UpdateInformation(c *fiber.Ctx) error {
 		imageUrl = fmt.Sprintf("/files/information/%s", filename)
 	}
 
-	information.UpdatedAt = time.Now().Format(time.RFC3339)
+	security.AuditBefore(c, repository.InformationCollection, information.Id.Hex(), information)
+	information.UpdatedAt = time.Now()
 	information.Name = name
 	information.Description = description
 	information.Content = content
@@ -209,11 +210,12 @@ func (i *informationController) UpdateInformation(c *fiber.Ctx) error {
 		information.FilePath = imageUrl
 	}
 
-	informationUpdate, err := i.infoRepo.Update(information)
+	informationUpdate, err := i.infoRepo.Update(c.UserContext(), information)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		i.logger.Error(c.UserContext(), "update information", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.InformationCollection, information.Id.Hex(), information)
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "update information success", map[string]interface{}{
 		"information_id": information.Id,
@@ -222,23 +224,14 @@ func (i *informationController) UpdateInformation(c *fiber.Ctx) error {
 }
 
 func (i *informationController) GetInformationAll(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], i.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
-	infos, err := i.infoRepo.GetAll()
+	infos, err := i.infoRepo.GetAll(c.UserContext())
 	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		i.logger.Error(c.UserContext(), "get information all", "error", err)
+		return err
 	}
 
 	if len(infos) == 0 {
-		log.Println("Information not found")
+		i.logger.Warn(c.UserContext(), "information not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -248,33 +241,21 @@ func (i *informationController) GetInformationAll(c *fiber.Ctx) error {
 }
 
 func (i *informationController) GetInformationById(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], i.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	id, err := util.CheckStringData(c.Query("id"), "id")
 	if err != nil {
-		log.Println(err)
+		i.logger.Warn(c.UserContext(), "get information by id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find information id:", id)
+	i.logger.Debug(c.UserContext(), "find information id", "id", id)
 
-	information, err := i.infoRepo.GetInformationById(id)
+	information, err := i.infoRepo.GetInformationById(c.UserContext(), id)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		i.logger.Error(c.UserContext(), "get information by id", "error", err)
+		return err
 	}
 
 	if information == nil {
-		log.Println("information not found")
+		i.logger.Warn(c.UserContext(), "information not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
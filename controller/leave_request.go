@@ -0,0 +1,582 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/realtime"
+	"school-notification-backend/repository"
+	"school-notification-backend/security"
+	"school-notification-backend/util"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// max size of leave request file, 3 MB is under body limit 4 MB of fiber with other form field
+const leaveRequestFileMaxSize = 3 << 20
+
+// extension of leave request file and content type that the file must be
+var leaveRequestFileTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+}
+
+type LeaveRequestController interface {
+	CreateLeaveRequest(c *fiber.Ctx) error
+	GetLeaveRequestList(c *fiber.Ctx) error
+	GetLeaveRequestById(c *fiber.Ctx) error
+	GetLeaveRequestFile(c *fiber.Ctx) error
+	ApproveLeaveRequest(c *fiber.Ctx) error
+}
+
+type leaveRequestController struct {
+	leaveRequestRepo    repository.LeaveRequestRepository
+	checkNameRepository repository.CheckNameRepository
+	courseRepo          repository.CourseRepository
+	classRepo           repository.ClassRepository
+	profileRepo         repository.ProfileRepository
+	notificationRepo    repository.NotificationRepository
+	hub                 realtime.Hub
+	logger              logger.Logger
+}
+
+func NewLeaveRequestController(leaveRequestRepo repository.LeaveRequestRepository, checkNameRepository repository.CheckNameRepository, courseRepo repository.CourseRepository, classRepo repository.ClassRepository, profileRepo repository.ProfileRepository, notificationRepo repository.NotificationRepository, hub realtime.Hub, logger logger.Logger) LeaveRequestController {
+	return &leaveRequestController{leaveRequestRepo: leaveRequestRepo, checkNameRepository: checkNameRepository, courseRepo: courseRepo, classRepo: classRepo, profileRepo: profileRepo, notificationRepo: notificationRepo, hub: hub, logger: logger}
+}
+
+func (l *leaveRequestController) CreateLeaveRequest(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	studentId := user.ProfileId
+	if user.Role == "parent" {
+		id, err := util.CheckStringData(c.FormValue("student_id"), "student_id")
+		if err != nil {
+			l.logger.Warn(c.UserContext(), "create leave request", "error", err)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+		}
+		studentId = id
+	}
+	l.logger.Debug(c.UserContext(), "leave student id", "student_id", studentId)
+
+	dateStart, err := util.CheckStringData(c.FormValue("date_start"), "date_start")
+	if err != nil {
+		l.logger.Warn(c.UserContext(), "create leave request", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	l.logger.Debug(c.UserContext(), "leave date start", "date_start", dateStart)
+
+	dateEnd, err := util.CheckStringData(c.FormValue("date_end"), "date_end")
+	if err != nil {
+		l.logger.Warn(c.UserContext(), "create leave request", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	l.logger.Debug(c.UserContext(), "leave date end", "date_end", dateEnd)
+
+	tStart, err := time.Parse("2006-01-02", dateStart)
+	if err != nil {
+		l.logger.Warn(c.UserContext(), "create leave request", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "date_start"+util.ErrValueInvalid.Error())
+	}
+
+	tEnd, err := time.Parse("2006-01-02", dateEnd)
+	if err != nil {
+		l.logger.Warn(c.UserContext(), "create leave request", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "date_end"+util.ErrValueInvalid.Error())
+	}
+
+	if tEnd.Before(tStart) {
+		l.logger.Warn(c.UserContext(), "date end before date start")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "date_end"+util.ErrValueInvalid.Error())
+	}
+
+	reason, err := util.CheckStringData(c.FormValue("reason"), "reason")
+	if err != nil {
+		l.logger.Warn(c.UserContext(), "create leave request", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	l.logger.Debug(c.UserContext(), "leave reason", "reason", reason)
+
+	p, err := l.profileRepo.GetProfileById(c.UserContext(), bson.M{"profile_id": studentId, "role": "student"}, "student")
+	if err != nil {
+		l.logger.Error(c.UserContext(), "create leave request", "error", err)
+		return err
+	}
+	student := p.(models.ProfileStudent)
+
+	if user.Role == "parent" && student.ParentId != user.ProfileId {
+		l.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
+	}
+
+	id := primitive.NewObjectID()
+	filePath := ""
+	file, err := c.FormFile("file")
+	if err == nil {
+		l.logger.Debug(c.UserContext(), "file type", "file_type", file.Header.Get("Content-Type"))
+
+		err = checkLeaveRequestFile(file)
+		if err != nil {
+			l.logger.Warn(c.UserContext(), "create leave request", "error", err)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+		}
+
+		if _, err := os.Stat("./storage"); os.IsNotExist(err) {
+			err = os.Mkdir("./storage", 0777)
+		}
+
+		if _, err := os.Stat("./storage/leave-request"); os.IsNotExist(err) {
+			err = os.Mkdir("./storage/leave-request", 0777)
+		}
+
+		filename := id.Hex() + "-" + filepath.Base(file.Filename)
+
+		err = c.SaveFile(file, fmt.Sprintf("./storage/leave-request/%s", filename))
+		if err != nil {
+			l.logger.Error(c.UserContext(), "file save error", "error", err)
+			value, ok := err.(*fiber.Error)
+			if ok {
+				return util.ResponseNotSuccess(c, value.Code, value.Message)
+			}
+
+			return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, err.Error())
+		}
+
+		filePath = fmt.Sprintf("/files/leave-request/%s", filename)
+	}
+
+	leaveRequestNew := &models.LeaveRequest{
+		Id:            id,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+		StudentId:     studentId,
+		RequestBy:     user.ProfileId,
+		RequestByRole: user.Role,
+		DateStart:     dateStart,
+		DateEnd:       dateEnd,
+		Reason:        reason,
+		FilePath:      filePath,
+		Status:        "pending",
+	}
+
+	_, err = l.leaveRequestRepo.Insert(c.UserContext(), leaveRequestNew)
+	if err != nil {
+		l.logger.Error(c.UserContext(), "create leave request", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.LeaveRequestCollection, leaveRequestNew.Id.Hex(), leaveRequestNew)
+
+	class, err := l.classRepo.GetClassById(c.UserContext(), student.ClassId)
+	if err != nil {
+		l.logger.Error(c.UserContext(), "create leave request", "error", err)
+	} else if class.AdvisorId != "" {
+		sendNotification(c.UserContext(), l.logger, l.notificationRepo, l.hub, []*models.Notification{
+			newNotification(class.AdvisorId, "teacher", "leave_request", "leave request", fmt.Sprintf("%s request leave %s to %s", student.Name, dateStart, dateEnd), id.Hex()),
+		})
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusCreated, "create leave request success", map[string]interface{}{
+		"leave_request_id": id,
+	})
+}
+
+func (l *leaveRequestController) GetLeaveRequestList(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	filter := bson.M{}
+
+	status := c.Query("status")
+	l.logger.Debug(c.UserContext(), "find leave request status", "status", status)
+	if status != "" {
+		if status != "pending" && status != "approved" && status != "rejected" {
+			l.logger.Warn(c.UserContext(), "status", "error", util.ErrValueInvalid)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "status"+util.ErrValueInvalid.Error())
+		}
+		filter["status"] = status
+	}
+
+	studentId := c.Query("student_id")
+	l.logger.Debug(c.UserContext(), "find leave request student id", "student_id", studentId)
+
+	if user.Role == "student" {
+		filter["student_id"] = user.ProfileId
+	} else if user.Role == "parent" {
+		parent, err := l.profileRepo.GetProfileById(c.UserContext(), bson.M{"profile_id": user.ProfileId, "role": "parent"}, "parent")
+		if err != nil {
+			l.logger.Error(c.UserContext(), "get leave request list", "error", err)
+			return err
+		}
+		filter["student_id"] = bson.M{"$in": parent.(models.ProfileParent).StudentIdList}
+	} else if user.Role == "teacher" {
+		studentIdList, err := l.getStudentIdListOfTeacher(c.UserContext(), user.ProfileId)
+		if err != nil {
+			l.logger.Error(c.UserContext(), "get leave request list", "error", err)
+			return util.ResponseError(c, err)
+		}
+		filter["student_id"] = bson.M{"$in": studentIdList}
+	}
+
+	if studentId != "" {
+		filter = bson.M{"$and": []bson.M{filter, {"student_id": studentId}}}
+	}
+
+	leaveRequests, err := l.leaveRequestRepo.GetByFilterAll(c.UserContext(), filter)
+	if err != nil {
+		l.logger.Error(c.UserContext(), "get leave request list", "error", err)
+		return err
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"leave_request_list": leaveRequests,
+	})
+}
+
+func (l *leaveRequestController) GetLeaveRequestById(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	id, err := util.CheckStringData(c.Query("id"), "id")
+	if err != nil {
+		l.logger.Warn(c.UserContext(), "get leave request by id", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	l.logger.Debug(c.UserContext(), "find leave request id", "id", id)
+
+	leaveRequest, err := l.leaveRequestRepo.GetById(c.UserContext(), id)
+	if err != nil {
+		l.logger.Error(c.UserContext(), "get leave request by id", "error", err)
+		return err
+	}
+
+	check, err := l.canReadLeaveRequest(c.UserContext(), user, leaveRequest)
+	if err != nil {
+		l.logger.Error(c.UserContext(), "get leave request by id", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if !check {
+		l.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"leave_request": leaveRequest,
+	})
+}
+
+// file may be medical document, only student, parent, class advisor and admin read it
+func (l *leaveRequestController) GetLeaveRequestFile(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	id, err := util.CheckStringData(c.Query("id"), "id")
+	if err != nil {
+		l.logger.Warn(c.UserContext(), "get leave request file", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	l.logger.Debug(c.UserContext(), "find leave request id", "id", id)
+
+	leaveRequest, err := l.leaveRequestRepo.GetById(c.UserContext(), id)
+	if err != nil {
+		l.logger.Error(c.UserContext(), "get leave request file", "error", err)
+		return err
+	}
+
+	check, err := l.canReadLeaveRequestFile(c.UserContext(), user, leaveRequest)
+	if err != nil {
+		l.logger.Error(c.UserContext(), "get leave request file", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if !check {
+		l.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
+	}
+
+	if leaveRequest.FilePath == "" {
+		l.logger.Warn(c.UserContext(), "get leave request file", "error", util.ErrNotFound)
+		return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
+	}
+
+	// file path is the old static path, only its name is used
+	return c.SendFile(filepath.Join("./storage/leave-request", filepath.Base(leaveRequest.FilePath)))
+}
+
+func (l *leaveRequestController) ApproveLeaveRequest(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	req := models.LeaveRequestRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		l.logger.Warn(c.UserContext(), "approve leave request", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	id, err := util.CheckStringData(req.Id, "id")
+	if err != nil {
+		l.logger.Warn(c.UserContext(), "approve leave request", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	l.logger.Debug(c.UserContext(), "leave request id", "id", id)
+
+	status, err := util.CheckStringData(req.Status, "status")
+	if err != nil {
+		l.logger.Warn(c.UserContext(), "approve leave request", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	l.logger.Debug(c.UserContext(), "leave request status", "status", status)
+
+	if status != "approved" && status != "rejected" {
+		l.logger.Warn(c.UserContext(), "approve leave request", "error", util.ReturnErrorStatusInvalid("status", "approved , rejected"))
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ReturnErrorStatusInvalid("status", "approved , rejected").Error())
+	}
+
+	leaveRequest, err := l.leaveRequestRepo.GetById(c.UserContext(), id)
+	if err != nil {
+		l.logger.Error(c.UserContext(), "approve leave request", "error", err)
+		return err
+	}
+
+	if leaveRequest.Status != "pending" {
+		l.logger.Warn(c.UserContext(), "leave request is not pending")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "leave request is not pending")
+	}
+
+	if user.Role == "teacher" {
+		check, err := l.isTeacherOfStudent(c.UserContext(), user.ProfileId, leaveRequest.StudentId)
+		if err != nil {
+			l.logger.Error(c.UserContext(), "approve leave request", "error", err)
+			return util.ResponseError(c, err)
+		}
+		if !check {
+			l.logger.Warn(c.UserContext(), "not permiistion")
+			return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
+		}
+	}
+
+	security.AuditBefore(c, repository.LeaveRequestCollection, leaveRequest.Id.Hex(), leaveRequest)
+	t := time.Now()
+	leaveRequest.Status = status
+	leaveRequest.ApproveBy = user.ProfileId
+	leaveRequest.ApprovedAt = &t
+	leaveRequest.Note = req.Note
+	leaveRequest.UpdatedAt = t
+
+	result, err := l.leaveRequestRepo.Update(c.UserContext(), leaveRequest)
+	if err != nil {
+		l.logger.Error(c.UserContext(), "approve leave request", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.LeaveRequestCollection, leaveRequest.Id.Hex(), leaveRequest)
+
+	checkNameCount := 0
+	if status == "approved" {
+		checkNameCount, err = l.applyLeave(c, leaveRequest)
+		if err != nil {
+			l.logger.Error(c.UserContext(), "approve leave request", "error", err)
+			return util.ResponseError(c, err)
+		}
+	}
+
+	message := fmt.Sprintf("leave %s to %s is %s", leaveRequest.DateStart, leaveRequest.DateEnd, status)
+	sendNotification(c.UserContext(), l.logger, l.notificationRepo, l.hub, newStudentNotification(c.UserContext(), l.logger, l.profileRepo, leaveRequest.StudentId, "leave_request", "leave request "+status, message, leaveRequest.Id.Hex()))
+
+	return util.ResponseSuccess(c, fiber.StatusCreated, "update leave request success", map[string]interface{}{
+		"leave_request_id": leaveRequest.Id,
+		"update_count":     result.ModifiedCount,
+		"check_name_count": checkNameCount,
+	})
+}
+
+// change absent in ended check name to leave, check name that does not end is changed at end date
+func (l *leaveRequestController) applyLeave(c *fiber.Ctx, leaveRequest *models.LeaveRequest) (int, error) {
+	courses, err := l.courseRepo.GetCourseAllByFilter(c.UserContext(), bson.M{"status": "progress", "student_id_list": leaveRequest.StudentId})
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	dateStart, err := util.ParseDate(leaveRequest.DateStart)
+	if err != nil {
+		return 0, err
+	}
+	dateEnd, err := util.ParseDate(leaveRequest.DateEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	t := time.Now()
+	for _, course := range courses {
+		checkNameList, err := l.checkNameRepository.GetByFilterAll(c.UserContext(), bson.M{
+			"course_id": course.Id.Hex(),
+			"status":    "end",
+			"date":      bson.M{"$gte": dateStart, "$lte": dateEnd},
+		})
+		if err != nil {
+			if errors.Is(err, util.ErrNotFound) {
+				continue
+			}
+			return count, err
+		}
+
+		for _, checkName := range checkNameList {
+			security.AuditBefore(c, repository.CheckNameCollection, checkName.Id.Hex(), checkName)
+			check := false
+			for i, d := range checkName.CheckNameData {
+				if d.StudentId == leaveRequest.StudentId && d.Status == "absent" {
+					checkName.CheckNameData[i].Status = "leave"
+					checkName.CheckNameData[i].CheckBy = leaveRequest.ApproveBy
+					checkName.CheckNameData[i].UpdatedAt = t
+					check = true
+					break
+				}
+			}
+			if !check {
+				continue
+			}
+
+			checkName.UpdatedAt = t
+			_, err = l.checkNameRepository.Update(c.UserContext(), checkName)
+			if err != nil {
+				return count, err
+			}
+			security.AuditAfter(c, repository.CheckNameCollection, checkName.Id.Hex(), checkName)
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// class advisor or teacher of progress course that student is in
+func (l *leaveRequestController) isTeacherOfStudent(ctx context.Context, teacherId string, studentId string) (bool, error) {
+	studentIdList, err := l.getStudentIdListOfTeacher(ctx, teacherId)
+	if err != nil {
+		return false, err
+	}
+
+	for _, v := range studentIdList {
+		if v == studentId {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (l *leaveRequestController) getStudentIdListOfTeacher(ctx context.Context, teacherId string) ([]string, error) {
+	studentIdList := []string{}
+
+	classes, err := l.classRepo.GetClassByFilterAll(ctx, bson.M{"advisor_id": teacherId})
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		return nil, err
+	}
+	for _, v := range classes {
+		studentIdList = append(studentIdList, v.StudentIdList...)
+	}
+
+	courses, err := l.courseRepo.GetCourseAllByFilter(ctx, bson.M{"instructor_id": teacherId, "status": "progress"})
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		return nil, err
+	}
+	for _, v := range courses {
+		studentIdList = append(studentIdList, v.StudentIdList...)
+	}
+
+	return studentIdList, nil
+}
+
+// class advisor of student, other teacher of student does not read file
+func (l *leaveRequestController) isAdvisorOfStudent(ctx context.Context, teacherId string, studentId string) (bool, error) {
+	classes, err := l.classRepo.GetClassByFilterAll(ctx, bson.M{"advisor_id": teacherId, "student_id_list": studentId})
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return len(classes) > 0, nil
+}
+
+func (l *leaveRequestController) canReadLeaveRequestFile(ctx context.Context, user *models.User, leaveRequest *models.LeaveRequest) (bool, error) {
+	switch user.Role {
+	case "admin":
+		return true, nil
+	case "student", "parent":
+		return l.canReadLeaveRequest(ctx, user, leaveRequest)
+	case "teacher":
+		return l.isAdvisorOfStudent(ctx, user.ProfileId, leaveRequest.StudentId)
+	}
+
+	return false, nil
+}
+
+func (l *leaveRequestController) canReadLeaveRequest(ctx context.Context, user *models.User, leaveRequest *models.LeaveRequest) (bool, error) {
+	if user.Role == "admin" || user.Role == "server" {
+		return true, nil
+	}
+
+	if user.Role == "student" {
+		return leaveRequest.StudentId == user.ProfileId, nil
+	}
+
+	if user.Role == "parent" {
+		p, err := l.profileRepo.GetProfileById(ctx, bson.M{"profile_id": leaveRequest.StudentId, "role": "student"}, "student")
+		if err != nil {
+			return false, err
+		}
+		return p.(models.ProfileStudent).ParentId == user.ProfileId, nil
+	}
+
+	if user.Role == "teacher" {
+		return l.isTeacherOfStudent(ctx, user.ProfileId, leaveRequest.StudentId)
+	}
+
+	return false, nil
+}
+
+// file must be pdf or image in size limit, content type is detect from the file not from header of client
+func checkLeaveRequestFile(file *multipart.FileHeader) error {
+	if file.Size > leaveRequestFileMaxSize {
+		return fmt.Errorf("file must not be larger than %d MB", leaveRequestFileMaxSize>>20)
+	}
+
+	contentType, ok := leaveRequestFileTypes[strings.ToLower(filepath.Ext(file.Filename))]
+	if !ok {
+		return errors.New("file must be pdf, jpg or png")
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	b := make([]byte, 512)
+	n, err := io.ReadFull(f, b)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if http.DetectContentType(b[:n]) != contentType {
+		return errors.New("file content does not match its extension")
+	}
+
+	return nil
+}
@@ -1,7 +1,8 @@
 package controller
 
 import (
-	"log"
+	"errors"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/repository"
 	"school-notification-backend/security"
@@ -12,7 +13,6 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type LocationController interface {
@@ -24,24 +24,18 @@ type LocationController interface {
 
 type locationController struct {
 	locationRepo repository.LocationRepository
-	userRepo     repository.UsersRepository
+	logger       logger.Logger
 }
 
-func NewLocationController(locationRepo repository.LocationRepository, userRepo repository.UsersRepository) LocationController {
-	return &locationController{locationRepo: locationRepo, userRepo: userRepo}
+func NewLocationController(locationRepo repository.LocationRepository, logger logger.Logger) LocationController {
+	return &locationController{locationRepo: locationRepo, logger: logger}
 }
 
 func (l *locationController) CreateLocation(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], l.userRepo, []string{"admin"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.LocationRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		l.logger.Warn(c.UserContext(), "create location", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -52,42 +46,42 @@ func (l *locationController) CreateLocation(c *fiber.Ctx) error {
 
 	buildingName, err := util.CheckStringData(req.BuildingName, "building_name")
 	if err != nil {
-		log.Println(err)
+		l.logger.Warn(c.UserContext(), "create location", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("building name:", buildingName)
+	l.logger.Debug(c.UserContext(), "building name", "building_name", buildingName)
 
 	floor, err := util.CheckStringData(req.Floor, "floor")
 	if err != nil {
-		log.Println(err)
+		l.logger.Warn(c.UserContext(), "create location", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("floor:", floor)
+	l.logger.Debug(c.UserContext(), "floor", "floor", floor)
 
 	room, err := util.CheckStringData(req.Room, "room")
 	if err != nil {
-		log.Println(err)
+		l.logger.Warn(c.UserContext(), "create location", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("room:", room)
+	l.logger.Debug(c.UserContext(), "room", "room", room)
 
-	_, err = l.locationRepo.GetLocationByFilter(bson.M{"building_name": buildingName, "floor": floor, "room": room})
+	_, err = l.locationRepo.GetLocationByFilter(c.UserContext(), bson.M{"building_name": buildingName, "floor": floor, "room": room})
 	if err == nil {
-		log.Println("location already exists")
+		l.logger.Warn(c.UserContext(), "location already exists")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "location"+util.ErrValueAlreadyExists.Error())
 	}
-	if err.Error() != "mongo: no documents in result" {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+	if !errors.Is(err, util.ErrNotFound) {
+		l.logger.Error(c.UserContext(), "create location", "error", err)
+		return util.ResponseError(c, err)
 	}
 
 	locationId := buildingName + "-" + floor + "-" + room
-	log.Println("location id:", locationId)
+	l.logger.Debug(c.UserContext(), "location id", "location_id", locationId)
 
 	locationNew := &models.Location{
 		Id:           primitive.NewObjectID(),
-		CreatedAt:    time.Now().Format(time.RFC3339),
-		UpdatedAt:    time.Now().Format(time.RFC3339),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
 		LocationId:   locationId,
 		BuildingName: buildingName,
 		Floor:        floor,
@@ -96,11 +90,12 @@ func (l *locationController) CreateLocation(c *fiber.Ctx) error {
 		Slot:         createTimeSlot(),
 	}
 
-	_, err = l.locationRepo.Insert(locationNew)
+	_, err = l.locationRepo.Insert(c.UserContext(), locationNew)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		l.logger.Error(c.UserContext(), "create location", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.LocationCollection, locationNew.Id.Hex(), locationNew)
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "create location success", map[string]interface{}{
 		"location_id": locationId,
@@ -108,16 +103,10 @@ func (l *locationController) CreateLocation(c *fiber.Ctx) error {
 }
 
 func (l *locationController) UpdateLocationData(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], l.userRepo, []string{"admin"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.LocationRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		l.logger.Warn(c.UserContext(), "update location data", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -128,54 +117,48 @@ func (l *locationController) UpdateLocationData(c *fiber.Ctx) error {
 
 	locationId, err := util.CheckStringData(req.LocationId, "location_id")
 	if err != nil {
-		log.Println(err)
+		l.logger.Warn(c.UserContext(), "update location data", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("location id:", locationId)
+	l.logger.Debug(c.UserContext(), "location id", "location_id", locationId)
 
-	location, err := l.locationRepo.GetLocationByFilter(bson.M{"location_id": req.LocationId})
+	location, err := l.locationRepo.GetLocationByFilter(c.UserContext(), bson.M{"location_id": req.LocationId})
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		l.logger.Error(c.UserContext(), "update location data", "error", err)
+		return err
 	}
 
 	buildingName, err := util.CheckStringData(req.BuildingName, "building_name")
 	if err != nil {
-		log.Println(err)
+		l.logger.Warn(c.UserContext(), "update location data", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("building name:", buildingName)
+	l.logger.Debug(c.UserContext(), "building name", "building_name", buildingName)
 
 	floor, err := util.CheckStringData(req.Floor, "floor")
 	if err != nil {
-		log.Println(err)
+		l.logger.Warn(c.UserContext(), "update location data", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("floor:", floor)
+	l.logger.Debug(c.UserContext(), "floor", "floor", floor)
 
 	room, err := util.CheckStringData(req.Room, "room")
 	if err != nil {
-		log.Println(err)
+		l.logger.Warn(c.UserContext(), "update location data", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("room:", room)
+	l.logger.Debug(c.UserContext(), "room", "room", room)
 
 	locationIdNew := location.BuildingName + "-" + location.Floor + "-" + location.Room
 
 	if locationId != locationIdNew {
-		_, err := l.locationRepo.GetLocationByFilter(bson.M{"location_id": location.LocationId})
+		_, err := l.locationRepo.GetLocationByFilter(c.UserContext(), bson.M{"location_id": location.LocationId})
 		if err == nil {
-			log.Println("location new already exists")
+			l.logger.Warn(c.UserContext(), "location new already exists")
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "location"+util.ErrValueAlreadyExists.Error())
 		}
-		if err.Error() != "mongo: no documents in result" {
-			log.Println(err)
+		if !errors.Is(err, util.ErrNotFound) {
+			l.logger.Warn(c.UserContext(), "update location data", "error", err)
 			return util.ErrInternalServerError
 		}
 	}
@@ -187,12 +170,12 @@ func (l *locationController) UpdateLocationData(c *fiber.Ctx) error {
 	if req.Status != nil {
 		location.Status = *req.Status
 	}
-	location.UpdatedAt = time.Now().Format(time.RFC3339)
+	location.UpdatedAt = time.Now()
 
-	locationUpdate, err := l.locationRepo.Update(location)
+	locationUpdate, err := l.locationRepo.Update(c.UserContext(), location)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		l.logger.Error(c.UserContext(), "update location data", "error", err)
+		return util.ResponseError(c, err)
 	}
 
 	return util.ResponseSuccess(c, fiber.StatusOK, "update location success", map[string]interface{}{
@@ -202,23 +185,14 @@ func (l *locationController) UpdateLocationData(c *fiber.Ctx) error {
 }
 
 func (l *locationController) GetLocationAll(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], l.userRepo, []string{"all"})
+	locations, err := l.locationRepo.GetAll(c.UserContext())
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
-	locations, err := l.locationRepo.GetAll()
-	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		l.logger.Error(c.UserContext(), "get location all", "error", err)
+		return err
 	}
 
 	if len(locations) == 0 {
-		log.Println("location not found")
+		l.logger.Warn(c.UserContext(), "location not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -228,33 +202,21 @@ func (l *locationController) GetLocationAll(c *fiber.Ctx) error {
 }
 
 func (l *locationController) GetLocationById(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], l.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	id, err := util.CheckStringData(c.Query("location_id"), "location_id")
 	if err != nil {
-		log.Println(err)
+		l.logger.Warn(c.UserContext(), "get location by id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find location id:", id)
+	l.logger.Debug(c.UserContext(), "find location id", "id", id)
 
-	location, err := l.locationRepo.GetLocationById(id)
+	location, err := l.locationRepo.GetLocationById(c.UserContext(), id)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		l.logger.Error(c.UserContext(), "get location by id", "error", err)
+		return err
 	}
 
 	if location == nil {
-		log.Println("location not found")
+		l.logger.Warn(c.UserContext(), "location not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
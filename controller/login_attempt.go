@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"math"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/repository"
+	"school-notification-backend/security"
+	"school-notification-backend/util"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LoginAttemptController interface {
+	GetLoginAttemptAll(c *fiber.Ctx) error
+	GetLoginLockAll(c *fiber.Ctx) error
+	UnlockLogin(c *fiber.Ctx) error
+}
+
+type loginAttemptController struct {
+	loginAttemptRepo repository.LoginAttemptRepository
+	loginLockRepo    repository.LoginLockRepository
+	logger           logger.Logger
+}
+
+func NewLoginAttemptController(loginAttemptRepo repository.LoginAttemptRepository, loginLockRepo repository.LoginLockRepository, logger logger.Logger) LoginAttemptController {
+	return &loginAttemptController{loginAttemptRepo: loginAttemptRepo, loginLockRepo: loginLockRepo, logger: logger}
+}
+
+// filter by username, ip and success, default limit is 100
+func (l *loginAttemptController) GetLoginAttemptAll(c *fiber.Ctx) error {
+	filter := bson.M{}
+	if c.Query("username") != "" {
+		filter["username"] = c.Query("username")
+	}
+	if c.Query("ip") != "" {
+		filter["ip"] = c.Query("ip")
+	}
+	if c.Query("success") != "" {
+		success, err := strconv.ParseBool(c.Query("success"))
+		if err != nil {
+			l.logger.Warn(c.UserContext(), "get login attempt all", "error", err)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "success"+util.ErrValueInvalid.Error())
+		}
+		filter["success"] = success
+	}
+
+	limit := int64(100)
+	if c.Query("limit") != "" {
+		n, err := strconv.ParseInt(c.Query("limit"), 10, 64)
+		if err != nil || n <= 0 {
+			l.logger.Warn(c.UserContext(), "limit", "error", util.ErrValueInvalid)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "limit"+util.ErrValueInvalid.Error())
+		}
+		limit = n
+	}
+	l.logger.Debug(c.UserContext(), "find login attempt limit", "filter", filter, "limit", limit)
+
+	attempts, err := l.loginAttemptRepo.GetByFilterAll(c.UserContext(), filter, limit)
+	if err != nil {
+		l.logger.Error(c.UserContext(), "get login attempt all", "error", err)
+		return err
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"login_attempts": attempts,
+	})
+}
+
+// username and ip that is lock now
+func (l *loginAttemptController) GetLoginLockAll(c *fiber.Ctx) error {
+	locks, err := l.loginLockRepo.GetByFilterAll(c.UserContext(), bson.M{"locked_until": bson.M{"$gt": time.Now()}})
+	if err != nil {
+		l.logger.Error(c.UserContext(), "get login lock all", "error", err)
+		return err
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"login_locks": locks,
+	})
+}
+
+func (l *loginAttemptController) UnlockLogin(c *fiber.Ctx) error {
+	req := models.LoginLockRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		l.logger.Warn(c.UserContext(), "unlock login", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	username := strings.TrimSpace(req.Username)
+	ip := strings.TrimSpace(req.Ip)
+	if username == "" && ip == "" {
+		l.logger.Warn(c.UserContext(), "do not have parameter username or ip")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"username or ip")
+	}
+
+	deleteCount := int64(0)
+	for _, v := range [][2]string{{security.LoginLockUsername, username}, {security.LoginLockIp, ip}} {
+		if v[1] == "" {
+			continue
+		}
+		l.logger.Debug(c.UserContext(), "unlock", "type", v[0], "value", v[1])
+
+		lock, err := l.loginLockRepo.GetByTypeAndValue(c.UserContext(), v[0], v[1])
+		if err != nil {
+			if errors.Is(err, util.ErrNotFound) {
+				continue
+			}
+			l.logger.Error(c.UserContext(), "unlock login", "error", err)
+			return util.ResponseError(c, err)
+		}
+		security.AuditBefore(c, repository.LoginLockCollection, lock.Id.Hex(), lock)
+
+		result, err := l.loginLockRepo.Delete(c.UserContext(), v[0], v[1])
+		if err != nil {
+			l.logger.Error(c.UserContext(), "unlock login", "error", err)
+			return util.ResponseError(c, err)
+		}
+		if result.DeletedCount > 0 {
+			security.AuditAfter(c, repository.LoginLockCollection, lock.Id.Hex(), nil)
+		}
+		deleteCount += result.DeletedCount
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "unlock success", map[string]interface{}{
+		"delete_count": deleteCount,
+	})
+}
+
+// longest wait of username and ip lock, zero is allow
+func loginRetryAfter(ctx context.Context, loginLockRepo repository.LoginLockRepository, username string, ip string) (time.Duration, error) {
+	wait := time.Duration(0)
+	for _, v := range [][2]string{{security.LoginLockUsername, username}, {security.LoginLockIp, ip}} {
+		lock, err := loginLockRepo.GetByTypeAndValue(ctx, v[0], v[1])
+		if err != nil {
+			if errors.Is(err, util.ErrNotFound) {
+				continue
+			}
+			return 0, err
+		}
+
+		d := security.LoginRetryAfter(lock, time.Now())
+		if d > wait {
+			wait = d
+		}
+	}
+
+	return wait, nil
+}
+
+func responseLoginLocked(c *fiber.Ctx, wait time.Duration) error {
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return util.ResponseNotSuccess(c, fiber.StatusTooManyRequests, "too many sign in attempts, retry after "+strconv.Itoa(retryAfter)+" seconds")
+}
+
+func addLoginFailure(ctx context.Context, logger logger.Logger, loginLockRepo repository.LoginLockRepository, username string, ip string) {
+	for _, v := range [][2]string{{security.LoginLockUsername, username}, {security.LoginLockIp, ip}} {
+		err := upsertLoginFailure(ctx, logger, loginLockRepo, v[0], v[1])
+		// other request insert lock of same key first, unique index reject this one so add to that lock
+		if errors.Is(err, util.ErrConflict) {
+			err = upsertLoginFailure(ctx, logger, loginLockRepo, v[0], v[1])
+		}
+		if err != nil {
+			logger.Error(ctx, "add login failure", "error", err)
+		}
+	}
+}
+
+func upsertLoginFailure(ctx context.Context, logger logger.Logger, loginLockRepo repository.LoginLockRepository, lockType string, value string) error {
+	now := time.Now()
+	lock, err := loginLockRepo.GetByTypeAndValue(ctx, lockType, value)
+	if err != nil {
+		if !errors.Is(err, util.ErrNotFound) {
+			return err
+		}
+		lock = &models.LoginLock{
+			Id:        primitive.NewObjectID(),
+			CreatedAt: now,
+			Type:      lockType,
+			Value:     value,
+		}
+	}
+
+	security.AddLoginFailure(lock, now)
+	if lock.LockedUntil != nil {
+		logger.Debug(ctx, "login lock until", "type", lock.Type, "value", lock.Value, "locked_until", *lock.LockedUntil)
+	}
+
+	_, err = loginLockRepo.Upsert(ctx, lock)
+	return err
+}
+
+func recordLoginAttempt(c *fiber.Ctx, logger logger.Logger, loginAttemptRepo repository.LoginAttemptRepository, username string, userId string, success bool, reason string) {
+	_, err := loginAttemptRepo.Insert(c.UserContext(), &models.LoginAttempt{
+		Id:        primitive.NewObjectID(),
+		CreatedAt: time.Now(),
+		Username:  username,
+		UserId:    userId,
+		Ip:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		Success:   success,
+		Reason:    reason,
+	})
+	if err != nil {
+		logger.Error(c.UserContext(), "record login attempt", "error", err)
+	}
+}
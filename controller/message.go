@@ -1,8 +1,9 @@
 package controller
 
 import (
-	"log"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
+	"school-notification-backend/realtime"
 	"school-notification-backend/repository"
 	"school-notification-backend/security"
 	"school-notification-backend/util"
@@ -21,24 +22,21 @@ type MessageController interface {
 type messageController struct {
 	messageRepo      repository.MessageRepository
 	conversationRepo repository.ConversationRepository
-	userRepo         repository.UsersRepository
+	hub              realtime.Hub
+	logger           logger.Logger
 }
 
-func NewMessageController(messageRepo repository.MessageRepository, conversationRepo repository.ConversationRepository, userRepo repository.UsersRepository) MessageController {
-	return &messageController{messageRepo: messageRepo, conversationRepo: conversationRepo, userRepo: userRepo}
+func NewMessageController(messageRepo repository.MessageRepository, conversationRepo repository.ConversationRepository, hub realtime.Hub, logger logger.Logger) MessageController {
+	return &messageController{messageRepo: messageRepo, conversationRepo: conversationRepo, hub: hub, logger: logger}
 }
 
 func (m *messageController) CreateMessage(c *fiber.Ctx) error {
-	user, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], m.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
+	user := security.GetUser(c)
 
 	req := models.MessageRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		m.logger.Warn(c.UserContext(), "create message", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -49,39 +47,33 @@ func (m *messageController) CreateMessage(c *fiber.Ctx) error {
 
 	senderId, err := util.CheckStringData(req.SenderId, "sender_id")
 	if err != nil {
-		log.Println(err)
+		m.logger.Warn(c.UserContext(), "create message", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("sender id:", senderId)
+	m.logger.Debug(c.UserContext(), "sender id", "sender_id", senderId)
 
 	if user.UserId != senderId {
-		log.Println("not permiistion")
-		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "not permission")
+		m.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
 	}
 
 	conversationId, err := util.CheckStringData(req.ConversationId, "conversation_id")
 	if err != nil {
-		log.Println(err)
+		m.logger.Warn(c.UserContext(), "create message", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("conversation id:", conversationId)
+	m.logger.Debug(c.UserContext(), "conversation id", "conversation_id", conversationId)
 
 	if req.Text == "" {
-		log.Println(util.ReturnError(util.ErrRequireParameter.Error() + "text"))
+		m.logger.Warn(c.UserContext(), "create message", "error", util.ReturnError(util.ErrRequireParameter.Error()+"text"))
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"text")
 	}
-	log.Println("conversation text:", req.Text)
+	m.logger.Debug(c.UserContext(), "conversation text", "text", req.Text)
 
-	con, err := m.conversationRepo.GetConversationById(conversationId)
+	con, err := m.conversationRepo.GetConversationById(c.UserContext(), conversationId)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		m.logger.Error(c.UserContext(), "create message", "error", err)
+		return err
 	}
 
 	chcek := true
@@ -93,23 +85,28 @@ func (m *messageController) CreateMessage(c *fiber.Ctx) error {
 	}
 
 	if chcek {
-		log.Println("not permiistion")
-		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "not permission")
+		m.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
 	}
 
 	messageNew := &models.Message{
 		Id:             primitive.NewObjectID(),
-		CreatedAt:      time.Now().Format(time.RFC3339),
-		UpdatedAt:      time.Now().Format(time.RFC3339),
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 		ConversationId: conversationId,
 		Sender:         senderId,
 		Text:           req.Text,
 	}
 
-	re, err := m.messageRepo.Insert(messageNew)
+	re, err := m.messageRepo.Insert(c.UserContext(), messageNew)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		m.logger.Error(c.UserContext(), "create message", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.MessageCollection, messageNew.Id.Hex(), messageNew)
+
+	for _, v := range con.Members {
+		m.hub.PublishToUser(v, "message", messageNew)
 	}
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "create message success", map[string]interface{}{
@@ -118,29 +115,19 @@ func (m *messageController) CreateMessage(c *fiber.Ctx) error {
 }
 
 func (m *messageController) GetByConversationId(c *fiber.Ctx) error {
-	user, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], m.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
+	user := security.GetUser(c)
 
 	conversationId, err := util.CheckStringData(c.Query("conversation_id"), "conversation_id")
 	if err != nil {
-		log.Println(err)
+		m.logger.Warn(c.UserContext(), "get by conversation id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find by conversation id:", conversationId)
+	m.logger.Debug(c.UserContext(), "find by conversation id", "conversation_id", conversationId)
 
-	con, err := m.conversationRepo.GetConversationById(conversationId)
+	con, err := m.conversationRepo.GetConversationById(c.UserContext(), conversationId)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		m.logger.Error(c.UserContext(), "get by conversation id", "error", err)
+		return err
 	}
 
 	check := true
@@ -152,24 +139,18 @@ func (m *messageController) GetByConversationId(c *fiber.Ctx) error {
 	}
 
 	if check {
-		log.Println("not permiistion")
-		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "not permission")
+		m.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
 	}
 
-	messages, err := m.messageRepo.GetConversationAllByFilter(bson.M{"conversation_id": conversationId})
+	messages, err := m.messageRepo.GetConversationAllByFilter(c.UserContext(), bson.M{"conversation_id": conversationId})
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		m.logger.Error(c.UserContext(), "get by conversation id", "error", err)
+		return err
 	}
 
 	if len(messages) == 0 {
-		log.Println("message not found")
+		m.logger.Warn(c.UserContext(), "message not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
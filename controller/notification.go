@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/realtime"
+	"school-notification-backend/repository"
+	"school-notification-backend/security"
+	"school-notification-backend/util"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type NotificationController interface {
+	GetNotificationList(c *fiber.Ctx) error
+	GetUnreadCount(c *fiber.Ctx) error
+	ReadNotification(c *fiber.Ctx) error
+	ReadNotificationAll(c *fiber.Ctx) error
+}
+
+type notificationController struct {
+	notificationRepo repository.NotificationRepository
+	logger           logger.Logger
+}
+
+func NewNotificationController(notificationRepo repository.NotificationRepository, logger logger.Logger) NotificationController {
+	return &notificationController{notificationRepo: notificationRepo, logger: logger}
+}
+
+func (n *notificationController) GetNotificationList(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	filter := bson.M{
+		"profile_id": user.ProfileId,
+		"role":       user.Role,
+	}
+
+	status := c.Query("status")
+	n.logger.Debug(c.UserContext(), "find notification status", "status", status)
+	if status == "unread" {
+		filter["read"] = false
+	} else if status == "read" {
+		filter["read"] = true
+	} else if status != "" && status != "all" {
+		n.logger.Warn(c.UserContext(), "status", "error", util.ErrValueInvalid)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "status"+util.ErrValueInvalid.Error())
+	}
+
+	// empty inbox is not an error
+	notifications, err := n.notificationRepo.GetByFilterAll(c.UserContext(), filter)
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		n.logger.Error(c.UserContext(), "get notification list", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if notifications == nil {
+		notifications = []*models.Notification{}
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"notification_list": notifications,
+	})
+}
+
+func (n *notificationController) GetUnreadCount(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	count, err := n.notificationRepo.CountByFilter(c.UserContext(), bson.M{
+		"profile_id": user.ProfileId,
+		"role":       user.Role,
+		"read":       false,
+	})
+	if err != nil {
+		n.logger.Error(c.UserContext(), "get unread count", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"unread_count": count,
+	})
+}
+
+func (n *notificationController) ReadNotification(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	req := models.NotificationRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		n.logger.Warn(c.UserContext(), "read notification", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	id, err := util.CheckStringData(req.Id, "id")
+	if err != nil {
+		n.logger.Warn(c.UserContext(), "read notification", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	n.logger.Debug(c.UserContext(), "notification id", "id", id)
+
+	notification, err := n.notificationRepo.GetById(c.UserContext(), id)
+	if err != nil {
+		n.logger.Error(c.UserContext(), "read notification", "error", err)
+		return err
+	}
+
+	if notification.ProfileId != user.ProfileId || notification.Role != user.Role {
+		n.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
+	}
+
+	if notification.Read {
+		return util.ResponseSuccess(c, fiber.StatusOK, "notification already read", map[string]interface{}{
+			"notification_id": notification.Id,
+			"update_count":    0,
+		})
+	}
+
+	security.AuditBefore(c, repository.NotificationCollection, notification.Id.Hex(), notification)
+	t := time.Now()
+	notification.Read = true
+	notification.ReadAt = &t
+	notification.UpdatedAt = t
+
+	result, err := n.notificationRepo.Update(c.UserContext(), notification)
+	if err != nil {
+		n.logger.Error(c.UserContext(), "read notification", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.NotificationCollection, notification.Id.Hex(), notification)
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "read notification success", map[string]interface{}{
+		"notification_id": notification.Id,
+		"update_count":    result.ModifiedCount,
+	})
+}
+
+func (n *notificationController) ReadNotificationAll(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	t := time.Now()
+	filter := bson.M{
+		"profile_id": user.ProfileId,
+		"role":       user.Role,
+		"read":       false,
+	}
+	update := bson.M{
+		"read":       true,
+		"read_at":    t,
+		"updated_at": t,
+	}
+	result, err := n.notificationRepo.UpdateMany(c.UserContext(), filter, update)
+	if err != nil {
+		n.logger.Error(c.UserContext(), "read notification all", "error", err)
+		return util.ResponseError(c, err)
+	}
+	auditUpdateMany(c, repository.NotificationCollection, filter, update, result.ModifiedCount)
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "read notification success", map[string]interface{}{
+		"update_count": result.ModifiedCount,
+	})
+}
+
+func newNotification(profileId string, role string, notificationType string, title string, message string, refId string) *models.Notification {
+	t := time.Now()
+	return &models.Notification{
+		Id:        primitive.NewObjectID(),
+		CreatedAt: t,
+		UpdatedAt: t,
+		ProfileId: profileId,
+		Role:      role,
+		Type:      notificationType,
+		Title:     title,
+		Message:   message,
+		RefId:     refId,
+		Read:      false,
+	}
+}
+
+// notifications are a side effect of the request, a failure is logged and does not fail the caller
+func sendNotification(ctx context.Context, logger logger.Logger, notificationRepo repository.NotificationRepository, hub realtime.Hub, notifications []*models.Notification) {
+	if len(notifications) == 0 {
+		return
+	}
+
+	_, err := notificationRepo.InsertMany(ctx, notifications)
+	if err != nil {
+		logger.Warn(ctx, "send notification failed", "error", err)
+		return
+	}
+
+	for _, n := range notifications {
+		hub.PublishToProfile(n.Role, n.ProfileId, "notification", n)
+	}
+
+	logger.Debug(ctx, "send notification", "send_notification", len(notifications))
+}
+
+// create notification for student and parent of student
+func newStudentNotification(ctx context.Context, logger logger.Logger, profileRepo repository.ProfileRepository, studentId string, notificationType string, title string, message string, refId string) []*models.Notification {
+	notifications := []*models.Notification{
+		newNotification(studentId, "student", notificationType, title, message, refId),
+	}
+
+	p, err := profileRepo.GetProfileById(ctx, bson.M{"profile_id": studentId, "role": "student"}, "student")
+	if err != nil {
+		logger.Warn(ctx, "new student notification", "error", err)
+		return notifications
+	}
+
+	profile, _ := p.(models.ProfileStudent)
+	if profile.ParentId != "" {
+		notifications = append(notifications, newNotification(profile.ParentId, "parent", notificationType, title, profile.Name+": "+message, refId))
+	}
+
+	return notifications
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"errors"
+	"school-notification-backend/models"
+	"school-notification-backend/repository"
+	"school-notification-backend/security"
+	"school-notification-backend/util"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var errNotOwnerOfCourse = errors.New("not permission")
+
+// teacher manage only own course, admin, server and custom role with course manage permission manage all course
+// api key manage only course and class that it is restrict to
+func canManageCourse(c *fiber.Ctx, course *models.Course) (bool, error) {
+	if !apiKeyAllowCourse(c, course) {
+		return false, nil
+	}
+
+	user := security.GetUser(c)
+	switch user.Role {
+	case "teacher":
+		return user.ProfileId == course.InstructorId, nil
+	case "admin", "server":
+		return true, nil
+	}
+
+	return isCustomRoleOfAllCourse(c, user)
+}
+
+// custom role is not scope to any course, it read and manage all course only when it has course manage permission
+// other custom role is deny, so student or teacher that is assign custom role does not get more access
+func isCustomRoleOfAllCourse(c *fiber.Ctx, user *models.User) (bool, error) {
+	if security.IsBuiltInRole(user.Role) {
+		return false, nil
+	}
+
+	return security.HasPermission(c.UserContext(), user, security.PermCourseManage)
+}
+
+// student of course that user can read, nil is all student and empty is not permission
+func readableStudentIdList(c *fiber.Ctx, profileRepo repository.ProfileRepository, classRepo repository.ClassRepository, course *models.Course) ([]string, error) {
+	studentIdList := []string{}
+	if !apiKeyAllowCourse(c, course) {
+		return studentIdList, nil
+	}
+
+	user := security.GetUser(c)
+
+	switch user.Role {
+	case "teacher":
+		if user.ProfileId == course.InstructorId {
+			return nil, nil
+		}
+
+		// class advisor read student in class
+		classes, err := classRepo.GetClassByFilterAll(c.UserContext(), bson.M{"advisor_id": user.ProfileId})
+		if err != nil && !errors.Is(err, util.ErrNotFound) {
+			return nil, err
+		}
+		for _, class := range classes {
+			for _, v := range class.StudentIdList {
+				if isStudentInCourse(course, v) {
+					studentIdList = append(studentIdList, v)
+				}
+			}
+		}
+	case "student":
+		if isStudentInCourse(course, user.ProfileId) {
+			studentIdList = append(studentIdList, user.ProfileId)
+		}
+	case "parent":
+		p, err := profileRepo.GetProfileById(c.UserContext(), bson.M{"profile_id": user.ProfileId, "role": "parent"}, "parent")
+		if err != nil {
+			if errors.Is(err, util.ErrNotFound) {
+				return studentIdList, nil
+			}
+			return nil, err
+		}
+		for _, v := range p.(models.ProfileParent).StudentIdList {
+			if !isStudentInCourse(course, v) {
+				continue
+			}
+			s, err := profileRepo.GetProfileById(c.UserContext(), bson.M{"profile_id": v, "role": "student"}, "student")
+			if err != nil {
+				if errors.Is(err, util.ErrNotFound) {
+					continue
+				}
+				return nil, err
+			}
+			if s.(models.ProfileStudent).ParentId != user.ProfileId {
+				continue
+			}
+			studentIdList = append(studentIdList, v)
+		}
+	case "admin", "server":
+		return nil, nil
+	default:
+		allCourse, err := isCustomRoleOfAllCourse(c, user)
+		if err != nil {
+			return nil, err
+		}
+		if allCourse {
+			return nil, nil
+		}
+	}
+
+	return studentIdList, nil
+}
+
+func apiKeyAllowCourse(c *fiber.Ctx, course *models.Course) bool {
+	classId := ""
+	if course.ClassId != nil {
+		classId = course.ClassId.Hex()
+	}
+
+	return security.ApiKeyAllowCourse(security.GetApiKey(c), course.Id.Hex(), classId)
+}
+
+func isStudentInCourse(course *models.Course, studentId string) bool {
+	for _, v := range course.StudentIdList {
+		if v == studentId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isStudentInList(studentIdList []string, studentId string) bool {
+	for _, v := range studentIdList {
+		if v == studentId {
+			return true
+		}
+	}
+
+	return false
+}
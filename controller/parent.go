@@ -0,0 +1,380 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/repository"
+	"school-notification-backend/security"
+	"school-notification-backend/util"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var errNotParentOfStudent = errors.New("not permission")
+
+type ParentController interface {
+	GetStudentList(c *fiber.Ctx) error
+	GetStudentCheckName(c *fiber.Ctx) error
+	GetStudentScore(c *fiber.Ctx) error
+	GetStudentSummary(c *fiber.Ctx) error
+	AddStudent(c *fiber.Ctx) error
+}
+
+type parentController struct {
+	profileRepo         repository.ProfileRepository
+	courseRepo          repository.CourseRepository
+	checkNameRepository repository.CheckNameRepository
+	scoreRepository     repository.ScoreRepository
+	courseSummaryRepo   repository.CourseSummaryRepository
+	logger              logger.Logger
+}
+
+func NewParentController(profileRepo repository.ProfileRepository, courseRepo repository.CourseRepository, checkNameRepository repository.CheckNameRepository, scoreRepository repository.ScoreRepository, courseSummaryRepo repository.CourseSummaryRepository, logger logger.Logger) ParentController {
+	return &parentController{profileRepo: profileRepo, courseRepo: courseRepo, checkNameRepository: checkNameRepository, scoreRepository: scoreRepository, courseSummaryRepo: courseSummaryRepo, logger: logger}
+}
+
+func (p *parentController) GetStudentList(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	parent, err := p.getParent(c.UserContext(), user.ProfileId)
+	if err != nil {
+		p.logger.Error(c.UserContext(), "get student list", "error", err)
+		return err
+	}
+
+	if len(parent.StudentIdList) == 0 {
+		p.logger.Warn(c.UserContext(), "student", "error", util.ErrNotFound)
+		return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
+	}
+
+	students, err := p.profileRepo.GetProfileByFilterAll(c.UserContext(), bson.M{
+		"profile_id": bson.M{"$in": parent.StudentIdList},
+		"parent_id":  parent.ProfileId,
+		"role":       "student",
+	}, "student")
+	if err != nil {
+		p.logger.Error(c.UserContext(), "get student list", "error", err)
+		return err
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"student_list": students,
+	})
+}
+
+func (p *parentController) GetStudentCheckName(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	studentId, err := util.CheckStringData(c.Query("student_id"), "student_id")
+	if err != nil {
+		p.logger.Warn(c.UserContext(), "get student check name", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	p.logger.Debug(c.UserContext(), "find check name student id", "student_id", studentId)
+
+	courseId, err := util.CheckStringData(c.Query("course_id"), "course_id")
+	if err != nil {
+		p.logger.Warn(c.UserContext(), "get student check name", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	p.logger.Debug(c.UserContext(), "find check name course id", "course_id", courseId)
+
+	_, err = p.getStudentOfParent(c.UserContext(), user.ProfileId, studentId)
+	if err != nil {
+		p.logger.Warn(c.UserContext(), "get student check name", "error", err)
+		return p.responseStudentError(c, err)
+	}
+
+	course, err := p.getCourseOfStudent(c.UserContext(), courseId, studentId)
+	if err != nil {
+		p.logger.Warn(c.UserContext(), "get student check name", "error", err)
+		return p.responseStudentError(c, err)
+	}
+
+	checkNameList, err := p.checkNameRepository.GetByFilterAll(c.UserContext(), bson.M{"course_id": course.Id.Hex()})
+	if err != nil {
+		p.logger.Error(c.UserContext(), "get student check name", "error", err)
+		return err
+	}
+
+	checkNameListRes := newCheckNameStudentResList(checkNameList, studentId)
+	if len(checkNameListRes) == 0 {
+		p.logger.Warn(c.UserContext(), "student id not have checked")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "student id not have checked")
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"student_id": studentId,
+		"course_id":  courseId,
+		"date_data":  checkNameListRes,
+	})
+}
+
+func (p *parentController) GetStudentScore(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	studentId, err := util.CheckStringData(c.Query("student_id"), "student_id")
+	if err != nil {
+		p.logger.Warn(c.UserContext(), "get student score", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	p.logger.Debug(c.UserContext(), "find score student id", "student_id", studentId)
+
+	courseId, err := util.CheckStringData(c.Query("course_id"), "course_id")
+	if err != nil {
+		p.logger.Warn(c.UserContext(), "get student score", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	p.logger.Debug(c.UserContext(), "find score course id", "course_id", courseId)
+
+	_, err = p.getStudentOfParent(c.UserContext(), user.ProfileId, studentId)
+	if err != nil {
+		p.logger.Warn(c.UserContext(), "get student score", "error", err)
+		return p.responseStudentError(c, err)
+	}
+
+	course, err := p.getCourseOfStudent(c.UserContext(), courseId, studentId)
+	if err != nil {
+		p.logger.Warn(c.UserContext(), "get student score", "error", err)
+		return p.responseStudentError(c, err)
+	}
+
+	scores, err := p.scoreRepository.GetByFilterAll(c.UserContext(), bson.M{"course_id": course.Id.Hex()})
+	if err != nil {
+		p.logger.Error(c.UserContext(), "get student score", "error", err)
+		return err
+	}
+
+	scoreList := newScoreStudentResList(scores, studentId)
+	if len(scoreList) == 0 {
+		p.logger.Warn(c.UserContext(), "student id", "error", util.ErrNotFound)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "score "+util.ErrNotFound.Error())
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"student_id": studentId,
+		"course_id":  courseId,
+		"score_data": scoreList,
+	})
+}
+
+func (p *parentController) GetStudentSummary(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	studentId, err := util.CheckStringData(c.Query("student_id"), "student_id")
+	if err != nil {
+		p.logger.Warn(c.UserContext(), "get student summary", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	p.logger.Debug(c.UserContext(), "find summary student id", "student_id", studentId)
+
+	year, err := util.CheckStringData(c.Query("year"), "year")
+	if err != nil {
+		p.logger.Warn(c.UserContext(), "get student summary", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	p.logger.Debug(c.UserContext(), "year", "year", year)
+
+	term, err := util.CheckStringData(c.Query("term"), "term")
+	if err != nil {
+		p.logger.Warn(c.UserContext(), "get student summary", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	p.logger.Debug(c.UserContext(), "term", "term", term)
+
+	student, err := p.getStudentOfParent(c.UserContext(), user.ProfileId, studentId)
+	if err != nil {
+		p.logger.Warn(c.UserContext(), "get student summary", "error", err)
+		return p.responseStudentError(c, err)
+	}
+
+	index := -1
+	for i, v := range student.TermScore {
+		if v.Year == year && v.Term == term {
+			index = i
+			break
+		}
+	}
+
+	if index == -1 {
+		p.logger.Warn(c.UserContext(), "year or term not found")
+		return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
+	}
+
+	dataList := []models.StudentDataRes{}
+	for _, v := range student.TermScore[index].CourseList {
+		courseSum, err := p.courseSummaryRepo.GetByFilter(c.UserContext(), bson.M{"course_id": v.Id.Hex()})
+		if err != nil {
+			p.logger.Warn(c.UserContext(), "get student summary", "error", err)
+			continue
+		}
+
+		for _, data := range courseSum.StudentData {
+			if data.StudentId == studentId {
+				course, err := p.courseRepo.GetCourseById(c.UserContext(), courseSum.CourseId)
+				if err != nil {
+					p.logger.Error(c.UserContext(), "get student summary", "error", err)
+					return err
+				}
+				dataList = append(dataList, newStudentDataRes(course.Name, data))
+				break
+			}
+		}
+	}
+
+	if len(dataList) == 0 {
+		p.logger.Warn(c.UserContext(), "get student summary", "error", util.ErrNotFound)
+		return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"student_id":     studentId,
+		"course_summary": dataList,
+	})
+}
+
+func (p *parentController) AddStudent(c *fiber.Ctx) error {
+	req := models.ProfileRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		p.logger.Warn(c.UserContext(), "add student", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	parentId, err := util.CheckStringData(req.ProfileId, "profile_id")
+	if err != nil {
+		p.logger.Warn(c.UserContext(), "add student", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	p.logger.Debug(c.UserContext(), "parent id", "parent_id", parentId)
+
+	studentId, err := util.CheckStringData(req.StudentId, "student_id")
+	if err != nil {
+		p.logger.Warn(c.UserContext(), "add student", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	p.logger.Debug(c.UserContext(), "student id", "student_id", studentId)
+
+	parent, err := p.getParent(c.UserContext(), parentId)
+	if err != nil {
+		p.logger.Error(c.UserContext(), "add student", "error", err)
+		return err
+	}
+
+	for _, v := range parent.StudentIdList {
+		if v == studentId {
+			p.logger.Warn(c.UserContext(), "student_id", "error", util.ErrValueAlreadyExists)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "student_id"+util.ErrValueAlreadyExists.Error())
+		}
+	}
+
+	student, err := getStudentForParent(c.UserContext(), p.logger, p.profileRepo, studentId, parentId)
+	if err != nil {
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	t := time.Now()
+	security.AuditBefore(c, repository.ProfileCollection, student.Id.Hex(), student)
+	student.ParentId = parentId
+	student.UpdatedAt = t
+	_, err = p.profileRepo.Update(c.UserContext(), student.Id, bson.M{
+		"parent_id":  parentId,
+		"updated_at": t,
+	})
+	if err != nil {
+		p.logger.Error(c.UserContext(), "add student", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.ProfileCollection, student.Id.Hex(), student)
+
+	security.AuditBefore(c, repository.ProfileCollection, parent.Id.Hex(), parent)
+	parent.StudentIdList = append(parent.StudentIdList, studentId)
+	parent.UpdatedAt = t
+	result, err := p.profileRepo.Update(c.UserContext(), parent.Id, bson.M{
+		"student_id_list": parent.StudentIdList,
+		"updated_at":      t,
+	})
+	if err != nil {
+		p.logger.Error(c.UserContext(), "add student", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.ProfileCollection, parent.Id.Hex(), parent)
+
+	return util.ResponseSuccess(c, fiber.StatusCreated, "add student success", map[string]interface{}{
+		"profile_id":   parentId,
+		"student_id":   studentId,
+		"update_count": result.ModifiedCount,
+	})
+}
+
+func (p *parentController) getParent(ctx context.Context, parentId string) (*models.ProfileParent, error) {
+	pp, err := p.profileRepo.GetProfileById(ctx, bson.M{"profile_id": parentId, "role": "parent"}, "parent")
+	if err != nil {
+		return nil, err
+	}
+
+	parent := pp.(models.ProfileParent)
+	return &parent, nil
+}
+
+// the link is kept on both profile, both side must match
+func (p *parentController) getStudentOfParent(ctx context.Context, parentId string, studentId string) (*models.ProfileStudent, error) {
+	parent, err := p.getParent(ctx, parentId)
+	if err != nil {
+		return nil, err
+	}
+
+	check := true
+	for _, v := range parent.StudentIdList {
+		if v == studentId {
+			check = false
+			break
+		}
+	}
+	if check {
+		return nil, errNotParentOfStudent
+	}
+
+	ps, err := p.profileRepo.GetProfileById(ctx, bson.M{"profile_id": studentId, "role": "student"}, "student")
+	if err != nil {
+		return nil, err
+	}
+
+	student := ps.(models.ProfileStudent)
+	if student.ParentId != parentId {
+		return nil, errNotParentOfStudent
+	}
+
+	return &student, nil
+}
+
+func (p *parentController) getCourseOfStudent(ctx context.Context, courseId string, studentId string) (*models.Course, error) {
+	course, err := p.courseRepo.GetCourseById(ctx, courseId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range course.StudentIdList {
+		if v == studentId {
+			return course, nil
+		}
+	}
+
+	return nil, errNotParentOfStudent
+}
+
+func (p *parentController) responseStudentError(c *fiber.Ctx, err error) error {
+	if err == errNotParentOfStudent {
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, err.Error())
+	}
+	return err
+}
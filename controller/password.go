@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"school-notification-backend/models"
+	"school-notification-backend/repository"
+	"school-notification-backend/security"
+	"school-notification-backend/util"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// change own password with old password, other session of user are revoked
+func (a *authController) ChangePassword(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	req := models.PasswordRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "change password", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	oldPassword, err := util.CheckStringData(req.OldPassword, "old_password")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "change password", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	newPassword, err := util.CheckStringData(req.NewPassword, "new_password")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "change password", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	err = security.VerifyPassword(user.Password, oldPassword)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "change password failed", "username", user.Username, "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "invalid credentials")
+	}
+
+	if oldPassword == newPassword {
+		a.logger.Warn(c.UserContext(), "new password is same as old password")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "new password must not be the old password")
+	}
+
+	err = security.CheckPasswordPolicy(newPassword, user.Username)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "change password", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	security.AuditBefore(c, repository.UsersCollection, user.Id.Hex(), user)
+	user.Password, err = security.EncryptPassword(newPassword)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "change password", "error", err)
+		return util.ResponseError(c, err)
+	}
+	now := time.Now()
+	user.MustChangePassword = false
+	user.PasswordChangedAt = &now
+
+	_, err = a.userRepo.Update(c.UserContext(), user)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "change password", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.UsersCollection, user.Id.Hex(), user)
+
+	payload, err := security.ParseToken(c.GetReqHeaders()["Authorization"])
+	if err != nil {
+		a.logger.Error(c.UserContext(), "change password", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	result, err := a.revokeOtherSession(c, user.Id.Hex(), payload.Subject, user.Id.Hex(), revokeReasonPasswordChange)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "change password", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "change password success", map[string]interface{}{
+		"user_id":               user.Id,
+		"revoked_session_count": result.ModifiedCount,
+	})
+}
+
+// admin reset password to temporary password, user must change it on next sign in
+func (a *authController) ResetPassword(c *fiber.Ctx) error {
+	admin := security.GetUser(c)
+
+	req := models.PasswordRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "reset password", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	userId, err := util.CheckStringData(req.UserId, "user_id")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "reset password", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	a.logger.Debug(c.UserContext(), "reset password of user id", "user_id", userId)
+
+	user, err := a.userRepo.GetById(c.UserContext(), userId)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "reset password", "error", err)
+		return err
+	}
+
+	temporaryPassword, err := security.NewTemporaryPassword()
+	if err != nil {
+		a.logger.Error(c.UserContext(), "reset password", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	security.AuditBefore(c, repository.UsersCollection, user.Id.Hex(), user)
+	user.Password, err = security.EncryptPassword(temporaryPassword)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "reset password", "error", err)
+		return util.ResponseError(c, err)
+	}
+	now := time.Now()
+	user.MustChangePassword = true
+	user.PasswordChangedAt = &now
+
+	_, err = a.userRepo.Update(c.UserContext(), user)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "reset password", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.UsersCollection, user.Id.Hex(), user)
+
+	_, err = a.revokeUserSession(c, user.Id.Hex(), admin.Id.Hex(), revokeReasonPasswordReset)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "reset password", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "reset password success", map[string]interface{}{
+		"user_id":            user.Id,
+		"username":           user.Username,
+		"temporary_password": temporaryPassword,
+	})
+}
+
+func (a *authController) revokeOtherSession(c *fiber.Ctx, userId string, sessionId string, revokedBy string, reason string) (*mongo.UpdateResult, error) {
+	filter := bson.M{"user_id": userId, "revoked": false}
+	if oID, err := primitive.ObjectIDFromHex(sessionId); err == nil {
+		filter["_id"] = bson.M{"$ne": oID}
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"revoked":       true,
+		"revoked_at":    now,
+		"revoked_by":    revokedBy,
+		"revoke_reason": reason,
+		"updated_at":    now,
+	}
+
+	result, err := a.sessionRepo.UpdateMany(c.UserContext(), filter, update)
+	if err != nil {
+		return nil, err
+	}
+	auditUpdateMany(c, repository.SessionCollection, filter, update, result.ModifiedCount)
+
+	return result, nil
+}
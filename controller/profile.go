@@ -1,19 +1,20 @@
 package controller
 
 import (
+	"context"
 	"errors"
-	"log"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/repository"
 	"school-notification-backend/security"
 	"school-notification-backend/util"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type ProfileController interface {
@@ -30,33 +31,25 @@ type profileController struct {
 	schoolDataRepository repository.SchoolDataRepository
 	userRepo             repository.UsersRepository
 	faceDetectionRepo    repository.FaceDetectionRepository
+	logger               logger.Logger
 }
 
-func NewProfileController(profileRepo repository.ProfileRepository, classRepo repository.ClassRepository, schoolDataRepository repository.SchoolDataRepository, userRepo repository.UsersRepository, faceDetectionRepo repository.FaceDetectionRepository) ProfileController {
-	return &profileController{profileRepo: profileRepo, classRepo: classRepo, schoolDataRepository: schoolDataRepository, userRepo: userRepo, faceDetectionRepo: faceDetectionRepo}
+func NewProfileController(profileRepo repository.ProfileRepository, classRepo repository.ClassRepository, schoolDataRepository repository.SchoolDataRepository, userRepo repository.UsersRepository, faceDetectionRepo repository.FaceDetectionRepository, logger logger.Logger) ProfileController {
+	return &profileController{profileRepo: profileRepo, classRepo: classRepo, schoolDataRepository: schoolDataRepository, userRepo: userRepo, faceDetectionRepo: faceDetectionRepo, logger: logger}
 }
 
 func (p *profileController) GetProfileAllByRole(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], p.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	role, err := util.CheckStringData(c.Query("role"), "role")
 	if err != nil {
-		log.Println(err)
+		p.logger.Warn(c.UserContext(), "get profile all by role", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find profile role is:", role)
+	p.logger.Debug(c.UserContext(), "find profile role is", "role", role)
 
-	profiles, err := p.profileRepo.GetAll(role)
+	profiles, err := p.profileRepo.GetAll(c.UserContext(), role)
 	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		p.logger.Error(c.UserContext(), "get profile all by role", "error", err)
+		return err
 	}
 
 	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
@@ -66,45 +59,33 @@ func (p *profileController) GetProfileAllByRole(c *fiber.Ctx) error {
 }
 
 func (p *profileController) GetProfileByProfileId(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], p.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	profileId, err := util.CheckStringData(c.Query("profile_id"), "profile_id")
 	if err != nil {
-		log.Println(err)
+		p.logger.Warn(c.UserContext(), "get profile by profile id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find profile id is:", profileId)
+	p.logger.Debug(c.UserContext(), "find profile id is", "profile_id", profileId)
 
 	role, err := util.CheckStringData(c.Query("role"), "role")
 	if err != nil {
-		log.Println(err)
+		p.logger.Warn(c.UserContext(), "get profile by profile id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find profile role is:", role)
+	p.logger.Debug(c.UserContext(), "find profile role is", "role", role)
 
 	filter := bson.M{
 		"profile_id": profileId,
 		"role":       role,
 	}
 
-	profile, err := p.profileRepo.GetProfileById(filter, role)
+	profile, err := p.profileRepo.GetProfileById(c.UserContext(), filter, role)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		p.logger.Error(c.UserContext(), "get profile by profile id", "error", err)
+		return err
 	}
 
 	if profile == nil {
-		log.Println("Profile not found")
+		p.logger.Warn(c.UserContext(), "profile not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -114,33 +95,21 @@ func (p *profileController) GetProfileByProfileId(c *fiber.Ctx) error {
 }
 
 func (p *profileController) GetProfileById(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], p.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	id, err := util.CheckStringData(c.Query("id"), "id")
 	if err != nil {
-		log.Println(err)
+		p.logger.Warn(c.UserContext(), "get profile by id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find id is:", id)
+	p.logger.Debug(c.UserContext(), "find id is", "id", id)
 
-	profile, err := p.profileRepo.GetProfileByIdHex(id)
+	profile, err := p.profileRepo.GetProfileByIdHex(c.UserContext(), id)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		p.logger.Error(c.UserContext(), "get profile by id", "error", err)
+		return err
 	}
 
 	if profile == nil {
-		log.Println("Profile not found")
+		p.logger.Warn(c.UserContext(), "profile not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -150,38 +119,26 @@ func (p *profileController) GetProfileById(c *fiber.Ctx) error {
 }
 
 func (p *profileController) GetProfileTeacherByCategory(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], p.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	category, err := util.CheckStringData(c.Query("category"), "category")
 	if err != nil {
-		log.Println(err)
+		p.logger.Warn(c.UserContext(), "get profile teacher by category", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find profile category:", category)
+	p.logger.Debug(c.UserContext(), "find profile category", "category", category)
 
 	filter := bson.M{
 		"category": category,
 		"role":     "teacher",
 	}
 
-	profiles, err := p.profileRepo.GetProfileByFilterAll(filter, "teacher")
+	profiles, err := p.profileRepo.GetProfileByFilterAll(c.UserContext(), filter, "teacher")
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		p.logger.Error(c.UserContext(), "get profile teacher by category", "error", err)
+		return err
 	}
 
 	if len(profiles) == 0 {
-		log.Println("profile not found")
+		p.logger.Warn(c.UserContext(), "profile not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -191,16 +148,10 @@ func (p *profileController) GetProfileTeacherByCategory(c *fiber.Ctx) error {
 }
 
 func (p *profileController) CreateNewProfile(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], p.userRepo, []string{"admin"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.ProfileRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		p.logger.Warn(c.UserContext(), "create new profile", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -211,110 +162,124 @@ func (p *profileController) CreateNewProfile(c *fiber.Ctx) error {
 
 	req.Role, err = util.CheckStringData(req.Role, "role")
 	if err != nil {
-		log.Println(err)
+		p.logger.Warn(c.UserContext(), "create new profile", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("create profile role:", req.Role)
+	p.logger.Debug(c.UserContext(), "create profile role", "role", req.Role)
 
 	req.ProfileId, err = util.CheckStringData(req.ProfileId, "profile_id")
 	if err != nil {
-		log.Println(err)
+		p.logger.Warn(c.UserContext(), "create new profile", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("profile id:", req.ProfileId)
+	p.logger.Debug(c.UserContext(), "profile id", "profile_id", req.ProfileId)
 
 	// validate and create profile
 	var profile interface{}
+	students := []models.ProfileStudent{}
 	if req.Role == "teacher" {
-		profile, err = newTeacherProfile(req, p.profileRepo, p.schoolDataRepository)
+		profile, err = newTeacherProfile(c.UserContext(), p.logger, req, p.profileRepo, p.schoolDataRepository)
 	} else if req.Role == "student" {
-		profile, err = newStudentProfile(req, p.profileRepo, p.classRepo, p.faceDetectionRepo)
+		profile, err = newStudentProfile(c, p.logger, req, p.profileRepo, p.classRepo, p.faceDetectionRepo)
 	} else if req.Role == "parent" {
-		// profile, err = newParentProfile(req, p.profileRepo)
+		profile, students, err = newParentProfile(c, p.logger, req, p.profileRepo)
 	} else {
-		log.Println("role is invalid")
+		p.logger.Warn(c.UserContext(), "role is invalid")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "role"+util.ErrValueInvalid.Error())
 	}
 	if err != nil {
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
 
-	profileInsert, err := p.profileRepo.Insert(profile)
+	profileInsert, err := p.profileRepo.Insert(c.UserContext(), profile)
 	// insert
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		p.logger.Error(c.UserContext(), "create new profile", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	id := profileInsert.InsertedID.(primitive.ObjectID)
+	security.AuditAfter(c, repository.ProfileCollection, id.Hex(), profile)
+
+	// student point to parent after parent is insert, so it never point to parent that does not exist
+	err = setParentOfStudent(c, p.logger, p.profileRepo, req.ProfileId, students)
+	if err != nil {
+		p.logger.Error(c.UserContext(), "create new profile", "error", err)
+		return util.ResponseError(c, err)
 	}
 
 	password, err := security.EncryptPassword(req.ProfileId)
 	if err != nil {
-		log.Println(err)
+		p.logger.Warn(c.UserContext(), "create new profile", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
 
 	// sign up
-	id := profileInsert.InsertedID.(primitive.ObjectID)
 	user := models.User{
 		Id:        primitive.NewObjectID(),
-		CreatedAt: time.Now().Format(time.RFC3339),
+		CreatedAt: time.Now(),
 		Username:  req.ProfileId,
 		Password:  password,
 		ProfileId: req.ProfileId,
 		Role:      req.Role,
 		UserId:    id.Hex(),
+
+		// first password is profile id
+		MustChangePassword: true,
 	}
 
-	result, err := p.userRepo.InsertUser(&user)
+	result, err := p.userRepo.InsertUser(c.UserContext(), &user)
 	if err != nil {
-		log.Println(err)
+		p.logger.Warn(c.UserContext(), "create new profile", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
+	security.AuditAfter(c, repository.UsersCollection, user.Id.Hex(), user)
 
-	log.Println("result sign up:", result)
-	log.Println("create profile success")
-	log.Println("profile id:", id)
+	p.logger.Debug(c.UserContext(), "result sign up", "result", result)
+	p.logger.Debug(c.UserContext(), "create profile success")
+	p.logger.Debug(c.UserContext(), "profile id", "id", id)
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "create profile success", map[string]interface{}{
 		"profile_id": req.ProfileId,
 	})
 }
 
-func newTeacherProfile(req models.ProfileRequest, profileRepo repository.ProfileRepository, schoolDataRepository repository.SchoolDataRepository) (*models.ProfileTeacher, error) {
+func newTeacherProfile(ctx context.Context, logger logger.Logger, req models.ProfileRequest, profileRepo repository.ProfileRepository, schoolDataRepository repository.SchoolDataRepository) (*models.ProfileTeacher, error) {
 
-	err := profileRepo.GetProfileByFilterForCheckExists(bson.M{
+	err := profileRepo.GetProfileByFilterForCheckExists(ctx, bson.M{
 		"profile_id": req.ProfileId,
 		"role":       req.Role})
 	if err == nil {
-		log.Println(util.ErrProfileIdAlreadyExists)
+		logger.Warn(ctx, "new teacher profile", "error", util.ErrProfileIdAlreadyExists)
 		return nil, util.ErrProfileIdAlreadyExists
 	}
-	if err.Error() != "mongo: no documents in result" {
-		log.Println(err)
+	if !errors.Is(err, util.ErrNotFound) {
+		logger.Warn(ctx, "new teacher profile", "error", err)
 		return nil, util.ErrInternalServerError
 	}
 
 	name, err := util.CheckStringData(req.Name, "name")
 	if err != nil {
-		log.Println(err)
+		logger.Warn(ctx, "new teacher profile", "error", err)
 		return nil, err
 	}
-	log.Println("create profile name:", name)
+	logger.Debug(ctx, "create profile name", "name", name)
 
 	category, err := util.CheckStringData(req.Category, "category")
 	if err != nil {
-		log.Println(err)
+		logger.Warn(ctx, "new teacher profile", "error", err)
 		return nil, err
 	}
-	log.Println("create profile category:", category)
+	logger.Debug(ctx, "create profile category", "category", category)
 
-	dataListSub, err := schoolDataRepository.GetByFilterAll(bson.M{"type": "SubjectCategory"})
+	dataListSub, err := schoolDataRepository.GetByFilterAll(ctx, bson.M{"type": "SubjectCategory"})
 	if err != nil {
-		log.Println(err)
+		logger.Warn(ctx, "new teacher profile", "error", err)
 		return nil, err
 	}
 
 	if len(dataListSub) == 0 {
-		log.Println("data SubjectCategory not found")
+		logger.Warn(ctx, "data SubjectCategory not found")
 		return nil, util.ErrNotFound
 	}
 
@@ -327,27 +292,27 @@ func newTeacherProfile(req models.ProfileRequest, profileRepo repository.Profile
 	}
 
 	if check {
-		log.Println("category", util.ErrValueNotAlreadyExists)
+		logger.Warn(ctx, "category", "error", util.ErrValueNotAlreadyExists)
 		return nil, util.ReturnError("category" + util.ErrValueNotAlreadyExists.Error())
 	}
 
-	dataList, err := schoolDataRepository.GetByFilterAll(bson.M{"type": "YearAndTerm"})
+	dataList, err := schoolDataRepository.GetByFilterAll(ctx, bson.M{"type": "YearAndTerm"})
 	if err != nil {
-		log.Println(err)
+		logger.Warn(ctx, "new teacher profile", "error", err)
 		return nil, err
 	}
 
 	if len(dataList) == 0 {
-		log.Println("data YearAndTerm not found")
+		logger.Warn(ctx, "data YearAndTerm not found")
 		return nil, util.ErrNotFound
 	}
 
 	sort.Slice(dataList, func(i, j int) bool {
-		return dataList[i].CreatedAt > dataList[j].CreatedAt
+		return dataList[i].CreatedAt.After(dataList[j].CreatedAt)
 	})
 
 	if *dataList[0].Status == true {
-		log.Println("school data invalid")
+		logger.Warn(ctx, "school data invalid")
 		return nil, errors.New("school data invalid")
 	}
 
@@ -356,12 +321,14 @@ func newTeacherProfile(req models.ProfileRequest, profileRepo repository.Profile
 
 	p := models.ProfileTeacher{
 		Id:        primitive.NewObjectID(),
-		CreatedAt: time.Now().Format(time.RFC3339),
-		UpdatedAt: time.Now().Format(time.RFC3339),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 		ProfileId: req.ProfileId,
 		Name:      name,
 		Role:      req.Role,
 		Category:  category,
+		Email:     strings.TrimSpace(req.Email),
+		Phone:     strings.TrimSpace(req.Phone),
 		Slot:      createTimeSlot(),
 		CourseTeachesList: []models.CourseTeachesList{
 			{
@@ -374,40 +341,55 @@ func newTeacherProfile(req models.ProfileRequest, profileRepo repository.Profile
 	return &p, nil
 }
 
-func newStudentProfile(req models.ProfileRequest, profileRepo repository.ProfileRepository, classRepo repository.ClassRepository, faceDetectionRepo repository.FaceDetectionRepository) (*models.ProfileStudent, error) {
+func newStudentProfile(c *fiber.Ctx, logger logger.Logger, req models.ProfileRequest, profileRepo repository.ProfileRepository, classRepo repository.ClassRepository, faceDetectionRepo repository.FaceDetectionRepository) (*models.ProfileStudent, error) {
 
-	err := profileRepo.GetProfileByFilterForCheckExists(bson.M{
+	err := profileRepo.GetProfileByFilterForCheckExists(c.UserContext(), bson.M{
 		"profile_id": req.ProfileId,
 		"role":       req.Role})
 	if err == nil {
-		log.Println(util.ErrProfileIdAlreadyExists)
+		logger.Warn(c.UserContext(), "new student profile", "error", util.ErrProfileIdAlreadyExists)
 		return nil, util.ErrProfileIdAlreadyExists
 	}
-	if err.Error() != "mongo: no documents in result" {
-		log.Println(err)
+	if !errors.Is(err, util.ErrNotFound) {
+		logger.Warn(c.UserContext(), "new student profile", "error", err)
 		return nil, util.ErrInternalServerError
 	}
 
 	name, err := util.CheckStringData(req.Name, "name")
 	if err != nil {
-		log.Println(err)
+		logger.Warn(c.UserContext(), "new student profile", "error", err)
 		return nil, err
 	}
-	log.Println("create profile name:", name)
+	logger.Debug(c.UserContext(), "create profile name", "name", name)
 
 	classId, err := util.CheckStringData(req.ClassId, "class_id")
 	if err != nil {
-		log.Println(err)
+		logger.Warn(c.UserContext(), "new student profile", "error", err)
 		return nil, err
 	}
-	log.Println("create profile in class id:", classId)
+	logger.Debug(c.UserContext(), "create profile in class id", "class_id", classId)
 
-	class, err := classRepo.GetClassById(req.ClassId)
+	class, err := classRepo.GetClassById(c.UserContext(), req.ClassId)
 	if err != nil {
-		log.Println(err)
+		logger.Warn(c.UserContext(), "new student profile", "error", err)
 		return nil, err
 	}
 
+	var parent *models.ProfileParent
+	if req.ParentId != "" {
+		logger.Debug(c.UserContext(), "create profile parent id", "parent_id", req.ParentId)
+		pp, err := profileRepo.GetProfileById(c.UserContext(), bson.M{"profile_id": req.ParentId, "role": "parent"}, "parent")
+		if err != nil {
+			logger.Warn(c.UserContext(), "new student profile", "error", err)
+			if errors.Is(err, util.ErrNotFound) {
+				return nil, util.ReturnError("parent_id" + util.ErrValueNotAlreadyExists.Error())
+			}
+			return nil, err
+		}
+		profileParent := pp.(models.ProfileParent)
+		parent = &profileParent
+	}
+
 	check := true
 	for _, s := range class.StudentIdList {
 		if s == req.ProfileId {
@@ -416,38 +398,58 @@ func newStudentProfile(req models.ProfileRequest, profileRepo repository.Profile
 		}
 	}
 	if check {
+		security.AuditBefore(c, repository.ClassCollection, class.Id.Hex(), class)
 		class.StudentIdList = append(class.StudentIdList, req.ProfileId)
 		class.NumberOfStudent = len(class.StudentIdList)
-		_, err = classRepo.Update(class)
+		_, err = classRepo.Update(c.UserContext(), class)
 		if err != nil {
-			log.Println(err)
+			logger.Warn(c.UserContext(), "new student profile", "error", err)
 			return nil, err
 		}
+		security.AuditAfter(c, repository.ClassCollection, class.Id.Hex(), class)
 	}
 
-	faceData, err := faceDetectionRepo.GetByFilter(bson.M{"class_id": classId})
+	faceData, err := faceDetectionRepo.GetByFilter(c.UserContext(), bson.M{"class_id": classId})
 	if err != nil {
-		log.Println(err)
+		logger.Warn(c.UserContext(), "new student profile", "error", err)
 		return nil, err
 	}
+	security.AuditBefore(c, repository.FaceDetectionCollection, faceData.Id.Hex(), faceData)
 	faceData.StudentIdList = append(faceData.StudentIdList, req.ProfileId)
 	faceData.NumberOfStudent = len(faceData.StudentIdList)
 	// var res [][]string
 	faceData.ImageStudentPathList = append(faceData.ImageStudentPathList, []string{})
-	_, err = faceDetectionRepo.Update(faceData)
+	_, err = faceDetectionRepo.Update(c.UserContext(), faceData)
 	if err != nil {
-		log.Println(err)
+		logger.Warn(c.UserContext(), "new student profile", "error", err)
 		return nil, err
 	}
+	security.AuditAfter(c, repository.FaceDetectionCollection, faceData.Id.Hex(), faceData)
+
+	if parent != nil {
+		security.AuditBefore(c, repository.ProfileCollection, parent.Id.Hex(), parent)
+		parent.StudentIdList = append(parent.StudentIdList, req.ProfileId)
+		parent.UpdatedAt = time.Now()
+		_, err = profileRepo.Update(c.UserContext(), parent.Id, bson.M{
+			"student_id_list": parent.StudentIdList,
+			"updated_at":      parent.UpdatedAt,
+		})
+		if err != nil {
+			logger.Warn(c.UserContext(), "new student profile", "error", err)
+			return nil, err
+		}
+		security.AuditAfter(c, repository.ProfileCollection, parent.Id.Hex(), parent)
+	}
 
 	p := models.ProfileStudent{
 		Id:        primitive.NewObjectID(),
-		CreatedAt: time.Now().Format(time.RFC3339),
-		UpdatedAt: time.Now().Format(time.RFC3339),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 		Name:      req.Name,
 		Role:      req.Role,
 		ProfileId: req.ProfileId,
 		ClassId:   req.ClassId,
+		ParentId:  req.ParentId,
 		TermScore: []models.TermScore{
 			{
 				Year: class.Year,
@@ -458,3 +460,109 @@ func newStudentProfile(req models.ProfileRequest, profileRepo repository.Profile
 
 	return &p, nil
 }
+
+// student of parent is return, it is set to parent after parent is insert
+func newParentProfile(c *fiber.Ctx, logger logger.Logger, req models.ProfileRequest, profileRepo repository.ProfileRepository) (*models.ProfileParent, []models.ProfileStudent, error) {
+
+	err := profileRepo.GetProfileByFilterForCheckExists(c.UserContext(), bson.M{
+		"profile_id": req.ProfileId,
+		"role":       req.Role})
+	if err == nil {
+		logger.Warn(c.UserContext(), "new parent profile", "error", util.ErrProfileIdAlreadyExists)
+		return nil, nil, util.ErrProfileIdAlreadyExists
+	}
+	if !errors.Is(err, util.ErrNotFound) {
+		logger.Warn(c.UserContext(), "new parent profile", "error", err)
+		return nil, nil, util.ErrInternalServerError
+	}
+
+	name, err := util.CheckStringData(req.Name, "name")
+	if err != nil {
+		logger.Warn(c.UserContext(), "new parent profile", "error", err)
+		return nil, nil, err
+	}
+	logger.Debug(c.UserContext(), "create profile name", "name", name)
+
+	students := []models.ProfileStudent{}
+	studentIdList := []string{}
+	for _, v := range req.StudentIdList {
+		studentId, err := util.CheckStringData(v, "student_id")
+		if err != nil {
+			logger.Warn(c.UserContext(), "new parent profile", "error", err)
+			return nil, nil, err
+		}
+
+		check := true
+		for _, id := range studentIdList {
+			if id == studentId {
+				check = false
+				break
+			}
+		}
+		if !check {
+			continue
+		}
+
+		student, err := getStudentForParent(c.UserContext(), logger, profileRepo, studentId, req.ProfileId)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		students = append(students, student)
+		studentIdList = append(studentIdList, studentId)
+	}
+	logger.Debug(c.UserContext(), "create profile student id list", "student_id_list", studentIdList)
+
+	p := models.ProfileParent{
+		Id:            primitive.NewObjectID(),
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+		ProfileId:     req.ProfileId,
+		Name:          name,
+		Role:          req.Role,
+		Email:         strings.TrimSpace(req.Email),
+		Phone:         strings.TrimSpace(req.Phone),
+		StudentIdList: studentIdList,
+	}
+
+	return &p, students, nil
+}
+
+func setParentOfStudent(c *fiber.Ctx, logger logger.Logger, profileRepo repository.ProfileRepository, parentId string, students []models.ProfileStudent) error {
+	for _, v := range students {
+		security.AuditBefore(c, repository.ProfileCollection, v.Id.Hex(), v)
+		v.ParentId = parentId
+		v.UpdatedAt = time.Now()
+		_, err := profileRepo.Update(c.UserContext(), v.Id, bson.M{
+			"parent_id":  v.ParentId,
+			"updated_at": v.UpdatedAt,
+		})
+		if err != nil {
+			logger.Warn(c.UserContext(), "set parent of student", "error", err)
+			return err
+		}
+		security.AuditAfter(c, repository.ProfileCollection, v.Id.Hex(), v)
+	}
+
+	return nil
+}
+
+// student can link to only one parent
+func getStudentForParent(ctx context.Context, logger logger.Logger, profileRepo repository.ProfileRepository, studentId string, parentId string) (models.ProfileStudent, error) {
+	p, err := profileRepo.GetProfileById(ctx, bson.M{"profile_id": studentId, "role": "student"}, "student")
+	if err != nil {
+		logger.Warn(ctx, "get student for parent", "error", err)
+		if errors.Is(err, util.ErrNotFound) {
+			return models.ProfileStudent{}, util.ReturnError("student_id " + studentId + util.ErrValueNotAlreadyExists.Error())
+		}
+		return models.ProfileStudent{}, err
+	}
+
+	student := p.(models.ProfileStudent)
+	if student.ParentId != "" && student.ParentId != parentId {
+		logger.Warn(ctx, "student already has parent", "parent_id", student.ParentId)
+		return models.ProfileStudent{}, util.ReturnError("student_id " + studentId + " already has parent")
+	}
+
+	return student, nil
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"context"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/realtime"
+	"school-notification-backend/repository"
+	"school-notification-backend/security"
+	"school-notification-backend/util"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+)
+
+type RealtimeController interface {
+	Upgrade(c *fiber.Ctx) error
+	Connect(conn *websocket.Conn)
+}
+
+type realtimeController struct {
+	hub      realtime.Hub
+	userRepo repository.UsersRepository
+	logger   logger.Logger
+}
+
+func NewRealtimeController(hub realtime.Hub, userRepo repository.UsersRepository, logger logger.Logger) RealtimeController {
+	return &realtimeController{hub: hub, userRepo: userRepo, logger: logger}
+}
+
+// check token before upgrade, browser can not set header in websocket so token can send in query
+func (r *realtimeController) Upgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return util.ResponseNotSuccess(c, fiber.StatusUpgradeRequired, "websocket upgrade required")
+	}
+
+	token := c.GetReqHeaders()["Authorization"]
+	if token == "" && c.Query("token") != "" {
+		token = "Bearer " + c.Query("token")
+	}
+
+	user, err := security.CheckRoleFromToken(c.UserContext(), token, r.userRepo, []string{"all"})
+	if err != nil {
+		r.logger.Warn(c.UserContext(), "upgrade", "error", err)
+		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
+	}
+
+	if user.Role == "server" {
+		r.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, "not permission")
+	}
+
+	c.Locals("user", user)
+	// websocket connection does not have user context, keep request id for its log
+	c.Locals("request_id", logger.RequestId(c.UserContext()))
+	return c.Next()
+}
+
+func (r *realtimeController) Connect(conn *websocket.Conn) {
+	user, ok := conn.Locals("user").(*models.User)
+	if !ok {
+		conn.Close()
+		return
+	}
+	requestId, _ := conn.Locals("request_id").(string)
+	ctx := logger.WithRequestId(context.Background(), requestId)
+	r.logger.Debug(ctx, "realtime connect user id", "user_id", user.UserId)
+
+	userSub, err := r.hub.Subscribe(realtime.UserTopic(user.UserId))
+	if err != nil {
+		r.logger.Warn(ctx, "connect", "error", err)
+		conn.Close()
+		return
+	}
+	defer userSub.Unsubscribe()
+
+	profileSub, err := r.hub.Subscribe(realtime.ProfileTopic(user.Role, user.ProfileId))
+	if err != nil {
+		r.logger.Warn(ctx, "connect", "error", err)
+		conn.Close()
+		return
+	}
+	defer profileSub.Unsubscribe()
+
+	// client does not send data, read only for detect close
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(30 * time.Second)
+	defer ping.Stop()
+
+	for {
+		var payload []byte
+		select {
+		case <-done:
+			r.logger.Warn(ctx, "realtime disconnect user id", "user_id", user.UserId)
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+				r.logger.Warn(ctx, "connect", "error", err)
+				return
+			}
+			continue
+		case payload, ok = <-userSub.Messages():
+		case payload, ok = <-profileSub.Messages():
+		}
+
+		if !ok {
+			conn.Close()
+			return
+		}
+
+		if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+			r.logger.Warn(ctx, "connect", "error", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package controller
+
+import (
+	"errors"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/repository"
+	"school-notification-backend/security"
+	"school-notification-backend/util"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RoleController interface {
+	CreateRole(c *fiber.Ctx) error
+	UpdateRole(c *fiber.Ctx) error
+	DeleteRole(c *fiber.Ctx) error
+	GetRoleAll(c *fiber.Ctx) error
+	GetPermissionAll(c *fiber.Ctx) error
+	AssignRole(c *fiber.Ctx) error
+}
+
+type roleController struct {
+	roleRepo repository.RoleRepository
+	userRepo repository.UsersRepository
+	logger   logger.Logger
+}
+
+func NewRoleController(roleRepo repository.RoleRepository, userRepo repository.UsersRepository, logger logger.Logger) RoleController {
+	return &roleController{roleRepo: roleRepo, userRepo: userRepo, logger: logger}
+}
+
+func (r *roleController) CreateRole(c *fiber.Ctx) error {
+	req := models.RoleRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		r.logger.Warn(c.UserContext(), "create role", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	name, err := util.CheckStringData(strings.ToLower(req.Name), "name")
+	if err != nil {
+		r.logger.Warn(c.UserContext(), "create role", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	r.logger.Debug(c.UserContext(), "role name", "name", name)
+
+	if security.IsBuiltInRole(name) || name == "all" {
+		r.logger.Warn(c.UserContext(), "role name is built-in role")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "name"+util.ErrValueAlreadyExists.Error())
+	}
+
+	_, err = r.roleRepo.GetByName(c.UserContext(), name)
+	if err == nil {
+		r.logger.Warn(c.UserContext(), "role name already exists")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "name"+util.ErrValueAlreadyExists.Error())
+	}
+	if !errors.Is(err, util.ErrNotFound) {
+		r.logger.Error(c.UserContext(), "create role", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	err = checkPermissionList(req.Permissions)
+	if err != nil {
+		r.logger.Warn(c.UserContext(), "create role", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	r.logger.Debug(c.UserContext(), "permissions", "permissions", req.Permissions)
+
+	role := &models.Role{
+		Id:          primitive.NewObjectID(),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		Name:        name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	}
+
+	_, err = r.roleRepo.Insert(c.UserContext(), role)
+	if err != nil {
+		r.logger.Error(c.UserContext(), "create role", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.RoleCollection, role.Id.Hex(), role)
+
+	return util.ResponseSuccess(c, fiber.StatusCreated, "create role success", map[string]interface{}{
+		"role_id": role.Id,
+	})
+}
+
+func (r *roleController) UpdateRole(c *fiber.Ctx) error {
+	req := models.RoleRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		r.logger.Warn(c.UserContext(), "update role", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	id, err := util.CheckStringData(req.Id, "id")
+	if err != nil {
+		r.logger.Warn(c.UserContext(), "update role", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	r.logger.Debug(c.UserContext(), "role id", "id", id)
+
+	role, err := r.roleRepo.GetById(c.UserContext(), id)
+	if err != nil {
+		r.logger.Error(c.UserContext(), "update role", "error", err)
+		return err
+	}
+
+	err = checkPermissionList(req.Permissions)
+	if err != nil {
+		r.logger.Warn(c.UserContext(), "update role", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	r.logger.Debug(c.UserContext(), "permissions", "permissions", req.Permissions)
+
+	security.AuditBefore(c, repository.RoleCollection, role.Id.Hex(), role)
+	role.Permissions = req.Permissions
+	if req.Description != "" {
+		role.Description = req.Description
+	}
+	role.UpdatedAt = time.Now()
+
+	result, err := r.roleRepo.Update(c.UserContext(), role)
+	if err != nil {
+		r.logger.Error(c.UserContext(), "update role", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.RoleCollection, role.Id.Hex(), role)
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "update role success", map[string]interface{}{
+		"role_id":      role.Id,
+		"update_count": result.ModifiedCount,
+	})
+}
+
+// role can delete when no user has the role
+func (r *roleController) DeleteRole(c *fiber.Ctx) error {
+	req := models.RoleRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		r.logger.Warn(c.UserContext(), "delete role", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	id, err := util.CheckStringData(req.Id, "id")
+	if err != nil {
+		r.logger.Warn(c.UserContext(), "delete role", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	r.logger.Debug(c.UserContext(), "role id", "id", id)
+
+	role, err := r.roleRepo.GetById(c.UserContext(), id)
+	if err != nil {
+		r.logger.Error(c.UserContext(), "delete role", "error", err)
+		return err
+	}
+
+	users, err := r.userRepo.GetAll(c.UserContext())
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		r.logger.Error(c.UserContext(), "delete role", "error", err)
+		return util.ResponseError(c, err)
+	}
+	for _, u := range users {
+		if u.Role == role.Name {
+			r.logger.Warn(c.UserContext(), "role is used by user", "role_is_used_by_user", u.Id.Hex())
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "role is used by user")
+		}
+	}
+
+	security.AuditBefore(c, repository.RoleCollection, role.Id.Hex(), role)
+	result, err := r.roleRepo.Delete(c.UserContext(), role.Id)
+	if err != nil {
+		r.logger.Error(c.UserContext(), "delete role", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.RoleCollection, role.Id.Hex(), nil)
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "delete role success", map[string]interface{}{
+		"role_id":      role.Id,
+		"delete_count": result.DeletedCount,
+	})
+}
+
+func (r *roleController) GetRoleAll(c *fiber.Ctx) error {
+	roles, err := r.roleRepo.GetAll(c.UserContext())
+	if err != nil {
+		r.logger.Error(c.UserContext(), "get role all", "error", err)
+		return err
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"roles": roles,
+	})
+}
+
+func (r *roleController) GetPermissionAll(c *fiber.Ctx) error {
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"built_in_roles": security.BuiltInRoles,
+		"permissions":    security.PermissionList(),
+	})
+}
+
+// assign custom role to user
+func (r *roleController) AssignRole(c *fiber.Ctx) error {
+	req := models.RoleRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		r.logger.Warn(c.UserContext(), "assign role", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	userId, err := util.CheckStringData(req.UserId, "user_id")
+	if err != nil {
+		r.logger.Warn(c.UserContext(), "assign role", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	r.logger.Debug(c.UserContext(), "user id", "user_id", userId)
+
+	name, err := util.CheckStringData(strings.ToLower(req.Name), "name")
+	if err != nil {
+		r.logger.Warn(c.UserContext(), "assign role", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	r.logger.Debug(c.UserContext(), "role name", "name", name)
+
+	_, err = r.roleRepo.GetByName(c.UserContext(), name)
+	if err != nil {
+		r.logger.Error(c.UserContext(), "assign role", "error", err)
+		if errors.Is(err, util.ErrNotFound) {
+			return util.ResponseNotSuccess(c, fiber.StatusNotFound, "role "+util.ErrNotFound.Error())
+		}
+		return util.ResponseError(c, err)
+	}
+
+	user, err := r.userRepo.GetById(c.UserContext(), userId)
+	if err != nil {
+		r.logger.Error(c.UserContext(), "assign role", "error", err)
+		return err
+	}
+
+	security.AuditBefore(c, repository.UsersCollection, user.Id.Hex(), user)
+	user.Role = name
+	result, err := r.userRepo.Update(c.UserContext(), user)
+	if err != nil {
+		r.logger.Error(c.UserContext(), "assign role", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.UsersCollection, user.Id.Hex(), user)
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "assign role success", map[string]interface{}{
+		"user_id":      user.Id,
+		"role":         user.Role,
+		"update_count": result.ModifiedCount,
+	})
+}
+
+func checkPermissionList(permissions []string) error {
+	if len(permissions) == 0 {
+		return util.ReturnError(util.ErrRequireParameter.Error() + "permissions")
+	}
+
+	for _, v := range permissions {
+		if !security.IsPermission(v) {
+			return util.ReturnError("permission " + v + util.ErrValueInvalid.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,406 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"school-notification-backend/models"
+	"school-notification-backend/repository"
+	"school-notification-backend/security"
+	"school-notification-backend/util"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	errHolidayDate    = errors.New("date is holiday")
+	errDateOutOfTerm  = errors.New("date is out of term")
+	errTermDateNotSet = errors.New("term date does not set")
+)
+
+func (s *schoolDataController) SetTermDate(c *fiber.Ctx) error {
+	req := models.SchoolDataRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		s.logger.Warn(c.UserContext(), "set term date", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	err = checkDateRange(req.DateStart, req.DateEnd)
+	if err != nil {
+		s.logger.Warn(c.UserContext(), "set term date", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	s.logger.Debug(c.UserContext(), "term date", "date_start", req.DateStart, "date_end", req.DateEnd)
+
+	data, err := getCurrentYearAndTerm(c.UserContext(), s.schoolDataRepository)
+	if err != nil {
+		s.logger.Error(c.UserContext(), "set term date", "error", err)
+		if err == errNoCurrentTerm {
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+		}
+		return util.ResponseError(c, err)
+	}
+
+	security.AuditBefore(c, repository.SchoolDataCollection, data.Id.Hex(), data)
+	data.DateStart = &req.DateStart
+	data.DateEnd = &req.DateEnd
+	data.UpdatedAt = time.Now()
+
+	_, err = s.schoolDataRepository.Update(c.UserContext(), data)
+	if err != nil {
+		s.logger.Error(c.UserContext(), "set term date", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.SchoolDataCollection, data.Id.Hex(), data)
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "update data success", map[string]interface{}{
+		"school_data": data,
+	})
+}
+
+// add exam week or special event, date_end default is date_start
+func (s *schoolDataController) AddCalendarEvent(c *fiber.Ctx) error {
+	req := models.SchoolDataRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		s.logger.Warn(c.UserContext(), "add calendar event", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	category, err := util.CheckStringData(req.Category, "category")
+	if err != nil {
+		s.logger.Warn(c.UserContext(), "add calendar event", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	s.logger.Debug(c.UserContext(), "category", "category", category)
+
+	if category != "ExamWeek" && category != "Event" {
+		s.logger.Warn(c.UserContext(), "category invalid")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ReturnErrorStatusInvalid("category", "ExamWeek,Event").Error())
+	}
+
+	name, err := util.CheckStringData(req.Name, "name")
+	if err != nil {
+		s.logger.Warn(c.UserContext(), "add calendar event", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	s.logger.Debug(c.UserContext(), "name", "name", name)
+
+	dateStart, err := util.CheckStringData(req.DateStart, "date_start")
+	if err != nil {
+		s.logger.Warn(c.UserContext(), "add calendar event", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	dateEnd := req.DateEnd
+	if dateEnd == "" {
+		dateEnd = dateStart
+	}
+
+	err = checkDateRange(dateStart, dateEnd)
+	if err != nil {
+		s.logger.Warn(c.UserContext(), "add calendar event", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	s.logger.Debug(c.UserContext(), "date", "date_start", dateStart, "date_end", dateEnd)
+
+	dataNew := &models.SchoolData{
+		Id:        primitive.NewObjectID(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Type:      category,
+		Name:      &name,
+		DateStart: &dateStart,
+		DateEnd:   &dateEnd,
+	}
+
+	_, err = s.schoolDataRepository.Insert(c.UserContext(), dataNew)
+	if err != nil {
+		s.logger.Error(c.UserContext(), "add calendar event", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.SchoolDataCollection, dataNew.Id.Hex(), dataNew)
+
+	return util.ResponseSuccess(c, fiber.StatusCreated, "create data success", map[string]interface{}{
+		"school_data_id": dataNew.Id,
+	})
+}
+
+func (s *schoolDataController) DeleteCalendarEvent(c *fiber.Ctx) error {
+	return s.deleteCalendarData(c, []string{"ExamWeek", "Event"})
+}
+
+// calendar of current term or year and term in query
+func (s *schoolDataController) GetCalendar(c *fiber.Ctx) error {
+	var data *models.SchoolData
+	var err error
+	if c.Query("year") != "" || c.Query("term") != "" {
+		year, err := util.CheckStringData(c.Query("year"), "year")
+		if err != nil {
+			s.logger.Warn(c.UserContext(), "get calendar", "error", err)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+		}
+		term, err := util.CheckStringData(c.Query("term"), "term")
+		if err != nil {
+			s.logger.Warn(c.UserContext(), "get calendar", "error", err)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+		}
+		s.logger.Debug(c.UserContext(), "calendar of year term", "year", year, "term", term)
+
+		data, err = getYearAndTerm(c.UserContext(), s.schoolDataRepository, year, term)
+	} else {
+		data, err = getCurrentYearAndTerm(c.UserContext(), s.schoolDataRepository)
+	}
+	if err != nil {
+		s.logger.Error(c.UserContext(), "get calendar", "error", err)
+		if err == errNoCurrentTerm {
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+		}
+		return err
+	}
+
+	calendar, err := newSchoolCalendar(c.UserContext(), s.schoolDataRepository, data)
+	if err != nil {
+		s.logger.Error(c.UserContext(), "get calendar", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"calendar": calendar,
+	})
+}
+
+func (s *schoolDataController) deleteCalendarData(c *fiber.Ctx, types []string) error {
+	req := models.SchoolDataRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		s.logger.Warn(c.UserContext(), "delete calendar data", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	id, err := util.CheckStringData(req.Id, "id")
+	if err != nil {
+		s.logger.Warn(c.UserContext(), "delete calendar data", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	s.logger.Debug(c.UserContext(), "id", "id", id)
+
+	data, err := s.schoolDataRepository.GetById(c.UserContext(), id)
+	if err != nil {
+		s.logger.Error(c.UserContext(), "delete calendar data", "error", err)
+		return err
+	}
+
+	check := true
+	for _, t := range types {
+		if data.Type == t {
+			check = false
+			break
+		}
+	}
+	if check {
+		s.logger.Warn(c.UserContext(), "delete calendar data", "error", util.ErrTypeInvalid)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrTypeInvalid.Error())
+	}
+
+	security.AuditBefore(c, repository.SchoolDataCollection, data.Id.Hex(), data)
+	result, err := s.schoolDataRepository.Delete(c.UserContext(), data.Id)
+	if err != nil {
+		s.logger.Error(c.UserContext(), "delete calendar data", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.SchoolDataCollection, data.Id.Hex(), nil)
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "delete data success", map[string]interface{}{
+		"school_data_id": data.Id,
+		"delete_count":   result.DeletedCount,
+	})
+}
+
+func newSchoolCalendar(ctx context.Context, schoolDataRepository repository.SchoolDataRepository, data *models.SchoolData) (*models.SchoolCalendar, error) {
+	calendar := &models.SchoolCalendar{
+		YearAndTerm: data,
+		Holiday:     []*models.SchoolData{},
+		ExamWeek:    []*models.SchoolData{},
+		Event:       []*models.SchoolData{},
+	}
+
+	dataList, err := schoolDataRepository.GetByFilterAll(ctx, bson.M{"type": bson.M{"$in": []string{"Holiday", "ExamWeek", "Event"}}})
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return calendar, nil
+		}
+		return nil, err
+	}
+
+	for _, v := range dataList {
+		switch v.Type {
+		case "Holiday":
+			if v.Date == nil || isOutOfTerm(data, *v.Date) {
+				continue
+			}
+			calendar.Holiday = append(calendar.Holiday, v)
+		case "ExamWeek", "Event":
+			if v.DateStart == nil || v.DateEnd == nil {
+				continue
+			}
+			// keep event overlap with term
+			if data.DateStart != nil && data.DateEnd != nil && (*v.DateEnd < *data.DateStart || *v.DateStart > *data.DateEnd) {
+				continue
+			}
+			if v.Type == "ExamWeek" {
+				calendar.ExamWeek = append(calendar.ExamWeek, v)
+			} else {
+				calendar.Event = append(calendar.Event, v)
+			}
+		}
+	}
+
+	sort.Slice(calendar.Holiday, func(i, j int) bool {
+		return *calendar.Holiday[i].Date < *calendar.Holiday[j].Date
+	})
+	sort.Slice(calendar.ExamWeek, func(i, j int) bool {
+		return *calendar.ExamWeek[i].DateStart < *calendar.ExamWeek[j].DateStart
+	})
+	sort.Slice(calendar.Event, func(i, j int) bool {
+		return *calendar.Event[i].DateStart < *calendar.Event[j].DateStart
+	})
+
+	return calendar, nil
+}
+
+// lesson date of course in term, skip holiday
+func newLessonDateList(ctx context.Context, schoolDataRepository repository.SchoolDataRepository, course *models.Course) ([]models.LessonDate, error) {
+	data, err := getYearAndTerm(ctx, schoolDataRepository, course.Year, course.Term)
+	if err != nil {
+		return nil, err
+	}
+	if data.DateStart == nil || data.DateEnd == nil {
+		return nil, errTermDateNotSet
+	}
+
+	holidays := map[string]bool{}
+	holidayList, err := schoolDataRepository.GetByFilterAll(ctx, bson.M{"type": "Holiday", "date": bson.M{"$gte": *data.DateStart, "$lte": *data.DateEnd}})
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		return nil, err
+	}
+	for _, v := range holidayList {
+		holidays[*v.Date] = true
+	}
+
+	start, _ := time.Parse("2006-01-02", *data.DateStart)
+	end, _ := time.Parse("2006-01-02", *data.DateEnd)
+
+	lessonDateList := []models.LessonDate{}
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		if holidays[date] {
+			continue
+		}
+
+		weekDay := strings.ToLower(d.Weekday().String())
+		for _, dt := range course.DateTime {
+			if dt.Day == weekDay {
+				lessonDateList = append(lessonDateList, models.LessonDate{Date: date, Day: weekDay, Time: dt.Time})
+			}
+		}
+	}
+
+	return lessonDateList, nil
+}
+
+// date must be in term date when set and not holiday
+func checkSchoolDay(ctx context.Context, schoolDataRepository repository.SchoolDataRepository, year string, term string, date string) error {
+	holiday, err := isHoliday(ctx, schoolDataRepository, date)
+	if err != nil {
+		return err
+	}
+	if holiday {
+		return errHolidayDate
+	}
+
+	data, err := getYearAndTerm(ctx, schoolDataRepository, year, term)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if isOutOfTerm(data, date) {
+		return errDateOutOfTerm
+	}
+
+	return nil
+}
+
+func getYearAndTerm(ctx context.Context, schoolDataRepository repository.SchoolDataRepository, year string, term string) (*models.SchoolData, error) {
+	return schoolDataRepository.GetByFilter(ctx, bson.M{"type": "YearAndTerm", "year": year, "term": term})
+}
+
+func getCurrentYearAndTerm(ctx context.Context, schoolDataRepository repository.SchoolDataRepository) (*models.SchoolData, error) {
+	dataList, err := schoolDataRepository.GetByFilterAll(ctx, bson.M{"type": "YearAndTerm"})
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return nil, errNoCurrentTerm
+		}
+		return nil, err
+	}
+
+	sort.Slice(dataList, func(i, j int) bool {
+		return dataList[i].CreatedAt.After(dataList[j].CreatedAt)
+	})
+
+	// school data that is not complete is not current term
+	if len(dataList) == 0 || dataList[0].Status == nil || *dataList[0].Status == true || dataList[0].Year == nil || dataList[0].Term == nil {
+		return nil, errNoCurrentTerm
+	}
+
+	return dataList[0], nil
+}
+
+// term without date is never out of term
+func isOutOfTerm(data *models.SchoolData, date string) bool {
+	if data.DateStart == nil || data.DateEnd == nil {
+		return false
+	}
+
+	return date < *data.DateStart || date > *data.DateEnd
+}
+
+func checkDateRange(dateStart string, dateEnd string) error {
+	start, err := time.Parse("2006-01-02", dateStart)
+	if err != nil {
+		return util.ReturnError("date_start" + util.ErrValueInvalid.Error())
+	}
+
+	end, err := time.Parse("2006-01-02", dateEnd)
+	if err != nil {
+		return util.ReturnError("date_end" + util.ErrValueInvalid.Error())
+	}
+
+	if end.Before(start) {
+		return util.ReturnError("date_end" + util.ErrValueInvalid.Error())
+	}
+
+	return nil
+}
@@ -1,7 +1,10 @@
 package controller
 
 import (
-	"log"
+	"context"
+	"errors"
+	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/repository"
 	"school-notification-backend/security"
@@ -14,7 +17,6 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type SchoolDataController interface {
@@ -26,6 +28,15 @@ type SchoolDataController interface {
 	GetSchoolDataById(c *fiber.Ctx) error
 	GetTermYear(c *fiber.Ctx) error
 	EndTerm(c *fiber.Ctx) error
+	SetAttendanceThreshold(c *fiber.Ctx) error
+	GetAttendanceThreshold(c *fiber.Ctx) error
+	AddHoliday(c *fiber.Ctx) error
+	GetHoliday(c *fiber.Ctx) error
+	DeleteHoliday(c *fiber.Ctx) error
+	SetTermDate(c *fiber.Ctx) error
+	AddCalendarEvent(c *fiber.Ctx) error
+	DeleteCalendarEvent(c *fiber.Ctx) error
+	GetCalendar(c *fiber.Ctx) error
 }
 
 type schoolDataController struct {
@@ -35,24 +46,19 @@ type schoolDataController struct {
 	profileRepo          repository.ProfileRepository
 	classRepo            repository.ClassRepository
 	locationRepo         repository.LocationRepository
-	userRepo             repository.UsersRepository
+	unitOfWork           db.UnitOfWork
+	logger               logger.Logger
 }
 
-func NewSchoolDataController(schoolDataRepository repository.SchoolDataRepository, courseRepo repository.CourseRepository, courseSummaryRepo repository.CourseSummaryRepository, profileRepo repository.ProfileRepository, classRepo repository.ClassRepository, locationRepo repository.LocationRepository, userRepo repository.UsersRepository) SchoolDataController {
-	return &schoolDataController{schoolDataRepository: schoolDataRepository, courseRepo: courseRepo, courseSummaryRepo: courseSummaryRepo, profileRepo: profileRepo, classRepo: classRepo, locationRepo: locationRepo, userRepo: userRepo}
+func NewSchoolDataController(schoolDataRepository repository.SchoolDataRepository, courseRepo repository.CourseRepository, courseSummaryRepo repository.CourseSummaryRepository, profileRepo repository.ProfileRepository, classRepo repository.ClassRepository, locationRepo repository.LocationRepository, unitOfWork db.UnitOfWork, logger logger.Logger) SchoolDataController {
+	return &schoolDataController{schoolDataRepository: schoolDataRepository, courseRepo: courseRepo, courseSummaryRepo: courseSummaryRepo, profileRepo: profileRepo, classRepo: classRepo, locationRepo: locationRepo, unitOfWork: unitOfWork, logger: logger}
 }
 
 func (s *schoolDataController) AddYearAndTerm(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"admin"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.SchoolDataRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "add year and term", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -63,27 +69,37 @@ func (s *schoolDataController) AddYearAndTerm(c *fiber.Ctx) error {
 
 	year, err := util.CheckStringData(req.Year, "year")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "add year and term", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("year:", year)
+	s.logger.Debug(c.UserContext(), "year", "year", year)
 
 	term, err := util.CheckStringData(req.Term, "term")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "add year and term", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("term:", term)
+	s.logger.Debug(c.UserContext(), "term", "term", term)
 
-	dataList, err := s.schoolDataRepository.GetByFilterAll(bson.M{"type": "YearAndTerm"})
-	if err != nil && err.Error() != "mongo: no documents in result" {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+	// term date is optional, it can be set later
+	if req.DateStart != "" || req.DateEnd != "" {
+		err = checkDateRange(req.DateStart, req.DateEnd)
+		if err != nil {
+			s.logger.Warn(c.UserContext(), "add year and term", "error", err)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+		}
+		s.logger.Debug(c.UserContext(), "term date", "date_start", req.DateStart, "date_end", req.DateEnd)
+	}
+
+	dataList, err := s.schoolDataRepository.GetByFilterAll(c.UserContext(), bson.M{"type": "YearAndTerm"})
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		s.logger.Error(c.UserContext(), "add year and term", "error", err)
+		return util.ResponseError(c, err)
 	}
 
 	if len(dataList) != 0 {
 		if *dataList[len(dataList)-1].Status == false {
-			log.Println("school year and term does not finish")
+			s.logger.Warn(c.UserContext(), "school year and term does not finish")
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "school year and term does not finish")
 		}
 	}
@@ -91,19 +107,24 @@ func (s *schoolDataController) AddYearAndTerm(c *fiber.Ctx) error {
 	status := false
 	dataNew := &models.SchoolData{
 		Id:        primitive.NewObjectID(),
-		CreatedAt: time.Now().Format(time.RFC3339),
-		UpdatedAt: time.Now().Format(time.RFC3339),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 		Type:      "YearAndTerm",
 		Year:      &year,
 		Term:      &term,
 		Status:    &status,
 	}
+	if req.DateStart != "" {
+		dataNew.DateStart = &req.DateStart
+		dataNew.DateEnd = &req.DateEnd
+	}
 
-	_, err = s.schoolDataRepository.Insert(dataNew)
+	_, err = s.schoolDataRepository.Insert(c.UserContext(), dataNew)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "add year and term", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.SchoolDataCollection, dataNew.Id.Hex(), dataNew)
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "create data success", map[string]interface{}{
 		"school_data_id": dataNew.Id,
@@ -111,16 +132,10 @@ func (s *schoolDataController) AddYearAndTerm(c *fiber.Ctx) error {
 }
 
 func (s *schoolDataController) AddSubjectCategory(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"admin"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.SchoolDataRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "add subject category", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -131,38 +146,39 @@ func (s *schoolDataController) AddSubjectCategory(c *fiber.Ctx) error {
 
 	category, err := util.CheckStringData(req.Category, "category")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "add subject category", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("category:", category)
+	s.logger.Debug(c.UserContext(), "category", "category", category)
 
-	dataList, err := s.schoolDataRepository.GetByFilterAll(bson.M{"type": "SubjectCategory"})
-	if err != nil && err.Error() != "mongo: no documents in result" {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+	dataList, err := s.schoolDataRepository.GetByFilterAll(c.UserContext(), bson.M{"type": "SubjectCategory"})
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		s.logger.Error(c.UserContext(), "add subject category", "error", err)
+		return util.ResponseError(c, err)
 	}
 
 	if len(dataList) != 0 {
 		for _, v := range dataList {
 			if *v.SubjectCategory == category {
-				log.Println("category has been addded")
+				s.logger.Warn(c.UserContext(), "category has been addded")
 				return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "category has been addded")
 			}
 		}
 	}
 	dataNew := &models.SchoolData{
 		Id:              primitive.NewObjectID(),
-		CreatedAt:       time.Now().Format(time.RFC3339),
-		UpdatedAt:       time.Now().Format(time.RFC3339),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 		Type:            "SubjectCategory",
 		SubjectCategory: &category,
 	}
 
-	_, err = s.schoolDataRepository.Insert(dataNew)
+	_, err = s.schoolDataRepository.Insert(c.UserContext(), dataNew)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "add subject category", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.SchoolDataCollection, dataNew.Id.Hex(), dataNew)
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "create data success", map[string]interface{}{
 		"school_data_id": dataNew.Id,
@@ -170,16 +186,10 @@ func (s *schoolDataController) AddSubjectCategory(c *fiber.Ctx) error {
 }
 
 func (s *schoolDataController) UpdateSchoolData(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"admin"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.SchoolDataRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "update school data", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -190,31 +200,25 @@ func (s *schoolDataController) UpdateSchoolData(c *fiber.Ctx) error {
 
 	id, err := util.CheckStringData(req.Id, "id")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "update school data", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("id:", id)
+	s.logger.Debug(c.UserContext(), "id", "id", id)
 
-	data, err := s.schoolDataRepository.GetById(id)
+	data, err := s.schoolDataRepository.GetById(c.UserContext(), id)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "update school data", "error", err)
+		return err
 	}
 
 	if *data.Status == true {
-		log.Println("term does ended")
+		s.logger.Error(c.UserContext(), "term does ended")
 		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, "term does ended")
 	}
 
-	data.UpdatedAt = time.Now().Format(time.RFC3339)
+	data.UpdatedAt = time.Now()
 	if req.Status == nil {
-		log.Println(util.ErrRequireParameter, "status")
+		s.logger.Warn(c.UserContext(), "status", "error", util.ErrRequireParameter)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"status")
 	}
 
@@ -223,10 +227,10 @@ func (s *schoolDataController) UpdateSchoolData(c *fiber.Ctx) error {
 
 	// }
 
-	result, err := s.schoolDataRepository.Update(data)
+	result, err := s.schoolDataRepository.Update(c.UserContext(), data)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "update school data", "error", err)
+		return util.ResponseError(c, err)
 	}
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "update data success", map[string]interface{}{
@@ -236,23 +240,14 @@ func (s *schoolDataController) UpdateSchoolData(c *fiber.Ctx) error {
 }
 
 func (s *schoolDataController) GetSchoolDataAll(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"admin"})
+	data, err := s.schoolDataRepository.GetAll(c.UserContext())
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
-	data, err := s.schoolDataRepository.GetAll()
-	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "get school data all", "error", err)
+		return err
 	}
 
 	if len(data) == 0 {
-		log.Println("data not found")
+		s.logger.Warn(c.UserContext(), "data not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -262,23 +257,14 @@ func (s *schoolDataController) GetSchoolDataAll(c *fiber.Ctx) error {
 }
 
 func (s *schoolDataController) GetSubjectCategory(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"all"})
+	data, err := s.schoolDataRepository.GetByFilterAll(c.UserContext(), bson.M{"type": "SubjectCategory"})
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
-	data, err := s.schoolDataRepository.GetByFilterAll(bson.M{"type": "SubjectCategory"})
-	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "get subject category", "error", err)
+		return err
 	}
 
 	if len(data) == 0 {
-		log.Println("data not found")
+		s.logger.Warn(c.UserContext(), "data not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -288,23 +274,14 @@ func (s *schoolDataController) GetSubjectCategory(c *fiber.Ctx) error {
 }
 
 func (s *schoolDataController) GetTermYear(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"all"})
+	data, err := s.schoolDataRepository.GetByFilterAll(c.UserContext(), bson.M{"type": "YearAndTerm"})
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
-	data, err := s.schoolDataRepository.GetByFilterAll(bson.M{"type": "YearAndTerm"})
-	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "get term year", "error", err)
+		return err
 	}
 
 	if len(data) == 0 {
-		log.Println("data not found")
+		s.logger.Warn(c.UserContext(), "data not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -314,33 +291,21 @@ func (s *schoolDataController) GetTermYear(c *fiber.Ctx) error {
 }
 
 func (s *schoolDataController) GetSchoolDataById(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	id, err := util.CheckStringData(c.Query("id"), "id")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "get school data by id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find subject id:", id)
+	s.logger.Debug(c.UserContext(), "find subject id", "id", id)
 
-	data, err := s.schoolDataRepository.GetById(id)
+	data, err := s.schoolDataRepository.GetById(c.UserContext(), id)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "get school data by id", "error", err)
+		return err
 	}
 
 	if data == nil {
-		log.Println("data not found")
+		s.logger.Warn(c.UserContext(), "data not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -350,187 +315,50 @@ func (s *schoolDataController) GetSchoolDataById(c *fiber.Ctx) error {
 }
 
 func (s *schoolDataController) EndTerm(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"admin"})
+	dataList, err := s.schoolDataRepository.GetByFilterAll(c.UserContext(), bson.M{"type": "YearAndTerm"})
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
-	dataList, err := s.schoolDataRepository.GetByFilterAll(bson.M{"type": "YearAndTerm"})
-	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "end term", "error", err)
+		return err
 	}
 
 	sort.Slice(dataList, func(i, j int) bool {
-		return dataList[i].CreatedAt > dataList[j].CreatedAt
+		return dataList[i].CreatedAt.After(dataList[j].CreatedAt)
 	})
 
 	// data := dataList[len(dataList)-1]
 	data := dataList[0]
 
 	if data.Status == nil || *data.Status == true {
-		log.Println("school data invalid")
+		s.logger.Error(c.UserContext(), "school data invalid")
 		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, "school data invalid")
 	}
 
+	security.AuditBefore(c, repository.SchoolDataCollection, data.Id.Hex(), data)
 	*data.Status = true
 
 	if data.Term == nil || data.Year == nil {
-		log.Println("school data invalid")
+		s.logger.Error(c.UserContext(), "school data invalid")
 		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, "school data invalid")
 	}
 
 	// check and finish course
-	log.Println("get course list in term")
-	courseList, err := s.courseRepo.GetCourseAllByFilter(bson.M{"year": *data.Year, "term": *data.Term})
-	if err != nil && err.Error() != "mongo: no documents in result" {
-		log.Println(err)
+	s.logger.Debug(c.UserContext(), "get course list in term")
+	courseList, err := s.courseRepo.GetCourseAllByFilter(c.UserContext(), bson.M{"year": *data.Year, "term": *data.Term})
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		s.logger.Error(c.UserContext(), "end term", "error", err)
 		// if err.Error() == "mongo: no documents in result" {
 		// 	return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
 		// }
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		return err
 	}
 
 	for _, v := range courseList {
 		if v.Status != "summary" && v.Status != "finish" {
-			log.Println("have course does not finish")
+			s.logger.Warn(c.UserContext(), "have course does not finish")
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "have course does not finish")
 		}
 	}
 
-	for _, cl := range courseList {
-		if cl.Status == "summary" {
-			log.Println("finish course:", cl.Id)
-			courseSum, err := s.courseSummaryRepo.GetByFilter(bson.M{"course_id": cl.Id.Hex()})
-			if err != nil && err.Error() != "mongo: no documents in result" {
-				log.Println(err)
-				if err == mongo.ErrNoDocuments {
-					return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-				}
-				return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
-			}
-
-			for _, sData := range courseSum.StudentData {
-				p, err := s.profileRepo.GetProfileById(bson.M{"profile_id": sData.StudentId, "role": "student"}, "student")
-				if err != nil {
-					log.Println(err)
-					if err.Error() == "mongo: no documents in result" {
-						return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-					}
-					if err.Error() == "Id is not primitive objectID" {
-						return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-					}
-					return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
-				}
-
-				profile, _ := p.(models.ProfileStudent)
-
-				for i, t := range profile.TermScore {
-					if t.Year == cl.Year && t.Term == cl.Term {
-						totalTermGrade := 0.0
-						for j, courseList := range t.CourseList {
-							if courseList.Id == cl.Id {
-								// profile.TermScore[i].CourseList[j].CreatedAt = time.Now().Format(time.RFC3339)
-								profile.TermScore[i].CourseList[j].Grade = sData.Grade
-								profile.TermScore[i].CourseList[j].ScoreWorkGet = sData.ScoreWorkGet
-								profile.TermScore[i].CourseList[j].ScoreWorkFull = sData.ScoreWorkFull
-								profile.TermScore[i].CourseList[j].ScoreMidGet = sData.ScoreMidGet
-								profile.TermScore[i].CourseList[j].ScoreMidFull = sData.ScoreMidFull
-								profile.TermScore[i].CourseList[j].ScoreFinalGet = sData.ScoreFinalGet
-								profile.TermScore[i].CourseList[j].ScoreFinaFull = sData.ScoreFinaFull
-								profile.TermScore[i].CourseList[j].Credit = cl.Credit
-								profile.TermScore[i].CourseList[j].AllDateCount = sData.AllDateCount
-								profile.TermScore[i].CourseList[j].CheckNameAttendCount = sData.CheckNameAttendCount
-								profile.TermScore[i].CourseList[j].CheckNameAbsentCount = sData.CheckNameAbsentCount
-								profile.TermScore[i].CourseList[j].CheckNameLateCount = sData.CheckNameLateCount
-
-								profile.TermScore[i].TermCredit += cl.Credit
-								profile.AllCredit += cl.Credit
-								break
-							}
-						}
-
-						for _, courseList := range profile.TermScore[i].CourseList {
-							totalTermGrade += courseList.Grade * float64(courseList.Credit)
-						}
-						// เกรด * หน่วยกิต นำมารวมกัน หารด้วยหน่วยกิตทั้งหมด
-						profile.TermScore[i].GPA = totalTermGrade / float64(profile.TermScore[i].TermCredit)
-					}
-
-					totalGrade := 0.0
-					for _, t := range profile.TermScore {
-						totalGrade += t.GPA * float64(t.TermCredit)
-					}
-
-					profile.GPA = totalGrade / float64(profile.AllCredit)
-
-					_, err = s.profileRepo.Update(profile.Id, profile)
-					if err != nil {
-						log.Println(err)
-						return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-					}
-
-					break
-				}
-			}
-
-			cl.Status = "finish"
-			cl.UpdatedAt = time.Now().Format(time.RFC3339)
-			_, err = s.courseRepo.Update(cl)
-			if err != nil {
-				log.Println(err)
-				return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
-			}
-
-			// clear location
-			log.Println("get location in course:", cl.Name)
-			location, err := s.locationRepo.GetLocationById(cl.LocationId.Hex())
-			if err != nil {
-				log.Println(err)
-				if err.Error() == "mongo: no documents in result" {
-					return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-				}
-				if err.Error() == "Id is not primitive objectID" {
-					return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-				}
-				return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
-			}
-
-			for _, dt := range cl.DateTime {
-				for i, slot := range location.Slot {
-					if slot.Day == dt.Day {
-						for _, t := range dt.Time {
-							for j, ts := range slot.TimeSlot {
-								if ts.Time == t && ts.CourseId != nil && *ts.CourseId == cl.Id {
-									location.Slot[i].TimeSlot[j].Status = false
-									location.Slot[i].TimeSlot[j].CourseId = nil
-									break
-								}
-							}
-						}
-						break
-					}
-				}
-			}
-
-			_, err = s.locationRepo.Update(location)
-			if err != nil {
-				log.Println(err)
-				return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
-			}
-		}
-	}
-
 	// add school data new term
 	status := false
 	var dataNew models.SchoolData
@@ -541,8 +369,8 @@ func (s *schoolDataController) EndTerm(c *fiber.Ctx) error {
 		term := "1"
 		dataNew = models.SchoolData{
 			Id:        primitive.NewObjectID(),
-			CreatedAt: time.Now().Format(time.RFC3339),
-			UpdatedAt: time.Now().Format(time.RFC3339),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
 			Type:      "YearAndTerm",
 			Year:      &yearStr,
 			Term:      &term,
@@ -552,158 +380,490 @@ func (s *schoolDataController) EndTerm(c *fiber.Ctx) error {
 		term := "2"
 		dataNew = models.SchoolData{
 			Id:        primitive.NewObjectID(),
-			CreatedAt: time.Now().Format(time.RFC3339),
-			UpdatedAt: time.Now().Format(time.RFC3339),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
 			Type:      "YearAndTerm",
 			Year:      data.Year,
 			Term:      &term,
 			Status:    &status,
 		}
 	} else {
-		log.Println("school data invalid")
+		s.logger.Error(c.UserContext(), "school data invalid")
 		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, "school data invalid")
 	}
 
-	// update class data
-	log.Println("get all class")
-	classes, err := s.classRepo.GetClassByFilterAll(bson.M{"status": false})
-	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
-	}
+	err = doUnitOfWork(c, s.unitOfWork, func(tx db.Tx) error {
+		ctx := tx.Context()
 
-	for _, class := range classes {
-		if class.Year == *dataNew.Year && class.Term == *dataNew.Term {
-			continue
-		}
-		if class.Status != true {
-			class.Year = *dataNew.Year
-			class.Term = *dataNew.Term
-		}
-		if class.Term == "1" {
-			if class.ClassYear == "1" {
-				class.ClassYear = "2"
-			} else if class.ClassYear == "2" {
-				class.ClassYear = "3"
-			} else if class.ClassYear == "3" {
-				class.ClassYear = "4"
-			} else if class.ClassYear == "4" {
-				class.ClassYear = "5"
-			} else if class.ClassYear == "5" {
-				class.ClassYear = "6"
-			} else if class.ClassYear == "6" {
-				class.Status = true
+		for _, cl := range courseList {
+			if cl.Status == "summary" {
+				s.logger.Debug(c.UserContext(), "finish course", "id", cl.Id)
+				courseSum, err := s.courseSummaryRepo.GetByFilter(ctx, bson.M{"course_id": cl.Id.Hex()})
+				if err != nil && !errors.Is(err, util.ErrNotFound) {
+					return err
+				}
+
+				for _, sData := range courseSum.StudentData {
+					p, err := s.profileRepo.GetProfileById(ctx, bson.M{"profile_id": sData.StudentId, "role": "student"}, "student")
+					if err != nil {
+						return err
+					}
+
+					profile, _ := p.(models.ProfileStudent)
+					security.AuditBefore(c, repository.ProfileCollection, profile.Id.Hex(), profile)
+
+					for i, t := range profile.TermScore {
+						if t.Year == cl.Year && t.Term == cl.Term {
+							totalTermGrade := 0.0
+							for j, courseList := range t.CourseList {
+								if courseList.Id == cl.Id {
+									// profile.TermScore[i].CourseList[j].CreatedAt = time.Now()
+									profile.TermScore[i].CourseList[j].Grade = sData.Grade
+									profile.TermScore[i].CourseList[j].ScoreWorkGet = sData.ScoreWorkGet
+									profile.TermScore[i].CourseList[j].ScoreWorkFull = sData.ScoreWorkFull
+									profile.TermScore[i].CourseList[j].ScoreMidGet = sData.ScoreMidGet
+									profile.TermScore[i].CourseList[j].ScoreMidFull = sData.ScoreMidFull
+									profile.TermScore[i].CourseList[j].ScoreFinalGet = sData.ScoreFinalGet
+									profile.TermScore[i].CourseList[j].ScoreFinaFull = sData.ScoreFinaFull
+									profile.TermScore[i].CourseList[j].Credit = cl.Credit
+									profile.TermScore[i].CourseList[j].AllDateCount = sData.AllDateCount
+									profile.TermScore[i].CourseList[j].CheckNameAttendCount = sData.CheckNameAttendCount
+									profile.TermScore[i].CourseList[j].CheckNameAbsentCount = sData.CheckNameAbsentCount
+									profile.TermScore[i].CourseList[j].CheckNameLeaveCount = sData.CheckNameLeaveCount
+									profile.TermScore[i].CourseList[j].CheckNameLateCount = sData.CheckNameLateCount
+
+									profile.TermScore[i].TermCredit += cl.Credit
+									profile.AllCredit += cl.Credit
+									break
+								}
+							}
+
+							for _, courseList := range profile.TermScore[i].CourseList {
+								totalTermGrade += courseList.Grade * float64(courseList.Credit)
+							}
+							// เกรด * หน่วยกิต นำมารวมกัน หารด้วยหน่วยกิตทั้งหมด
+							profile.TermScore[i].GPA = totalTermGrade / float64(profile.TermScore[i].TermCredit)
+						}
+
+						totalGrade := 0.0
+						for _, t := range profile.TermScore {
+							totalGrade += t.GPA * float64(t.TermCredit)
+						}
+
+						profile.GPA = totalGrade / float64(profile.AllCredit)
+
+						err = tx.Track(repository.ProfileCollection, profile.Id)
+						if err != nil {
+							return err
+						}
+						_, err = s.profileRepo.Update(ctx, profile.Id, profile)
+						if err != nil {
+							return err
+						}
+						security.AuditAfter(c, repository.ProfileCollection, profile.Id.Hex(), profile)
+
+						break
+					}
+				}
+
+				security.AuditBefore(c, repository.CourseCollection, cl.Id.Hex(), cl)
+				cl.Status = "finish"
+				cl.UpdatedAt = time.Now()
+				err = tx.Track(repository.CourseCollection, cl.Id)
+				if err != nil {
+					return err
+				}
+				_, err = s.courseRepo.Update(ctx, cl)
+				if err != nil {
+					return err
+				}
+				security.AuditAfter(c, repository.CourseCollection, cl.Id.Hex(), cl)
+
+				// clear location
+				s.logger.Debug(c.UserContext(), "get location in course", "name", cl.Name)
+				location, err := s.locationRepo.GetLocationById(ctx, cl.LocationId.Hex())
+				if err != nil {
+					return err
+				}
+
+				security.AuditBefore(c, repository.LocationCollection, location.Id.Hex(), location)
+				for _, dt := range cl.DateTime {
+					for i, slot := range location.Slot {
+						if slot.Day == dt.Day {
+							for _, t := range dt.Time {
+								for j, ts := range slot.TimeSlot {
+									if ts.Time == t && ts.CourseId != nil && *ts.CourseId == cl.Id {
+										location.Slot[i].TimeSlot[j].Status = false
+										location.Slot[i].TimeSlot[j].CourseId = nil
+										break
+									}
+								}
+							}
+							break
+						}
+					}
+				}
+
+				err = tx.Track(repository.LocationCollection, location.Id)
+				if err != nil {
+					return err
+				}
+				_, err = s.locationRepo.Update(ctx, location)
+				if err != nil {
+					return err
+				}
+				security.AuditAfter(c, repository.LocationCollection, location.Id.Hex(), location)
 			}
 		}
 
-		class.Slot = createTimeSlot()
-
-		_, err = s.classRepo.Update(class)
+		// update class data
+		s.logger.Debug(c.UserContext(), "get all class")
+		classes, err := s.classRepo.GetClassByFilterAll(ctx, bson.M{"status": false})
 		if err != nil {
-			log.Println(err)
-			return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+			return err
 		}
 
-		// update profile student term
-		log.Println("get student in class:", class.Id)
-		if class.Status == false {
-			for _, sid := range class.StudentIdList {
-				filter := bson.M{
-					"profile_id": sid,
-					"role":       "student",
+		for _, class := range classes {
+			if class.Year == *dataNew.Year && class.Term == *dataNew.Term {
+				continue
+			}
+			security.AuditBefore(c, repository.ClassCollection, class.Id.Hex(), class)
+			if class.Status != true {
+				class.Year = *dataNew.Year
+				class.Term = *dataNew.Term
+			}
+			if class.Term == "1" {
+				if class.ClassYear == "1" {
+					class.ClassYear = "2"
+				} else if class.ClassYear == "2" {
+					class.ClassYear = "3"
+				} else if class.ClassYear == "3" {
+					class.ClassYear = "4"
+				} else if class.ClassYear == "4" {
+					class.ClassYear = "5"
+				} else if class.ClassYear == "5" {
+					class.ClassYear = "6"
+				} else if class.ClassYear == "6" {
+					class.Status = true
 				}
-				p, err := s.profileRepo.GetProfileById(filter, "student")
-				if err != nil {
-					log.Println(err)
-					if err.Error() == "mongo: no documents in result" {
-						return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
+			}
+
+			class.Slot = createTimeSlot()
+
+			err = tx.Track(repository.ClassCollection, class.Id)
+			if err != nil {
+				return err
+			}
+			_, err = s.classRepo.Update(ctx, class)
+			if err != nil {
+				return err
+			}
+			security.AuditAfter(c, repository.ClassCollection, class.Id.Hex(), class)
+
+			// update profile student term
+			s.logger.Debug(c.UserContext(), "get student in class", "id", class.Id)
+			if class.Status == false {
+				for _, sid := range class.StudentIdList {
+					filter := bson.M{
+						"profile_id": sid,
+						"role":       "student",
 					}
-					if err.Error() == "Id is not primitive objectID" {
-						return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+					p, err := s.profileRepo.GetProfileById(ctx, filter, "student")
+					if err != nil {
+						return err
 					}
-					return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
-				}
-				profile, _ := p.(models.ProfileStudent)
+					profile, _ := p.(models.ProfileStudent)
 
-				check := false
-				for _, ts := range profile.TermScore {
-					if ts.Year == class.Year && ts.Term == class.Term {
-						check = true
-						break
+					check := false
+					for _, ts := range profile.TermScore {
+						if ts.Year == class.Year && ts.Term == class.Term {
+							check = true
+							break
+						}
 					}
+					if check {
+						continue
+					}
+
+					security.AuditBefore(c, repository.ProfileCollection, profile.Id.Hex(), profile)
+					profile.TermScore = append(profile.TermScore, models.TermScore{
+						Year: class.Year,
+						Term: class.Term,
+					})
+
+					err = tx.Track(repository.ProfileCollection, profile.Id)
+					if err != nil {
+						return err
+					}
+					_, err = s.profileRepo.Update(ctx, profile.Id, profile)
+					if err != nil {
+						return err
+					}
+					security.AuditAfter(c, repository.ProfileCollection, profile.Id.Hex(), profile)
 				}
-				if check {
-					continue
-				}
+			}
+		}
 
-				profile.TermScore = append(profile.TermScore, models.TermScore{
-					Year: class.Year,
-					Term: class.Term,
-				})
+		// update teacher student term
+		s.logger.Debug(c.UserContext(), "get all teacher")
+		p, err := s.profileRepo.GetAll(ctx, "teacher")
+		if err != nil {
+			return err
+		}
 
-				_, err = s.profileRepo.Update(profile.Id, profile)
-				if err != nil {
-					log.Println(err)
-					return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		for _, v := range p {
+			profileTeacher, _ := v.(*models.ProfileTeacher)
+			check := false
+			for _, ctl := range profileTeacher.CourseTeachesList {
+				if ctl.Year == *dataNew.Year && ctl.Term == *dataNew.Term {
+					check = true
+					break
 				}
 			}
+			if check {
+				continue
+			}
+
+			security.AuditBefore(c, repository.ProfileCollection, profileTeacher.Id.Hex(), profileTeacher)
+			profileTeacher.Slot = createTimeSlot()
+
+			profileTeacher.CourseTeachesList = append(profileTeacher.CourseTeachesList, models.CourseTeachesList{
+				Year: *dataNew.Year,
+				Term: *dataNew.Term,
+			})
+
+			err = tx.Track(repository.ProfileCollection, profileTeacher.Id)
+			if err != nil {
+				return err
+			}
+			_, err = s.profileRepo.Update(ctx, profileTeacher.Id, profileTeacher)
+			if err != nil {
+				return err
+			}
+			security.AuditAfter(c, repository.ProfileCollection, profileTeacher.Id.Hex(), profileTeacher)
 		}
+
+		err = tx.Track(repository.SchoolDataCollection, data.Id)
+		if err != nil {
+			return err
+		}
+		_, err = s.schoolDataRepository.Update(ctx, data)
+		if err != nil {
+			return err
+		}
+		security.AuditAfter(c, repository.SchoolDataCollection, data.Id.Hex(), data)
+
+		err = tx.Track(repository.SchoolDataCollection, dataNew.Id)
+		if err != nil {
+			return err
+		}
+		_, err = s.schoolDataRepository.Insert(ctx, dataNew)
+		if err != nil {
+			return err
+		}
+		security.AuditAfter(c, repository.SchoolDataCollection, dataNew.Id.Hex(), dataNew)
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Error(c.UserContext(), "end term", "error", err)
+		return err
 	}
 
-	// update teacher student term
-	log.Println("get all teacher")
-	p, err := s.profileRepo.GetAll("teacher")
+	return util.ResponseSuccess(c, fiber.StatusCreated, "end term success", map[string]interface{}{
+		"school_data_id": data.Id,
+	})
+}
+
+func (s *schoolDataController) SetAttendanceThreshold(c *fiber.Ctx) error {
+	req := models.SchoolDataRequest{}
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
+		s.logger.Warn(c.UserContext(), "set attendance threshold", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
 		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
 	}
 
-	for _, v := range p {
-		profileTeacher, _ := v.(*models.ProfileTeacher)
-		check := false
-		for _, ctl := range profileTeacher.CourseTeachesList {
-			if ctl.Year == *dataNew.Year && ctl.Term == *dataNew.Term {
-				check = true
-				break
-			}
-		}
-		if check {
-			continue
+	absenceRate, err := util.CheckFloatData(req.AbsenceRate, "absence_rate")
+	if err != nil || absenceRate > 100 {
+		s.logger.Warn(c.UserContext(), "absence_rate", "error", util.ErrValueInvalid)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "absence_rate"+util.ErrValueInvalid.Error())
+	}
+	s.logger.Debug(c.UserContext(), "absence rate", "absence_rate", absenceRate)
+
+	lateRate, err := util.CheckFloatData(req.LateRate, "late_rate")
+	if err != nil || lateRate > 100 {
+		s.logger.Warn(c.UserContext(), "late_rate", "error", util.ErrValueInvalid)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "late_rate"+util.ErrValueInvalid.Error())
+	}
+	s.logger.Debug(c.UserContext(), "late rate", "late_rate", lateRate)
+
+	consecutiveAbsence, err := util.CheckIntegerData(req.ConsecutiveAbsence, "consecutive_absence")
+	if err != nil {
+		s.logger.Warn(c.UserContext(), "set attendance threshold", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	s.logger.Debug(c.UserContext(), "consecutive absence", "consecutive_absence", consecutiveAbsence)
+
+	threshold := &models.AttendanceThreshold{
+		AbsenceRate:        absenceRate,
+		LateRate:           lateRate,
+		ConsecutiveAbsence: consecutiveAbsence,
+	}
+
+	// keep only one threshold data
+	data, err := s.schoolDataRepository.GetByFilter(c.UserContext(), bson.M{"type": "AttendanceThreshold"})
+	if err != nil && !errors.Is(err, util.ErrNotFound) {
+		s.logger.Error(c.UserContext(), "set attendance threshold", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	if errors.Is(err, util.ErrNotFound) {
+		data = &models.SchoolData{
+			Id:                  primitive.NewObjectID(),
+			CreatedAt:           time.Now(),
+			UpdatedAt:           time.Now(),
+			Type:                "AttendanceThreshold",
+			AttendanceThreshold: threshold,
 		}
 
-		profileTeacher.Slot = createTimeSlot()
+		_, err = s.schoolDataRepository.Insert(c.UserContext(), data)
+		if err != nil {
+			s.logger.Error(c.UserContext(), "set attendance threshold", "error", err)
+			return util.ResponseError(c, err)
+		}
+		security.AuditAfter(c, repository.SchoolDataCollection, data.Id.Hex(), data)
 
-		profileTeacher.CourseTeachesList = append(profileTeacher.CourseTeachesList, models.CourseTeachesList{
-			Year: *dataNew.Year,
-			Term: *dataNew.Term,
+		return util.ResponseSuccess(c, fiber.StatusCreated, "create data success", map[string]interface{}{
+			"school_data_id": data.Id,
 		})
+	}
 
-		_, err = s.profileRepo.Update(profileTeacher.Id, profileTeacher)
-		if err != nil {
-			log.Println(err)
-			return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+	security.AuditBefore(c, repository.SchoolDataCollection, data.Id.Hex(), data)
+	data.UpdatedAt = time.Now()
+	data.AttendanceThreshold = threshold
+
+	result, err := s.schoolDataRepository.Update(c.UserContext(), data)
+	if err != nil {
+		s.logger.Error(c.UserContext(), "set attendance threshold", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.SchoolDataCollection, data.Id.Hex(), data)
+
+	return util.ResponseSuccess(c, fiber.StatusCreated, "update data success", map[string]interface{}{
+		"school_data_id": data.Id,
+		"update_count":   result.ModifiedCount,
+	})
+}
+
+func (s *schoolDataController) GetAttendanceThreshold(c *fiber.Ctx) error {
+	data, err := s.schoolDataRepository.GetByFilter(c.UserContext(), bson.M{"type": "AttendanceThreshold"})
+	if err != nil {
+		s.logger.Error(c.UserContext(), "get attendance threshold", "error", err)
+		return err
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"school_data": data,
+	})
+}
+
+func (s *schoolDataController) AddHoliday(c *fiber.Ctx) error {
+	req := models.SchoolDataRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		s.logger.Warn(c.UserContext(), "add holiday", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
 		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
 	}
 
-	_, err = s.schoolDataRepository.Update(data)
+	date, err := util.CheckStringData(req.Date, "date")
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Warn(c.UserContext(), "add holiday", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
+	s.logger.Debug(c.UserContext(), "holiday date", "date", date)
 
-	_, err = s.schoolDataRepository.Insert(dataNew)
+	_, err = time.Parse("2006-01-02", date)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Warn(c.UserContext(), "add holiday", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "date"+util.ErrValueInvalid.Error())
 	}
 
-	return util.ResponseSuccess(c, fiber.StatusCreated, "end term success", map[string]interface{}{
-		"school_data_id": data.Id,
+	name, err := util.CheckStringData(req.Name, "name")
+	if err != nil {
+		s.logger.Warn(c.UserContext(), "add holiday", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	s.logger.Debug(c.UserContext(), "holiday name", "name", name)
+
+	holiday, err := isHoliday(c.UserContext(), s.schoolDataRepository, date)
+	if err != nil {
+		s.logger.Error(c.UserContext(), "add holiday", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if holiday {
+		s.logger.Warn(c.UserContext(), "holiday date already exists")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "date"+util.ErrValueAlreadyExists.Error())
+	}
+
+	dataNew := &models.SchoolData{
+		Id:        primitive.NewObjectID(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Type:      "Holiday",
+		Date:      &date,
+		Name:      &name,
+	}
+
+	_, err = s.schoolDataRepository.Insert(c.UserContext(), dataNew)
+	if err != nil {
+		s.logger.Error(c.UserContext(), "add holiday", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.SchoolDataCollection, dataNew.Id.Hex(), dataNew)
+
+	return util.ResponseSuccess(c, fiber.StatusCreated, "create data success", map[string]interface{}{
+		"school_data_id": dataNew.Id,
+	})
+}
+
+func (s *schoolDataController) GetHoliday(c *fiber.Ctx) error {
+	data, err := s.schoolDataRepository.GetByFilterAll(c.UserContext(), bson.M{"type": "Holiday"})
+	if err != nil {
+		s.logger.Error(c.UserContext(), "get holiday", "error", err)
+		return err
+	}
+
+	sort.Slice(data, func(i, j int) bool {
+		return *data[i].Date < *data[j].Date
+	})
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"school_data": data,
 	})
 }
+
+func (s *schoolDataController) DeleteHoliday(c *fiber.Ctx) error {
+	return s.deleteCalendarData(c, []string{"Holiday"})
+}
+
+func isHoliday(ctx context.Context, schoolDataRepository repository.SchoolDataRepository, date string) (bool, error) {
+	_, err := schoolDataRepository.GetByFilter(ctx, bson.M{"type": "Holiday", "date": date})
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
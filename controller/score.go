@@ -1,8 +1,11 @@
 package controller
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
+	"school-notification-backend/realtime"
 	"school-notification-backend/repository"
 	"school-notification-backend/security"
 	"school-notification-backend/util"
@@ -11,7 +14,6 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type ScoreController interface {
@@ -23,26 +25,24 @@ type ScoreController interface {
 }
 
 type scoreController struct {
-	scoreRepository repository.ScoreRepository
-	courseRepo      repository.CourseRepository
-	userRepo        repository.UsersRepository
+	scoreRepository  repository.ScoreRepository
+	courseRepo       repository.CourseRepository
+	profileRepo      repository.ProfileRepository
+	classRepo        repository.ClassRepository
+	notificationRepo repository.NotificationRepository
+	hub              realtime.Hub
+	logger           logger.Logger
 }
 
-func NewScoreController(scoreRepository repository.ScoreRepository, courseRepo repository.CourseRepository, userRepo repository.UsersRepository) ScoreController {
-	return &scoreController{scoreRepository: scoreRepository, courseRepo: courseRepo, userRepo: userRepo}
+func NewScoreController(scoreRepository repository.ScoreRepository, courseRepo repository.CourseRepository, profileRepo repository.ProfileRepository, classRepo repository.ClassRepository, notificationRepo repository.NotificationRepository, hub realtime.Hub, logger logger.Logger) ScoreController {
+	return &scoreController{scoreRepository: scoreRepository, courseRepo: courseRepo, profileRepo: profileRepo, classRepo: classRepo, notificationRepo: notificationRepo, hub: hub, logger: logger}
 }
 
 func (s *scoreController) CreateScore(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"admin", "teacher"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.ScoreRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "create score", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -53,75 +53,79 @@ func (s *scoreController) CreateScore(c *fiber.Ctx) error {
 
 	courseId, err := util.CheckStringData(req.CourseId, "course_id")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "create score", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("course id:", courseId)
+	s.logger.Debug(c.UserContext(), "course id", "course_id", courseId)
 
-	course, err := s.courseRepo.GetCourseById(courseId)
+	course, err := s.courseRepo.GetCourseById(c.UserContext(), courseId)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "create score", "error", err)
+		return err
+	}
+
+	canManage, err := canManageCourse(c, course)
+	if err != nil {
+		s.logger.Error(c.UserContext(), "can manage course", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if !canManage {
+		s.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, errNotOwnerOfCourse.Error())
 	}
 
 	if course.Status != "progress" {
-		log.Println("course status does not progress")
+		s.logger.Warn(c.UserContext(), "course status does not progress")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "course status does not progress")
 	}
 
 	name, err := util.CheckStringData(req.Name, "name")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "create score", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("Score name:", name)
+	s.logger.Debug(c.UserContext(), "score name", "name", name)
 
 	scoreFull, err := util.CheckFloatData(req.ScoreFull, "score_full")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "create score", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("score full:", scoreFull)
+	s.logger.Debug(c.UserContext(), "score full", "score_full", scoreFull)
 
-	_, err = s.scoreRepository.GetScoreByFilter(bson.M{"course_id": courseId, "name": name})
+	_, err = s.scoreRepository.GetScoreByFilter(c.UserContext(), bson.M{"course_id": courseId, "name": name})
 	if err == nil {
-		log.Println("score name already exists")
+		s.logger.Warn(c.UserContext(), "score name already exists")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "score name"+util.ErrValueAlreadyExists.Error())
 	}
-	if err.Error() != "mongo: no documents in result" {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+	if !errors.Is(err, util.ErrNotFound) {
+		s.logger.Error(c.UserContext(), "create score", "error", err)
+		return util.ResponseError(c, err)
 	}
 
 	typeScore, err := util.CheckStringData(req.Type, "type")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "create score", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("Score type:", typeScore)
+	s.logger.Debug(c.UserContext(), "score type", "type_score", typeScore)
 	if typeScore != "midterm" && typeScore != "final" && typeScore != "work" {
-		log.Println(util.ErrTypeInvalid)
+		s.logger.Warn(c.UserContext(), "create score", "error", util.ErrTypeInvalid)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrTypeInvalid.Error())
 	}
 	if typeScore == "midterm" || typeScore == "final" {
-		_, err = s.scoreRepository.GetScoreByFilter(bson.M{"course_id": courseId, "type": typeScore})
+		_, err = s.scoreRepository.GetScoreByFilter(c.UserContext(), bson.M{"course_id": courseId, "type": typeScore})
 		if err == nil {
-			log.Println("score type already exists")
+			s.logger.Warn(c.UserContext(), "score type already exists")
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "score type"+util.ErrValueAlreadyExists.Error())
 		}
-		if err.Error() != "mongo: no documents in result" {
-			log.Println(err)
-			return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		if !errors.Is(err, util.ErrNotFound) {
+			s.logger.Error(c.UserContext(), "create score", "error", err)
+			return util.ResponseError(c, err)
 		}
 	}
 
-	t := time.Now().Format(time.RFC3339)
+	t := time.Now()
 	scoreNew := &models.Score{
 		Id:               primitive.NewObjectID(),
 		CreatedAt:        t,
@@ -133,11 +137,12 @@ func (s *scoreController) CreateScore(c *fiber.Ctx) error {
 		ScoreInformation: createScoreinformation(course.StudentIdList, t),
 	}
 
-	result, err := s.scoreRepository.Insert(scoreNew)
+	result, err := s.scoreRepository.Insert(c.UserContext(), scoreNew)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "create score", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.ScoreCollection, scoreNew.Id.Hex(), scoreNew)
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "create Score success", map[string]interface{}{
 		"score_id": result.InsertedID,
@@ -145,16 +150,10 @@ func (s *scoreController) CreateScore(c *fiber.Ctx) error {
 }
 
 func (s *scoreController) UpdateStudentScore(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"admin", "teacher"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.ScoreRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "update student score", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -165,64 +164,65 @@ func (s *scoreController) UpdateStudentScore(c *fiber.Ctx) error {
 
 	courseId, err := util.CheckStringData(req.CourseId, "course_id")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "update student score", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("course id:", courseId)
+	s.logger.Debug(c.UserContext(), "course id", "course_id", courseId)
 
-	course, err := s.courseRepo.GetCourseById(courseId)
+	course, err := s.courseRepo.GetCourseById(c.UserContext(), courseId)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "update student score", "error", err)
+		return err
+	}
+
+	canManage, err := canManageCourse(c, course)
+	if err != nil {
+		s.logger.Error(c.UserContext(), "can manage course", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if !canManage {
+		s.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, errNotOwnerOfCourse.Error())
 	}
 
 	if course.Status != "progress" {
-		log.Println("course status does not progress")
+		s.logger.Warn(c.UserContext(), "course status does not progress")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "course status does not progress")
 	}
 
 	name, err := util.CheckStringData(req.Name, "name")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "update student score", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("Score name:", name)
+	s.logger.Debug(c.UserContext(), "score name", "name", name)
 
 	scoreGet, err := util.CheckFloatData(req.ScoreGet, "score_get")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "update student score", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("score get:", scoreGet)
+	s.logger.Debug(c.UserContext(), "score get", "score_get", scoreGet)
 
-	score, err := s.scoreRepository.GetScoreByFilter(bson.M{"course_id": courseId, "name": name})
+	score, err := s.scoreRepository.GetScoreByFilter(c.UserContext(), bson.M{"course_id": courseId, "name": name})
 	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "update student score", "error", err)
+		return err
 	}
 
 	studentId, err := util.CheckStringData(req.StudentId, "student_id")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "update student score", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("student id:", studentId)
+	s.logger.Debug(c.UserContext(), "student id", "student_id", studentId)
 
 	status, err := util.CheckStringData(req.Status, "status")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "update student score", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("status:", status)
+	s.logger.Debug(c.UserContext(), "status", "status", status)
 
 	check := true
 	for _, v := range course.StudentIdList {
@@ -233,29 +233,30 @@ func (s *scoreController) UpdateStudentScore(c *fiber.Ctx) error {
 	}
 
 	if check {
-		log.Println("student id not found in course")
+		s.logger.Warn(c.UserContext(), "student id not found in course")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "student id not found in course")
 	}
 
 	// check status
 	if status != "normal" && status != "late" {
-		log.Println("status", util.ErrValueInvalid)
+		s.logger.Warn(c.UserContext(), "status", "error", util.ErrValueInvalid)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "status"+util.ErrValueInvalid.Error())
 	}
 
 	if scoreGet > score.ScoreFull {
-		log.Println("score get", util.ErrValueInvalid)
+		s.logger.Warn(c.UserContext(), "score get", "error", util.ErrValueInvalid)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "score get"+util.ErrValueInvalid.Error())
 	}
 
 	if len(score.ScoreInformation) != len(course.StudentIdList) {
-		log.Println("score info", util.ErrValueInvalid)
+		s.logger.Warn(c.UserContext(), "score info", "error", util.ErrValueInvalid)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "score info"+util.ErrValueInvalid.Error())
 	}
 
+	security.AuditBefore(c, repository.ScoreCollection, score.Id.Hex(), score)
 	for i, v := range score.ScoreInformation {
 		if v.StudentId == studentId {
-			score.ScoreInformation[i].UpdatedAt = time.Now().Format(time.RFC3339)
+			score.ScoreInformation[i].UpdatedAt = time.Now()
 			score.ScoreInformation[i].ScoreGet = &scoreGet
 			score.ScoreInformation[i].Status = status
 			break
@@ -264,16 +265,19 @@ func (s *scoreController) UpdateStudentScore(c *fiber.Ctx) error {
 
 	// score.ScoreInformation = append(score.ScoreInformation, models.ScoreInformation{
 	// 	StudentId: studentId,
-	// 	UpdatedAt: time.Now().Format(time.RFC3339),
+	// 	UpdatedAt: time.Now(),
 	// 	ScoreGet:  scoreGet,
 	// 	Status:    status,
 	// })
 
-	result, err := s.scoreRepository.Update(score)
+	result, err := s.scoreRepository.Update(c.UserContext(), score)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "update student score", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.ScoreCollection, score.Id.Hex(), score)
+
+	sendNotification(c.UserContext(), s.logger, s.notificationRepo, s.hub, newStudentNotification(c.UserContext(), s.logger, s.profileRepo, studentId, "score", "score published", fmt.Sprintf("%s %s: %v/%v", course.Name, score.Name, scoreGet, score.ScoreFull), score.Id.Hex()))
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "update subject success", map[string]interface{}{
 		"score_id":     score.Id,
@@ -302,7 +306,7 @@ func (s *scoreController) UpdateStudentScore(c *fiber.Ctx) error {
 // 	}
 // 	log.Println("course id:", courseId)
 
-// 	course, err := s.courseRepo.GetCourseById(courseId)
+// 	course, err := s.courseRepo.GetCourseById(c.UserContext(), courseId)
 // 	if err != nil {
 // 		log.Println(err)
 // 		if err.Error() == "mongo: no documents in result" {
@@ -333,7 +337,7 @@ func (s *scoreController) UpdateStudentScore(c *fiber.Ctx) error {
 // 	}
 // 	log.Println("score get:", scoreGet)
 
-// 	score, err := s.scoreRepository.GetScoreByFilter(bson.M{"course_id": courseId, "name": name})
+// 	score, err := s.scoreRepository.GetScoreByFilter(c.UserContext(), bson.M{"course_id": courseId, "name": name})
 // 	if err != nil {
 // 		log.Println(err)
 // 		if err == mongo.ErrNoDocuments {
@@ -390,9 +394,9 @@ func (s *scoreController) UpdateStudentScore(c *fiber.Ctx) error {
 
 // 	score.ScoreInformation[index].ScoreGet = scoreGet
 // 	score.ScoreInformation[index].Status = status
-// 	score.ScoreInformation[index].UpdatedAt = time.Now().Format(time.RFC3339)
+// 	score.ScoreInformation[index].UpdatedAt = time.Now()
 
-// 	result, err := s.scoreRepository.Update(score)
+// 	result, err := s.scoreRepository.Update(c.UserContext(), score)
 // 	if err != nil {
 // 		log.Println(err)
 // 		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
@@ -405,69 +409,37 @@ func (s *scoreController) UpdateStudentScore(c *fiber.Ctx) error {
 // }
 
 func (s *scoreController) GetScoreByCourseId(c *fiber.Ctx) error {
-	user, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-	role := user.Role
-	profileId := user.ProfileId
-
 	courseId, err := util.CheckStringData(c.Query("course_id"), "course_id")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "get score by course id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find score of course id:", courseId)
-	course, err := s.courseRepo.GetCourseById(courseId)
+	s.logger.Debug(c.UserContext(), "find score of course id", "course_id", courseId)
+	course, err := s.courseRepo.GetCourseById(c.UserContext(), courseId)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "get score by course id", "error", err)
+		return err
 	}
 
-	scores, err := s.scoreRepository.GetByFilterAll(bson.M{"course_id": courseId})
+	studentIdList, err := readableStudentIdList(c, s.profileRepo, s.classRepo, course)
 	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "get score by course id", "error", err)
+		return util.ResponseError(c, err)
 	}
-
-	if len(scores) == 0 {
-		log.Println("score not found")
-		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
+	if studentIdList != nil && len(studentIdList) == 0 {
+		s.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, errNotOwnerOfCourse.Error())
 	}
 
-	if role == "teacher" {
-		if profileId != course.InstructorId {
-			log.Println("not permiistion")
-			return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "not permission")
-		}
+	scores, err := s.scoreRepository.GetByFilterAll(c.UserContext(), bson.M{"course_id": courseId})
+	if err != nil {
+		s.logger.Error(c.UserContext(), "get score by course id", "error", err)
+		return err
+	}
 
-	} else if role == "student" {
-		check := true
-		for _, v := range course.StudentIdList {
-			if v == profileId {
-				check = false
-				break
-			}
-		}
-		if check {
-			log.Println("not permiistion")
-			return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "not permission")
-		}
-	} else {
-		if role != "server" {
-			log.Println("role", util.ErrValueInvalid)
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "role"+util.ErrValueInvalid.Error())
-		}
+	if len(scores) == 0 {
+		s.logger.Warn(c.UserContext(), "score not found")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
 	scoreNames := []string{}
@@ -476,7 +448,7 @@ func (s *scoreController) GetScoreByCourseId(c *fiber.Ctx) error {
 	}
 
 	if len(scoreNames) == 0 {
-		log.Println("score name ", util.ErrNotFound)
+		s.logger.Warn(c.UserContext(), "score name", "error", util.ErrNotFound)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "score name "+util.ErrNotFound.Error())
 	}
 
@@ -485,107 +457,75 @@ func (s *scoreController) GetScoreByCourseId(c *fiber.Ctx) error {
 	})
 }
 
+// instructor and admin read all student, student read own score, advisor and parent read score of their student
 func (s *scoreController) GetScoreDataByCourseIdAndNameSore(c *fiber.Ctx) error {
-	user, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-	role := user.Role
-	profileId := user.ProfileId
+	user := security.GetUser(c)
 
 	courseId, err := util.CheckStringData(c.Query("course_id"), "course_id")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "get score data by course id and name sore", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find score of course id:", courseId)
-	course, err := s.courseRepo.GetCourseById(courseId)
+	s.logger.Debug(c.UserContext(), "find score of course id", "course_id", courseId)
+	course, err := s.courseRepo.GetCourseById(c.UserContext(), courseId)
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "get score data by course id and name sore", "error", err)
+		return err
+	}
+
+	studentIdList, err := readableStudentIdList(c, s.profileRepo, s.classRepo, course)
+	if err != nil {
+		s.logger.Error(c.UserContext(), "get score data by course id and name sore", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if studentIdList != nil && len(studentIdList) == 0 {
+		s.logger.Warn(c.UserContext(), "not permiistion")
+		return util.ResponseNotSuccess(c, fiber.StatusForbidden, errNotOwnerOfCourse.Error())
 	}
 
 	var scoreRes interface{}
-	if role == "teacher" {
-		if profileId != course.InstructorId {
-			log.Println("not permiistion")
-			return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "not permission")
+	if user.Role == "student" {
+		scores, err := s.scoreRepository.GetByFilterAll(c.UserContext(), bson.M{"course_id": courseId})
+		if err != nil {
+			s.logger.Error(c.UserContext(), "get score data by course id and name sore", "error", err)
+			return err
 		}
 
+		if len(scores) == 0 {
+			s.logger.Warn(c.UserContext(), "score not found")
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
+		}
+		scoreList := newScoreStudentResList(scores, user.ProfileId)
+		if len(scoreList) == 0 {
+			s.logger.Warn(c.UserContext(), "student id", "error", util.ErrNotFound)
+			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "score "+util.ErrNotFound.Error())
+		}
+		scoreRes = scoreList
+	} else {
 		name, err := util.CheckStringData(c.Query("name"), "name")
 		if err != nil {
-			log.Println(err)
+			s.logger.Warn(c.UserContext(), "get score data by course id and name sore", "error", err)
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 		}
-		log.Println("find score name:", name)
+		s.logger.Debug(c.UserContext(), "find score name", "name", name)
 
-		score, err := s.scoreRepository.GetScoreByFilter(bson.M{"course_id": courseId, "name": name})
+		score, err := s.scoreRepository.GetScoreByFilter(c.UserContext(), bson.M{"course_id": courseId, "name": name})
 		if err != nil {
-			log.Println(err)
-			if err == mongo.ErrNoDocuments {
-				return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-			}
-			return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+			s.logger.Error(c.UserContext(), "get score data by course id and name sore", "error", err)
+			return err
 		}
 
-		scoreRes = score
-	} else if role == "student" {
-		check := true
-		for _, v := range course.StudentIdList {
-			if v == profileId {
-				check = false
-				break
-			}
-		}
-		if check {
-			log.Println("not permiistion")
-			return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "not permission")
-		}
-		scores, err := s.scoreRepository.GetByFilterAll(bson.M{"course_id": courseId})
-		if err != nil {
-			log.Println(err)
-			if err == mongo.ErrNoDocuments {
-				return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-			}
-			return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
-		}
-
-		if len(scores) == 0 {
-			log.Println("score not found")
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
-		}
-		scoreList := []models.ScoreStudentRes{}
-		for _, v := range scores {
-			for _, info := range v.ScoreInformation {
-				if info.StudentId == profileId {
-					scoreList = append(scoreList, models.ScoreStudentRes{
-						Name:      v.Name,
-						UpdatedAt: info.UpdatedAt,
-						ScoreFull: v.ScoreFull,
-						ScoreGet:  info.ScoreGet,
-						Status:    info.Status,
-					})
-					break
+		if studentIdList != nil {
+			scoreInformation := []models.ScoreInformation{}
+			for _, v := range score.ScoreInformation {
+				if isStudentInList(studentIdList, v.StudentId) {
+					scoreInformation = append(scoreInformation, v)
 				}
 			}
+			score.ScoreInformation = scoreInformation
 		}
-		if len(scoreList) == 0 {
-			log.Println("student id", util.ErrNotFound)
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "score "+util.ErrNotFound.Error())
-		}
-		scoreRes = scoreList
-	} else {
-		if role != "server" {
-			log.Println("role", util.ErrValueInvalid)
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "role"+util.ErrValueInvalid.Error())
-		}
+
+		scoreRes = score
 	}
 
 	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
@@ -593,7 +533,7 @@ func (s *scoreController) GetScoreDataByCourseIdAndNameSore(c *fiber.Ctx) error
 	})
 }
 
-func createScoreinformation(studentIdList []string, t string) []models.ScoreInformation {
+func createScoreinformation(studentIdList []string, t time.Time) []models.ScoreInformation {
 
 	var res []models.ScoreInformation
 	for _, s := range studentIdList {
@@ -606,3 +546,23 @@ func createScoreinformation(studentIdList []string, t string) []models.ScoreInfo
 
 	return res
 }
+
+func newScoreStudentResList(scores []*models.Score, studentId string) []models.ScoreStudentRes {
+	scoreList := []models.ScoreStudentRes{}
+	for _, v := range scores {
+		for _, info := range v.ScoreInformation {
+			if info.StudentId == studentId {
+				scoreList = append(scoreList, models.ScoreStudentRes{
+					Name:      v.Name,
+					UpdatedAt: info.UpdatedAt,
+					ScoreFull: v.ScoreFull,
+					ScoreGet:  info.ScoreGet,
+					Status:    info.Status,
+				})
+				break
+			}
+		}
+	}
+
+	return scoreList
+}
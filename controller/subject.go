@@ -1,7 +1,8 @@
 package controller
 
 import (
-	"log"
+	"errors"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/repository"
 	"school-notification-backend/security"
@@ -12,7 +13,6 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type SubjectController interface {
@@ -28,24 +28,18 @@ type subjectController struct {
 	subjectRepository    repository.SubjectRepository
 	schoolDataRepository repository.SchoolDataRepository
 	profileRepo          repository.ProfileRepository
-	userRepo             repository.UsersRepository
+	logger               logger.Logger
 }
 
-func NewSubjectController(subjectRepository repository.SubjectRepository, schoolDataRepository repository.SchoolDataRepository, profileRepo repository.ProfileRepository, userRepo repository.UsersRepository) SubjectController {
-	return &subjectController{subjectRepository: subjectRepository, schoolDataRepository: schoolDataRepository, profileRepo: profileRepo, userRepo: userRepo}
+func NewSubjectController(subjectRepository repository.SubjectRepository, schoolDataRepository repository.SchoolDataRepository, profileRepo repository.ProfileRepository, logger logger.Logger) SubjectController {
+	return &subjectController{subjectRepository: subjectRepository, schoolDataRepository: schoolDataRepository, profileRepo: profileRepo, logger: logger}
 }
 
 func (s *subjectController) CreateSubject(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"admin"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.SubjectRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "create subject", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -56,60 +50,57 @@ func (s *subjectController) CreateSubject(c *fiber.Ctx) error {
 
 	subjectId, err := util.CheckStringData(req.SubjectId, "subject_id")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "create subject", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("subject id:", subjectId)
+	s.logger.Debug(c.UserContext(), "subject id", "subject_id", subjectId)
 
-	_, err = s.subjectRepository.GetSubjectByFilter(bson.M{"subject_id": subjectId})
+	_, err = s.subjectRepository.GetSubjectByFilter(c.UserContext(), bson.M{"subject_id": subjectId})
 	if err == nil {
-		log.Println("subject id already exists")
+		s.logger.Warn(c.UserContext(), "subject id already exists")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "subject id"+util.ErrValueAlreadyExists.Error())
 	}
-	if err.Error() != "mongo: no documents in result" {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+	if !errors.Is(err, util.ErrNotFound) {
+		s.logger.Error(c.UserContext(), "create subject", "error", err)
+		return util.ResponseError(c, err)
 	}
 
 	name, err := util.CheckStringData(req.Name, "name")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "create subject", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("subject name:", name)
+	s.logger.Debug(c.UserContext(), "subject name", "name", name)
 
 	category, err := util.CheckStringData(req.Category, "category")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "create subject", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("subject category:", category)
+	s.logger.Debug(c.UserContext(), "subject category", "category", category)
 
 	classYear, err := util.CheckStringData(req.ClassYear, "class_year")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "create subject", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("subject class year:", classYear)
+	s.logger.Debug(c.UserContext(), "subject class year", "class_year", classYear)
 
 	credit, err := util.CheckIntegerData(req.Credit, "credit")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "create subject", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("subject credit:", credit)
+	s.logger.Debug(c.UserContext(), "subject credit", "credit", credit)
 
-	data, err := s.schoolDataRepository.GetByFilterAll(bson.M{"type": "SubjectCategory"})
+	data, err := s.schoolDataRepository.GetByFilterAll(c.UserContext(), bson.M{"type": "SubjectCategory"})
 	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "create subject", "error", err)
+		return err
 	}
 
 	if len(data) == 0 {
-		log.Println("data not found")
+		s.logger.Warn(c.UserContext(), "data not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -122,7 +113,7 @@ func (s *subjectController) CreateSubject(c *fiber.Ctx) error {
 	}
 
 	if check {
-		log.Println("category", util.ErrValueNotAlreadyExists)
+		s.logger.Warn(c.UserContext(), "category", "error", util.ErrValueNotAlreadyExists)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "category"+util.ErrValueNotAlreadyExists.Error())
 	}
 
@@ -138,8 +129,8 @@ func (s *subjectController) CreateSubject(c *fiber.Ctx) error {
 
 	subjectNew := &models.Subject{
 		Id:        primitive.NewObjectID(),
-		CreatedAt: time.Now().Format(time.RFC3339),
-		UpdatedAt: time.Now().Format(time.RFC3339),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 		SubjectId: subjectId,
 		Name:      name,
 		Credit:    credit,
@@ -148,11 +139,12 @@ func (s *subjectController) CreateSubject(c *fiber.Ctx) error {
 		// InstructorId: instructorId,
 	}
 
-	_, err = s.subjectRepository.Insert(subjectNew)
+	_, err = s.subjectRepository.Insert(c.UserContext(), subjectNew)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "create subject", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.SubjectCollection, subjectNew.Id.Hex(), subjectNew)
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "create subject success", map[string]interface{}{
 		"subject_id": req.SubjectId,
@@ -160,16 +152,10 @@ func (s *subjectController) CreateSubject(c *fiber.Ctx) error {
 }
 
 func (s *subjectController) UpdateSubject(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"admin"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.SubjectRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "update subject", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -180,50 +166,44 @@ func (s *subjectController) UpdateSubject(c *fiber.Ctx) error {
 
 	subjectId, err := util.CheckStringData(req.SubjectId, "subject_id")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "update subject", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("subject id:", subjectId)
+	s.logger.Debug(c.UserContext(), "subject id", "subject_id", subjectId)
 
-	subject, err := s.subjectRepository.GetSubjectByFilter(bson.M{"subject_id": subjectId})
+	subject, err := s.subjectRepository.GetSubjectByFilter(c.UserContext(), bson.M{"subject_id": subjectId})
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "update subject", "error", err)
+		return err
 	}
 
 	name, err := util.CheckStringData(req.Name, "name")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "update subject", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("subject name:", name)
+	s.logger.Debug(c.UserContext(), "subject name", "name", name)
 
 	category, err := util.CheckStringData(req.Category, "category")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "update subject", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("subject category:", category)
+	s.logger.Debug(c.UserContext(), "subject category", "category", category)
 
 	classYear, err := util.CheckStringData(req.ClassYear, "class_year")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "update subject", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("subject class year:", classYear)
+	s.logger.Debug(c.UserContext(), "subject class year", "class_year", classYear)
 
 	credit, err := util.CheckIntegerData(req.Credit, "credit")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "update subject", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("subject credit:", credit)
+	s.logger.Debug(c.UserContext(), "subject credit", "credit", credit)
 
 	// instructorId := []string{}
 	// for _, v := range req.InstructorId {
@@ -235,17 +215,17 @@ func (s *subjectController) UpdateSubject(c *fiber.Ctx) error {
 	// 	instructorId = append(instructorId, inId)
 	// }
 
-	subject.UpdatedAt = time.Now().Format(time.RFC3339)
+	subject.UpdatedAt = time.Now()
 	subject.Name = name
 	subject.Category = category
 	subject.Credit = credit
 	subject.ClassYear = classYear
 	// subject.InstructorId = instructorId
 
-	result, err := s.subjectRepository.Update(subject)
+	result, err := s.subjectRepository.Update(c.UserContext(), subject)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "update subject", "error", err)
+		return util.ResponseError(c, err)
 	}
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "update subject success", map[string]interface{}{
@@ -255,23 +235,14 @@ func (s *subjectController) UpdateSubject(c *fiber.Ctx) error {
 }
 
 func (s *subjectController) GetSubjectAll(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"all"})
+	subjects, err := s.subjectRepository.GetAll(c.UserContext())
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
-	subjects, err := s.subjectRepository.GetAll()
-	if err != nil {
-		log.Println(err)
-		if err == mongo.ErrNoDocuments {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "get subject all", "error", err)
+		return err
 	}
 
 	if len(subjects) == 0 {
-		log.Println("Subject not found")
+		s.logger.Warn(c.UserContext(), "subject not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -281,33 +252,21 @@ func (s *subjectController) GetSubjectAll(c *fiber.Ctx) error {
 }
 
 func (s *subjectController) GetSubjectById(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	id, err := util.CheckStringData(c.Query("subject_id"), "subject_id")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "get subject by id", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find subject id:", id)
+	s.logger.Debug(c.UserContext(), "find subject id", "id", id)
 
-	subject, err := s.subjectRepository.GetSubjectByFilter(bson.M{"subject_id": id})
+	subject, err := s.subjectRepository.GetSubjectByFilter(c.UserContext(), bson.M{"subject_id": id})
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "get subject by id", "error", err)
+		return err
 	}
 
 	if subject == nil {
-		log.Println("Subject not found")
+		s.logger.Warn(c.UserContext(), "subject not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -317,33 +276,21 @@ func (s *subjectController) GetSubjectById(c *fiber.Ctx) error {
 }
 
 func (s *subjectController) GetSubjectByCategory(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"all"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	category, err := util.CheckStringData(c.Query("category"), "category")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "get subject by category", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("find subject category:", category)
+	s.logger.Debug(c.UserContext(), "find subject category", "category", category)
 
-	subjects, err := s.subjectRepository.GetSubjectByFilterAll(bson.M{"category": category})
+	subjects, err := s.subjectRepository.GetSubjectByFilterAll(c.UserContext(), bson.M{"category": category})
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "get subject by category", "error", err)
+		return err
 	}
 
 	if len(subjects) == 0 {
-		log.Println("Subject not found")
+		s.logger.Warn(c.UserContext(), "subject not found")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrNotFound.Error())
 	}
 
@@ -353,16 +300,10 @@ func (s *subjectController) GetSubjectByCategory(c *fiber.Ctx) error {
 }
 
 func (s *subjectController) AddInstructor(c *fiber.Ctx) error {
-	_, err := security.CheckRoleFromToken(c.GetReqHeaders()["Authorization"], s.userRepo, []string{"admin"})
-	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
-	}
-
 	req := models.SubjectRequest{}
-	err = c.BodyParser(&req)
+	err := c.BodyParser(&req)
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "add instructor", "error", err)
 		value, ok := err.(*fiber.Error)
 		if ok {
 			return util.ResponseNotSuccess(c, value.Code, value.Message)
@@ -373,38 +314,32 @@ func (s *subjectController) AddInstructor(c *fiber.Ctx) error {
 
 	subjectId, err := util.CheckStringData(req.SubjectId, "subject_id")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "add instructor", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("subject id:", subjectId)
+	s.logger.Debug(c.UserContext(), "subject id", "subject_id", subjectId)
 
-	subject, err := s.subjectRepository.GetSubjectByFilter(bson.M{"subject_id": subjectId})
+	subject, err := s.subjectRepository.GetSubjectByFilter(c.UserContext(), bson.M{"subject_id": subjectId})
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "add instructor", "error", err)
+		return err
 	}
 
 	if len(subject.InstructorId) == 3 {
-		log.Println("this subject is fully")
+		s.logger.Warn(c.UserContext(), "this subject is fully")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "this subject is fully")
 	}
 
 	instructorId, err := util.CheckStringData(req.InstructorId, "instructor_id")
 	if err != nil {
-		log.Println(err)
+		s.logger.Warn(c.UserContext(), "add instructor", "error", err)
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
 	}
-	log.Println("instructor id:", instructorId)
+	s.logger.Debug(c.UserContext(), "instructor id", "instructor_id", instructorId)
 
 	for _, v := range subject.InstructorId {
 		if v == instructorId {
-			log.Println("instructor" + util.ErrValueAlreadyExists.Error())
+			s.logger.Warn(c.UserContext(), "add instructor", "error", "instructor"+util.ErrValueAlreadyExists.Error())
 			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "instructor"+util.ErrValueAlreadyExists.Error())
 		}
 	}
@@ -414,40 +349,38 @@ func (s *subjectController) AddInstructor(c *fiber.Ctx) error {
 		"role":       "teacher",
 	}
 
-	p, err := s.profileRepo.GetProfileById(filter, "teacher")
+	p, err := s.profileRepo.GetProfileById(c.UserContext(), filter, "teacher")
 	if err != nil {
-		log.Println(err)
-		if err.Error() == "mongo: no documents in result" {
-			return util.ResponseNotSuccess(c, fiber.StatusNotFound, util.ErrNotFound.Error())
-		}
-		if err.Error() == "Id is not primitive objectID" {
-			return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
-		}
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "add instructor", "error", err)
+		return err
 	}
 
 	profile, _ := p.(models.ProfileTeacher)
 
 	if profile.Category != subject.Category {
-		log.Println("category not match")
+		s.logger.Warn(c.UserContext(), "category not match")
 		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "category not match")
 	}
 
+	security.AuditBefore(c, repository.ProfileCollection, profile.Id.Hex(), profile)
 	profile.SubjectId = subjectId
-	_, err = s.profileRepo.Update(profile.Id, profile)
+	_, err = s.profileRepo.Update(c.UserContext(), profile.Id, profile)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "add instructor", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.ProfileCollection, profile.Id.Hex(), profile)
 
-	subject.UpdatedAt = time.Now().Format(time.RFC3339)
+	security.AuditBefore(c, repository.SubjectCollection, subject.Id.Hex(), subject)
+	subject.UpdatedAt = time.Now()
 	subject.InstructorId = append(subject.InstructorId, instructorId)
 
-	result, err := s.subjectRepository.Update(subject)
+	result, err := s.subjectRepository.Update(c.UserContext(), subject)
 	if err != nil {
-		log.Println(err)
-		return util.ResponseNotSuccess(c, fiber.StatusInternalServerError, util.ErrInternalServerError.Error())
+		s.logger.Error(c.UserContext(), "add instructor", "error", err)
+		return util.ResponseError(c, err)
 	}
+	security.AuditAfter(c, repository.SubjectCollection, subject.Id.Hex(), subject)
 
 	return util.ResponseSuccess(c, fiber.StatusCreated, "update subject success", map[string]interface{}{
 		"subject_id":   req.SubjectId,
@@ -0,0 +1,482 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/repository"
+	"school-notification-backend/security"
+	"school-notification-backend/util"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// second step of sign in, totp code or recovery code exchange challenge token for token
+func (a *authController) SignInTwoFactor(c *fiber.Ctx) error {
+	req := models.TwoFactorRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "sign in two factor", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	challengeToken, err := util.CheckStringData(req.ChallengeToken, "challenge_token")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "sign in two factor", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	code, err := util.CheckStringData(req.Code, "code")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "sign in two factor", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	userId, err := security.ParseChallengeToken(challengeToken)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "sign in two factor", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	user, err := a.userRepo.GetById(c.UserContext(), userId)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "sign in two factor", "error", err)
+		if errors.Is(err, util.ErrNotFound) {
+			return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, security.ErrChallengeTokenInvalid.Error())
+		}
+		return util.ResponseError(c, err)
+	}
+	a.logger.Debug(c.UserContext(), "two factor signin", "username", user.Username)
+
+	wait, err := loginRetryAfter(c.UserContext(), a.loginLockRepo, user.Username, c.IP())
+	if err != nil {
+		a.logger.Error(c.UserContext(), "sign in two factor", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if wait > 0 {
+		a.logger.Warn(c.UserContext(), "signin locked", "username", user.Username, "ip", c.IP())
+		recordLoginAttempt(c, a.logger, a.loginAttemptRepo, user.Username, user.Id.Hex(), false, "locked")
+		return responseLoginLocked(c, wait)
+	}
+
+	if !user.TotpEnabled {
+		a.logger.Warn(c.UserContext(), "two factor is not enabled")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "two factor is not enabled")
+	}
+
+	if !verifyTwoFactorCode(c.UserContext(), a.logger, user, code, true) {
+		a.logger.Warn(c.UserContext(), "two factor signin failed", "username", user.Username)
+		addLoginFailure(c.UserContext(), a.logger, a.loginLockRepo, user.Username, c.IP())
+		recordLoginAttempt(c, a.logger, a.loginAttemptRepo, user.Username, user.Id.Hex(), false, "invalid two factor code")
+		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "invalid two factor code")
+	}
+
+	_, err = a.userRepo.Update(c.UserContext(), user)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "sign in two factor", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	return a.signInSuccess(c, user)
+}
+
+// new secret is pending until confirm with code from authenticator app
+func (a *authController) EnrollTwoFactor(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	if user.TotpEnabled {
+		a.logger.Warn(c.UserContext(), "two factor already enabled")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "two factor already enabled")
+	}
+
+	secret, err := security.NewTotpSecret()
+	if err != nil {
+		a.logger.Error(c.UserContext(), "enroll two factor", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditBefore(c, repository.UsersCollection, user.Id.Hex(), user)
+	user.TotpPendingSecret = secret
+
+	_, err = a.userRepo.Update(c.UserContext(), user)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "enroll two factor", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.UsersCollection, user.Id.Hex(), user)
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "enroll two factor success", map[string]interface{}{
+		"secret":      secret,
+		"otpauth_uri": security.TotpURI(secret, user.Username),
+	})
+}
+
+// recovery codes are return only once
+func (a *authController) ConfirmTwoFactor(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	req := models.TwoFactorRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "confirm two factor", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	code, err := util.CheckStringData(req.Code, "code")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "confirm two factor", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	if user.TotpEnabled {
+		a.logger.Warn(c.UserContext(), "two factor already enabled")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "two factor already enabled")
+	}
+
+	if user.TotpPendingSecret == "" {
+		a.logger.Warn(c.UserContext(), "two factor is not enrolled")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "two factor is not enrolled")
+	}
+
+	step, ok := security.VerifyTotp(user.TotpPendingSecret, code, 0, time.Now())
+	if !ok {
+		a.logger.Warn(c.UserContext(), "confirm two factor failed", "username", user.Username)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "invalid two factor code")
+	}
+
+	codes, hashes, err := security.NewRecoveryCodes()
+	if err != nil {
+		a.logger.Error(c.UserContext(), "confirm two factor", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	security.AuditBefore(c, repository.UsersCollection, user.Id.Hex(), user)
+	user.TotpEnabled = true
+	user.TotpSecret = user.TotpPendingSecret
+	user.TotpPendingSecret = ""
+	user.TotpLastStep = step
+	user.RecoveryCodeHashes = hashes
+
+	_, err = a.userRepo.Update(c.UserContext(), user)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "confirm two factor", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.UsersCollection, user.Id.Hex(), user)
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "enable two factor success", map[string]interface{}{
+		"recovery_codes": codes,
+	})
+}
+
+// disable need password and code, role that require two factor can not disable
+func (a *authController) DisableTwoFactor(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	req := models.TwoFactorRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "disable two factor", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	password, err := util.CheckStringData(req.Password, "password")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "disable two factor", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	code, err := util.CheckStringData(req.Code, "code")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "disable two factor", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	if !user.TotpEnabled {
+		a.logger.Warn(c.UserContext(), "two factor is not enabled")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "two factor is not enabled")
+	}
+
+	required, err := security.IsTwoFactorRequired(c.UserContext(), user.Role)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "disable two factor", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if required {
+		a.logger.Warn(c.UserContext(), "two factor is required for role", "role", user.Role)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "two factor is required for role "+user.Role)
+	}
+
+	// wrong code count to the lock of sign in, so stolen token can not guess the code
+	wait, err := loginRetryAfter(c.UserContext(), a.loginLockRepo, user.Username, c.IP())
+	if err != nil {
+		a.logger.Error(c.UserContext(), "disable two factor", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if wait > 0 {
+		a.logger.Warn(c.UserContext(), "disable two factor locked", "username", user.Username, "ip", c.IP())
+		return responseLoginLocked(c, wait)
+	}
+
+	security.AuditBefore(c, repository.UsersCollection, user.Id.Hex(), user)
+	err = security.VerifyPassword(user.Password, password)
+	if err != nil || !verifyTwoFactorCode(c.UserContext(), a.logger, user, code, true) {
+		a.logger.Warn(c.UserContext(), "disable two factor failed", "username", user.Username)
+		addLoginFailure(c.UserContext(), a.logger, a.loginLockRepo, user.Username, c.IP())
+		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, errInvalidCredentials.Error())
+	}
+
+	clearTwoFactor(user)
+	_, err = a.userRepo.Update(c.UserContext(), user)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "disable two factor", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.UsersCollection, user.Id.Hex(), user)
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "disable two factor success", map[string]interface{}{
+		"user_id": user.Id,
+	})
+}
+
+// new recovery codes replace all old codes, need totp code
+func (a *authController) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	user := security.GetUser(c)
+
+	req := models.TwoFactorRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "regenerate recovery codes", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	code, err := util.CheckStringData(req.Code, "code")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "regenerate recovery codes", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	if !user.TotpEnabled {
+		a.logger.Warn(c.UserContext(), "two factor is not enabled")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "two factor is not enabled")
+	}
+
+	// wrong code count to the lock of sign in, so stolen token can not guess the code
+	wait, err := loginRetryAfter(c.UserContext(), a.loginLockRepo, user.Username, c.IP())
+	if err != nil {
+		a.logger.Error(c.UserContext(), "regenerate recovery codes", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if wait > 0 {
+		a.logger.Warn(c.UserContext(), "regenerate recovery codes locked", "username", user.Username, "ip", c.IP())
+		return responseLoginLocked(c, wait)
+	}
+
+	security.AuditBefore(c, repository.UsersCollection, user.Id.Hex(), user)
+	if !verifyTwoFactorCode(c.UserContext(), a.logger, user, code, false) {
+		a.logger.Warn(c.UserContext(), "regenerate recovery codes failed", "username", user.Username)
+		addLoginFailure(c.UserContext(), a.logger, a.loginLockRepo, user.Username, c.IP())
+		return util.ResponseNotSuccess(c, fiber.StatusUnauthorized, "invalid two factor code")
+	}
+
+	codes, hashes, err := security.NewRecoveryCodes()
+	if err != nil {
+		a.logger.Error(c.UserContext(), "regenerate recovery codes", "error", err)
+		return util.ResponseError(c, err)
+	}
+	user.RecoveryCodeHashes = hashes
+
+	_, err = a.userRepo.Update(c.UserContext(), user)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "regenerate recovery codes", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.UsersCollection, user.Id.Hex(), user)
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "regenerate recovery codes success", map[string]interface{}{
+		"recovery_codes": codes,
+	})
+}
+
+// admin remove two factor of user that lost device, user session are revoked
+func (a *authController) ResetTwoFactor(c *fiber.Ctx) error {
+	admin := security.GetUser(c)
+
+	req := models.TwoFactorRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "reset two factor", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	userId, err := util.CheckStringData(req.UserId, "user_id")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "reset two factor", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	a.logger.Debug(c.UserContext(), "reset two factor of user id", "user_id", userId)
+
+	user, err := a.userRepo.GetById(c.UserContext(), userId)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "reset two factor", "error", err)
+		return err
+	}
+
+	security.AuditBefore(c, repository.UsersCollection, user.Id.Hex(), user)
+	clearTwoFactor(user)
+	_, err = a.userRepo.Update(c.UserContext(), user)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "reset two factor", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.UsersCollection, user.Id.Hex(), user)
+
+	_, err = a.revokeUserSession(c, user.Id.Hex(), admin.Id.Hex(), revokeReasonTwoFactorReset)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "reset two factor", "error", err)
+		return util.ResponseError(c, err)
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "reset two factor success", map[string]interface{}{
+		"user_id": user.Id,
+	})
+}
+
+func (a *authController) GetTwoFactorPolicyAll(c *fiber.Ctx) error {
+	policies, err := a.twoFactorRepo.GetAll(c.UserContext())
+	if err != nil {
+		a.logger.Error(c.UserContext(), "get two factor policy all", "error", err)
+		return err
+	}
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "success", map[string]interface{}{
+		"policies": policies,
+	})
+}
+
+// enforce or stop enforce two factor for role
+func (a *authController) SetTwoFactorPolicy(c *fiber.Ctx) error {
+	admin := security.GetUser(c)
+
+	req := models.TwoFactorRequest{}
+	err := c.BodyParser(&req)
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "set two factor policy", "error", err)
+		value, ok := err.(*fiber.Error)
+		if ok {
+			return util.ResponseNotSuccess(c, value.Code, value.Message)
+		}
+
+		return util.ResponseNotSuccess(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	role, err := util.CheckStringData(strings.ToLower(req.Role), "role")
+	if err != nil {
+		a.logger.Warn(c.UserContext(), "set two factor policy", "error", err)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, err.Error())
+	}
+	a.logger.Debug(c.UserContext(), "role", "role", role)
+
+	ok, err := security.IsRole(c.UserContext(), role)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "set two factor policy", "error", err)
+		return util.ResponseError(c, err)
+	}
+	if !ok {
+		a.logger.Warn(c.UserContext(), "role", "error", util.ErrValueInvalid)
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, "role"+util.ErrValueInvalid.Error())
+	}
+
+	if req.Required == nil {
+		a.logger.Warn(c.UserContext(), "do not have parameter required")
+		return util.ResponseNotSuccess(c, fiber.StatusBadRequest, util.ErrRequireParameter.Error()+"required")
+	}
+	a.logger.Debug(c.UserContext(), "required", "required", *req.Required)
+
+	policy, err := a.twoFactorRepo.GetByRole(c.UserContext(), role)
+	if err != nil {
+		if !errors.Is(err, util.ErrNotFound) {
+			a.logger.Error(c.UserContext(), "set two factor policy", "error", err)
+			return util.ResponseError(c, err)
+		}
+		policy = &models.TwoFactorPolicy{
+			Id:        primitive.NewObjectID(),
+			CreatedAt: time.Now(),
+			Role:      role,
+		}
+	} else {
+		security.AuditBefore(c, repository.TwoFactorPolicyCollection, policy.Id.Hex(), policy)
+	}
+	policy.Required = *req.Required
+	policy.UpdatedAt = time.Now()
+	policy.UpdatedBy = admin.Id.Hex()
+
+	_, err = a.twoFactorRepo.Upsert(c.UserContext(), policy)
+	if err != nil {
+		a.logger.Error(c.UserContext(), "set two factor policy", "error", err)
+		return util.ResponseError(c, err)
+	}
+	security.AuditAfter(c, repository.TwoFactorPolicyCollection, policy.Id.Hex(), policy)
+
+	return util.ResponseSuccess(c, fiber.StatusOK, "set two factor policy success", map[string]interface{}{
+		"policy": policy,
+	})
+}
+
+// totp code or unused recovery code when allowRecovery, used code can not use again
+func verifyTwoFactorCode(ctx context.Context, logger logger.Logger, user *models.User, code string, allowRecovery bool) bool {
+	step, ok := security.VerifyTotp(user.TotpSecret, code, user.TotpLastStep, time.Now())
+	if ok {
+		user.TotpLastStep = step
+		return true
+	}
+
+	if !allowRecovery {
+		return false
+	}
+
+	i := security.MatchRecoveryCode(user.RecoveryCodeHashes, code)
+	if i == -1 {
+		return false
+	}
+	user.RecoveryCodeHashes = append(user.RecoveryCodeHashes[:i], user.RecoveryCodeHashes[i+1:]...)
+	logger.Debug(ctx, "use recovery code", "username", user.Username, "codes_left", len(user.RecoveryCodeHashes))
+
+	return true
+}
+
+func clearTwoFactor(user *models.User) {
+	user.TotpEnabled = false
+	user.TotpSecret = ""
+	user.TotpPendingSecret = ""
+	user.TotpLastStep = 0
+	user.RecoveryCodeHashes = []string{}
+}
@@ -0,0 +1,19 @@
+package controller
+
+import (
+	"errors"
+	"school-notification-backend/db"
+	"school-notification-backend/security"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// write in fn is apply together, change of write that is roll back is not in audit log
+func doUnitOfWork(c *fiber.Ctx, unitOfWork db.UnitOfWork, fn func(tx db.Tx) error) error {
+	err := unitOfWork.Do(c.UserContext(), fn)
+	if err != nil && !errors.Is(err, db.ErrRollbackFailed) {
+		security.AuditDiscard(c)
+	}
+
+	return err
+}
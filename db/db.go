@@ -3,10 +3,14 @@ package db
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
+	"reflect"
+	"school-notification-backend/logger"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonoptions"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -14,30 +18,42 @@ import (
 type Connection interface {
 	Close()
 	DB() *mongo.Database
+	Client() *mongo.Client
+	// replica set and sharded cluster support transaction, standalone server does not
+	SupportTransaction() bool
 }
 
 type conn struct {
-	client *mongo.Client
+	client      *mongo.Client
+	transaction bool
 }
 
-func NewConnection() Connection {
+func NewConnection(logger logger.Logger) Connection {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	log.Println("dbURL:", getURLLocal())
-	clientOptions := options.Client().ApplyURI(getURLLocal())
+	logger.Info(ctx, "connect database", "url", getURLLocal())
+	// date is decode in time.Local that main set, else it is UTC and response is not local time
+	timeCodec := bsoncodec.NewTimeCodec(bsonoptions.TimeCodec().SetUseLocalTimeZone(true))
+	registry := bson.NewRegistryBuilder().RegisterTypeDecoder(reflect.TypeOf(time.Time{}), timeCodec).Build()
+	clientOptions := options.Client().ApplyURI(getURLLocal()).SetRegistry(registry)
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		log.Fatal(err)
+		logger.Error(ctx, "connect database failed", "error", err)
+		os.Exit(1)
 	}
 
 	err = client.Ping(ctx, nil)
 	if err != nil {
-		log.Fatal(err)
+		logger.Error(ctx, "ping database failed", "error", err)
+		os.Exit(1)
 	}
 
-	return &conn{client: client}
+	transaction := supportTransaction(ctx, client)
+	logger.Info(ctx, "database connected", "transaction", transaction)
+
+	return &conn{client: client, transaction: transaction}
 }
 
 func (c *conn) Close() {
@@ -49,6 +65,28 @@ func (c *conn) DB() *mongo.Database {
 	return c.client.Database("SchoolManagement")
 }
 
+func (c *conn) Client() *mongo.Client {
+	return c.client
+}
+
+func (c *conn) SupportTransaction() bool {
+	return c.transaction
+}
+
+// member of replica set has set name, mongos answer msg isdbgrid
+func supportTransaction(ctx context.Context, client *mongo.Client) bool {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return false
+	}
+
+	return hello.SetName != "" || hello.Msg == "isdbgrid"
+}
+
 func getURLLocal() string {
 	return fmt.Sprintf("mongodb://%s:%s", os.Getenv("APP_IP"), os.Getenv("DB_PORT"))
 }
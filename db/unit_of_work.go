@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"school-notification-backend/logger"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// some write of failed unit of work can not be undo, data may be inconsistent
+var ErrRollbackFailed = errors.New("rollback failed")
+
+// UnitOfWork run multi document write as one, all of it is apply or none
+type UnitOfWork interface {
+	// fn run once, no retry, error of fn roll back every write in it
+	Do(ctx context.Context, fn func(tx Tx) error) error
+}
+
+type Tx interface {
+	// repository call in fn must use this context
+	Context() context.Context
+	// keep document before it is write, call before insert or update document in fn
+	// only use when server does not support transaction, document that is not found is delete on rollback
+	Track(collection string, id primitive.ObjectID) error
+}
+
+type unitOfWork struct {
+	conn   Connection
+	logger logger.Logger
+}
+
+// transaction when server support it, else compensation that restore tracked document
+func NewUnitOfWork(conn Connection, logger logger.Logger) UnitOfWork {
+	return &unitOfWork{conn: conn, logger: logger}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(tx Tx) error) error {
+	if u.conn.SupportTransaction() {
+		return u.doTransaction(ctx, fn)
+	}
+
+	return u.doCompensation(ctx, fn)
+}
+
+func (u *unitOfWork) doTransaction(ctx context.Context, fn func(tx Tx) error) error {
+	session, err := u.conn.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	err = session.StartTransaction()
+	if err != nil {
+		return err
+	}
+
+	sc := mongo.NewSessionContext(ctx, session)
+	err = fn(&transactionTx{ctx: sc})
+	if err != nil {
+		abortErr := session.AbortTransaction(context.Background())
+		if abortErr != nil {
+			u.logger.Error(ctx, "abort transaction failed", "error", abortErr)
+		}
+		return err
+	}
+
+	return session.CommitTransaction(sc)
+}
+
+// standalone server, write is apply at once and undo by restore tracked document
+// change of other request to the same document between write and rollback is lost
+func (u *unitOfWork) doCompensation(ctx context.Context, fn func(tx Tx) error) error {
+	tx := &compensationTx{ctx: ctx, db: u.conn.DB(), tracked: map[string]bool{}}
+	err := fn(tx)
+	if err == nil {
+		return nil
+	}
+
+	u.logger.Warn(ctx, "unit of work failed, roll back", "document_count", len(tx.undo), "error", err)
+	failed := 0
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		undoErr := tx.undo[i](context.Background())
+		if undoErr != nil {
+			failed++
+			u.logger.Error(ctx, "roll back document failed", "error", undoErr)
+		}
+	}
+	if failed != 0 {
+		return fmt.Errorf("%w: %d documents, %v", ErrRollbackFailed, failed, err)
+	}
+
+	return err
+}
+
+type transactionTx struct {
+	ctx context.Context
+}
+
+func (t *transactionTx) Context() context.Context {
+	return t.ctx
+}
+
+// transaction roll back by itself
+func (t *transactionTx) Track(collection string, id primitive.ObjectID) error {
+	return nil
+}
+
+type compensationTx struct {
+	ctx     context.Context
+	db      *mongo.Database
+	tracked map[string]bool
+	undo    []func(ctx context.Context) error
+}
+
+func (t *compensationTx) Context() context.Context {
+	return t.ctx
+}
+
+// document that is track many time keep the first state
+func (t *compensationTx) Track(collection string, id primitive.ObjectID) error {
+	key := collection + ":" + id.Hex()
+	if t.tracked[key] {
+		return nil
+	}
+
+	c := t.db.Collection(collection)
+	raw, err := c.FindOne(t.ctx, bson.M{"_id": id}).DecodeBytes()
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+	t.tracked[key] = true
+
+	if err == mongo.ErrNoDocuments {
+		t.undo = append(t.undo, func(ctx context.Context) error {
+			_, err := c.DeleteOne(ctx, bson.M{"_id": id})
+			return err
+		})
+		return nil
+	}
+
+	t.undo = append(t.undo, func(ctx context.Context) error {
+		_, err := c.ReplaceOne(ctx, bson.M{"_id": id}, raw)
+		return err
+	})
+	return nil
+}
@@ -5,7 +5,7 @@ go 1.18
 require (
 	github.com/form3tech-oss/jwt-go v3.2.5+incompatible
 	github.com/gofiber/fiber/v2 v2.43.0
-	github.com/gofiber/jwt/v2 v2.2.7
+	github.com/gofiber/websocket/v2 v2.1.1
 	github.com/joho/godotenv v1.5.1
 	go.mongodb.org/mongo-driver v1.11.4
 	golang.org/x/crypto v0.7.0
@@ -13,7 +13,7 @@ require (
 
 require (
 	github.com/andybalholm/brotli v1.0.5 // indirect
-	github.com/golang-jwt/jwt/v4 v4.0.0 // indirect
+	github.com/fasthttp/websocket v1.5.0 // indirect
 	github.com/golang/snappy v0.0.3 // indirect
 	github.com/google/uuid v1.3.0 // indirect
 	github.com/klauspost/compress v1.16.3 // indirect
@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// debug , info , warn , error
+func ParseLevel(s string) (Level, error) {
+	for k, v := range levelNames {
+		if strings.EqualFold(strings.TrimSpace(s), v) {
+			return k, nil
+		}
+	}
+
+	return LevelInfo, fmt.Errorf("log level %q is invalid", s)
+}
+
+// args is key value pairs, request id of ctx is add to every line
+type Logger interface {
+	Debug(ctx context.Context, msg string, args ...interface{})
+	Info(ctx context.Context, msg string, args ...interface{})
+	Warn(ctx context.Context, msg string, args ...interface{})
+	Error(ctx context.Context, msg string, args ...interface{})
+	With(args ...interface{}) Logger
+}
+
+type jsonLogger struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	level  Level
+	fields []interface{}
+}
+
+// one json object per line
+func New(out io.Writer, level Level) Logger {
+	return &jsonLogger{mu: &sync.Mutex{}, out: out, level: level}
+}
+
+// LOG_LEVEL in env, default is info, write to stdout
+func NewLoggerFromEnv() Logger {
+	level := LevelInfo
+	if os.Getenv("LOG_LEVEL") != "" {
+		l, err := ParseLevel(os.Getenv("LOG_LEVEL"))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		} else {
+			level = l
+		}
+	}
+
+	return New(os.Stdout, level)
+}
+
+func (j *jsonLogger) Debug(ctx context.Context, msg string, args ...interface{}) {
+	j.log(ctx, LevelDebug, msg, args)
+}
+
+func (j *jsonLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	j.log(ctx, LevelInfo, msg, args)
+}
+
+func (j *jsonLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	j.log(ctx, LevelWarn, msg, args)
+}
+
+func (j *jsonLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	j.log(ctx, LevelError, msg, args)
+}
+
+func (j *jsonLogger) With(args ...interface{}) Logger {
+	fields := make([]interface{}, 0, len(j.fields)+len(args))
+	fields = append(fields, j.fields...)
+	fields = append(fields, args...)
+
+	return &jsonLogger{mu: j.mu, out: j.out, level: j.level, fields: fields}
+}
+
+func (j *jsonLogger) log(ctx context.Context, level Level, msg string, args []interface{}) {
+	if level < j.level {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("{")
+	writeField(&b, "time", time.Now().Format(time.RFC3339Nano))
+	b.WriteString(",")
+	writeField(&b, "level", level.String())
+	b.WriteString(",")
+	writeField(&b, "msg", msg)
+	if id := RequestId(ctx); id != "" {
+		b.WriteString(",")
+		writeField(&b, "request_id", id)
+	}
+
+	for _, kv := range [][]interface{}{j.fields, args} {
+		for i := 0; i < len(kv); i += 2 {
+			key, ok := kv[i].(string)
+			if !ok {
+				key = fmt.Sprint(kv[i])
+			}
+			var value interface{} = "!missing"
+			if i+1 < len(kv) {
+				value = kv[i+1]
+			}
+
+			b.WriteString(",")
+			writeField(&b, key, redactValue(key, value))
+		}
+	}
+	b.WriteString("}\n")
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	io.WriteString(j.out, b.String())
+}
+
+func writeField(b *strings.Builder, key string, value interface{}) {
+	k, _ := json.Marshal(key)
+	b.Write(k)
+	b.WriteString(":")
+
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+
+	v, err := json.Marshal(value)
+	if err != nil {
+		v, _ = json.Marshal(fmt.Sprint(value))
+	}
+	b.Write(v)
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+const redacted = "[redacted]"
+
+// key that contain one of these is never write, also key of nested map and struct
+var redactKeys = []string{
+	"password",
+	"authorization",
+	"token",
+	"secret",
+	"cookie",
+	"key_hash",
+	"recovery_code",
+}
+
+// value that look like credential is redact whatever its key is
+var redactPrefixes = []string{
+	"Bearer ",
+	"ApiKey ",
+}
+
+func isRedactKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, v := range redactKeys {
+		if strings.Contains(key, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func redactValue(key string, value interface{}) interface{} {
+	if isRedactKey(key) {
+		return redacted
+	}
+
+	switch v := value.(type) {
+	case nil, error, bool, int, int64, float64:
+		return value
+	case string:
+		return redactString(v)
+	}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map, reflect.Struct, reflect.Slice:
+	default:
+		return value
+	}
+
+	// struct and map is walk as json so tag name is the key
+	b, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var generic interface{}
+	err = json.Unmarshal(b, &generic)
+	if err != nil {
+		return value
+	}
+
+	return redactGeneric(generic)
+}
+
+func redactGeneric(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			if isRedactKey(k) {
+				v[k] = redacted
+				continue
+			}
+			v[k] = redactGeneric(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactGeneric(item)
+		}
+	case string:
+		return redactString(v)
+	}
+
+	return value
+}
+
+func redactString(s string) string {
+	for _, v := range redactPrefixes {
+		if strings.HasPrefix(s, v) {
+			return v + redacted
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedactValue(t *testing.T) {
+	type login struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	type session struct {
+		UserId       string   `json:"user_id"`
+		RefreshToken string   `json:"refresh_token"`
+		Login        *login   `json:"login"`
+		Header       []string `json:"header"`
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		value interface{}
+		want  interface{}
+	}{
+		{"password key", "password", "p@ssw0rd", redacted},
+		{"key contain word", "new_password", "p@ssw0rd", redacted},
+		{"key with other case", "Authorization", "Bearer abc", redacted},
+		{"token key", "refresh_token", "abc", redacted},
+		{"secret key of number", "totp_secret", 123456, redacted},
+		{"recovery code key", "recovery_codes", []string{"abcd-efgh"}, redacted},
+		{"plain string", "username", "student01", "student01"},
+		{"bearer value", "header", "Bearer abc.def.ghi", "Bearer " + redacted},
+		{"api key value", "header", "ApiKey sk_123", "ApiKey " + redacted},
+		{"bearer not prefix", "msg", "use Bearer abc", "use Bearer abc"},
+		{"number", "count", 3, 3},
+		{"bool", "ok", true, true},
+		{"nil", "value", nil, nil},
+		{
+			"map",
+			"body",
+			map[string]interface{}{"username": "student01", "password": "p@ssw0rd"},
+			map[string]interface{}{"username": "student01", "password": redacted},
+		},
+		{
+			"struct by json tag",
+			"body",
+			login{Username: "student01", Password: "p@ssw0rd"},
+			map[string]interface{}{"username": "student01", "password": redacted},
+		},
+		{
+			"nested struct pointer and slice",
+			"session",
+			&session{UserId: "u1", RefreshToken: "abc", Login: &login{Username: "student01", Password: "p@ssw0rd"}, Header: []string{"Bearer abc", "text"}},
+			map[string]interface{}{
+				"user_id":       "u1",
+				"refresh_token": redacted,
+				"login":         map[string]interface{}{"username": "student01", "password": redacted},
+				"header":        []interface{}{"Bearer " + redacted, "text"},
+			},
+		},
+		{
+			"array of map",
+			"list",
+			[]map[string]string{{"cookie": "a=b"}, {"name": "x"}},
+			[]interface{}{map[string]interface{}{"cookie": redacted}, map[string]interface{}{"name": "x"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactValue(tt.key, tt.value)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// value of caller is not change by redact
+func TestRedactValueKeepOriginal(t *testing.T) {
+	m := map[string]interface{}{"password": "p@ssw0rd"}
+	redactValue("body", m)
+	if m["password"] != "p@ssw0rd" {
+		t.Errorf("original map is change to %v", m["password"])
+	}
+}
+
+func TestRedactValueError(t *testing.T) {
+	err := errors.New("not found")
+	if got := redactValue("error", err); got != err {
+		t.Errorf("got %v, want %v", got, err)
+	}
+}
+
+func TestLoggerRedact(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, LevelDebug).With("authorization", "Bearer abc")
+	l.Info(context.Background(), "sign in", "username", "student01", "password", "p@ssw0rd", "body", map[string]string{"token": "abc"})
+
+	line := out.String()
+	for _, secret := range []string{"p@ssw0rd", "Bearer abc", `"abc"`} {
+		if strings.Contains(line, secret) {
+			t.Errorf("log line %s contain %s", line, secret)
+		}
+	}
+
+	fields := map[string]interface{}{}
+	err := json.Unmarshal([]byte(line), &fields)
+	if err != nil {
+		t.Fatalf("log line is not json: %v", err)
+	}
+	if fields["username"] != "student01" {
+		t.Errorf("got username %v, want %v", fields["username"], "student01")
+	}
+	if fields["password"] != redacted || fields["authorization"] != redacted {
+		t.Errorf("got password %v and authorization %v, want %v", fields["password"], fields["authorization"], redacted)
+	}
+}
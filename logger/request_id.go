@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const HeaderRequestId = "X-Request-ID"
+
+type requestIdKey struct{}
+
+func WithRequestId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, id)
+}
+
+// empty when ctx is not from request
+func RequestId(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
+
+// first middleware, id from client header is keep so log of caller and this service can join
+// id is in user context of request and in response header, then write access log
+func RequestLogger(l Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		id := c.Get(HeaderRequestId)
+		if !validRequestId(id) {
+			id = newRequestId()
+		}
+		c.SetUserContext(WithRequestId(c.UserContext(), id))
+		c.Set(HeaderRequestId, id)
+
+		err := c.Next()
+		if err != nil {
+			// let app error handler write the response so status is correct in log
+			if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+				c.Status(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		level := l.Info
+		if status >= fiber.StatusInternalServerError {
+			level = l.Error
+		}
+		level(c.UserContext(), "request",
+			"method", c.Method(),
+			"path", c.Path(),
+			"status", status,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"ip", c.IP(),
+		)
+
+		return nil
+	}
+}
+
+func validRequestId(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestId() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return hex.EncodeToString([]byte(time.Now().Format(time.RFC3339Nano)))
+	}
+
+	return hex.EncodeToString(b)
+}
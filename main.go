@@ -1,24 +1,33 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"os"
 	"school-notification-backend/controller"
 	"school-notification-backend/db"
+	"school-notification-backend/logger"
+	"school-notification-backend/migration"
 	"school-notification-backend/models"
+	"school-notification-backend/notifier"
+	"school-notification-backend/realtime"
 	"school-notification-backend/repository"
 	"school-notification-backend/routes"
 	"school-notification-backend/security"
+	"school-notification-backend/util"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// wait between migration try while other instance hold the lock
+const migrateRetryInterval = 5 * time.Second
+
 func init() {
 	// load env
 	err := godotenv.Load(".env")
@@ -26,6 +35,12 @@ func init() {
 		log.Panicf("Some error occured. Err: %s", err)
 	}
 
+	// load jwt signing key
+	err = security.LoadKeysFromEnv()
+	if err != nil {
+		log.Panicf("Load jwt key error. Err: %s", err)
+	}
+
 	// set local location time
 	ict, err := time.LoadLocation("Asia/Bangkok")
 	if err != nil {
@@ -36,87 +51,180 @@ func init() {
 }
 
 func main() {
-	conn := db.NewConnection()
+	appLogger := logger.NewLoggerFromEnv()
+	security.SetLogger(appLogger)
+
+	conn := db.NewConnection(appLogger)
 	defer conn.Close()
+	unitOfWork := db.NewUnitOfWork(conn, appLogger)
+
+	// migration
+	migrator := migration.NewMigrator(conn, appLogger)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(appLogger, migrator, os.Args[2:])
+		return
+	}
+	if os.Getenv("MIGRATE_ON_STARTUP") != "false" {
+		ctx := context.Background()
+		err := migrateOnStartup(ctx, appLogger, migrator)
+		if err != nil {
+			appLogger.Error(ctx, "migrate failed", "error", err)
+			os.Exit(1)
+		}
+	}
 
 	// school data
-	schoolDataRepository := repository.NewSchoolDataRepository(conn)
-	userRepository := repository.NewUsersRepository(conn)
+	schoolDataRepository := repository.NewSchoolDataRepository(conn, appLogger)
+	userRepository := repository.NewUsersRepository(conn, appLogger)
+
+	// role
+	roleRepository := repository.NewRoleRepository(conn, appLogger)
+	security.SetUserRepository(userRepository)
+	security.SetRoleRepository(roleRepository)
+
+	// audit log
+	auditLogRepository := repository.NewAuditLogRepository(conn, appLogger)
+	security.SetAuditLogRepository(auditLogRepository)
+	auditLogController := controller.NewAuditLogController(auditLogRepository, appLogger)
+	auditLogRoutes := routes.NewAuditLogRoute(auditLogController)
+	roleController := controller.NewRoleController(roleRepository, userRepository, appLogger)
+	roleRoutes := routes.NewRoleRoute(roleController)
+
+	// realtime
+	realtimeBroker := realtime.NewMemoryBroker(64, appLogger)
+	defer realtimeBroker.Close()
+	realtimeHub := realtime.NewHub(realtimeBroker, appLogger)
+	realtimeController := controller.NewRealtimeController(realtimeHub, userRepository, appLogger)
+	realtimeRoutes := routes.NewRealtimeRoute(realtimeController)
+
+	// notification
+	notificationRepository := repository.NewNotificationRepository(conn, appLogger)
+	notificationController := controller.NewNotificationController(notificationRepository, appLogger)
+	notificationRoutes := routes.NewNotificationRoute(notificationController)
+	alertNotifier := notifier.NewNotifierFromEnv(appLogger)
 
 	// information
-	informationRepository := repository.NewInformationRepository(conn)
-	informationController := controller.NewInformationController(informationRepository, userRepository)
+	informationRepository := repository.NewInformationRepository(conn, appLogger)
+	informationController := controller.NewInformationController(informationRepository, userRepository, notificationRepository, realtimeHub, appLogger)
 	informationRoutes := routes.NewInformationRoutes(informationController)
 
 	// class
-	classRepository := repository.NewClassRepository(conn)
+	classRepository := repository.NewClassRepository(conn, appLogger)
 
 	// location
-	locationRepository := repository.NewLocationRepository(conn)
-	locationController := controller.NewLocationController(locationRepository, userRepository)
+	locationRepository := repository.NewLocationRepository(conn, appLogger)
+	locationController := controller.NewLocationController(locationRepository, appLogger)
 	locationRoutes := routes.NewLocationRoute(locationController)
 
 	// profile
-	profileRepository := repository.NewProfileRepository(conn)
+	profileRepository := repository.NewProfileRepository(conn, appLogger)
 
 	// subject
-	subjectRepository := repository.NewSubjectRepository(conn)
-	subjectController := controller.NewSubjectController(subjectRepository, schoolDataRepository, profileRepository, userRepository)
+	subjectRepository := repository.NewSubjectRepository(conn, appLogger)
+	subjectController := controller.NewSubjectController(subjectRepository, schoolDataRepository, profileRepository, appLogger)
 	subjectRoutes := routes.NewSubjectRoute(subjectController)
 
 	// course
-	courseRepository := repository.NewCoursesRepository(conn)
-	courseSummaryRepository := repository.NewCourseSummaryRepository(conn)
-	courseController := controller.NewCourseController(courseRepository, subjectRepository, schoolDataRepository, locationRepository, classRepository, profileRepository, courseSummaryRepository, userRepository)
+	courseRepository := repository.NewCoursesRepository(conn, appLogger)
+	courseSummaryRepository := repository.NewCourseSummaryRepository(conn, appLogger)
+	courseController := controller.NewCourseController(courseRepository, subjectRepository, schoolDataRepository, locationRepository, classRepository, profileRepository, courseSummaryRepository, unitOfWork, appLogger)
 	courseRoutes := routes.NewCourseRoute(courseController)
 
 	// score
-	scoreRepository := repository.NewScoreRepository(conn)
-	scoreController := controller.NewScoreController(scoreRepository, courseRepository, userRepository)
+	scoreRepository := repository.NewScoreRepository(conn, appLogger)
+	scoreController := controller.NewScoreController(scoreRepository, courseRepository, profileRepository, classRepository, notificationRepository, realtimeHub, appLogger)
 	scoreRoutes := routes.NewScoreRoute(scoreController)
 
 	// check name
-	checkNameRepository := repository.NewCheckNameRepository(conn)
-	checkNameController := controller.NewCheckNameController(checkNameRepository, courseRepository, userRepository)
+	checkNameRepository := repository.NewCheckNameRepository(conn, appLogger)
+	leaveRequestRepository := repository.NewLeaveRequestRepository(conn, appLogger)
+	checkNameController := controller.NewCheckNameController(checkNameRepository, courseRepository, schoolDataRepository, profileRepository, classRepository, leaveRequestRepository, notificationRepository, realtimeHub, alertNotifier, appLogger)
 	checkNameRoutes := routes.NewCheckNameRoute(checkNameController)
+	if interval, err := time.ParseDuration(os.Getenv("CHECK_NAME_SCHEDULER_INTERVAL")); err == nil && interval > 0 {
+		timeLate, err := time.ParseDuration(os.Getenv("CHECK_NAME_TIME_LATE"))
+		if err != nil {
+			timeLate = 15 * time.Minute
+		}
+		endAfter, err := time.ParseDuration(os.Getenv("CHECK_NAME_END_AFTER"))
+		if err != nil {
+			endAfter = 2 * time.Hour
+		}
+		go checkNameController.RunCheckNameScheduler(interval, timeLate, endAfter)
+	}
 
 	// course summary
-	courseSummaryController := controller.NewCourseSummaryController(courseSummaryRepository, courseRepository, scoreRepository, checkNameRepository, userRepository, profileRepository)
+	courseSummaryController := controller.NewCourseSummaryController(courseSummaryRepository, courseRepository, scoreRepository, checkNameRepository, profileRepository, classRepository, notificationRepository, realtimeHub, appLogger)
 	courseSummaryRoutes := routes.NewCourseSummaryRoute(courseSummaryController)
 
-	schoolDataController := controller.NewSchoolDataController(schoolDataRepository, courseRepository, courseSummaryRepository, profileRepository, classRepository, locationRepository, userRepository)
+	// attendance
+	chronicAbsenceNoticeRepository := repository.NewChronicAbsenceNoticeRepository(conn, appLogger)
+	attendanceController := controller.NewAttendanceController(schoolDataRepository, courseRepository, checkNameRepository, profileRepository, classRepository, notificationRepository, chronicAbsenceNoticeRepository, realtimeHub, appLogger)
+	attendanceRoutes := routes.NewAttendanceRoute(attendanceController)
+	if interval, err := time.ParseDuration(os.Getenv("CHRONIC_ABSENCE_JOB_INTERVAL")); err == nil && interval > 0 {
+		go attendanceController.RunChronicAbsenceJob(interval)
+	}
+
+	// leave request
+	leaveRequestController := controller.NewLeaveRequestController(leaveRequestRepository, checkNameRepository, courseRepository, classRepository, profileRepository, notificationRepository, realtimeHub, appLogger)
+	leaveRequestRoutes := routes.NewLeaveRequestRoute(leaveRequestController)
+
+	// parent
+	parentController := controller.NewParentController(profileRepository, courseRepository, checkNameRepository, scoreRepository, courseSummaryRepository, appLogger)
+	parentRoutes := routes.NewParentRoute(parentController)
+
+	schoolDataController := controller.NewSchoolDataController(schoolDataRepository, courseRepository, courseSummaryRepository, profileRepository, classRepository, locationRepository, unitOfWork, appLogger)
 	schoolDataRoutes := routes.NewSchoolDataRoute(schoolDataController)
 
 	// auth
-	authController := controller.NewAuthController(userRepository, profileRepository)
+	sessionRepository := repository.NewSessionRepository(conn, appLogger)
+	security.SetSessionRepository(sessionRepository)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(conn, appLogger)
+	loginLockRepository := repository.NewLoginLockRepository(conn, appLogger)
+	twoFactorPolicyRepository := repository.NewTwoFactorPolicyRepository(conn, appLogger)
+	security.SetTwoFactorPolicyRepository(twoFactorPolicyRepository)
+	authController := controller.NewAuthController(userRepository, profileRepository, sessionRepository, loginAttemptRepository, loginLockRepository, twoFactorPolicyRepository, appLogger)
 	authRoutes := routes.NewAuthRoutes(authController)
+	loginAttemptController := controller.NewLoginAttemptController(loginAttemptRepository, loginLockRepository, appLogger)
+	loginAttemptRoutes := routes.NewLoginAttemptRoute(loginAttemptController)
 
 	// conversation
-	conversationRepository := repository.NewConversationRepository(conn)
-	conversationController := controller.NewConversationController(conversationRepository, profileRepository, userRepository)
+	conversationRepository := repository.NewConversationRepository(conn, appLogger)
+	conversationController := controller.NewConversationController(conversationRepository, profileRepository, appLogger)
 	conversationRoutes := routes.NewConversationRoute(conversationController)
 
 	// message
-	messageRepository := repository.NewMessageRepository(conn)
-	messageController := controller.NewMessageController(messageRepository, conversationRepository, userRepository)
+	messageRepository := repository.NewMessageRepository(conn, appLogger)
+	messageController := controller.NewMessageController(messageRepository, conversationRepository, realtimeHub, appLogger)
 	messageRoutes := routes.NewMessageRoute(messageController)
 
-	faceDetectionRepository := repository.NewFaceDetectionRepository(conn)
-	faceDetectionController := controller.NewFaceDetectionController(faceDetectionRepository, classRepository, userRepository)
+	faceDetectionRepository := repository.NewFaceDetectionRepository(conn, appLogger)
+	faceDetectionController := controller.NewFaceDetectionController(faceDetectionRepository, classRepository, appLogger)
 	faceDetectionRoutes := routes.NewFaceDetectionRoute(faceDetectionController)
 
-	profileController := controller.NewProfileController(profileRepository, classRepository, schoolDataRepository, userRepository, faceDetectionRepository)
+	profileController := controller.NewProfileController(profileRepository, classRepository, schoolDataRepository, userRepository, faceDetectionRepository, appLogger)
 	profileRoutes := routes.NewProfileRoute(profileController)
 
-	classController := controller.NewClassController(classRepository, schoolDataRepository, profileRepository, userRepository, faceDetectionRepository)
+	classController := controller.NewClassController(classRepository, schoolDataRepository, profileRepository, faceDetectionRepository, appLogger)
 	classRoutes := routes.NewClassRoute(classController)
 
+	// api key
+	apiKeyRepository := repository.NewApiKeyRepository(conn, appLogger)
+	security.SetApiKeyRepository(apiKeyRepository)
+	apiKeyController := controller.NewApiKeyController(apiKeyRepository, classRepository, courseRepository, appLogger)
+	apiKeyRoutes := routes.NewApiKeyRoute(apiKeyController)
+
 	staticRoutes := routes.NewStaticRoutes()
 
-	initFirstData(profileRepository, userRepository)
-	route := fiber.New()
+	initFirstData(appLogger, profileRepository, userRepository)
+	route := fiber.New(fiber.Config{ErrorHandler: util.ErrorHandler})
+
+	requestTimeout, err := time.ParseDuration(os.Getenv("REQUEST_TIMEOUT"))
+	if err != nil || requestTimeout <= 0 {
+		requestTimeout = 10 * time.Second
+	}
 
-	route.Use(logger.New())
+	route.Use(logger.RequestLogger(appLogger))
+	route.Use(util.RequestTimeout(requestTimeout))
 	route.Use(cors.New())
 
 	schoolDataRoutes.Install(route)
@@ -133,26 +241,85 @@ func main() {
 	conversationRoutes.Install(route)
 	messageRoutes.Install(route)
 	faceDetectionRoutes.Install(route)
+	notificationRoutes.Install(route)
+	realtimeRoutes.Install(route)
+	parentRoutes.Install(route)
+	attendanceRoutes.Install(route)
+	leaveRequestRoutes.Install(route)
+	roleRoutes.Install(route)
+	apiKeyRoutes.Install(route)
+	loginAttemptRoutes.Install(route)
+	auditLogRoutes.Install(route)
 	staticRoutes.Install(route)
 
 	route.Listen(":" + os.Getenv("APP_PORT"))
 }
 
-func initFirstData(profileRepo repository.ProfileRepository, userRepo repository.UsersRepository) {
-	err := profileRepo.GetProfileByFilterForCheckExists(bson.M{
+// instance wait while other instance run migration, it does not serve before schema is migrate
+// lock is expire after migration.LockTimeout, so wait longer than that is failed
+func migrateOnStartup(ctx context.Context, appLogger logger.Logger, migrator migration.Migrator) error {
+	deadline := time.Now().Add(migration.LockTimeout + time.Minute)
+	for {
+		err := migrator.Up(ctx)
+		if !errors.Is(err, migration.ErrLocked) {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+
+		appLogger.Warn(ctx, "migration is run by other instance, wait", "retry_after", migrateRetryInterval.String())
+		time.Sleep(migrateRetryInterval)
+	}
+}
+
+// go run . migrate [up|status]
+func runMigrate(appLogger logger.Logger, migrator migration.Migrator, args []string) {
+	ctx := context.Background()
+	cmd := "up"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	switch cmd {
+	case "up":
+		err := migrator.Up(ctx)
+		if err != nil {
+			appLogger.Error(ctx, "migrate failed", "error", err)
+			os.Exit(1)
+		}
+		appLogger.Info(ctx, "migrate success")
+	case "status":
+		list, err := migrator.Status(ctx)
+		if err != nil {
+			appLogger.Error(ctx, "migration status failed", "error", err)
+			os.Exit(1)
+		}
+		for _, v := range list {
+			appLogger.Info(ctx, "migration", "version", v.Version, "name", v.Name, "applied_at", v.AppliedAt)
+		}
+	default:
+		appLogger.Error(ctx, "migrate command is invalid", "command", cmd)
+		os.Exit(1)
+	}
+}
+
+func initFirstData(appLogger logger.Logger, profileRepo repository.ProfileRepository, userRepo repository.UsersRepository) {
+	ctx := context.Background()
+	err := profileRepo.GetProfileByFilterForCheckExists(ctx, bson.M{
 		"profile_id": "admin1",
 		"role":       "admin",
 	})
-	if err != nil && err.Error() == "mongo: no documents in result" {
+	if errors.Is(err, util.ErrNotFound) {
 		profileAdmin := models.ProfileAdmin{
 			Id:        primitive.NewObjectID(),
-			CreatedAt: time.Now().Format(time.RFC3339),
-			UpdatedAt: time.Now().Format(time.RFC3339),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
 			ProfileId: "admin1",
 			Name:      "admin1",
 			Role:      "admin",
 		}
-		_, err = profileRepo.Insert(profileAdmin)
+		_, err = profileRepo.Insert(ctx, profileAdmin)
 		if err != nil {
 			panic(err)
 		}
@@ -166,19 +333,38 @@ func initFirstData(profileRepo repository.ProfileRepository, userRepo repository
 		id := profileAdmin.Id
 		user := models.User{
 			Id:        primitive.NewObjectID(),
-			CreatedAt: time.Now().Format(time.RFC3339),
+			CreatedAt: time.Now(),
 			Username:  "admin1",
 			Password:  password,
 			ProfileId: "admin1",
 			Role:      "admin",
 			UserId:    id.Hex(),
+
+			MustChangePassword: true,
 		}
 
-		_, err = userRepo.InsertUser(&user)
+		_, err = userRepo.InsertUser(ctx, &user)
 		if err != nil {
 			panic(err)
 		}
 	} else if err != nil {
 		panic(err)
 	}
+
+	// seeded admin that still use default password must change it
+	user, err := userRepo.GetByUsername(ctx, "admin1")
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return
+		}
+		panic(err)
+	}
+	if !user.MustChangePassword && security.VerifyPassword(user.Password, "admin1") == nil {
+		appLogger.Warn(context.Background(), "admin1 use default password, password change is required")
+		user.MustChangePassword = true
+		_, err = userRepo.Update(ctx, user)
+		if err != nil {
+			panic(err)
+		}
+	}
 }
@@ -0,0 +1,111 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"school-notification-backend/logger"
+	"school-notification-backend/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func index(keys bson.D) mongo.IndexModel {
+	return mongo.IndexModel{Keys: keys}
+}
+
+// unique index replace check then insert of controller, insert that break it return util.ErrConflict
+func uniqueIndex(keys bson.D) mongo.IndexModel {
+	return mongo.IndexModel{Keys: keys, Options: options.Index().SetUnique(true)}
+}
+
+// index of filter that repository is call with, index that already exists is skip by server
+// unique index is failed when collection has duplicate document, remove it and run again
+func createIndexes(ctx context.Context, db *mongo.Database, logger logger.Logger) error {
+	indexes := map[string][]mongo.IndexModel{
+		repository.UsersCollection: {
+			uniqueIndex(bson.D{{Key: "username", Value: 1}}),
+		},
+		repository.ProfileCollection: {
+			uniqueIndex(bson.D{{Key: "profile_id", Value: 1}, {Key: "role", Value: 1}}),
+		},
+		repository.CourseCollection: {
+			uniqueIndex(bson.D{{Key: "subject_id", Value: 1}, {Key: "class_id", Value: 1}}),
+			index(bson.D{{Key: "instructor_id", Value: 1}, {Key: "status", Value: 1}}),
+			index(bson.D{{Key: "status", Value: 1}, {Key: "student_id_list", Value: 1}}),
+			index(bson.D{{Key: "year", Value: 1}, {Key: "term", Value: 1}}),
+		},
+		repository.CheckNameCollection: {
+			uniqueIndex(bson.D{{Key: "course_id", Value: 1}, {Key: "date", Value: 1}, {Key: "time_start", Value: 1}}),
+			index(bson.D{{Key: "status", Value: 1}}),
+		},
+		repository.ScoreCollection: {
+			uniqueIndex(bson.D{{Key: "course_id", Value: 1}, {Key: "name", Value: 1}}),
+			index(bson.D{{Key: "course_id", Value: 1}, {Key: "type", Value: 1}}),
+		},
+		repository.SubjectCollection: {
+			uniqueIndex(bson.D{{Key: "subject_id", Value: 1}}),
+			index(bson.D{{Key: "category", Value: 1}}),
+		},
+		repository.LocationCollection: {
+			uniqueIndex(bson.D{{Key: "location_id", Value: 1}}),
+			uniqueIndex(bson.D{{Key: "building_name", Value: 1}, {Key: "floor", Value: 1}, {Key: "room", Value: 1}}),
+		},
+		repository.FaceDetectionCollection: {
+			uniqueIndex(bson.D{{Key: "class_id", Value: 1}}),
+		},
+		repository.RoleCollection: {
+			uniqueIndex(bson.D{{Key: "name", Value: 1}}),
+		},
+		repository.ClassCollection: {
+			index(bson.D{{Key: "class_year", Value: 1}, {Key: "class_room", Value: 1}, {Key: "status", Value: 1}}),
+			index(bson.D{{Key: "advisor_id", Value: 1}}),
+		},
+		repository.CourseSummaryCollection: {
+			index(bson.D{{Key: "course_id", Value: 1}}),
+		},
+		repository.SchoolDataCollection: {
+			index(bson.D{{Key: "type", Value: 1}, {Key: "year", Value: 1}, {Key: "term", Value: 1}}),
+			index(bson.D{{Key: "type", Value: 1}, {Key: "date", Value: 1}}),
+		},
+		repository.NotificationCollection: {
+			index(bson.D{{Key: "profile_id", Value: 1}, {Key: "role", Value: 1}, {Key: "created_at", Value: -1}}),
+		},
+		repository.LeaveRequestCollection: {
+			index(bson.D{{Key: "student_id", Value: 1}, {Key: "created_at", Value: -1}}),
+		},
+		repository.MessageCollection: {
+			index(bson.D{{Key: "conversation_id", Value: 1}}),
+		},
+		repository.ConversationCollection: {
+			index(bson.D{{Key: "members", Value: 1}}),
+		},
+		repository.SessionCollection: {
+			index(bson.D{{Key: "user_id", Value: 1}, {Key: "revoked", Value: 1}}),
+		},
+		repository.LoginLockCollection: {
+			uniqueIndex(bson.D{{Key: "type", Value: 1}, {Key: "value", Value: 1}}),
+			index(bson.D{{Key: "locked_until", Value: 1}}),
+		},
+		repository.LoginAttemptCollection: {
+			index(bson.D{{Key: "created_at", Value: -1}}),
+		},
+		repository.ChronicAbsenceNoticeCollection: {
+			uniqueIndex(bson.D{{Key: "student_id", Value: 1}, {Key: "last_absent_at", Value: 1}, {Key: "last_late_at", Value: 1}}),
+		},
+		repository.AuditLogCollection: {
+			index(bson.D{{Key: "created_at", Value: -1}}),
+		},
+	}
+
+	for collection, models := range indexes {
+		_, err := db.Collection(collection).Indexes().CreateMany(ctx, models)
+		if err != nil {
+			return fmt.Errorf("%s: %w", collection, err)
+		}
+		logger.Debug(ctx, "create indexes", "target_collection", collection, "index_count", len(models))
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"school-notification-backend/db"
+	"school-notification-backend/logger"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const MigrationCollection = "migrations"
+const MigrationLockCollection = "migration_lock"
+
+// lock of instance that crash is release after this time
+const LockTimeout = 10 * time.Minute
+
+// other instance is running migration
+var ErrLocked = errors.New("migration is locked by other instance")
+
+// Migration change index or data of database, it is apply once and record by its version
+type Migration struct {
+	Version int
+	Name    string
+	// run again from the start when it failed, so it must be safe to run twice
+	// logger is for document that is skip, migration error is return
+	Up func(ctx context.Context, db *mongo.Database, logger logger.Logger) error
+}
+
+type Status struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	// nil when migration is not applied
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+type record struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+type Migrator interface {
+	// apply migration that is not applied in order of version, stop at the first failed
+	Up(ctx context.Context) error
+	Status(ctx context.Context) ([]*Status, error)
+}
+
+type migrator struct {
+	db         *mongo.Database
+	migrations []Migration
+	logger     logger.Logger
+}
+
+// migrator of every migration in this package
+func NewMigrator(conn db.Connection, logger logger.Logger) Migrator {
+	list := make([]Migration, len(migrations))
+	copy(list, migrations)
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+
+	return &migrator{db: conn.DB(), migrations: list, logger: logger.With("collection", MigrationCollection)}
+}
+
+func (m *migrator) Up(ctx context.Context) error {
+	err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer m.unlock()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range m.migrations {
+		if _, ok := applied[v.Version]; ok {
+			continue
+		}
+
+		m.logger.Info(ctx, "apply migration", "version", v.Version, "name", v.Name)
+		err := v.Up(ctx, m.db, m.logger)
+		if err != nil {
+			return fmt.Errorf("migration %d %s: %w", v.Version, v.Name, err)
+		}
+
+		_, err = m.db.Collection(MigrationCollection).InsertOne(ctx, &record{
+			Version:   v.Version,
+			Name:      v.Name,
+			AppliedAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d %s: %w", v.Version, v.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (m *migrator) Status(ctx context.Context) ([]*Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []*Status{}
+	for _, v := range m.migrations {
+		status := &Status{Version: v.Version, Name: v.Name}
+		if r, ok := applied[v.Version]; ok {
+			status.AppliedAt = &r.AppliedAt
+		}
+		list = append(list, status)
+	}
+
+	return list, nil
+}
+
+func (m *migrator) applied(ctx context.Context) (map[int]*record, error) {
+	cur, err := m.db.Collection(MigrationCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	applied := map[int]*record{}
+	for cur.Next(ctx) {
+		var r *record
+		err := cur.Decode(&r)
+		if err != nil {
+			m.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
+			return nil, err
+		}
+
+		applied[r.Version] = r
+	}
+
+	return applied, cur.Err()
+}
+
+// only one instance run migration, lock that is not expire make upsert insert the same _id
+func (m *migrator) lock(ctx context.Context) error {
+	now := time.Now()
+	_, err := m.db.Collection(MigrationLockCollection).UpdateOne(ctx,
+		bson.M{"_id": "migration", "locked_until": bson.M{"$lt": now}},
+		bson.M{"$set": bson.M{"locked_until": now.Add(LockTimeout)}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrLocked
+	}
+
+	return err
+}
+
+func (m *migrator) unlock() {
+	ctx := context.Background()
+	_, err := m.db.Collection(MigrationLockCollection).DeleteOne(ctx, bson.M{"_id": "migration"})
+	if err != nil {
+		m.logger.Error(ctx, "unlock migration", "error", err)
+	}
+}
@@ -0,0 +1,7 @@
+package migration
+
+// every migration, version is never reuse or change after it is release
+var migrations = []Migration{
+	{Version: 1, Name: "create indexes", Up: createIndexes},
+	{Version: 2, Name: "convert timestamps to date", Up: convertTimestamps},
+}
@@ -0,0 +1,189 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"school-notification-backend/logger"
+	"school-notification-backend/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// value of field that is RFC3339 string as date, empty string as null, other value is keep
+// string that can not be parse is keep and logged after convert
+func toDate(field string) bson.M {
+	return bson.M{"$cond": bson.A{
+		bson.M{"$ne": bson.A{bson.M{"$type": field}, "string"}},
+		field,
+		bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{field, ""}},
+			nil,
+			bson.M{"$convert": bson.M{"input": field, "to": "date", "onError": field, "onNull": nil}},
+		}},
+	}}
+}
+
+// time of day "15:04:05" of check name at date "2006-01-02" as date in time.Local, empty time is not checked
+func toDateTime(date string, field string) bson.M {
+	return bson.M{"$cond": bson.A{
+		bson.M{"$ne": bson.A{bson.M{"$type": field}, "string"}},
+		field,
+		bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{field, ""}},
+			nil,
+			bson.M{"$dateFromString": bson.M{
+				"dateString": bson.M{"$concat": bson.A{date, " ", field}},
+				"format":     "%Y-%m-%d %H:%M:%S",
+				"timezone":   time.Local.String(),
+				"onError":    field,
+				"onNull":     nil,
+			}},
+		}},
+	}}
+}
+
+// set fields of every element in array, field that is not array is keep
+func mapArray(field string, as string, set bson.M) bson.M {
+	return bson.M{"$cond": bson.A{
+		bson.M{"$isArray": field},
+		bson.M{"$map": bson.M{
+			"input": field,
+			"as":    as,
+			"in":    bson.M{"$mergeObjects": bson.A{"$$" + as, set}},
+		}},
+		field,
+	}}
+}
+
+// created_at, updated_at, other time field and date of check name was RFC3339 or "2006-01-02" string, sort and range filter need date
+// only string value is convert so document that is already convert is skip
+func convertTimestamps(ctx context.Context, db *mongo.Database, logger logger.Logger) error {
+	// time field of collection other than created_at and updated_at
+	fields := map[string][]string{
+		repository.ApiKeyCollection:       {"expires_at", "last_used_at", "revoked_at"},
+		repository.LeaveRequestCollection: {"approved_at"},
+		repository.LoginLockCollection:    {"last_failed_at", "locked_until"},
+		repository.NotificationCollection: {"read_at"},
+		repository.SessionCollection:      {"expires_at", "revoked_at"},
+		repository.UsersCollection:        {"password_changed_at"},
+	}
+
+	collections := []string{
+		repository.ApiKeyCollection,
+		repository.AuditLogCollection,
+		repository.CheckNameCollection,
+		repository.ClassCollection,
+		repository.ConversationCollection,
+		repository.CourseCollection,
+		repository.CourseSummaryCollection,
+		repository.FaceDetectionCollection,
+		repository.InformationCollection,
+		repository.LeaveRequestCollection,
+		repository.LocationCollection,
+		repository.LoginAttemptCollection,
+		repository.LoginLockCollection,
+		repository.MessageCollection,
+		repository.NotificationCollection,
+		repository.ProfileCollection,
+		repository.RoleCollection,
+		repository.SchoolDataCollection,
+		repository.ScoreCollection,
+		repository.SessionCollection,
+		repository.SubjectCollection,
+		repository.TwoFactorPolicyCollection,
+		repository.UsersCollection,
+	}
+	for _, collection := range collections {
+		names := append([]string{"created_at", "updated_at"}, fields[collection]...)
+		set := bson.M{}
+		for _, name := range names {
+			set[name] = toDate("$" + name)
+		}
+
+		_, err := db.Collection(collection).UpdateMany(ctx, stringFilter(names), mongo.Pipeline{{{Key: "$set", Value: set}}})
+		if err != nil {
+			return fmt.Errorf("%s: %w", collection, err)
+		}
+
+		err = logNotConverted(ctx, db, logger, collection, names)
+		if err != nil {
+			return err
+		}
+	}
+
+	// time of check name data is join with date, so it is convert before date
+	_, err := db.Collection(repository.CheckNameCollection).UpdateMany(ctx,
+		bson.M{"date": bson.M{"$type": "string"}},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
+				"check_name_data": mapArray("$check_name_data", "d", bson.M{
+					"updated_at": toDate("$$d.updated_at"),
+					"time":       toDateTime("$date", "$$d.time"),
+					"history": mapArray("$$d.history", "h", bson.M{
+						"updated_at": toDate("$$h.updated_at"),
+						"edited_at":  toDate("$$h.edited_at"),
+						"time":       toDateTime("$date", "$$h.time"),
+					}),
+				}),
+			}}},
+			{{Key: "$set", Value: bson.M{
+				"date": bson.M{"$dateFromString": bson.M{
+					"dateString": "$date",
+					"format":     "%Y-%m-%d",
+					"timezone":   time.Local.String(),
+					"onError":    "$date",
+				}},
+				"time_start": toDate("$time_start"),
+				"time_late":  toDate("$time_late"),
+				"time_close": toDate("$time_close"),
+			}}},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", repository.CheckNameCollection, err)
+	}
+	err = logNotConverted(ctx, db, logger, repository.CheckNameCollection, []string{"date", "time_start", "time_late", "time_close", "check_name_data.updated_at", "check_name_data.time", "check_name_data.history.updated_at", "check_name_data.history.edited_at", "check_name_data.history.time"})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection(repository.ScoreCollection).UpdateMany(ctx,
+		bson.M{"score_information.updated_at": bson.M{"$type": "string"}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"score_information": mapArray("$score_information", "s", bson.M{"updated_at": toDate("$$s.updated_at")}),
+		}}}},
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", repository.ScoreCollection, err)
+	}
+
+	return logNotConverted(ctx, db, logger, repository.ScoreCollection, []string{"score_information.updated_at"})
+}
+
+// document that any of fields is string, path of array element match when one element is string
+func stringFilter(fields []string) bson.M {
+	filter := bson.A{}
+	for _, name := range fields {
+		filter = append(filter, bson.M{name: bson.M{"$type": "string"}})
+	}
+
+	return bson.M{"$or": filter}
+}
+
+// string that can not be parse is skip, migration does not fail and it is fixed by hand
+func logNotConverted(ctx context.Context, db *mongo.Database, logger logger.Logger, collection string, fields []string) error {
+	cur, err := db.Collection(collection).Find(ctx, stringFilter(fields), options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return fmt.Errorf("%s: %w", collection, err)
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		logger.Warn(ctx, "timestamp is not converted", "target_collection", collection, "id", cur.Current.Lookup("_id").String())
+	}
+
+	return cur.Err()
+}
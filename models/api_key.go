@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// secret of key is keep as sha256 hash, class id and course id restrict the key when set
+type ApiKey struct {
+	Id         primitive.ObjectID `json:"id" bson:"_id"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at" bson:"updated_at"`
+	Name       string             `json:"name" bson:"name"`
+	KeyHash    string             `json:"-" bson:"key_hash"`
+	Scopes     []string           `json:"scopes" bson:"scopes"`
+	ClassId    *string            `json:"class_id" bson:"class_id"`
+	CourseId   *string            `json:"course_id" bson:"course_id"`
+	ExpiresAt  *time.Time         `json:"expires_at" bson:"expires_at"`
+	LastUsedAt *time.Time         `json:"last_used_at" bson:"last_used_at"`
+	CreatedBy  string             `json:"created_by" bson:"created_by"`
+	Revoked    bool               `json:"revoked" bson:"revoked"`
+	RevokedAt  *time.Time         `json:"revoked_at" bson:"revoked_at"`
+	RevokedBy  *string            `json:"revoked_by" bson:"revoked_by"`
+}
+
+type ApiKeyRequest struct {
+	Id        string   `json:"id"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	ClassId   *string  `json:"class_id"`
+	CourseId  *string  `json:"course_id"`
+	ExpiresAt *string  `json:"expires_at"`
+}
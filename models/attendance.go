@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// absence_rate , late_rate , consecutive_absence
+// count and rate of all course in progress of the student
+type ChronicAbsenceReport struct {
+	StudentId          string  `json:"student_id"`
+	StudentName        string  `json:"student_name"`
+	ClassId            string  `json:"class_id"`
+	AdvisorId          string  `json:"advisor_id"`
+	AllDateCount       int     `json:"all_date_count"`
+	AttendCount        int     `json:"attend_count"`
+	AbsentCount        int     `json:"absent_count"`
+	LeaveCount         int     `json:"leave_count"`
+	LateCount          int     `json:"late_count"`
+	AbsenceRate        float64 `json:"absence_rate"`
+	LateRate           float64 `json:"late_rate"`
+	ConsecutiveAbsence int     `json:"consecutive_absence"`
+	// time start of the last lesson that is absent or late
+	LastAbsentAt *time.Time             `json:"last_absent_at"`
+	LastLateAt   *time.Time             `json:"last_late_at"`
+	Reason       []string               `json:"reason"`
+	CourseList   []ChronicAbsenceCourse `json:"course_list"`
+}
+
+// count of one course, threshold is not check for it
+type ChronicAbsenceCourse struct {
+	CourseId     string  `json:"course_id"`
+	CourseName   string  `json:"course_name"`
+	AllDateCount int     `json:"all_date_count"`
+	AttendCount  int     `json:"attend_count"`
+	AbsentCount  int     `json:"absent_count"`
+	LeaveCount   int     `json:"leave_count"`
+	LateCount    int     `json:"late_count"`
+	AbsenceRate  float64 `json:"absence_rate"`
+	LateRate     float64 `json:"late_rate"`
+}
+
+// advisor is notified of the student at this last absent and late lesson, it is not notify again until new absent or late
+type ChronicAbsenceNotice struct {
+	Id           primitive.ObjectID `json:"id" bson:"_id"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	StudentId    string             `json:"student_id" bson:"student_id"`
+	AdvisorId    string             `json:"advisor_id" bson:"advisor_id"`
+	LastAbsentAt *time.Time         `json:"last_absent_at" bson:"last_absent_at"`
+	LastLateAt   *time.Time         `json:"last_late_at" bson:"last_late_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// record of mutating request, audit log is never update or delete
+type AuditLog struct {
+	Id            primitive.ObjectID `json:"id" bson:"_id"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	ActorId       string             `json:"actor_id" bson:"actor_id"`
+	ActorUsername string             `json:"actor_username" bson:"actor_username"`
+	ActorRole     string             `json:"actor_role" bson:"actor_role"`
+	ApiKeyId      *string            `json:"api_key_id" bson:"api_key_id"`
+	Action        string             `json:"action" bson:"action"`
+	Method        string             `json:"method" bson:"method"`
+	Path          string             `json:"path" bson:"path"`
+	StatusCode    int                `json:"status_code" bson:"status_code"`
+	Targets       []AuditTarget      `json:"targets" bson:"targets"`
+	Ip            string             `json:"ip" bson:"ip"`
+	UserAgent     string             `json:"user_agent" bson:"user_agent"`
+}
+
+// entity that is change by request, changes is value that different between before and after
+type AuditTarget struct {
+	Collection string        `json:"collection" bson:"collection"`
+	TargetId   string        `json:"target_id" bson:"target_id"`
+	Changes    []AuditChange `json:"changes" bson:"changes"`
+}
+
+// field is path of value like "score_information.3.score_get"
+type AuditChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
@@ -1,26 +1,48 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // attend , absent , leave , late
 type CheckName struct {
-	Id            primitive.ObjectID `json:"id" bson:"_id"`
-	CreatedAt     string             `json:"created_at" bson:"created_at"`
-	UpdatedAt     string             `json:"updated_at" bson:"updated_at"`
-	CourseId      string             `json:"course_id" bson:"course_id"`
-	Date          string             `json:"date" bson:"date"`
-	Status        string             `json:"status" bson:"status"`
-	TimeLate      string             `json:"time_late" bson:"time_late"`
-	CheckNameData []CheckNameData    `json:"check_name_data,omitempty" bson:"check_name_data,omitempty"`
+	Id          primitive.ObjectID `json:"id" bson:"_id"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+	CourseId    string             `json:"course_id" bson:"course_id"`
+	Date        time.Time          `json:"date" bson:"date"`
+	Status      string             `json:"status" bson:"status"`
+	TimeStart   time.Time          `json:"time_start" bson:"time_start"`
+	TimeLate    time.Time          `json:"time_late" bson:"time_late"`
+	TimeClose   *time.Time         `json:"time_close,omitempty" bson:"time_close,omitempty"`
+	CloseAction string             `json:"close_action,omitempty" bson:"close_action,omitempty"`
+	// scheduler when it is open by scheduler, empty when it is add by teacher
+	Source        string          `json:"source,omitempty" bson:"source,omitempty"`
+	CheckNameData []CheckNameData `json:"check_name_data,omitempty" bson:"check_name_data,omitempty"`
 }
 
 type CheckNameData struct {
-	StudentId string  `json:"student_id" bson:"student_id"`
-	UpdatedAt string  `json:"updated_at" bson:"updated_at"`
-	Time      string  `json:"time" bson:"time"`
-	Status    string  `json:"status" bson:"status"`
-	CheckBy   string  `json:"check_by" bson:"check_by"`
-	Note      *string `json:"note" bson:"note"`
+	StudentId string     `json:"student_id" bson:"student_id"`
+	UpdatedAt time.Time  `json:"updated_at" bson:"updated_at"`
+	Time      *time.Time `json:"time" bson:"time"`
+	Status    string     `json:"status" bson:"status"`
+	CheckBy   string     `json:"check_by" bson:"check_by"`
+	Note      *string    `json:"note" bson:"note"`
+	// previous value before override
+	History []CheckNameHistory `json:"history,omitempty" bson:"history,omitempty"`
+}
+
+type CheckNameHistory struct {
+	UpdatedAt time.Time  `json:"updated_at" bson:"updated_at"`
+	Time      *time.Time `json:"time" bson:"time"`
+	Status    string     `json:"status" bson:"status"`
+	CheckBy   string     `json:"check_by" bson:"check_by"`
+	Note      *string    `json:"note" bson:"note"`
+	EditBy    string     `json:"edit_by" bson:"edit_by"`
+	EditRole  string     `json:"edit_role" bson:"edit_role"`
+	EditedAt  time.Time  `json:"edited_at" bson:"edited_at"`
 }
 
 type CheckNameRequest struct {
@@ -28,20 +50,27 @@ type CheckNameRequest struct {
 	// StudentId string  `json:"student_id"`
 	Date     string `json:"date"`
 	TimeLate *int   `json:"time_late"`
-	// Time      string  `json:"time"`
+	// HH:MM, lesson of check name when course has many lesson in a day
+	// default is first lesson of the course for new check name, lesson in progress for the others
+	TimeStart string `json:"time_start"`
+	// minute after time start, check name after this is reject or absent by close action
+	TimeClose   *int   `json:"time_close"`
+	CloseAction string `json:"close_action"`
+	// HH:MM, check in time for backfill
+	Time      string `json:"time"`
 	CourseId  string `json:"course_id"`
 	StudentId string `json:"student_id" bson:"student_id"`
 	CheckBy   string `json:"check_by" bson:"check_by"`
-	// Status    string  `json:"status"`
-	// Note      *string `json:"note"`
+	Status    string `json:"status"`
+	Note      string `json:"note"`
 
 	// TimeEnd string `json:"time_end"`
 }
 
 type CheckNameStudentRes struct {
-	Date      string `json:"date"`
-	UpdatedAt string `json:"updated_at" bson:"updated_at"`
-	Time      string `json:"time" bson:"time"`
-	Status    string `json:"status" bson:"status"`
-	CheckBy   string `json:"check_by" bson:"check_by"`
+	Date      time.Time  `json:"date"`
+	UpdatedAt time.Time  `json:"updated_at" bson:"updated_at"`
+	Time      *time.Time `json:"time" bson:"time"`
+	Status    string     `json:"status" bson:"status"`
+	CheckBy   string     `json:"check_by" bson:"check_by"`
 }
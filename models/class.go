@@ -1,11 +1,15 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type ClassData struct {
 	Id              primitive.ObjectID `json:"id" bson:"_id"`
-	CreatedAt       string             `json:"created_at" bson:"created_at"`
-	UpdatedAt       string             `json:"updated_at" bson:"updated_at"`
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at" bson:"updated_at"`
 	ClassYear       string             `json:"class_year" bson:"class_year"`
 	ClassRoom       string             `json:"class_room" bson:"class_room"`
 	AdvisorId       string             `json:"advisor_id" bson:"advisor_id"`
@@ -1,12 +1,16 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // กำหนดคะแนนตามหลักสูตรไว้แล้ว เต็ม 100
 type Course struct {
 	Id        primitive.ObjectID `json:"id" bson:"_id"`
-	CreatedAt string             `json:"created_at" bson:"created_at"`
-	UpdatedAt string             `json:"updated_at" bson:"updated_at"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
 	// CourseId        string                `json:"course_id" bson:"course_id"`
 	Status          string              `json:"status" bson:"status"`
 	SubjectId       string              `json:"subject_id" bson:"subject_id"`
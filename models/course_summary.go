@@ -1,11 +1,15 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type CourseSummary struct {
 	Id          primitive.ObjectID `json:"id" bson:"_id"`
-	CreatedAt   string             `json:"created_at" bson:"created_at"`
-	UpdatedAt   string             `json:"updated_at" bson:"updated_at"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
 	CourseId    string             `json:"course_id" bson:"course_id"`
 	StudentData []StudentData      `json:"student_data" bson:"student_data"`
 }
@@ -23,8 +27,8 @@ type StudentData struct {
 	AllDateCount         int `json:"all_date_count" bson:"all_date_count"`
 	CheckNameAttendCount int `json:"check_name_attend_count" bson:"check_name_attend_count"`
 	CheckNameAbsentCount int `json:"check_name_absent_count" bson:"check_name_absent_count"`
-	// CheckNameLeaveCount  int
-	CheckNameLateCount int `json:"check_name_late_count" bson:"check_name_late_count"`
+	CheckNameLeaveCount  int `json:"check_name_leave_count" bson:"check_name_leave_count"`
+	CheckNameLateCount   int `json:"check_name_late_count" bson:"check_name_late_count"`
 	// CheckNameStatus      bool
 	// CheckNameStatusMsg   string
 }
@@ -42,6 +46,7 @@ type StudentDataRes struct {
 	AllDateCount         int `json:"all_date_count" bson:"all_date_count"`
 	CheckNameAttendCount int `json:"check_name_attend_count" bson:"check_name_attend_count"`
 	CheckNameAbsentCount int `json:"check_name_absent_count" bson:"check_name_absent_count"`
+	CheckNameLeaveCount  int `json:"check_name_leave_count" bson:"check_name_leave_count"`
 	CheckNameLateCount   int `json:"check_name_late_count" bson:"check_name_late_count"`
 }
 
@@ -1,11 +1,15 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type FaceDetectData struct {
 	Id                   primitive.ObjectID `json:"id" bson:"_id"`
-	CreatedAt            string             `json:"created_at" bson:"created_at"`
-	UpdatedAt            string             `json:"updated_at" bson:"updated_at"`
+	CreatedAt            time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt            time.Time          `json:"updated_at" bson:"updated_at"`
 	Status               string             `json:"status" bson:"status"`
 	Name                 string             `json:"name" bson:"name"`
 	ClassId              string             `json:"class_id" bson:"class_id"`
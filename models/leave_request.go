@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// pending , approved , rejected
+type LeaveRequest struct {
+	Id            primitive.ObjectID `json:"id" bson:"_id"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at"`
+	StudentId     string             `json:"student_id" bson:"student_id"`
+	RequestBy     string             `json:"request_by" bson:"request_by"`
+	RequestByRole string             `json:"request_by_role" bson:"request_by_role"`
+	DateStart     string             `json:"date_start" bson:"date_start"`
+	DateEnd       string             `json:"date_end" bson:"date_end"`
+	Reason        string             `json:"reason" bson:"reason"`
+	FilePath      string             `json:"filepath" bson:"filepath"`
+	Status        string             `json:"status" bson:"status"`
+	ApproveBy     string             `json:"approve_by" bson:"approve_by"`
+	ApprovedAt    *time.Time         `json:"approved_at" bson:"approved_at"`
+	Note          string             `json:"note" bson:"note"`
+}
+
+type LeaveRequestRequest struct {
+	Id     string `json:"id"`
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
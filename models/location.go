@@ -1,11 +1,15 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type Location struct {
 	Id           primitive.ObjectID `json:"id" bson:"_id"`
-	CreatedAt    string             `json:"created_at" bson:"created_at"`
-	UpdatedAt    string             `json:"updated_at" bson:"updated_at"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
 	LocationId   string             `json:"location_id" bson:"location_id"`
 	BuildingName string             `json:"building_name" bson:"building_name"`
 	Floor        string             `json:"floor" bson:"floor"`
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// record of each sign in, password is never keep
+type LoginAttempt struct {
+	Id        primitive.ObjectID `json:"id" bson:"_id"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	Username  string             `json:"username" bson:"username"`
+	UserId    string             `json:"user_id" bson:"user_id"`
+	Ip        string             `json:"ip" bson:"ip"`
+	UserAgent string             `json:"user_agent" bson:"user_agent"`
+	Success   bool               `json:"success" bson:"success"`
+	Reason    string             `json:"reason" bson:"reason"`
+}
+
+// fail count of username or ip, type is username or ip
+type LoginLock struct {
+	Id           primitive.ObjectID `json:"id" bson:"_id"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
+	Type         string             `json:"type" bson:"type"`
+	Value        string             `json:"value" bson:"value"`
+	FailCount    int                `json:"fail_count" bson:"fail_count"`
+	LastFailedAt time.Time          `json:"last_failed_at" bson:"last_failed_at"`
+	LockedUntil  *time.Time         `json:"locked_until" bson:"locked_until"`
+}
+
+type LoginLockRequest struct {
+	Username string `json:"username"`
+	Ip       string `json:"ip"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// check_name , score , course_summary , information
+type Notification struct {
+	Id        primitive.ObjectID `json:"id" bson:"_id"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	ProfileId string             `json:"profile_id" bson:"profile_id"`
+	Role      string             `json:"role" bson:"role"`
+	Type      string             `json:"type" bson:"type"`
+	Title     string             `json:"title" bson:"title"`
+	Message   string             `json:"message" bson:"message"`
+	RefId     string             `json:"ref_id" bson:"ref_id"`
+	Read      bool               `json:"read" bson:"read"`
+	ReadAt    *time.Time         `json:"read_at,omitempty" bson:"read_at,omitempty"`
+}
+
+type NotificationRequest struct {
+	Id string `json:"id"`
+}
@@ -1,12 +1,16 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // admin
 type ProfileAdmin struct {
 	Id        primitive.ObjectID `json:"id" bson:"_id"`
-	CreatedAt string             `json:"created_at" bson:"created_at"`
-	UpdatedAt string             `json:"updated_at" bson:"updated_at"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
 	ProfileId string             `json:"profile_id" bson:"profile_id"`
 	Name      string             `json:"name" bson:"name"`
 	Role      string             `json:"role" bson:"role"`
@@ -15,12 +19,14 @@ type ProfileAdmin struct {
 // teacher
 type ProfileTeacher struct {
 	Id                primitive.ObjectID  `json:"id" bson:"_id"`
-	CreatedAt         string              `json:"created_at" bson:"created_at"`
-	UpdatedAt         string              `json:"updated_at" bson:"updated_at"`
+	CreatedAt         time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt         time.Time           `json:"updated_at" bson:"updated_at"`
 	ProfileId         string              `json:"profile_id" bson:"profile_id"`
 	Name              string              `json:"name" bson:"name"`
 	Role              string              `json:"role" bson:"role"`
 	Category          string              `json:"category" bson:"category"`
+	Email             string              `json:"email" bson:"email"`
+	Phone             string              `json:"phone" bson:"phone"`
 	SubjectId         string              `json:"subject_id" bson:"subject_id"`
 	ClassInCounseling string              `json:"class_in_counseling" bson:"class_in_counseling"`
 	CourseTeachesList []CourseTeachesList `json:"course_teaches_list" bson:"course_teaches_list"`
@@ -36,8 +42,8 @@ type CourseTeachesList struct {
 // student
 type ProfileStudent struct {
 	Id        primitive.ObjectID `json:"id" bson:"_id"`
-	CreatedAt string             `json:"created_at" bson:"created_at"`
-	UpdatedAt string             `json:"updated_at" bson:"updated_at"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
 	ProfileId string             `json:"profile_id" bson:"profile_id"`
 	Name      string             `json:"name" bson:"name"`
 	Role      string             `json:"role" bson:"role"`
@@ -48,6 +54,19 @@ type ProfileStudent struct {
 	TermScore []TermScore        `json:"term_score" bson:"term_score"`
 }
 
+// parent
+type ProfileParent struct {
+	Id            primitive.ObjectID `json:"id" bson:"_id"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at"`
+	ProfileId     string             `json:"profile_id" bson:"profile_id"`
+	Name          string             `json:"name" bson:"name"`
+	Role          string             `json:"role" bson:"role"`
+	Email         string             `json:"email" bson:"email"`
+	Phone         string             `json:"phone" bson:"phone"`
+	StudentIdList []string           `json:"student_id_list" bson:"student_id_list"`
+}
+
 type TermScore struct {
 	Year       string       `json:"year" bson:"year"`
 	Term       string       `json:"term" bson:"term"`
@@ -71,6 +90,7 @@ type CourseList struct {
 	AllDateCount         int `json:"all_date_count" bson:"all_date_count"`
 	CheckNameAttendCount int `json:"check_name_attend_count" bson:"check_name_attend_count"`
 	CheckNameAbsentCount int `json:"check_name_absent_count" bson:"check_name_absent_count"`
+	CheckNameLeaveCount  int `json:"check_name_leave_count" bson:"check_name_leave_count"`
 	CheckNameLateCount   int `json:"check_name_late_count" bson:"check_name_late_count"`
 }
 
@@ -81,15 +101,18 @@ type ProfileRequest struct {
 	ProfileId string `json:"profile_id" bson:"profile_id"`
 	Name      string `json:"name"`
 
-	ProfileIdNew      string `json:"profile_id_new" bson:"profile_id_new"`
-	AdvisorId         string `json:"advisor_id" bson:"advisor_id"`
-	SubjectId         string `json:"subject_id"`
-	ClassId           string `json:"class_id"`
-	ClassInCounseling string `json:"class_in_counseling" bson:"class_in_counseling"`
-	ParentId          string `json:"parent_id"`
-	StudentId         string `json:"student_id"`
-	CoursesId         string `json:"courses_id" bson:"courses_id"`
-	Category          string `json:"category" bson:"category"`
+	ProfileIdNew      string   `json:"profile_id_new" bson:"profile_id_new"`
+	AdvisorId         string   `json:"advisor_id" bson:"advisor_id"`
+	SubjectId         string   `json:"subject_id"`
+	ClassId           string   `json:"class_id"`
+	ClassInCounseling string   `json:"class_in_counseling" bson:"class_in_counseling"`
+	ParentId          string   `json:"parent_id"`
+	StudentId         string   `json:"student_id"`
+	StudentIdList     []string `json:"student_id_list"`
+	CoursesId         string   `json:"courses_id" bson:"courses_id"`
+	Category          string   `json:"category" bson:"category"`
+	Email             string   `json:"email"`
+	Phone             string   `json:"phone"`
 }
 
 type ProfileForChat struct {
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// custom role with permission set, built-in role is not keep in collection
+type Role struct {
+	Id          primitive.ObjectID `json:"id" bson:"_id"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description"`
+	Permissions []string           `json:"permissions" bson:"permissions"`
+}
+
+type RoleRequest struct {
+	Id          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	UserId      string   `json:"user_id"`
+}
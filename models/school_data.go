@@ -1,26 +1,64 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type SchoolData struct {
-	Id                  primitive.ObjectID `json:"id" bson:"_id"`
-	CreatedAt           string             `json:"created_at" bson:"created_at"`
-	UpdatedAt           string             `json:"updated_at" bson:"updated_at"`
-	Type                string             `json:"type" bson:"type"`
-	Year                *string            `json:"year,omitempty" bson:"year,omitempty"`
-	Term                *string            `json:"term,omitempty" bson:"term,omitempty"`
-	Status              *bool              `json:"status,omitempty" bson:"status,omitempty"`
-	SubjectCategory     *string            `json:"subject_category,omitempty" bson:"subject_category,omitempty"`
-	InformationCategory *string            `json:"information_category,omitempty" bson:"information_category,omitempty"`
+	Id                  primitive.ObjectID   `json:"id" bson:"_id"`
+	CreatedAt           time.Time            `json:"created_at" bson:"created_at"`
+	UpdatedAt           time.Time            `json:"updated_at" bson:"updated_at"`
+	Type                string               `json:"type" bson:"type"`
+	Year                *string              `json:"year,omitempty" bson:"year,omitempty"`
+	Term                *string              `json:"term,omitempty" bson:"term,omitempty"`
+	Status              *bool                `json:"status,omitempty" bson:"status,omitempty"`
+	SubjectCategory     *string              `json:"subject_category,omitempty" bson:"subject_category,omitempty"`
+	InformationCategory *string              `json:"information_category,omitempty" bson:"information_category,omitempty"`
+	AttendanceThreshold *AttendanceThreshold `json:"attendance_threshold,omitempty" bson:"attendance_threshold,omitempty"`
+	Date                *string              `json:"date,omitempty" bson:"date,omitempty"`
+	Name                *string              `json:"name,omitempty" bson:"name,omitempty"`
+	DateStart           *string              `json:"date_start,omitempty" bson:"date_start,omitempty"`
+	DateEnd             *string              `json:"date_end,omitempty" bson:"date_end,omitempty"`
+}
+
+// rate is percent, zero is not check
+type AttendanceThreshold struct {
+	AbsenceRate        float64 `json:"absence_rate" bson:"absence_rate"`
+	LateRate           float64 `json:"late_rate" bson:"late_rate"`
+	ConsecutiveAbsence int     `json:"consecutive_absence" bson:"consecutive_absence"`
 }
 
 type SchoolDataRequest struct {
-	Id       string `json:"id"`
-	Year     string `json:"year"`
-	Term     string `json:"term"`
-	Status   *bool  `json:"status"`
-	Category string `json:"category" bson:"category"`
+	Id        string `json:"id"`
+	Year      string `json:"year"`
+	Term      string `json:"term"`
+	Status    *bool  `json:"status"`
+	Category  string `json:"category" bson:"category"`
+	Date      string `json:"date"`
+	Name      string `json:"name"`
+	DateStart string `json:"date_start"`
+	DateEnd   string `json:"date_end"`
+
+	AbsenceRate        *float64 `json:"absence_rate"`
+	LateRate           *float64 `json:"late_rate"`
+	ConsecutiveAbsence *int     `json:"consecutive_absence"`
 }
 
 type YearAndTerm struct {
 }
+
+// calendar of year and term, event list is sort by date
+type SchoolCalendar struct {
+	YearAndTerm *SchoolData   `json:"year_and_term"`
+	Holiday     []*SchoolData `json:"holiday"`
+	ExamWeek    []*SchoolData `json:"exam_week"`
+	Event       []*SchoolData `json:"event"`
+}
+
+type LessonDate struct {
+	Date string   `json:"date"`
+	Day  string   `json:"day"`
+	Time []string `json:"time"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// refresh token is keep as sha256 hash
+// revoked by is user id, it is empty when server revoke the session
+// revoke reason is logout , logout_all , revoke , password_change , password_reset , two_factor_reset , refresh_token_reuse
+type Session struct {
+	Id               primitive.ObjectID `json:"id" bson:"_id"`
+	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at" bson:"updated_at"`
+	UserId           string             `json:"user_id" bson:"user_id"`
+	RefreshTokenHash string             `json:"-" bson:"refresh_token_hash"`
+	UserAgent        string             `json:"user_agent" bson:"user_agent"`
+	Ip               string             `json:"ip" bson:"ip"`
+	ExpiresAt        time.Time          `json:"expires_at" bson:"expires_at"`
+	Revoked          bool               `json:"revoked" bson:"revoked"`
+	RevokedAt        *time.Time         `json:"revoked_at" bson:"revoked_at"`
+	RevokedBy        *string            `json:"revoked_by" bson:"revoked_by"`
+	RevokeReason     string             `json:"revoke_reason,omitempty" bson:"revoke_reason,omitempty"`
+}
+
+type SessionRequest struct {
+	Id           string `json:"id"`
+	UserId       string `json:"user_id"`
+	RefreshToken string `json:"refresh_token"`
+}
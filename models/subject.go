@@ -1,11 +1,15 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type Subject struct {
 	Id           primitive.ObjectID `json:"id" bson:"_id"`
-	CreatedAt    string             `json:"created_at" bson:"created_at"`
-	UpdatedAt    string             `json:"updated_at" bson:"updated_at"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
 	SubjectId    string             `json:"subject_id" bson:"subject_id"`
 	Name         string             `json:"name" bson:"name"`
 	Category     string             `json:"category" bson:"category"`
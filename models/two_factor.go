@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// two factor is required for user of role when required is true
+type TwoFactorPolicy struct {
+	Id        primitive.ObjectID `json:"id" bson:"_id"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	Role      string             `json:"role" bson:"role"`
+	Required  bool               `json:"required" bson:"required"`
+	UpdatedBy string             `json:"updated_by" bson:"updated_by"`
+}
+
+type TwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	Password       string `json:"password"`
+	UserId         string `json:"user_id"`
+	Role           string `json:"role"`
+	Required       *bool  `json:"required"`
+}
@@ -1,15 +1,29 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type User struct {
 	Id        primitive.ObjectID `json:"id" bson:"_id"`
 	Username  string             `json:"username" bson:"username"`
 	Password  string             `json:"password" bson:"password"`
-	CreatedAt string             `json:"created_at" bson:"created_at"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
 	UserId    string             `json:"user_id" bson:"user_id"`
 	ProfileId string             `json:"profile_id" bson:"profile_id"`
 	Role      string             `json:"role" bson:"role"`
+
+	MustChangePassword bool       `json:"must_change_password" bson:"must_change_password"`
+	PasswordChangedAt  *time.Time `json:"password_changed_at" bson:"password_changed_at"`
+
+	// totp secret and recovery code hash are never return
+	TotpEnabled        bool     `json:"totp_enabled" bson:"totp_enabled"`
+	TotpSecret         string   `json:"-" bson:"totp_secret"`
+	TotpPendingSecret  string   `json:"-" bson:"totp_pending_secret"`
+	TotpLastStep       int64    `json:"-" bson:"totp_last_step"`
+	RecoveryCodeHashes []string `json:"-" bson:"recovery_code_hashes"`
 }
 
 type UserRequest struct {
@@ -19,3 +33,9 @@ type UserRequest struct {
 	ProfileId string `json:"profile_id" bson:"profile_id"`
 	Role      string `json:"role" bson:"role"`
 }
+
+type PasswordRequest struct {
+	UserId      string `json:"user_id"`
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
@@ -0,0 +1,115 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"school-notification-backend/logger"
+	"strings"
+)
+
+type emailNotifier struct {
+	addr   string
+	auth   smtp.Auth
+	from   string
+	logger logger.Logger
+}
+
+func NewEmailNotifier(host string, port string, username string, password string, from string, logger logger.Logger) Notifier {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &emailNotifier{addr: host + ":" + port, auth: auth, from: from, logger: logger}
+}
+
+func (n *emailNotifier) Notify(ctx context.Context, alert *Alert) error {
+	if alert.Email == "" {
+		n.logger.Debug(ctx, "email notifier skip", "profile_id", alert.ProfileId)
+		return nil
+	}
+
+	msg := strings.Join([]string{
+		"From: " + n.from,
+		"To: " + alert.Email,
+		"Subject: " + alert.Title(),
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		alert.Message(),
+	}, "\r\n")
+
+	err := n.sendMail(ctx, alert.Email, []byte(msg))
+	if err != nil {
+		return fmt.Errorf("email notifier: %w", err)
+	}
+
+	return nil
+}
+
+// same as smtp.SendMail, connection is closed at deadline of ctx
+func (n *emailNotifier) sendMail(ctx context.Context, to string, msg []byte) error {
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		err = conn.SetDeadline(deadline)
+		if err != nil {
+			return err
+		}
+	}
+
+	host, _, err := net.SplitHostPort(n.addr)
+	if err != nil {
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		err = c.StartTLS(&tls.Config{ServerName: host})
+		if err != nil {
+			return err
+		}
+	}
+	if n.auth != nil {
+		err = c.Auth(n.auth)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.Mail(n.from)
+	if err != nil {
+		return err
+	}
+	err = c.Rcpt(to)
+	if err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(msg)
+	if err != nil {
+		return err
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
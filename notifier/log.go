@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"school-notification-backend/logger"
+	"sync"
+)
+
+// for local testing, write alert as json line to file or to log when path is empty
+type logNotifier struct {
+	path   string
+	mu     sync.Mutex
+	logger logger.Logger
+}
+
+func NewLogNotifier(path string, logger logger.Logger) Notifier {
+	return &logNotifier{path: path, logger: logger}
+}
+
+func (n *logNotifier) Notify(ctx context.Context, alert *Alert) error {
+	b, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	if n.path == "" {
+		n.logger.Info(ctx, "alert", "alert", alert)
+		return nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
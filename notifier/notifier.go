@@ -0,0 +1,90 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"school-notification-backend/logger"
+	"strings"
+	"time"
+)
+
+// absent , late
+type Alert struct {
+	Type        string `json:"type"`
+	ProfileId   string `json:"profile_id"`
+	Role        string `json:"role"`
+	Email       string `json:"email,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+	StudentId   string `json:"student_id"`
+	StudentName string `json:"student_name"`
+	CourseName  string `json:"course_name"`
+	Date        string `json:"date"`
+	Time        string `json:"time"`
+	RefId       string `json:"ref_id"`
+}
+
+func (a *Alert) Title() string {
+	return a.Type + " alert"
+}
+
+func (a *Alert) Message() string {
+	return fmt.Sprintf("%s %s from %s on %s at %s", a.StudentName, a.Type, a.CourseName, a.Date, a.Time)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, alert *Alert) error
+}
+
+type multiNotifier struct {
+	notifiers []Notifier
+}
+
+// send to every notifier, one channel failed does not stop the others
+func NewMultiNotifier(notifiers ...Notifier) Notifier {
+	return &multiNotifier{notifiers: notifiers}
+}
+
+func (m *multiNotifier) Notify(ctx context.Context, alert *Alert) error {
+	errs := []string{}
+	for _, n := range m.notifiers {
+		err := n.Notify(ctx, alert)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) != 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+// worker, waiting alert and timeout of each alert of background sending
+const queueWorkers = 4
+const queueSize = 1000
+const sendTimeout = 30 * time.Second
+
+// NOTIFIER is comma separated list of email , webhook , log
+// in app notification is always send by controller, so inapp is not a channel and is skip
+// alert is send in background, Notify only put it in queue
+func NewNotifierFromEnv(logger logger.Logger) Notifier {
+	notifiers := []Notifier{}
+	for _, name := range strings.Split(os.Getenv("NOTIFIER"), ",") {
+		switch strings.TrimSpace(name) {
+		case "", "inapp":
+		case "email":
+			notifiers = append(notifiers, NewEmailNotifier(os.Getenv("SMTP_HOST"), os.Getenv("SMTP_PORT"), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), os.Getenv("SMTP_FROM"), logger))
+		case "webhook":
+			notifiers = append(notifiers, NewWebhookNotifier(os.Getenv("NOTIFIER_WEBHOOK_URL"), os.Getenv("NOTIFIER_WEBHOOK_TOKEN")))
+		case "log":
+			notifiers = append(notifiers, NewLogNotifier(os.Getenv("NOTIFIER_LOG_FILE"), logger))
+		default:
+			logger.Warn(context.Background(), "notifier is invalid", "name", name)
+		}
+	}
+
+	return NewQueueNotifier(NewMultiNotifier(notifiers...), queueWorkers, queueSize, sendTimeout, logger)
+}
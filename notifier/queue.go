@@ -0,0 +1,77 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"school-notification-backend/logger"
+	"time"
+)
+
+var ErrQueueFull = errors.New("alert queue is full")
+
+// wait for free space of full queue, ctx of scheduler does not have deadline
+const enqueueTimeout = 5 * time.Second
+
+type queueItem struct {
+	requestId string
+	alert     *Alert
+}
+
+// send alert in background worker, request does not wait for slow gateway
+type queueNotifier struct {
+	notifier Notifier
+	queue    chan queueItem
+	timeout  time.Duration
+	logger   logger.Logger
+}
+
+// workers send at the same time, Notify wait when size alerts are waiting
+// each alert is send with its own timeout, not context of request that already end
+func NewQueueNotifier(notifier Notifier, workers int, size int, timeout time.Duration, logger logger.Logger) Notifier {
+	q := &queueNotifier{notifier: notifier, queue: make(chan queueItem, size), timeout: timeout, logger: logger}
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+
+	return q
+}
+
+// full queue block caller until ctx is done or enqueueTimeout, then alert is not send and error is return
+func (q *queueNotifier) Notify(ctx context.Context, alert *Alert) error {
+	item := queueItem{requestId: logger.RequestId(ctx), alert: alert}
+	select {
+	case q.queue <- item:
+		return nil
+	default:
+	}
+
+	q.logger.Warn(ctx, "alert queue is full, wait", "size", cap(q.queue))
+	timer := time.NewTimer(enqueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case q.queue <- item:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", ErrQueueFull, ctx.Err())
+	case <-timer.C:
+		return ErrQueueFull
+	}
+}
+
+func (q *queueNotifier) work() {
+	for item := range q.queue {
+		q.send(item)
+	}
+}
+
+func (q *queueNotifier) send(item queueItem) {
+	ctx, cancel := context.WithTimeout(logger.WithRequestId(context.Background(), item.requestId), q.timeout)
+	defer cancel()
+
+	err := q.notifier.Notify(ctx, item.alert)
+	if err != nil {
+		q.logger.Error(ctx, "send alert failed", "type", item.alert.Type, "profile_id", item.alert.ProfileId, "error", err)
+	}
+}
@@ -0,0 +1,54 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// webhook for sms or line gateway, the gateway receive alert as json
+type webhookNotifier struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, token string) Notifier {
+	return &webhookNotifier{url: url, token: token, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, alert *Alert) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"to":      alert.Phone,
+		"title":   alert.Title(),
+		"message": alert.Message(),
+		"alert":   alert,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook notifier: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("webhook notifier: status %d", res.StatusCode)
+	}
+
+	return nil
+}
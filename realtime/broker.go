@@ -0,0 +1,116 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"school-notification-backend/logger"
+	"sync"
+)
+
+var ErrBrokerClosed = errors.New("broker is closed")
+
+// Broker deliver payload to every subscription of a topic
+type Broker interface {
+	Publish(topic string, payload []byte) error
+	Subscribe(topic string) (Subscription, error)
+	Close() error
+}
+
+type Subscription interface {
+	Messages() <-chan []byte
+	Unsubscribe()
+}
+
+type memoryBroker struct {
+	mu     sync.RWMutex
+	topics map[string]map[*memorySubscription]struct{}
+	size   int
+	closed bool
+	logger logger.Logger
+}
+
+type memorySubscription struct {
+	broker *memoryBroker
+	topic  string
+	ch     chan []byte
+	once   sync.Once
+}
+
+// in process broker, size is the buffer of each subscription
+func NewMemoryBroker(size int, logger logger.Logger) Broker {
+	return &memoryBroker{topics: map[string]map[*memorySubscription]struct{}{}, size: size, logger: logger}
+}
+
+func (b *memoryBroker) Publish(topic string, payload []byte) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return ErrBrokerClosed
+	}
+
+	for s := range b.topics[topic] {
+		select {
+		case s.ch <- payload:
+		default:
+			// slow subscriber should not block the publisher
+			b.logger.Warn(context.Background(), "drop realtime message", "topic", topic)
+		}
+	}
+
+	return nil
+}
+
+func (b *memoryBroker) Subscribe(topic string) (Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrBrokerClosed
+	}
+
+	s := &memorySubscription{broker: b, topic: topic, ch: make(chan []byte, b.size)}
+	if b.topics[topic] == nil {
+		b.topics[topic] = map[*memorySubscription]struct{}{}
+	}
+	b.topics[topic][s] = struct{}{}
+
+	return s, nil
+}
+
+func (b *memoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+
+	for _, subs := range b.topics {
+		for s := range subs {
+			s.once.Do(func() { close(s.ch) })
+		}
+	}
+	b.topics = map[string]map[*memorySubscription]struct{}{}
+
+	return nil
+}
+
+func (s *memorySubscription) Messages() <-chan []byte {
+	return s.ch
+}
+
+func (s *memorySubscription) Unsubscribe() {
+	b := s.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if subs, ok := b.topics[s.topic]; ok {
+		delete(subs, s)
+		if len(subs) == 0 {
+			delete(b.topics, s.topic)
+		}
+	}
+	s.once.Do(func() { close(s.ch) })
+}
@@ -0,0 +1,133 @@
+package realtime
+
+import (
+	"errors"
+	"io"
+	"school-notification-backend/logger"
+	"testing"
+)
+
+func newTestBroker(size int) Broker {
+	return NewMemoryBroker(size, logger.New(io.Discard, logger.LevelError))
+}
+
+func TestMemoryBrokerPublish(t *testing.T) {
+	b := newTestBroker(1)
+	first, err := b.Subscribe("profile:student:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := b.Subscribe("profile:student:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := b.Subscribe("profile:student:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = b.Publish("profile:student:1", []byte("attend"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []Subscription{first, second} {
+		select {
+		case got := <-s.Messages():
+			if string(got) != "attend" {
+				t.Errorf("got %q, want %q", got, "attend")
+			}
+		default:
+			t.Error("subscriber of topic did not get the message")
+		}
+	}
+	select {
+	case got := <-other.Messages():
+		t.Errorf("subscriber of other topic got %q", got)
+	default:
+	}
+}
+
+func TestMemoryBrokerPublishFullBuffer(t *testing.T) {
+	b := newTestBroker(1)
+	s, err := b.Subscribe("topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// second message is drop, publisher is not block by slow subscriber
+	for _, payload := range []string{"first", "second"} {
+		err = b.Publish("topic", []byte(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := <-s.Messages(); string(got) != "first" {
+		t.Errorf("got %q, want %q", got, "first")
+	}
+	select {
+	case got := <-s.Messages():
+		t.Errorf("got %q after buffer is full", got)
+	default:
+	}
+}
+
+func TestMemoryBrokerUnsubscribe(t *testing.T) {
+	b := newTestBroker(1)
+	s, err := b.Subscribe("topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, err := b.Subscribe("topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.Unsubscribe()
+	// second unsubscribe must not close the channel again
+	s.Unsubscribe()
+
+	if _, ok := <-s.Messages(); ok {
+		t.Error("channel of unsubscribed subscription is not closed")
+	}
+
+	err = b.Publish("topic", []byte("late"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := <-kept.Messages(); string(got) != "late" {
+		t.Errorf("got %q, want %q", got, "late")
+	}
+
+	kept.Unsubscribe()
+	mb := b.(*memoryBroker)
+	if _, ok := mb.topics["topic"]; ok {
+		t.Error("topic without subscription is not removed")
+	}
+}
+
+func TestMemoryBrokerClose(t *testing.T) {
+	b := newTestBroker(1)
+	s, err := b.Subscribe("topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = b.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-s.Messages(); ok {
+		t.Error("channel is not closed after broker is closed")
+	}
+	// unsubscribe after close does not panic on closed channel
+	s.Unsubscribe()
+
+	if err := b.Publish("topic", []byte("attend")); !errors.Is(err, ErrBrokerClosed) {
+		t.Errorf("publish after close got %v, want %v", err, ErrBrokerClosed)
+	}
+	if _, err := b.Subscribe("topic"); !errors.Is(err, ErrBrokerClosed) {
+		t.Errorf("subscribe after close got %v, want %v", err, ErrBrokerClosed)
+	}
+}
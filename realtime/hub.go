@@ -0,0 +1,69 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"school-notification-backend/logger"
+	"time"
+)
+
+// message , notification
+type Event struct {
+	Type      string      `json:"type"`
+	CreatedAt string      `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Hub publish event to the topic of user or profile
+type Hub interface {
+	PublishToUser(userId string, eventType string, data interface{})
+	PublishToProfile(role string, profileId string, eventType string, data interface{})
+	Subscribe(topic string) (Subscription, error)
+}
+
+type hub struct {
+	broker Broker
+	logger logger.Logger
+}
+
+func NewHub(broker Broker, logger logger.Logger) Hub {
+	return &hub{broker: broker, logger: logger}
+}
+
+func UserTopic(userId string) string {
+	return "user:" + userId
+}
+
+func ProfileTopic(role string, profileId string) string {
+	return "profile:" + role + ":" + profileId
+}
+
+func (h *hub) PublishToUser(userId string, eventType string, data interface{}) {
+	h.publish(UserTopic(userId), eventType, data)
+}
+
+func (h *hub) PublishToProfile(role string, profileId string, eventType string, data interface{}) {
+	h.publish(ProfileTopic(role, profileId), eventType, data)
+}
+
+func (h *hub) Subscribe(topic string) (Subscription, error) {
+	return h.broker.Subscribe(topic)
+}
+
+// realtime push is best effort, client can load the missing data from the api
+func (h *hub) publish(topic string, eventType string, data interface{}) {
+	payload, err := json.Marshal(Event{
+		Type:      eventType,
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Data:      data,
+	})
+	if err != nil {
+		h.logger.Error(context.Background(), "marshal realtime event", "type", eventType, "error", err)
+		return
+	}
+
+	err = h.broker.Publish(topic, payload)
+	if err != nil {
+		h.logger.Warn(context.Background(), "publish realtime failed", "topic", topic, "error", err)
+	}
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"school-notification-backend/db"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/util"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const ApiKeyCollection = "api_keys"
+
+type ApiKeyRepository interface {
+	Insert(ctx context.Context, apiKey *models.ApiKey) (*mongo.InsertOneResult, error)
+	Update(ctx context.Context, apiKey *models.ApiKey) (*mongo.UpdateResult, error)
+	UpdateLastUsed(ctx context.Context, id primitive.ObjectID, t time.Time) (*mongo.UpdateResult, error)
+	GetById(ctx context.Context, id string) (apiKey *models.ApiKey, err error)
+	GetAll(ctx context.Context) (apiKeys []*models.ApiKey, err error)
+}
+
+type apiKeyRepository struct {
+	c      *mongo.Collection
+	logger logger.Logger
+}
+
+func NewApiKeyRepository(conn db.Connection, logger logger.Logger) ApiKeyRepository {
+	return &apiKeyRepository{c: conn.DB().Collection(ApiKeyCollection), logger: logger.With("collection", ApiKeyCollection)}
+}
+
+func (a *apiKeyRepository) Insert(ctx context.Context, apiKey *models.ApiKey) (*mongo.InsertOneResult, error) {
+	result, err := a.c.InsertOne(ctx, apiKey)
+	return result, domainError(err)
+}
+
+func (a *apiKeyRepository) Update(ctx context.Context, apiKey *models.ApiKey) (*mongo.UpdateResult, error) {
+	result, err := a.c.UpdateByID(ctx, apiKey.Id, bson.M{"$set": apiKey})
+	return result, domainError(err)
+}
+
+func (a *apiKeyRepository) UpdateLastUsed(ctx context.Context, id primitive.ObjectID, t time.Time) (*mongo.UpdateResult, error) {
+	result, err := a.c.UpdateByID(ctx, id, bson.M{"$set": bson.M{"last_used_at": t}})
+	return result, domainError(err)
+}
+
+func (a *apiKeyRepository) GetById(ctx context.Context, id string) (apiKey *models.ApiKey, err error) {
+	if ok := primitive.IsValidObjectID(id); ok == false {
+		return nil, util.ErrInvalidID
+	}
+
+	oID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.c.FindOne(ctx, bson.M{"_id": oID}).Decode(&apiKey)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return apiKey, nil
+}
+
+func (a *apiKeyRepository) GetAll(ctx context.Context) (apiKeys []*models.ApiKey, err error) {
+
+	cur, err := a.c.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+
+	for cur.Next(ctx) {
+		var b *models.ApiKey
+		err := cur.Decode(&b)
+		if err != nil {
+			a.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
+			return nil, err
+		}
+
+		apiKeys = append(apiKeys, b)
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	cur.Close(ctx)
+
+	if len(apiKeys) == 0 {
+		return nil, util.ErrNotFound
+	}
+
+	return apiKeys, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"school-notification-backend/db"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const AuditLogCollection = "audit_logs"
+
+// append only, there is no update or delete
+type AuditLogRepository interface {
+	Insert(ctx context.Context, auditLog *models.AuditLog) (*mongo.InsertOneResult, error)
+	GetByFilterAll(ctx context.Context, filter interface{}, limit int64) (auditLogs []*models.AuditLog, err error)
+}
+
+type auditLogRepository struct {
+	c      *mongo.Collection
+	logger logger.Logger
+}
+
+func NewAuditLogRepository(conn db.Connection, logger logger.Logger) AuditLogRepository {
+	return &auditLogRepository{c: conn.DB().Collection(AuditLogCollection), logger: logger.With("collection", AuditLogCollection)}
+}
+
+func (a *auditLogRepository) Insert(ctx context.Context, auditLog *models.AuditLog) (*mongo.InsertOneResult, error) {
+	result, err := a.c.InsertOne(ctx, auditLog)
+	return result, domainError(err)
+}
+
+// newest first
+func (a *auditLogRepository) GetByFilterAll(ctx context.Context, filter interface{}, limit int64) (auditLogs []*models.AuditLog, err error) {
+
+	cur, err := a.c.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	for cur.Next(ctx) {
+		var b *models.AuditLog
+		err := cur.Decode(&b)
+		if err != nil {
+			a.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
+			return nil, err
+		}
+
+		auditLogs = append(auditLogs, b)
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	cur.Close(ctx)
+
+	if len(auditLogs) == 0 {
+		return nil, util.ErrNotFound
+	}
+
+	return auditLogs, nil
+}
@@ -3,61 +3,66 @@ package repository
 import (
 	"context"
 	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
+	"school-notification-backend/util"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const checkNameCollection = "check-name"
+const CheckNameCollection = "check-name"
 
 type CheckNameRepository interface {
-	Insert(checkName *models.CheckName) (*mongo.InsertOneResult, error)
-	Update(checkName *models.CheckName) (*mongo.UpdateResult, error)
-	GetByFilter(filter interface{}) (checkName *models.CheckName, err error)
-	GetByFilterAll(filter interface{}) (checkNameList []*models.CheckName, err error)
+	Insert(ctx context.Context, checkName *models.CheckName) (*mongo.InsertOneResult, error)
+	Update(ctx context.Context, checkName *models.CheckName) (*mongo.UpdateResult, error)
+	GetByFilter(ctx context.Context, filter interface{}) (checkName *models.CheckName, err error)
+	GetByFilterAll(ctx context.Context, filter interface{}) (checkNameList []*models.CheckName, err error)
 }
 
 type checkNameRepository struct {
-	c   *mongo.Collection
-	ctx context.Context
+	c      *mongo.Collection
+	logger logger.Logger
 }
 
-func NewCheckNameRepository(conn db.Connection) CheckNameRepository {
-	return &checkNameRepository{c: conn.DB().Collection(checkNameCollection), ctx: context.TODO()}
+func NewCheckNameRepository(conn db.Connection, logger logger.Logger) CheckNameRepository {
+	return &checkNameRepository{c: conn.DB().Collection(CheckNameCollection), logger: logger.With("collection", CheckNameCollection)}
 }
 
-func (c *checkNameRepository) Insert(checkName *models.CheckName) (*mongo.InsertOneResult, error) {
-	return c.c.InsertOne(c.ctx, checkName)
+func (c *checkNameRepository) Insert(ctx context.Context, checkName *models.CheckName) (*mongo.InsertOneResult, error) {
+	result, err := c.c.InsertOne(ctx, checkName)
+	return result, domainError(err)
 }
 
-func (c *checkNameRepository) Update(checkName *models.CheckName) (*mongo.UpdateResult, error) {
-	return c.c.UpdateByID(c.ctx, checkName.Id, bson.M{"$set": checkName})
+func (c *checkNameRepository) Update(ctx context.Context, checkName *models.CheckName) (*mongo.UpdateResult, error) {
+	result, err := c.c.UpdateByID(ctx, checkName.Id, bson.M{"$set": checkName})
+	return result, domainError(err)
 }
 
-func (c *checkNameRepository) GetByFilter(filter interface{}) (checkName *models.CheckName, err error) {
+func (c *checkNameRepository) GetByFilter(ctx context.Context, filter interface{}) (checkName *models.CheckName, err error) {
 
-	result := c.c.FindOne(c.ctx, filter)
+	result := c.c.FindOne(ctx, filter)
 
 	err = result.Decode(&checkName)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return checkName, result.Err()
 }
 
-func (c *checkNameRepository) GetByFilterAll(filter interface{}) (checkNameList []*models.CheckName, err error) {
+func (c *checkNameRepository) GetByFilterAll(ctx context.Context, filter interface{}) (checkNameList []*models.CheckName, err error) {
 
-	cur, err := c.c.Find(c.ctx, filter, nil)
+	cur, err := c.c.Find(ctx, filter, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(c.ctx) {
+	for cur.Next(ctx) {
 		var b *models.CheckName
 		err := cur.Decode(&b)
 		if err != nil {
+			c.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -68,10 +73,10 @@ func (c *checkNameRepository) GetByFilterAll(filter interface{}) (checkNameList
 		return nil, err
 	}
 
-	cur.Close(c.ctx)
+	cur.Close(ctx)
 
 	if len(checkNameList) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return checkNameList, nil
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"school-notification-backend/db"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/util"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const ChronicAbsenceNoticeCollection = "chronic_absence_notices"
+
+type ChronicAbsenceNoticeRepository interface {
+	Insert(ctx context.Context, notice *models.ChronicAbsenceNotice) (*mongo.InsertOneResult, error)
+	GetByFilterAll(ctx context.Context, filter interface{}) (notices []*models.ChronicAbsenceNotice, err error)
+}
+
+type chronicAbsenceNoticeRepository struct {
+	c      *mongo.Collection
+	logger logger.Logger
+}
+
+func NewChronicAbsenceNoticeRepository(conn db.Connection, logger logger.Logger) ChronicAbsenceNoticeRepository {
+	return &chronicAbsenceNoticeRepository{c: conn.DB().Collection(ChronicAbsenceNoticeCollection), logger: logger.With("collection", ChronicAbsenceNoticeCollection)}
+}
+
+// notice that already exists return util.ErrConflict
+func (n *chronicAbsenceNoticeRepository) Insert(ctx context.Context, notice *models.ChronicAbsenceNotice) (*mongo.InsertOneResult, error) {
+	result, err := n.c.InsertOne(ctx, notice)
+	return result, domainError(err)
+}
+
+func (n *chronicAbsenceNoticeRepository) GetByFilterAll(ctx context.Context, filter interface{}) (notices []*models.ChronicAbsenceNotice, err error) {
+
+	cur, err := n.c.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for cur.Next(ctx) {
+		var b *models.ChronicAbsenceNotice
+		err := cur.Decode(&b)
+		if err != nil {
+			n.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
+			return nil, err
+		}
+
+		notices = append(notices, b)
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	cur.Close(ctx)
+
+	if len(notices) == 0 {
+		return nil, util.ErrNotFound
+	}
+
+	return notices, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/util"
 
@@ -11,38 +12,40 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const classCollection = "classes"
+const ClassCollection = "classes"
 
 type ClassRepository interface {
-	Insert(class *models.ClassData) (*mongo.InsertOneResult, error)
-	Update(class *models.ClassData) (*mongo.UpdateResult, error)
-	GetAll() (classes []*models.ClassData, err error)
-	GetClassById(id string) (class *models.ClassData, err error)
-	GetClassByFilter(filter interface{}) (class *models.ClassData, err error)
-	GetClassByFilterAll(filter interface{}) (classes []*models.ClassData, err error)
-	GetCountOfClassYear(classYear string) (num int, err error)
+	Insert(ctx context.Context, class *models.ClassData) (*mongo.InsertOneResult, error)
+	Update(ctx context.Context, class *models.ClassData) (*mongo.UpdateResult, error)
+	GetAll(ctx context.Context) (classes []*models.ClassData, err error)
+	GetClassById(ctx context.Context, id string) (class *models.ClassData, err error)
+	GetClassByFilter(ctx context.Context, filter interface{}) (class *models.ClassData, err error)
+	GetClassByFilterAll(ctx context.Context, filter interface{}) (classes []*models.ClassData, err error)
+	GetCountOfClassYear(ctx context.Context, classYear string) (num int, err error)
 }
 
 type classRepository struct {
-	c   *mongo.Collection
-	ctx context.Context
+	c      *mongo.Collection
+	logger logger.Logger
 }
 
-func NewClassRepository(conn db.Connection) ClassRepository {
-	return &classRepository{c: conn.DB().Collection(classCollection), ctx: context.TODO()}
+func NewClassRepository(conn db.Connection, logger logger.Logger) ClassRepository {
+	return &classRepository{c: conn.DB().Collection(ClassCollection), logger: logger.With("collection", ClassCollection)}
 }
 
-func (c *classRepository) Insert(class *models.ClassData) (*mongo.InsertOneResult, error) {
-	return c.c.InsertOne(c.ctx, class)
+func (c *classRepository) Insert(ctx context.Context, class *models.ClassData) (*mongo.InsertOneResult, error) {
+	result, err := c.c.InsertOne(ctx, class)
+	return result, domainError(err)
 }
 
-func (c *classRepository) Update(class *models.ClassData) (*mongo.UpdateResult, error) {
-	return c.c.UpdateByID(c.ctx, class.Id, bson.M{"$set": class})
+func (c *classRepository) Update(ctx context.Context, class *models.ClassData) (*mongo.UpdateResult, error) {
+	result, err := c.c.UpdateByID(ctx, class.Id, bson.M{"$set": class})
+	return result, domainError(err)
 }
 
-func (c *classRepository) GetClassById(id string) (class *models.ClassData, err error) {
+func (c *classRepository) GetClassById(ctx context.Context, id string) (class *models.ClassData, err error) {
 	if ok := primitive.IsValidObjectID(id); ok == false {
-		return nil, util.ErrIdIsNotPrimitiveObjectID
+		return nil, util.ErrInvalidID
 	}
 
 	oID, err := primitive.ObjectIDFromHex(id)
@@ -50,27 +53,28 @@ func (c *classRepository) GetClassById(id string) (class *models.ClassData, err
 		return nil, err
 	}
 
-	result := c.c.FindOne(c.ctx, bson.M{"_id": oID})
+	result := c.c.FindOne(ctx, bson.M{"_id": oID})
 
 	err = result.Decode(&class)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return class, result.Err()
 }
 
-func (c *classRepository) GetAll() (classes []*models.ClassData, err error) {
+func (c *classRepository) GetAll(ctx context.Context) (classes []*models.ClassData, err error) {
 
-	cur, err := c.c.Find(c.ctx, bson.M{}, nil)
+	cur, err := c.c.Find(ctx, bson.M{}, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(c.ctx) {
+	for cur.Next(ctx) {
 		var b *models.ClassData
 		err := cur.Decode(&b)
 		if err != nil {
+			c.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -81,38 +85,39 @@ func (c *classRepository) GetAll() (classes []*models.ClassData, err error) {
 		return nil, err
 	}
 
-	cur.Close(c.ctx)
+	cur.Close(ctx)
 
 	if len(classes) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return classes, nil
 }
 
-func (c *classRepository) GetClassByFilter(filter interface{}) (class *models.ClassData, err error) {
+func (c *classRepository) GetClassByFilter(ctx context.Context, filter interface{}) (class *models.ClassData, err error) {
 
-	result := c.c.FindOne(c.ctx, filter)
+	result := c.c.FindOne(ctx, filter)
 
 	err = result.Decode(&class)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return class, result.Err()
 }
 
-func (c *classRepository) GetClassByFilterAll(filter interface{}) (classes []*models.ClassData, err error) {
+func (c *classRepository) GetClassByFilterAll(ctx context.Context, filter interface{}) (classes []*models.ClassData, err error) {
 
-	cur, err := c.c.Find(c.ctx, filter, nil)
+	cur, err := c.c.Find(ctx, filter, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(c.ctx) {
+	for cur.Next(ctx) {
 		var b *models.ClassData
 		err := cur.Decode(&b)
 		if err != nil {
+			c.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -123,24 +128,24 @@ func (c *classRepository) GetClassByFilterAll(filter interface{}) (classes []*mo
 		return nil, err
 	}
 
-	cur.Close(c.ctx)
+	cur.Close(ctx)
 
 	if len(classes) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return classes, nil
 }
 
-func (c *classRepository) GetCountOfClassYear(classYear string) (num int, err error) {
+func (c *classRepository) GetCountOfClassYear(ctx context.Context, classYear string) (num int, err error) {
 
-	cur, err := c.c.Find(c.ctx, bson.M{"class_year": classYear}, nil)
+	cur, err := c.c.Find(ctx, bson.M{"class_year": classYear}, nil)
 	if err != nil {
 		return num, err
 	}
 
 	num = 0
-	for cur.Next(c.ctx) {
+	for cur.Next(ctx) {
 		num++
 	}
 
@@ -148,7 +153,7 @@ func (c *classRepository) GetCountOfClassYear(classYear string) (num int, err er
 		return num, err
 	}
 
-	cur.Close(c.ctx)
+	cur.Close(ctx)
 
 	return num, nil
 }
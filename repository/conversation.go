@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/util"
 
@@ -11,44 +12,47 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const conversationCollection = "conversations"
+const ConversationCollection = "conversations"
 
 type ConversationRepository interface {
-	Insert(conversation *models.Conversation) (*mongo.InsertOneResult, error)
-	Update(conversation *models.Conversation) (*mongo.UpdateResult, error)
-	GetConversationAllByFilter(filter interface{}) (conversations []*models.Conversation, err error)
-	GetConversationById(id string) (conversation *models.Conversation, err error)
-	GetByFilter(filter interface{}) (conversation *models.Conversation, err error)
+	Insert(ctx context.Context, conversation *models.Conversation) (*mongo.InsertOneResult, error)
+	Update(ctx context.Context, conversation *models.Conversation) (*mongo.UpdateResult, error)
+	GetConversationAllByFilter(ctx context.Context, filter interface{}) (conversations []*models.Conversation, err error)
+	GetConversationById(ctx context.Context, id string) (conversation *models.Conversation, err error)
+	GetByFilter(ctx context.Context, filter interface{}) (conversation *models.Conversation, err error)
 }
 
 type conversationRepository struct {
-	c   *mongo.Collection
-	ctx context.Context
+	c      *mongo.Collection
+	logger logger.Logger
 }
 
-func NewConversationRepository(conn db.Connection) ConversationRepository {
-	return &conversationRepository{c: conn.DB().Collection(conversationCollection), ctx: context.TODO()}
+func NewConversationRepository(conn db.Connection, logger logger.Logger) ConversationRepository {
+	return &conversationRepository{c: conn.DB().Collection(ConversationCollection), logger: logger.With("collection", ConversationCollection)}
 }
 
-func (c *conversationRepository) Insert(conversation *models.Conversation) (*mongo.InsertOneResult, error) {
-	return c.c.InsertOne(c.ctx, conversation)
+func (c *conversationRepository) Insert(ctx context.Context, conversation *models.Conversation) (*mongo.InsertOneResult, error) {
+	result, err := c.c.InsertOne(ctx, conversation)
+	return result, domainError(err)
 }
 
-func (c *conversationRepository) Update(conversation *models.Conversation) (*mongo.UpdateResult, error) {
-	return c.c.UpdateByID(c.ctx, conversation.Id, bson.M{"$set": conversation})
+func (c *conversationRepository) Update(ctx context.Context, conversation *models.Conversation) (*mongo.UpdateResult, error) {
+	result, err := c.c.UpdateByID(ctx, conversation.Id, bson.M{"$set": conversation})
+	return result, domainError(err)
 }
 
-func (c *conversationRepository) GetConversationAllByFilter(filter interface{}) (conversations []*models.Conversation, err error) {
+func (c *conversationRepository) GetConversationAllByFilter(ctx context.Context, filter interface{}) (conversations []*models.Conversation, err error) {
 
-	cur, err := c.c.Find(c.ctx, filter, nil)
+	cur, err := c.c.Find(ctx, filter, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(c.ctx) {
+	for cur.Next(ctx) {
 		var b *models.Conversation
 		err := cur.Decode(&b)
 		if err != nil {
+			c.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -59,30 +63,30 @@ func (c *conversationRepository) GetConversationAllByFilter(filter interface{})
 		return nil, err
 	}
 
-	cur.Close(c.ctx)
+	cur.Close(ctx)
 
 	if len(conversations) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return conversations, nil
 }
 
-func (c *conversationRepository) GetByFilter(filter interface{}) (conversation *models.Conversation, err error) {
+func (c *conversationRepository) GetByFilter(ctx context.Context, filter interface{}) (conversation *models.Conversation, err error) {
 
-	result := c.c.FindOne(c.ctx, filter)
+	result := c.c.FindOne(ctx, filter)
 
 	err = result.Decode(&conversation)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return conversation, result.Err()
 }
 
-func (c *conversationRepository) GetConversationById(id string) (conversation *models.Conversation, err error) {
+func (c *conversationRepository) GetConversationById(ctx context.Context, id string) (conversation *models.Conversation, err error) {
 	if ok := primitive.IsValidObjectID(id); ok == false {
-		return nil, util.ErrIdIsNotPrimitiveObjectID
+		return nil, util.ErrInvalidID
 	}
 
 	oID, err := primitive.ObjectIDFromHex(id)
@@ -90,11 +94,11 @@ func (c *conversationRepository) GetConversationById(id string) (conversation *m
 		return nil, err
 	}
 
-	result := c.c.FindOne(c.ctx, bson.M{"_id": oID})
+	result := c.c.FindOne(ctx, bson.M{"_id": oID})
 
 	err = result.Decode(&conversation)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return conversation, result.Err()
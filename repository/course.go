@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/util"
 
@@ -11,36 +12,38 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const courseCollection = "courses"
+const CourseCollection = "courses"
 
 type CourseRepository interface {
-	Insert(course *models.Course) (*mongo.InsertOneResult, error)
-	Update(course *models.Course) (*mongo.UpdateResult, error)
-	GetCourseById(id string) (course *models.Course, err error)
-	GetCourseByFilter(filter interface{}) (course *models.Course, err error)
-	GetCourseAllByFilter(filter interface{}) (courses []*models.Course, err error)
+	Insert(ctx context.Context, course *models.Course) (*mongo.InsertOneResult, error)
+	Update(ctx context.Context, course *models.Course) (*mongo.UpdateResult, error)
+	GetCourseById(ctx context.Context, id string) (course *models.Course, err error)
+	GetCourseByFilter(ctx context.Context, filter interface{}) (course *models.Course, err error)
+	GetCourseAllByFilter(ctx context.Context, filter interface{}) (courses []*models.Course, err error)
 }
 
 type courseRepository struct {
-	c   *mongo.Collection
-	ctx context.Context
+	c      *mongo.Collection
+	logger logger.Logger
 }
 
-func NewCoursesRepository(conn db.Connection) CourseRepository {
-	return &courseRepository{c: conn.DB().Collection(courseCollection), ctx: context.TODO()}
+func NewCoursesRepository(conn db.Connection, logger logger.Logger) CourseRepository {
+	return &courseRepository{c: conn.DB().Collection(CourseCollection), logger: logger.With("collection", CourseCollection)}
 }
 
-func (c *courseRepository) Insert(course *models.Course) (*mongo.InsertOneResult, error) {
-	return c.c.InsertOne(c.ctx, course)
+func (c *courseRepository) Insert(ctx context.Context, course *models.Course) (*mongo.InsertOneResult, error) {
+	result, err := c.c.InsertOne(ctx, course)
+	return result, domainError(err)
 }
 
-func (c *courseRepository) Update(course *models.Course) (*mongo.UpdateResult, error) {
-	return c.c.UpdateByID(c.ctx, course.Id, bson.M{"$set": course})
+func (c *courseRepository) Update(ctx context.Context, course *models.Course) (*mongo.UpdateResult, error) {
+	result, err := c.c.UpdateByID(ctx, course.Id, bson.M{"$set": course})
+	return result, domainError(err)
 }
 
-func (c *courseRepository) GetCourseById(id string) (course *models.Course, err error) {
+func (c *courseRepository) GetCourseById(ctx context.Context, id string) (course *models.Course, err error) {
 	if ok := primitive.IsValidObjectID(id); ok == false {
-		return nil, util.ErrIdIsNotPrimitiveObjectID
+		return nil, util.ErrInvalidID
 	}
 
 	oID, err := primitive.ObjectIDFromHex(id)
@@ -48,27 +51,28 @@ func (c *courseRepository) GetCourseById(id string) (course *models.Course, err
 		return nil, err
 	}
 
-	result := c.c.FindOne(c.ctx, bson.M{"_id": oID})
+	result := c.c.FindOne(ctx, bson.M{"_id": oID})
 
 	err = result.Decode(&course)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return course, result.Err()
 }
 
-func (c *courseRepository) GetCourseAllByFilter(filter interface{}) (courses []*models.Course, err error) {
+func (c *courseRepository) GetCourseAllByFilter(ctx context.Context, filter interface{}) (courses []*models.Course, err error) {
 
-	cur, err := c.c.Find(c.ctx, filter, nil)
+	cur, err := c.c.Find(ctx, filter, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(c.ctx) {
+	for cur.Next(ctx) {
 		var b *models.Course
 		err := cur.Decode(&b)
 		if err != nil {
+			c.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -79,22 +83,22 @@ func (c *courseRepository) GetCourseAllByFilter(filter interface{}) (courses []*
 		return nil, err
 	}
 
-	cur.Close(c.ctx)
+	cur.Close(ctx)
 
 	if len(courses) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return courses, nil
 }
 
-func (c *courseRepository) GetCourseByFilter(filter interface{}) (course *models.Course, err error) {
+func (c *courseRepository) GetCourseByFilter(ctx context.Context, filter interface{}) (course *models.Course, err error) {
 
-	result := c.c.FindOne(c.ctx, filter)
+	result := c.c.FindOne(ctx, filter)
 
 	err = result.Decode(&course)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return course, result.Err()
@@ -3,62 +3,67 @@ package repository
 import (
 	"context"
 	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
+	"school-notification-backend/util"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const courseSummaryCollection = "course-summary"
+const CourseSummaryCollection = "course-summary"
 
 type CourseSummaryRepository interface {
-	Insert(courseSummary *models.CourseSummary) (*mongo.InsertOneResult, error)
-	Update(courseSummary *models.CourseSummary) (*mongo.UpdateResult, error)
-	GetByFilter(filter interface{}) (courseSummary *models.CourseSummary, err error)
-	GetAll() (courseSummaryList []*models.CourseSummary, err error)
-	GetByFilterAll(filter interface{}) (courseSummaryList []*models.CourseSummary, err error)
+	Insert(ctx context.Context, courseSummary *models.CourseSummary) (*mongo.InsertOneResult, error)
+	Update(ctx context.Context, courseSummary *models.CourseSummary) (*mongo.UpdateResult, error)
+	GetByFilter(ctx context.Context, filter interface{}) (courseSummary *models.CourseSummary, err error)
+	GetAll(ctx context.Context) (courseSummaryList []*models.CourseSummary, err error)
+	GetByFilterAll(ctx context.Context, filter interface{}) (courseSummaryList []*models.CourseSummary, err error)
 }
 
 type courseSummaryRepository struct {
-	c   *mongo.Collection
-	ctx context.Context
+	c      *mongo.Collection
+	logger logger.Logger
 }
 
-func NewCourseSummaryRepository(conn db.Connection) CourseSummaryRepository {
-	return &courseSummaryRepository{c: conn.DB().Collection(courseSummaryCollection), ctx: context.TODO()}
+func NewCourseSummaryRepository(conn db.Connection, logger logger.Logger) CourseSummaryRepository {
+	return &courseSummaryRepository{c: conn.DB().Collection(CourseSummaryCollection), logger: logger.With("collection", CourseSummaryCollection)}
 }
 
-func (c *courseSummaryRepository) Insert(courseSummary *models.CourseSummary) (*mongo.InsertOneResult, error) {
-	return c.c.InsertOne(c.ctx, courseSummary)
+func (c *courseSummaryRepository) Insert(ctx context.Context, courseSummary *models.CourseSummary) (*mongo.InsertOneResult, error) {
+	result, err := c.c.InsertOne(ctx, courseSummary)
+	return result, domainError(err)
 }
 
-func (c *courseSummaryRepository) Update(courseSummary *models.CourseSummary) (*mongo.UpdateResult, error) {
-	return c.c.UpdateByID(c.ctx, courseSummary.Id, bson.M{"$set": courseSummary})
+func (c *courseSummaryRepository) Update(ctx context.Context, courseSummary *models.CourseSummary) (*mongo.UpdateResult, error) {
+	result, err := c.c.UpdateByID(ctx, courseSummary.Id, bson.M{"$set": courseSummary})
+	return result, domainError(err)
 }
 
-func (c *courseSummaryRepository) GetByFilter(filter interface{}) (courseSummary *models.CourseSummary, err error) {
+func (c *courseSummaryRepository) GetByFilter(ctx context.Context, filter interface{}) (courseSummary *models.CourseSummary, err error) {
 
-	result := c.c.FindOne(c.ctx, filter)
+	result := c.c.FindOne(ctx, filter)
 
 	err = result.Decode(&courseSummary)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return courseSummary, result.Err()
 }
 
-func (c *courseSummaryRepository) GetByFilterAll(filter interface{}) (courseSummaryList []*models.CourseSummary, err error) {
+func (c *courseSummaryRepository) GetByFilterAll(ctx context.Context, filter interface{}) (courseSummaryList []*models.CourseSummary, err error) {
 
-	cur, err := c.c.Find(c.ctx, filter, nil)
+	cur, err := c.c.Find(ctx, filter, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(c.ctx) {
+	for cur.Next(ctx) {
 		var b *models.CourseSummary
 		err := cur.Decode(&b)
 		if err != nil {
+			c.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -69,26 +74,27 @@ func (c *courseSummaryRepository) GetByFilterAll(filter interface{}) (courseSumm
 		return nil, err
 	}
 
-	cur.Close(c.ctx)
+	cur.Close(ctx)
 
 	if len(courseSummaryList) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return courseSummaryList, nil
 }
 
-func (c *courseSummaryRepository) GetAll() (courseSummaryList []*models.CourseSummary, err error) {
+func (c *courseSummaryRepository) GetAll(ctx context.Context) (courseSummaryList []*models.CourseSummary, err error) {
 
-	cur, err := c.c.Find(c.ctx, bson.M{}, nil)
+	cur, err := c.c.Find(ctx, bson.M{}, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(c.ctx) {
+	for cur.Next(ctx) {
 		var b *models.CourseSummary
 		err := cur.Decode(&b)
 		if err != nil {
+			c.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -99,10 +105,10 @@ func (c *courseSummaryRepository) GetAll() (courseSummaryList []*models.CourseSu
 		return nil, err
 	}
 
-	cur.Close(c.ctx)
+	cur.Close(ctx)
 
 	if len(courseSummaryList) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return courseSummaryList, nil
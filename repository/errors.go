@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"school-notification-backend/util"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// error of mongo driver to error of util, caller check it with errors.Is and not depend on driver
+func domainError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return util.ErrNotFound
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: %v", util.ErrConflict, err)
+	}
+
+	return err
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/util"
 
@@ -11,37 +12,39 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const faceDetectionCollection = "face-detection"
+const FaceDetectionCollection = "face-detection"
 
 type FaceDetectionRepository interface {
-	Insert(faceDetectData *models.FaceDetectData) (*mongo.InsertOneResult, error)
-	Update(faceDetectData *models.FaceDetectData) (*mongo.UpdateResult, error)
-	GetById(id string) (faceDetectData *models.FaceDetectData, err error)
-	GetAll() (faceDetectDataList []*models.FaceDetectData, err error)
-	GetByFilter(filter interface{}) (faceDetectData *models.FaceDetectData, err error)
-	GetByFilterAll(filter interface{}) (faceDetectDataList []*models.FaceDetectData, err error)
+	Insert(ctx context.Context, faceDetectData *models.FaceDetectData) (*mongo.InsertOneResult, error)
+	Update(ctx context.Context, faceDetectData *models.FaceDetectData) (*mongo.UpdateResult, error)
+	GetById(ctx context.Context, id string) (faceDetectData *models.FaceDetectData, err error)
+	GetAll(ctx context.Context) (faceDetectDataList []*models.FaceDetectData, err error)
+	GetByFilter(ctx context.Context, filter interface{}) (faceDetectData *models.FaceDetectData, err error)
+	GetByFilterAll(ctx context.Context, filter interface{}) (faceDetectDataList []*models.FaceDetectData, err error)
 }
 
 type faceDetectionRepository struct {
-	c   *mongo.Collection
-	ctx context.Context
+	c      *mongo.Collection
+	logger logger.Logger
 }
 
-func NewFaceDetectionRepository(conn db.Connection) FaceDetectionRepository {
-	return &faceDetectionRepository{c: conn.DB().Collection(faceDetectionCollection), ctx: context.TODO()}
+func NewFaceDetectionRepository(conn db.Connection, logger logger.Logger) FaceDetectionRepository {
+	return &faceDetectionRepository{c: conn.DB().Collection(FaceDetectionCollection), logger: logger.With("collection", FaceDetectionCollection)}
 }
 
-func (c *faceDetectionRepository) Insert(faceDetectData *models.FaceDetectData) (*mongo.InsertOneResult, error) {
-	return c.c.InsertOne(c.ctx, faceDetectData)
+func (c *faceDetectionRepository) Insert(ctx context.Context, faceDetectData *models.FaceDetectData) (*mongo.InsertOneResult, error) {
+	result, err := c.c.InsertOne(ctx, faceDetectData)
+	return result, domainError(err)
 }
 
-func (c *faceDetectionRepository) Update(faceDetectData *models.FaceDetectData) (*mongo.UpdateResult, error) {
-	return c.c.UpdateByID(c.ctx, faceDetectData.Id, bson.M{"$set": faceDetectData})
+func (c *faceDetectionRepository) Update(ctx context.Context, faceDetectData *models.FaceDetectData) (*mongo.UpdateResult, error) {
+	result, err := c.c.UpdateByID(ctx, faceDetectData.Id, bson.M{"$set": faceDetectData})
+	return result, domainError(err)
 }
 
-func (f *faceDetectionRepository) GetById(id string) (faceDetectData *models.FaceDetectData, err error) {
+func (f *faceDetectionRepository) GetById(ctx context.Context, id string) (faceDetectData *models.FaceDetectData, err error) {
 	if ok := primitive.IsValidObjectID(id); ok == false {
-		return nil, util.ErrIdIsNotPrimitiveObjectID
+		return nil, util.ErrInvalidID
 	}
 
 	oID, err := primitive.ObjectIDFromHex(id)
@@ -49,27 +52,28 @@ func (f *faceDetectionRepository) GetById(id string) (faceDetectData *models.Fac
 		return nil, err
 	}
 
-	result := f.c.FindOne(f.ctx, bson.M{"_id": oID})
+	result := f.c.FindOne(ctx, bson.M{"_id": oID})
 
 	err = result.Decode(&faceDetectData)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return faceDetectData, result.Err()
 }
 
-func (f *faceDetectionRepository) GetAll() (faceDetectDataList []*models.FaceDetectData, err error) {
+func (f *faceDetectionRepository) GetAll(ctx context.Context) (faceDetectDataList []*models.FaceDetectData, err error) {
 
-	cur, err := f.c.Find(f.ctx, bson.M{}, nil)
+	cur, err := f.c.Find(ctx, bson.M{}, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(f.ctx) {
+	for cur.Next(ctx) {
 		var b *models.FaceDetectData
 		err := cur.Decode(&b)
 		if err != nil {
+			f.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -80,38 +84,39 @@ func (f *faceDetectionRepository) GetAll() (faceDetectDataList []*models.FaceDet
 		return nil, err
 	}
 
-	cur.Close(f.ctx)
+	cur.Close(ctx)
 
 	if len(faceDetectDataList) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return faceDetectDataList, nil
 }
 
-func (f *faceDetectionRepository) GetByFilter(filter interface{}) (faceDetectData *models.FaceDetectData, err error) {
+func (f *faceDetectionRepository) GetByFilter(ctx context.Context, filter interface{}) (faceDetectData *models.FaceDetectData, err error) {
 
-	result := f.c.FindOne(f.ctx, filter)
+	result := f.c.FindOne(ctx, filter)
 
 	err = result.Decode(&faceDetectData)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return faceDetectData, result.Err()
 }
 
-func (f *faceDetectionRepository) GetByFilterAll(filter interface{}) (faceDetectDataList []*models.FaceDetectData, err error) {
+func (f *faceDetectionRepository) GetByFilterAll(ctx context.Context, filter interface{}) (faceDetectDataList []*models.FaceDetectData, err error) {
 
-	cur, err := f.c.Find(f.ctx, filter, nil)
+	cur, err := f.c.Find(ctx, filter, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(f.ctx) {
+	for cur.Next(ctx) {
 		var b *models.FaceDetectData
 		err := cur.Decode(&b)
 		if err != nil {
+			f.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -122,10 +127,10 @@ func (f *faceDetectionRepository) GetByFilterAll(filter interface{}) (faceDetect
 		return nil, err
 	}
 
-	cur.Close(f.ctx)
+	cur.Close(ctx)
 
 	if len(faceDetectDataList) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return faceDetectDataList, nil
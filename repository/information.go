@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/util"
 
@@ -11,35 +12,37 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const informationCollection = "informations"
+const InformationCollection = "informations"
 
 type InformationRepository interface {
-	Insert(information *models.Information) (*mongo.InsertOneResult, error)
-	Update(information *models.Information) (*mongo.UpdateResult, error)
-	GetAll() (informations []*models.Information, err error)
-	GetInformationById(id string) (information *models.Information, err error)
+	Insert(ctx context.Context, information *models.Information) (*mongo.InsertOneResult, error)
+	Update(ctx context.Context, information *models.Information) (*mongo.UpdateResult, error)
+	GetAll(ctx context.Context) (informations []*models.Information, err error)
+	GetInformationById(ctx context.Context, id string) (information *models.Information, err error)
 }
 
 type informationRepository struct {
-	c   *mongo.Collection
-	ctx context.Context
+	c      *mongo.Collection
+	logger logger.Logger
 }
 
-func NewInformationRepository(conn db.Connection) InformationRepository {
-	return &informationRepository{c: conn.DB().Collection(informationCollection), ctx: context.TODO()}
+func NewInformationRepository(conn db.Connection, logger logger.Logger) InformationRepository {
+	return &informationRepository{c: conn.DB().Collection(InformationCollection), logger: logger.With("collection", InformationCollection)}
 }
 
-func (i *informationRepository) Insert(information *models.Information) (*mongo.InsertOneResult, error) {
-	return i.c.InsertOne(i.ctx, information)
+func (i *informationRepository) Insert(ctx context.Context, information *models.Information) (*mongo.InsertOneResult, error) {
+	result, err := i.c.InsertOne(ctx, information)
+	return result, domainError(err)
 }
 
-func (i *informationRepository) Update(information *models.Information) (*mongo.UpdateResult, error) {
-	return i.c.UpdateByID(i.ctx, information.Id, bson.M{"$set": information})
+func (i *informationRepository) Update(ctx context.Context, information *models.Information) (*mongo.UpdateResult, error) {
+	result, err := i.c.UpdateByID(ctx, information.Id, bson.M{"$set": information})
+	return result, domainError(err)
 }
 
-func (i *informationRepository) GetInformationById(id string) (information *models.Information, err error) {
+func (i *informationRepository) GetInformationById(ctx context.Context, id string) (information *models.Information, err error) {
 	if ok := primitive.IsValidObjectID(id); ok == false {
-		return nil, util.ErrIdIsNotPrimitiveObjectID
+		return nil, util.ErrInvalidID
 	}
 
 	oID, err := primitive.ObjectIDFromHex(id)
@@ -47,27 +50,28 @@ func (i *informationRepository) GetInformationById(id string) (information *mode
 		return nil, err
 	}
 
-	result := i.c.FindOne(i.ctx, bson.M{"_id": oID})
+	result := i.c.FindOne(ctx, bson.M{"_id": oID})
 
 	err = result.Decode(&information)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return information, result.Err()
 }
 
-func (i *informationRepository) GetAll() (informations []*models.Information, err error) {
+func (i *informationRepository) GetAll(ctx context.Context) (informations []*models.Information, err error) {
 
-	cur, err := i.c.Find(i.ctx, bson.M{}, nil)
+	cur, err := i.c.Find(ctx, bson.M{}, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(i.ctx) {
+	for cur.Next(ctx) {
 		var b *models.Information
 		err := cur.Decode(&b)
 		if err != nil {
+			i.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -78,10 +82,10 @@ func (i *informationRepository) GetAll() (informations []*models.Information, er
 		return nil, err
 	}
 
-	cur.Close(i.ctx)
+	cur.Close(ctx)
 
 	if len(informations) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return informations, nil
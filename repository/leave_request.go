@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"school-notification-backend/db"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const LeaveRequestCollection = "leave-requests"
+
+type LeaveRequestRepository interface {
+	Insert(ctx context.Context, leaveRequest *models.LeaveRequest) (*mongo.InsertOneResult, error)
+	Update(ctx context.Context, leaveRequest *models.LeaveRequest) (*mongo.UpdateResult, error)
+	GetById(ctx context.Context, id string) (leaveRequest *models.LeaveRequest, err error)
+	GetByFilterAll(ctx context.Context, filter interface{}) (leaveRequests []*models.LeaveRequest, err error)
+}
+
+type leaveRequestRepository struct {
+	c      *mongo.Collection
+	logger logger.Logger
+}
+
+func NewLeaveRequestRepository(conn db.Connection, logger logger.Logger) LeaveRequestRepository {
+	return &leaveRequestRepository{c: conn.DB().Collection(LeaveRequestCollection), logger: logger.With("collection", LeaveRequestCollection)}
+}
+
+func (l *leaveRequestRepository) Insert(ctx context.Context, leaveRequest *models.LeaveRequest) (*mongo.InsertOneResult, error) {
+	result, err := l.c.InsertOne(ctx, leaveRequest)
+	return result, domainError(err)
+}
+
+func (l *leaveRequestRepository) Update(ctx context.Context, leaveRequest *models.LeaveRequest) (*mongo.UpdateResult, error) {
+	result, err := l.c.UpdateByID(ctx, leaveRequest.Id, bson.M{"$set": leaveRequest})
+	return result, domainError(err)
+}
+
+func (l *leaveRequestRepository) GetById(ctx context.Context, id string) (leaveRequest *models.LeaveRequest, err error) {
+	if ok := primitive.IsValidObjectID(id); ok == false {
+		return nil, util.ErrInvalidID
+	}
+
+	oID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := l.c.FindOne(ctx, bson.M{"_id": oID})
+
+	err = result.Decode(&leaveRequest)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return leaveRequest, result.Err()
+}
+
+func (l *leaveRequestRepository) GetByFilterAll(ctx context.Context, filter interface{}) (leaveRequests []*models.LeaveRequest, err error) {
+
+	cur, err := l.c.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+
+	for cur.Next(ctx) {
+		var b *models.LeaveRequest
+		err := cur.Decode(&b)
+		if err != nil {
+			l.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
+			return nil, err
+		}
+
+		leaveRequests = append(leaveRequests, b)
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	cur.Close(ctx)
+
+	if len(leaveRequests) == 0 {
+		return nil, util.ErrNotFound
+	}
+
+	return leaveRequests, nil
+}
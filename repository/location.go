@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/util"
 
@@ -11,36 +12,38 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const locationCollection = "locations"
+const LocationCollection = "locations"
 
 type LocationRepository interface {
-	Insert(location *models.Location) (*mongo.InsertOneResult, error)
-	Update(location *models.Location) (*mongo.UpdateResult, error)
-	GetAll() (locations []*models.Location, err error)
-	GetLocationById(id string) (location *models.Location, err error)
-	GetLocationByFilter(filter interface{}) (location *models.Location, err error)
+	Insert(ctx context.Context, location *models.Location) (*mongo.InsertOneResult, error)
+	Update(ctx context.Context, location *models.Location) (*mongo.UpdateResult, error)
+	GetAll(ctx context.Context) (locations []*models.Location, err error)
+	GetLocationById(ctx context.Context, id string) (location *models.Location, err error)
+	GetLocationByFilter(ctx context.Context, filter interface{}) (location *models.Location, err error)
 }
 
 type locationRepository struct {
-	c   *mongo.Collection
-	ctx context.Context
+	c      *mongo.Collection
+	logger logger.Logger
 }
 
-func NewLocationRepository(conn db.Connection) LocationRepository {
-	return &locationRepository{c: conn.DB().Collection(locationCollection), ctx: context.TODO()}
+func NewLocationRepository(conn db.Connection, logger logger.Logger) LocationRepository {
+	return &locationRepository{c: conn.DB().Collection(LocationCollection), logger: logger.With("collection", LocationCollection)}
 }
 
-func (l *locationRepository) Insert(location *models.Location) (*mongo.InsertOneResult, error) {
-	return l.c.InsertOne(l.ctx, location)
+func (l *locationRepository) Insert(ctx context.Context, location *models.Location) (*mongo.InsertOneResult, error) {
+	result, err := l.c.InsertOne(ctx, location)
+	return result, domainError(err)
 }
 
-func (l *locationRepository) Update(location *models.Location) (*mongo.UpdateResult, error) {
-	return l.c.UpdateByID(l.ctx, location.Id, bson.M{"$set": location})
+func (l *locationRepository) Update(ctx context.Context, location *models.Location) (*mongo.UpdateResult, error) {
+	result, err := l.c.UpdateByID(ctx, location.Id, bson.M{"$set": location})
+	return result, domainError(err)
 }
 
-func (l *locationRepository) GetLocationById(id string) (location *models.Location, err error) {
+func (l *locationRepository) GetLocationById(ctx context.Context, id string) (location *models.Location, err error) {
 	if ok := primitive.IsValidObjectID(id); ok == false {
-		return nil, util.ErrIdIsNotPrimitiveObjectID
+		return nil, util.ErrInvalidID
 	}
 
 	oID, err := primitive.ObjectIDFromHex(id)
@@ -48,27 +51,28 @@ func (l *locationRepository) GetLocationById(id string) (location *models.Locati
 		return nil, err
 	}
 
-	result := l.c.FindOne(l.ctx, bson.M{"_id": oID})
+	result := l.c.FindOne(ctx, bson.M{"_id": oID})
 
 	err = result.Decode(&location)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return location, result.Err()
 }
 
-func (l *locationRepository) GetAll() (locations []*models.Location, err error) {
+func (l *locationRepository) GetAll(ctx context.Context) (locations []*models.Location, err error) {
 
-	cur, err := l.c.Find(l.ctx, bson.M{}, nil)
+	cur, err := l.c.Find(ctx, bson.M{}, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(l.ctx) {
+	for cur.Next(ctx) {
 		var b *models.Location
 		err := cur.Decode(&b)
 		if err != nil {
+			l.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -79,22 +83,22 @@ func (l *locationRepository) GetAll() (locations []*models.Location, err error)
 		return nil, err
 	}
 
-	cur.Close(l.ctx)
+	cur.Close(ctx)
 
 	if len(locations) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return locations, nil
 }
 
-func (l *locationRepository) GetLocationByFilter(filter interface{}) (location *models.Location, err error) {
+func (l *locationRepository) GetLocationByFilter(ctx context.Context, filter interface{}) (location *models.Location, err error) {
 
-	result := l.c.FindOne(l.ctx, filter)
+	result := l.c.FindOne(ctx, filter)
 
 	err = result.Decode(&location)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return location, result.Err()
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"school-notification-backend/db"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const LoginAttemptCollection = "login_attempts"
+
+type LoginAttemptRepository interface {
+	Insert(ctx context.Context, attempt *models.LoginAttempt) (*mongo.InsertOneResult, error)
+	GetByFilterAll(ctx context.Context, filter interface{}, limit int64) (attempts []*models.LoginAttempt, err error)
+}
+
+type loginAttemptRepository struct {
+	c      *mongo.Collection
+	logger logger.Logger
+}
+
+func NewLoginAttemptRepository(conn db.Connection, logger logger.Logger) LoginAttemptRepository {
+	return &loginAttemptRepository{c: conn.DB().Collection(LoginAttemptCollection), logger: logger.With("collection", LoginAttemptCollection)}
+}
+
+func (l *loginAttemptRepository) Insert(ctx context.Context, attempt *models.LoginAttempt) (*mongo.InsertOneResult, error) {
+	result, err := l.c.InsertOne(ctx, attempt)
+	return result, domainError(err)
+}
+
+// newest first
+func (l *loginAttemptRepository) GetByFilterAll(ctx context.Context, filter interface{}, limit int64) (attempts []*models.LoginAttempt, err error) {
+
+	cur, err := l.c.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	for cur.Next(ctx) {
+		var b *models.LoginAttempt
+		err := cur.Decode(&b)
+		if err != nil {
+			l.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
+			return nil, err
+		}
+
+		attempts = append(attempts, b)
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	cur.Close(ctx)
+
+	if len(attempts) == 0 {
+		return nil, util.ErrNotFound
+	}
+
+	return attempts, nil
+}
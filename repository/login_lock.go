@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"school-notification-backend/db"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const LoginLockCollection = "login_locks"
+
+type LoginLockRepository interface {
+	Upsert(ctx context.Context, lock *models.LoginLock) (*mongo.UpdateResult, error)
+	Delete(ctx context.Context, lockType string, value string) (*mongo.DeleteResult, error)
+	GetByTypeAndValue(ctx context.Context, lockType string, value string) (lock *models.LoginLock, err error)
+	GetByFilterAll(ctx context.Context, filter interface{}) (locks []*models.LoginLock, err error)
+}
+
+type loginLockRepository struct {
+	c      *mongo.Collection
+	logger logger.Logger
+}
+
+func NewLoginLockRepository(conn db.Connection, logger logger.Logger) LoginLockRepository {
+	return &loginLockRepository{c: conn.DB().Collection(LoginLockCollection), logger: logger.With("collection", LoginLockCollection)}
+}
+
+func (l *loginLockRepository) Upsert(ctx context.Context, lock *models.LoginLock) (*mongo.UpdateResult, error) {
+	result, err := l.c.UpdateByID(ctx, lock.Id, bson.M{"$set": lock}, options.Update().SetUpsert(true))
+	return result, domainError(err)
+}
+
+func (l *loginLockRepository) Delete(ctx context.Context, lockType string, value string) (*mongo.DeleteResult, error) {
+	return l.c.DeleteMany(ctx, bson.M{"type": lockType, "value": value})
+}
+
+func (l *loginLockRepository) GetByTypeAndValue(ctx context.Context, lockType string, value string) (lock *models.LoginLock, err error) {
+	err = l.c.FindOne(ctx, bson.M{"type": lockType, "value": value}).Decode(&lock)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return lock, nil
+}
+
+func (l *loginLockRepository) GetByFilterAll(ctx context.Context, filter interface{}) (locks []*models.LoginLock, err error) {
+
+	cur, err := l.c.Find(ctx, filter, options.Find().SetSort(bson.M{"updated_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+
+	for cur.Next(ctx) {
+		var b *models.LoginLock
+		err := cur.Decode(&b)
+		if err != nil {
+			l.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
+			return nil, err
+		}
+
+		locks = append(locks, b)
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	cur.Close(ctx)
+
+	if len(locks) == 0 {
+		return nil, util.ErrNotFound
+	}
+
+	return locks, nil
+}
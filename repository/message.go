@@ -3,48 +3,53 @@ package repository
 import (
 	"context"
 	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
+	"school-notification-backend/util"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const messageCollection = "messages"
+const MessageCollection = "messages"
 
 type MessageRepository interface {
-	Insert(message *models.Message) (*mongo.InsertOneResult, error)
-	Update(message *models.Message) (*mongo.UpdateResult, error)
-	GetConversationAllByFilter(filter interface{}) (messages []*models.Message, err error)
+	Insert(ctx context.Context, message *models.Message) (*mongo.InsertOneResult, error)
+	Update(ctx context.Context, message *models.Message) (*mongo.UpdateResult, error)
+	GetConversationAllByFilter(ctx context.Context, filter interface{}) (messages []*models.Message, err error)
 }
 
 type messageRepository struct {
-	c   *mongo.Collection
-	ctx context.Context
+	c      *mongo.Collection
+	logger logger.Logger
 }
 
-func NewMessageRepository(conn db.Connection) MessageRepository {
-	return &messageRepository{c: conn.DB().Collection(messageCollection), ctx: context.TODO()}
+func NewMessageRepository(conn db.Connection, logger logger.Logger) MessageRepository {
+	return &messageRepository{c: conn.DB().Collection(MessageCollection), logger: logger.With("collection", MessageCollection)}
 }
 
-func (m *messageRepository) Insert(message *models.Message) (*mongo.InsertOneResult, error) {
-	return m.c.InsertOne(m.ctx, message)
+func (m *messageRepository) Insert(ctx context.Context, message *models.Message) (*mongo.InsertOneResult, error) {
+	result, err := m.c.InsertOne(ctx, message)
+	return result, domainError(err)
 }
 
-func (m *messageRepository) Update(message *models.Message) (*mongo.UpdateResult, error) {
-	return m.c.UpdateByID(m.ctx, message.Id, bson.M{"$set": message})
+func (m *messageRepository) Update(ctx context.Context, message *models.Message) (*mongo.UpdateResult, error) {
+	result, err := m.c.UpdateByID(ctx, message.Id, bson.M{"$set": message})
+	return result, domainError(err)
 }
 
-func (m *messageRepository) GetConversationAllByFilter(filter interface{}) (messages []*models.Message, err error) {
+func (m *messageRepository) GetConversationAllByFilter(ctx context.Context, filter interface{}) (messages []*models.Message, err error) {
 
-	cur, err := m.c.Find(m.ctx, filter, nil)
+	cur, err := m.c.Find(ctx, filter, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(m.ctx) {
+	for cur.Next(ctx) {
 		var b *models.Message
 		err := cur.Decode(&b)
 		if err != nil {
+			m.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -55,10 +60,10 @@ func (m *messageRepository) GetConversationAllByFilter(filter interface{}) (mess
 		return nil, err
 	}
 
-	cur.Close(m.ctx)
+	cur.Close(ctx)
 
 	if len(messages) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return messages, nil
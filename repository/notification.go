@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"school-notification-backend/db"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const NotificationCollection = "notifications"
+
+type NotificationRepository interface {
+	Insert(ctx context.Context, notification *models.Notification) (*mongo.InsertOneResult, error)
+	InsertMany(ctx context.Context, notifications []*models.Notification) (*mongo.InsertManyResult, error)
+	Update(ctx context.Context, notification *models.Notification) (*mongo.UpdateResult, error)
+	UpdateMany(ctx context.Context, filter interface{}, update interface{}) (*mongo.UpdateResult, error)
+	GetById(ctx context.Context, id string) (notification *models.Notification, err error)
+	GetByFilterAll(ctx context.Context, filter interface{}) (notifications []*models.Notification, err error)
+	CountByFilter(ctx context.Context, filter interface{}) (int64, error)
+}
+
+type notificationRepository struct {
+	c      *mongo.Collection
+	logger logger.Logger
+}
+
+func NewNotificationRepository(conn db.Connection, logger logger.Logger) NotificationRepository {
+	return &notificationRepository{c: conn.DB().Collection(NotificationCollection), logger: logger.With("collection", NotificationCollection)}
+}
+
+func (n *notificationRepository) Insert(ctx context.Context, notification *models.Notification) (*mongo.InsertOneResult, error) {
+	result, err := n.c.InsertOne(ctx, notification)
+	return result, domainError(err)
+}
+
+func (n *notificationRepository) InsertMany(ctx context.Context, notifications []*models.Notification) (*mongo.InsertManyResult, error) {
+	docs := make([]interface{}, len(notifications))
+	for i, v := range notifications {
+		docs[i] = v
+	}
+
+	result, err := n.c.InsertMany(ctx, docs)
+	return result, domainError(err)
+}
+
+func (n *notificationRepository) Update(ctx context.Context, notification *models.Notification) (*mongo.UpdateResult, error) {
+	result, err := n.c.UpdateByID(ctx, notification.Id, bson.M{"$set": notification})
+	return result, domainError(err)
+}
+
+func (n *notificationRepository) UpdateMany(ctx context.Context, filter interface{}, update interface{}) (*mongo.UpdateResult, error) {
+	result, err := n.c.UpdateMany(ctx, filter, bson.M{"$set": update})
+	return result, domainError(err)
+}
+
+func (n *notificationRepository) GetById(ctx context.Context, id string) (notification *models.Notification, err error) {
+	if ok := primitive.IsValidObjectID(id); ok == false {
+		return nil, util.ErrInvalidID
+	}
+
+	oID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := n.c.FindOne(ctx, bson.M{"_id": oID})
+
+	err = result.Decode(&notification)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return notification, result.Err()
+}
+
+func (n *notificationRepository) GetByFilterAll(ctx context.Context, filter interface{}) (notifications []*models.Notification, err error) {
+
+	cur, err := n.c.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+
+	for cur.Next(ctx) {
+		var b *models.Notification
+		err := cur.Decode(&b)
+		if err != nil {
+			n.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
+			return nil, err
+		}
+
+		notifications = append(notifications, b)
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	cur.Close(ctx)
+
+	if len(notifications) == 0 {
+		return nil, util.ErrNotFound
+	}
+
+	return notifications, nil
+}
+
+func (n *notificationRepository) CountByFilter(ctx context.Context, filter interface{}) (int64, error) {
+	return n.c.CountDocuments(ctx, filter)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/util"
 
@@ -11,69 +12,84 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const profileCollection = "profiles"
+const ProfileCollection = "profiles"
 
 type ProfileRepository interface {
-	Insert(profile interface{}) (*mongo.InsertOneResult, error)
-	Update(id primitive.ObjectID, filter interface{}) (*mongo.UpdateResult, error)
-	GetProfileByFilterForCheckExists(filter interface{}) (err error)
-	GetProfileById(filter interface{}, role string) (profile interface{}, err error)
-	GetAll(role string) (profiles []interface{}, err error)
-	GetProfileByFilterAll(filter interface{}, role string) (profiles []interface{}, err error)
-	GetProfileByIdHex(id string) (profile *models.ProfileForChat, err error)
+	Insert(ctx context.Context, profile interface{}) (*mongo.InsertOneResult, error)
+	Update(ctx context.Context, id primitive.ObjectID, filter interface{}) (*mongo.UpdateResult, error)
+	GetProfileByFilterForCheckExists(ctx context.Context, filter interface{}) (err error)
+	GetProfileById(ctx context.Context, filter interface{}, role string) (profile interface{}, err error)
+	GetAll(ctx context.Context, role string) (profiles []interface{}, err error)
+	GetProfileByFilterAll(ctx context.Context, filter interface{}, role string) (profiles []interface{}, err error)
+	GetProfileByIdHex(ctx context.Context, id string) (profile *models.ProfileForChat, err error)
 }
 
 type profileRepository struct {
-	c   *mongo.Collection
-	ctx context.Context
+	c      *mongo.Collection
+	logger logger.Logger
 }
 
-func NewProfileRepository(conn db.Connection) ProfileRepository {
-	return &profileRepository{c: conn.DB().Collection(profileCollection), ctx: context.TODO()}
+func NewProfileRepository(conn db.Connection, logger logger.Logger) ProfileRepository {
+	return &profileRepository{c: conn.DB().Collection(ProfileCollection), logger: logger.With("collection", ProfileCollection)}
 }
 
-func (p *profileRepository) Insert(profile interface{}) (*mongo.InsertOneResult, error) {
-	return p.c.InsertOne(p.ctx, profile)
+func (p *profileRepository) Insert(ctx context.Context, profile interface{}) (*mongo.InsertOneResult, error) {
+	result, err := p.c.InsertOne(ctx, profile)
+	return result, domainError(err)
 }
 
-func (p *profileRepository) Update(id primitive.ObjectID, filter interface{}) (*mongo.UpdateResult, error) {
-	return p.c.UpdateByID(p.ctx, id, bson.M{"$set": filter})
+func (p *profileRepository) Update(ctx context.Context, id primitive.ObjectID, filter interface{}) (*mongo.UpdateResult, error) {
+	result, err := p.c.UpdateByID(ctx, id, bson.M{"$set": filter})
+	return result, domainError(err)
 }
 
-func (p *profileRepository) GetProfileByFilterForCheckExists(filter interface{}) (err error) {
+func (p *profileRepository) GetProfileByFilterForCheckExists(ctx context.Context, filter interface{}) (err error) {
 
-	result := p.c.FindOne(p.ctx, filter)
+	result := p.c.FindOne(ctx, filter)
 
 	if result.Err() != nil {
-		return result.Err()
+		return domainError(result.Err())
 	}
 
 	return nil
 }
 
-// func (p *profileRepository) GetAll(role string) (profiles []*models.Profile, err error) {
-func (p *profileRepository) GetAll(role string) (profiles []interface{}, err error) {
+// func (p *profileRepository) GetAll(ctx context.Context, role string) (profiles []*models.Profile, err error) {
+func (p *profileRepository) GetAll(ctx context.Context, role string) (profiles []interface{}, err error) {
 
-	cur, err := p.c.Find(p.ctx, bson.M{"role": role}, nil)
+	cur, err := p.c.Find(ctx, bson.M{"role": role}, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	if role == "teacher" {
-		for cur.Next(p.ctx) {
+		for cur.Next(ctx) {
 			var b *models.ProfileTeacher
 			err := cur.Decode(&b)
 			if err != nil {
+				p.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 				return nil, err
 			}
 
 			profiles = append(profiles, b)
 		}
 	} else if role == "student" {
-		for cur.Next(p.ctx) {
+		for cur.Next(ctx) {
 			var b *models.ProfileStudent
 			err := cur.Decode(&b)
 			if err != nil {
+				p.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
+				return nil, err
+			}
+
+			profiles = append(profiles, b)
+		}
+	} else if role == "parent" {
+		for cur.Next(ctx) {
+			var b *models.ProfileParent
+			err := cur.Decode(&b)
+			if err != nil {
+				p.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 				return nil, err
 			}
 
@@ -85,31 +101,38 @@ func (p *profileRepository) GetAll(role string) (profiles []interface{}, err err
 		return nil, err
 	}
 
-	cur.Close(p.ctx)
+	cur.Close(ctx)
 
 	if len(profiles) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return profiles, nil
 }
 
-func (p *profileRepository) GetProfileById(filter interface{}, role string) (profile interface{}, err error) {
+func (p *profileRepository) GetProfileById(ctx context.Context, filter interface{}, role string) (profile interface{}, err error) {
 
-	result := p.c.FindOne(p.ctx, filter)
+	result := p.c.FindOne(ctx, filter)
 
 	if role == "teacher" {
 		p := models.ProfileTeacher{}
 		err = result.Decode(&p)
 		if err != nil {
-			return nil, err
+			return nil, domainError(err)
 		}
 		profile = p
 	} else if role == "student" {
 		p := models.ProfileStudent{}
 		err = result.Decode(&p)
 		if err != nil {
-			return nil, err
+			return nil, domainError(err)
+		}
+		profile = p
+	} else if role == "parent" {
+		p := models.ProfileParent{}
+		err = result.Decode(&p)
+		if err != nil {
+			return nil, domainError(err)
 		}
 		profile = p
 	}
@@ -117,10 +140,10 @@ func (p *profileRepository) GetProfileById(filter interface{}, role string) (pro
 	return profile, result.Err()
 }
 
-func (p *profileRepository) GetProfileByIdHex(id string) (profile *models.ProfileForChat, err error) {
+func (p *profileRepository) GetProfileByIdHex(ctx context.Context, id string) (profile *models.ProfileForChat, err error) {
 
 	if ok := primitive.IsValidObjectID(id); ok == false {
-		return nil, util.ErrIdIsNotPrimitiveObjectID
+		return nil, util.ErrInvalidID
 	}
 
 	oID, err := primitive.ObjectIDFromHex(id)
@@ -128,38 +151,51 @@ func (p *profileRepository) GetProfileByIdHex(id string) (profile *models.Profil
 		return nil, err
 	}
 
-	result := p.c.FindOne(p.ctx, bson.M{"_id": oID})
+	result := p.c.FindOne(ctx, bson.M{"_id": oID})
 
 	err = result.Decode(&profile)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return profile, result.Err()
 }
 
-func (p *profileRepository) GetProfileByFilterAll(filter interface{}, role string) (profiles []interface{}, err error) {
+func (p *profileRepository) GetProfileByFilterAll(ctx context.Context, filter interface{}, role string) (profiles []interface{}, err error) {
 
-	cur, err := p.c.Find(p.ctx, filter, nil)
+	cur, err := p.c.Find(ctx, filter, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	if role == "teacher" {
-		for cur.Next(p.ctx) {
+		for cur.Next(ctx) {
 			var b *models.ProfileTeacher
 			err := cur.Decode(&b)
 			if err != nil {
+				p.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 				return nil, err
 			}
 
 			profiles = append(profiles, b)
 		}
 	} else if role == "student" {
-		for cur.Next(p.ctx) {
+		for cur.Next(ctx) {
 			var b *models.ProfileStudent
 			err := cur.Decode(&b)
 			if err != nil {
+				p.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
+				return nil, err
+			}
+
+			profiles = append(profiles, b)
+		}
+	} else if role == "parent" {
+		for cur.Next(ctx) {
+			var b *models.ProfileParent
+			err := cur.Decode(&b)
+			if err != nil {
+				p.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 				return nil, err
 			}
 
@@ -171,10 +207,10 @@ func (p *profileRepository) GetProfileByFilterAll(filter interface{}, role strin
 		return nil, err
 	}
 
-	cur.Close(p.ctx)
+	cur.Close(ctx)
 
 	if len(profiles) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return profiles, nil
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"school-notification-backend/db"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const RoleCollection = "roles"
+
+type RoleRepository interface {
+	Insert(ctx context.Context, role *models.Role) (*mongo.InsertOneResult, error)
+	Update(ctx context.Context, role *models.Role) (*mongo.UpdateResult, error)
+	Delete(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error)
+	GetById(ctx context.Context, id string) (role *models.Role, err error)
+	GetByName(ctx context.Context, name string) (role *models.Role, err error)
+	GetAll(ctx context.Context) (roles []*models.Role, err error)
+}
+
+type roleRepository struct {
+	c      *mongo.Collection
+	logger logger.Logger
+}
+
+func NewRoleRepository(conn db.Connection, logger logger.Logger) RoleRepository {
+	return &roleRepository{c: conn.DB().Collection(RoleCollection), logger: logger.With("collection", RoleCollection)}
+}
+
+func (r *roleRepository) Insert(ctx context.Context, role *models.Role) (*mongo.InsertOneResult, error) {
+	result, err := r.c.InsertOne(ctx, role)
+	return result, domainError(err)
+}
+
+func (r *roleRepository) Update(ctx context.Context, role *models.Role) (*mongo.UpdateResult, error) {
+	result, err := r.c.UpdateByID(ctx, role.Id, bson.M{"$set": role})
+	return result, domainError(err)
+}
+
+func (r *roleRepository) Delete(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error) {
+	return r.c.DeleteOne(ctx, bson.M{"_id": id})
+}
+
+func (r *roleRepository) GetById(ctx context.Context, id string) (role *models.Role, err error) {
+	if ok := primitive.IsValidObjectID(id); ok == false {
+		return nil, util.ErrInvalidID
+	}
+
+	oID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.c.FindOne(ctx, bson.M{"_id": oID}).Decode(&role)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return role, nil
+}
+
+func (r *roleRepository) GetByName(ctx context.Context, name string) (role *models.Role, err error) {
+	err = r.c.FindOne(ctx, bson.M{"name": name}).Decode(&role)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return role, nil
+}
+
+func (r *roleRepository) GetAll(ctx context.Context) (roles []*models.Role, err error) {
+
+	cur, err := r.c.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	for cur.Next(ctx) {
+		var b *models.Role
+		err := cur.Decode(&b)
+		if err != nil {
+			r.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
+			return nil, err
+		}
+
+		roles = append(roles, b)
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	cur.Close(ctx)
+
+	if len(roles) == 0 {
+		return nil, util.ErrNotFound
+	}
+
+	return roles, nil
+}
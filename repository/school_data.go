@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/util"
 
@@ -11,37 +12,44 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const schoolDataCollection = "school-data"
+const SchoolDataCollection = "school-data"
 
 type SchoolDataRepository interface {
-	GetAll() (schoolDataList []*models.SchoolData, err error)
-	Insert(schoolData interface{}) (*mongo.InsertOneResult, error)
-	GetById(id string) (schoolData *models.SchoolData, err error)
-	Update(schoolData *models.SchoolData) (*mongo.UpdateResult, error)
-	GetByFilter(filter interface{}) (schoolData *models.SchoolData, err error)
-	GetByFilterAll(filter interface{}) (schoolDataList []*models.SchoolData, err error)
+	GetAll(ctx context.Context) (schoolDataList []*models.SchoolData, err error)
+	Insert(ctx context.Context, schoolData interface{}) (*mongo.InsertOneResult, error)
+	GetById(ctx context.Context, id string) (schoolData *models.SchoolData, err error)
+	Update(ctx context.Context, schoolData *models.SchoolData) (*mongo.UpdateResult, error)
+	Delete(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error)
+	GetByFilter(ctx context.Context, filter interface{}) (schoolData *models.SchoolData, err error)
+	GetByFilterAll(ctx context.Context, filter interface{}) (schoolDataList []*models.SchoolData, err error)
 }
 
 type schoolDataRepository struct {
-	c   *mongo.Collection
-	ctx context.Context
+	c      *mongo.Collection
+	logger logger.Logger
 }
 
-func NewSchoolDataRepository(conn db.Connection) SchoolDataRepository {
-	return &schoolDataRepository{c: conn.DB().Collection(schoolDataCollection), ctx: context.TODO()}
+func NewSchoolDataRepository(conn db.Connection, logger logger.Logger) SchoolDataRepository {
+	return &schoolDataRepository{c: conn.DB().Collection(SchoolDataCollection), logger: logger.With("collection", SchoolDataCollection)}
 }
 
-func (s *schoolDataRepository) Insert(schoolData interface{}) (*mongo.InsertOneResult, error) {
-	return s.c.InsertOne(s.ctx, schoolData)
+func (s *schoolDataRepository) Insert(ctx context.Context, schoolData interface{}) (*mongo.InsertOneResult, error) {
+	result, err := s.c.InsertOne(ctx, schoolData)
+	return result, domainError(err)
 }
 
-func (s *schoolDataRepository) Update(schoolData *models.SchoolData) (*mongo.UpdateResult, error) {
-	return s.c.UpdateByID(s.ctx, schoolData.Id, bson.M{"$set": schoolData})
+func (s *schoolDataRepository) Update(ctx context.Context, schoolData *models.SchoolData) (*mongo.UpdateResult, error) {
+	result, err := s.c.UpdateByID(ctx, schoolData.Id, bson.M{"$set": schoolData})
+	return result, domainError(err)
 }
 
-func (s *schoolDataRepository) GetById(id string) (schoolData *models.SchoolData, err error) {
+func (s *schoolDataRepository) Delete(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error) {
+	return s.c.DeleteOne(ctx, bson.M{"_id": id})
+}
+
+func (s *schoolDataRepository) GetById(ctx context.Context, id string) (schoolData *models.SchoolData, err error) {
 	if ok := primitive.IsValidObjectID(id); ok == false {
-		return nil, util.ErrIdIsNotPrimitiveObjectID
+		return nil, util.ErrInvalidID
 	}
 
 	oID, err := primitive.ObjectIDFromHex(id)
@@ -49,27 +57,28 @@ func (s *schoolDataRepository) GetById(id string) (schoolData *models.SchoolData
 		return nil, err
 	}
 
-	result := s.c.FindOne(s.ctx, bson.M{"_id": oID})
+	result := s.c.FindOne(ctx, bson.M{"_id": oID})
 
 	err = result.Decode(&schoolData)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return schoolData, result.Err()
 }
 
-func (s *schoolDataRepository) GetAll() (schoolDataList []*models.SchoolData, err error) {
+func (s *schoolDataRepository) GetAll(ctx context.Context) (schoolDataList []*models.SchoolData, err error) {
 
-	cur, err := s.c.Find(s.ctx, bson.M{}, nil)
+	cur, err := s.c.Find(ctx, bson.M{}, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(s.ctx) {
+	for cur.Next(ctx) {
 		var b *models.SchoolData
 		err := cur.Decode(&b)
 		if err != nil {
+			s.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -80,38 +89,39 @@ func (s *schoolDataRepository) GetAll() (schoolDataList []*models.SchoolData, er
 		return nil, err
 	}
 
-	cur.Close(s.ctx)
+	cur.Close(ctx)
 
 	if len(schoolDataList) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return schoolDataList, nil
 }
 
-func (s *schoolDataRepository) GetByFilter(filter interface{}) (schoolData *models.SchoolData, err error) {
+func (s *schoolDataRepository) GetByFilter(ctx context.Context, filter interface{}) (schoolData *models.SchoolData, err error) {
 
-	result := s.c.FindOne(s.ctx, filter)
+	result := s.c.FindOne(ctx, filter)
 
 	err = result.Decode(&schoolData)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return schoolData, result.Err()
 }
 
-func (s *schoolDataRepository) GetByFilterAll(filter interface{}) (schoolDataList []*models.SchoolData, err error) {
+func (s *schoolDataRepository) GetByFilterAll(ctx context.Context, filter interface{}) (schoolDataList []*models.SchoolData, err error) {
 
-	cur, err := s.c.Find(s.ctx, filter, nil)
+	cur, err := s.c.Find(ctx, filter, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(s.ctx) {
+	for cur.Next(ctx) {
 		var b *models.SchoolData
 		err := cur.Decode(&b)
 		if err != nil {
+			s.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -122,10 +132,10 @@ func (s *schoolDataRepository) GetByFilterAll(filter interface{}) (schoolDataLis
 		return nil, err
 	}
 
-	cur.Close(s.ctx)
+	cur.Close(ctx)
 
 	if len(schoolDataList) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return schoolDataList, nil
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/util"
 
@@ -11,57 +12,60 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const scoreCollection = "scores"
+const ScoreCollection = "scores"
 
 type ScoreRepository interface {
 	// GetAll() (scores []*models.Score, err error)
-	Insert(score *models.Score) (*mongo.InsertOneResult, error)
+	Insert(ctx context.Context, score *models.Score) (*mongo.InsertOneResult, error)
 	// GetScoreById(id string) (score *models.Score, err error)
-	GetScoreByFilter(filter interface{}) (score *models.Score, err error)
-	GetByFilterAll(filter interface{}) (scores []*models.Score, err error)
-	Update(score *models.Score) (*mongo.UpdateResult, error)
+	GetScoreByFilter(ctx context.Context, filter interface{}) (score *models.Score, err error)
+	GetByFilterAll(ctx context.Context, filter interface{}) (scores []*models.Score, err error)
+	Update(ctx context.Context, score *models.Score) (*mongo.UpdateResult, error)
 }
 
 type scoreRepository struct {
-	c   *mongo.Collection
-	ctx context.Context
+	c      *mongo.Collection
+	logger logger.Logger
 }
 
-func NewScoreRepository(conn db.Connection) ScoreRepository {
-	return &scoreRepository{c: conn.DB().Collection(scoreCollection), ctx: context.TODO()}
+func NewScoreRepository(conn db.Connection, logger logger.Logger) ScoreRepository {
+	return &scoreRepository{c: conn.DB().Collection(ScoreCollection), logger: logger.With("collection", ScoreCollection)}
 }
 
-func (s *scoreRepository) Insert(score *models.Score) (*mongo.InsertOneResult, error) {
-	return s.c.InsertOne(s.ctx, score)
+func (s *scoreRepository) Insert(ctx context.Context, score *models.Score) (*mongo.InsertOneResult, error) {
+	result, err := s.c.InsertOne(ctx, score)
+	return result, domainError(err)
 }
 
-func (s *scoreRepository) Update(score *models.Score) (*mongo.UpdateResult, error) {
-	return s.c.UpdateByID(s.ctx, score.Id, bson.M{"$set": score})
+func (s *scoreRepository) Update(ctx context.Context, score *models.Score) (*mongo.UpdateResult, error) {
+	result, err := s.c.UpdateByID(ctx, score.Id, bson.M{"$set": score})
+	return result, domainError(err)
 }
 
-func (s *scoreRepository) GetScoreByFilter(filter interface{}) (score *models.Score, err error) {
+func (s *scoreRepository) GetScoreByFilter(ctx context.Context, filter interface{}) (score *models.Score, err error) {
 
-	result := s.c.FindOne(s.ctx, filter)
+	result := s.c.FindOne(ctx, filter)
 
 	err = result.Decode(&score)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return score, result.Err()
 }
 
-func (s *scoreRepository) GetByFilterAll(filter interface{}) (scores []*models.Score, err error) {
+func (s *scoreRepository) GetByFilterAll(ctx context.Context, filter interface{}) (scores []*models.Score, err error) {
 
-	cur, err := s.c.Find(s.ctx, filter, nil)
+	cur, err := s.c.Find(ctx, filter, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(s.ctx) {
+	for cur.Next(ctx) {
 		var b *models.Score
 		err := cur.Decode(&b)
 		if err != nil {
+			s.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -72,18 +76,18 @@ func (s *scoreRepository) GetByFilterAll(filter interface{}) (scores []*models.S
 		return nil, err
 	}
 
-	cur.Close(s.ctx)
+	cur.Close(ctx)
 
 	if len(scores) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return scores, nil
 }
 
-func (s *scoreRepository) GetScoreById(id string) (score *models.Score, err error) {
+func (s *scoreRepository) GetScoreById(ctx context.Context, id string) (score *models.Score, err error) {
 	if ok := primitive.IsValidObjectID(id); ok == false {
-		return nil, util.ErrIdIsNotPrimitiveObjectID
+		return nil, util.ErrInvalidID
 	}
 
 	oID, err := primitive.ObjectIDFromHex(id)
@@ -91,27 +95,28 @@ func (s *scoreRepository) GetScoreById(id string) (score *models.Score, err erro
 		return nil, err
 	}
 
-	result := s.c.FindOne(s.ctx, bson.M{"_id": oID})
+	result := s.c.FindOne(ctx, bson.M{"_id": oID})
 
 	err = result.Decode(&score)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return score, result.Err()
 }
 
-func (s *scoreRepository) GetAll() (scores []*models.Score, err error) {
+func (s *scoreRepository) GetAll(ctx context.Context) (scores []*models.Score, err error) {
 
-	cur, err := s.c.Find(s.ctx, bson.M{}, nil)
+	cur, err := s.c.Find(ctx, bson.M{}, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(s.ctx) {
+	for cur.Next(ctx) {
 		var b *models.Score
 		err := cur.Decode(&b)
 		if err != nil {
+			s.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -122,10 +127,10 @@ func (s *scoreRepository) GetAll() (scores []*models.Score, err error) {
 		return nil, err
 	}
 
-	cur.Close(s.ctx)
+	cur.Close(ctx)
 
 	if len(scores) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return scores, nil
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"school-notification-backend/db"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const SessionCollection = "sessions"
+
+type SessionRepository interface {
+	Insert(ctx context.Context, session *models.Session) (*mongo.InsertOneResult, error)
+	Update(ctx context.Context, session *models.Session) (*mongo.UpdateResult, error)
+	UpdateMany(ctx context.Context, filter interface{}, update interface{}) (*mongo.UpdateResult, error)
+	GetById(ctx context.Context, id string) (session *models.Session, err error)
+	GetByFilterAll(ctx context.Context, filter interface{}) (sessions []*models.Session, err error)
+}
+
+type sessionRepository struct {
+	c      *mongo.Collection
+	logger logger.Logger
+}
+
+func NewSessionRepository(conn db.Connection, logger logger.Logger) SessionRepository {
+	return &sessionRepository{c: conn.DB().Collection(SessionCollection), logger: logger.With("collection", SessionCollection)}
+}
+
+func (s *sessionRepository) Insert(ctx context.Context, session *models.Session) (*mongo.InsertOneResult, error) {
+	result, err := s.c.InsertOne(ctx, session)
+	return result, domainError(err)
+}
+
+func (s *sessionRepository) Update(ctx context.Context, session *models.Session) (*mongo.UpdateResult, error) {
+	result, err := s.c.UpdateByID(ctx, session.Id, bson.M{"$set": session})
+	return result, domainError(err)
+}
+
+func (s *sessionRepository) UpdateMany(ctx context.Context, filter interface{}, update interface{}) (*mongo.UpdateResult, error) {
+	result, err := s.c.UpdateMany(ctx, filter, bson.M{"$set": update})
+	return result, domainError(err)
+}
+
+func (s *sessionRepository) GetById(ctx context.Context, id string) (session *models.Session, err error) {
+	if ok := primitive.IsValidObjectID(id); ok == false {
+		return nil, util.ErrInvalidID
+	}
+
+	oID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.c.FindOne(ctx, bson.M{"_id": oID}).Decode(&session)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return session, nil
+}
+
+func (s *sessionRepository) GetByFilterAll(ctx context.Context, filter interface{}) (sessions []*models.Session, err error) {
+
+	cur, err := s.c.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, err
+	}
+
+	for cur.Next(ctx) {
+		var b *models.Session
+		err := cur.Decode(&b)
+		if err != nil {
+			s.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
+			return nil, err
+		}
+
+		sessions = append(sessions, b)
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	cur.Close(ctx)
+
+	if len(sessions) == 0 {
+		return nil, util.ErrNotFound
+	}
+
+	return sessions, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/util"
 
@@ -11,57 +12,60 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const subjectCollection = "subjects"
+const SubjectCollection = "subjects"
 
 type SubjectRepository interface {
-	GetAll() (subjects []*models.Subject, err error)
-	Insert(subject *models.Subject) (*mongo.InsertOneResult, error)
-	GetSubjectById(id string) (subject *models.Subject, err error)
-	GetSubjectByFilter(filter interface{}) (subject *models.Subject, err error)
-	Update(subject *models.Subject) (*mongo.UpdateResult, error)
-	GetSubjectByFilterAll(filter interface{}) (subjects []*models.Subject, err error)
+	GetAll(ctx context.Context) (subjects []*models.Subject, err error)
+	Insert(ctx context.Context, subject *models.Subject) (*mongo.InsertOneResult, error)
+	GetSubjectById(ctx context.Context, id string) (subject *models.Subject, err error)
+	GetSubjectByFilter(ctx context.Context, filter interface{}) (subject *models.Subject, err error)
+	Update(ctx context.Context, subject *models.Subject) (*mongo.UpdateResult, error)
+	GetSubjectByFilterAll(ctx context.Context, filter interface{}) (subjects []*models.Subject, err error)
 }
 
 type subjectRepository struct {
-	c   *mongo.Collection
-	ctx context.Context
+	c      *mongo.Collection
+	logger logger.Logger
 }
 
-func NewSubjectRepository(conn db.Connection) SubjectRepository {
-	return &subjectRepository{c: conn.DB().Collection(subjectCollection), ctx: context.TODO()}
+func NewSubjectRepository(conn db.Connection, logger logger.Logger) SubjectRepository {
+	return &subjectRepository{c: conn.DB().Collection(SubjectCollection), logger: logger.With("collection", SubjectCollection)}
 }
 
-func (s *subjectRepository) Insert(subject *models.Subject) (*mongo.InsertOneResult, error) {
-	return s.c.InsertOne(s.ctx, subject)
+func (s *subjectRepository) Insert(ctx context.Context, subject *models.Subject) (*mongo.InsertOneResult, error) {
+	result, err := s.c.InsertOne(ctx, subject)
+	return result, domainError(err)
 }
 
-func (s *subjectRepository) Update(subject *models.Subject) (*mongo.UpdateResult, error) {
-	return s.c.UpdateByID(s.ctx, subject.Id, bson.M{"$set": subject})
+func (s *subjectRepository) Update(ctx context.Context, subject *models.Subject) (*mongo.UpdateResult, error) {
+	result, err := s.c.UpdateByID(ctx, subject.Id, bson.M{"$set": subject})
+	return result, domainError(err)
 }
 
-func (s *subjectRepository) GetSubjectByFilter(filter interface{}) (subject *models.Subject, err error) {
+func (s *subjectRepository) GetSubjectByFilter(ctx context.Context, filter interface{}) (subject *models.Subject, err error) {
 
-	result := s.c.FindOne(s.ctx, filter)
+	result := s.c.FindOne(ctx, filter)
 
 	err = result.Decode(&subject)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return subject, result.Err()
 }
 
-func (s *subjectRepository) GetSubjectByFilterAll(filter interface{}) (subjects []*models.Subject, err error) {
+func (s *subjectRepository) GetSubjectByFilterAll(ctx context.Context, filter interface{}) (subjects []*models.Subject, err error) {
 
-	cur, err := s.c.Find(s.ctx, filter, nil)
+	cur, err := s.c.Find(ctx, filter, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(s.ctx) {
+	for cur.Next(ctx) {
 		var b *models.Subject
 		err := cur.Decode(&b)
 		if err != nil {
+			s.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -72,18 +76,18 @@ func (s *subjectRepository) GetSubjectByFilterAll(filter interface{}) (subjects
 		return nil, err
 	}
 
-	cur.Close(s.ctx)
+	cur.Close(ctx)
 
 	if len(subjects) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return subjects, nil
 }
 
-func (s *subjectRepository) GetSubjectById(id string) (subject *models.Subject, err error) {
+func (s *subjectRepository) GetSubjectById(ctx context.Context, id string) (subject *models.Subject, err error) {
 	if ok := primitive.IsValidObjectID(id); ok == false {
-		return nil, util.ErrIdIsNotPrimitiveObjectID
+		return nil, util.ErrInvalidID
 	}
 
 	oID, err := primitive.ObjectIDFromHex(id)
@@ -91,27 +95,28 @@ func (s *subjectRepository) GetSubjectById(id string) (subject *models.Subject,
 		return nil, err
 	}
 
-	result := s.c.FindOne(s.ctx, bson.M{"_id": oID})
+	result := s.c.FindOne(ctx, bson.M{"_id": oID})
 
 	err = result.Decode(&subject)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return subject, result.Err()
 }
 
-func (s *subjectRepository) GetAll() (subjects []*models.Subject, err error) {
+func (s *subjectRepository) GetAll(ctx context.Context) (subjects []*models.Subject, err error) {
 
-	cur, err := s.c.Find(s.ctx, bson.M{}, nil)
+	cur, err := s.c.Find(ctx, bson.M{}, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(s.ctx) {
+	for cur.Next(ctx) {
 		var b *models.Subject
 		err := cur.Decode(&b)
 		if err != nil {
+			s.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -122,10 +127,10 @@ func (s *subjectRepository) GetAll() (subjects []*models.Subject, err error) {
 		return nil, err
 	}
 
-	cur.Close(s.ctx)
+	cur.Close(ctx)
 
 	if len(subjects) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 
 	return subjects, nil
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"school-notification-backend/db"
+	"school-notification-backend/logger"
+	"school-notification-backend/models"
+	"school-notification-backend/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const TwoFactorPolicyCollection = "two_factor_policies"
+
+type TwoFactorPolicyRepository interface {
+	Upsert(ctx context.Context, policy *models.TwoFactorPolicy) (*mongo.UpdateResult, error)
+	GetByRole(ctx context.Context, role string) (policy *models.TwoFactorPolicy, err error)
+	GetAll(ctx context.Context) (policies []*models.TwoFactorPolicy, err error)
+}
+
+type twoFactorPolicyRepository struct {
+	c      *mongo.Collection
+	logger logger.Logger
+}
+
+func NewTwoFactorPolicyRepository(conn db.Connection, logger logger.Logger) TwoFactorPolicyRepository {
+	return &twoFactorPolicyRepository{c: conn.DB().Collection(TwoFactorPolicyCollection), logger: logger.With("collection", TwoFactorPolicyCollection)}
+}
+
+func (t *twoFactorPolicyRepository) Upsert(ctx context.Context, policy *models.TwoFactorPolicy) (*mongo.UpdateResult, error) {
+	result, err := t.c.UpdateByID(ctx, policy.Id, bson.M{"$set": policy}, options.Update().SetUpsert(true))
+	return result, domainError(err)
+}
+
+func (t *twoFactorPolicyRepository) GetByRole(ctx context.Context, role string) (policy *models.TwoFactorPolicy, err error) {
+	err = t.c.FindOne(ctx, bson.M{"role": role}).Decode(&policy)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return policy, nil
+}
+
+func (t *twoFactorPolicyRepository) GetAll(ctx context.Context) (policies []*models.TwoFactorPolicy, err error) {
+
+	cur, err := t.c.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"role": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	for cur.Next(ctx) {
+		var b *models.TwoFactorPolicy
+		err := cur.Decode(&b)
+		if err != nil {
+			t.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
+			return nil, err
+		}
+
+		policies = append(policies, b)
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	cur.Close(ctx)
+
+	if len(policies) == 0 {
+		return nil, util.ErrNotFound
+	}
+
+	return policies, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"school-notification-backend/db"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/util"
 
@@ -11,38 +12,40 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-const usersCollection = "users"
+const UsersCollection = "users"
 
 type UsersRepository interface {
-	InsertUser(user *models.User) (*mongo.InsertOneResult, error)
-	Update(user *models.User) (*mongo.UpdateResult, error)
-	GetById(id string) (user *models.User, err error)
-	GetByUsername(username string) (user *models.User, err error)
-	GetAll() (users []*models.User, err error)
-	Delete(id string) (*mongo.DeleteResult, error)
+	InsertUser(ctx context.Context, user *models.User) (*mongo.InsertOneResult, error)
+	Update(ctx context.Context, user *models.User) (*mongo.UpdateResult, error)
+	GetById(ctx context.Context, id string) (user *models.User, err error)
+	GetByUsername(ctx context.Context, username string) (user *models.User, err error)
+	GetAll(ctx context.Context) (users []*models.User, err error)
+	Delete(ctx context.Context, id string) (*mongo.DeleteResult, error)
 }
 
 type usersRepository struct {
-	c   *mongo.Collection
-	ctx context.Context
+	c      *mongo.Collection
+	logger logger.Logger
 }
 
-func NewUsersRepository(conn db.Connection) UsersRepository {
-	return &usersRepository{c: conn.DB().Collection(usersCollection), ctx: context.TODO()}
+func NewUsersRepository(conn db.Connection, logger logger.Logger) UsersRepository {
+	return &usersRepository{c: conn.DB().Collection(UsersCollection), logger: logger.With("collection", UsersCollection)}
 }
 
-func (u *usersRepository) InsertUser(user *models.User) (*mongo.InsertOneResult, error) {
-	return u.c.InsertOne(u.ctx, user)
+func (u *usersRepository) InsertUser(ctx context.Context, user *models.User) (*mongo.InsertOneResult, error) {
+	result, err := u.c.InsertOne(ctx, user)
+	return result, domainError(err)
 }
 
-func (u *usersRepository) Update(user *models.User) (*mongo.UpdateResult, error) {
-	return u.c.UpdateByID(u.ctx, user.Id, bson.M{"$set": user})
+func (u *usersRepository) Update(ctx context.Context, user *models.User) (*mongo.UpdateResult, error) {
+	result, err := u.c.UpdateByID(ctx, user.Id, bson.M{"$set": user})
+	return result, domainError(err)
 }
 
-func (u *usersRepository) GetById(id string) (user *models.User, err error) {
+func (u *usersRepository) GetById(ctx context.Context, id string) (user *models.User, err error) {
 
 	if ok := primitive.IsValidObjectID(id); ok == false {
-		return nil, util.ErrIdIsNotPrimitiveObjectID
+		return nil, util.ErrInvalidID
 	}
 
 	oID, err := primitive.ObjectIDFromHex(id)
@@ -50,34 +53,35 @@ func (u *usersRepository) GetById(id string) (user *models.User, err error) {
 		return nil, err
 	}
 
-	err = u.c.FindOne(u.ctx, bson.M{"_id": oID}).Decode(&user)
+	err = u.c.FindOne(ctx, bson.M{"_id": oID}).Decode(&user)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return user, err
 }
 
-func (u *usersRepository) GetByUsername(username string) (user *models.User, err error) {
+func (u *usersRepository) GetByUsername(ctx context.Context, username string) (user *models.User, err error) {
 
-	err = u.c.FindOne(u.ctx, bson.M{"username": username}).Decode(&user)
+	err = u.c.FindOne(ctx, bson.M{"username": username}).Decode(&user)
 	if err != nil {
-		return nil, err
+		return nil, domainError(err)
 	}
 
 	return user, err
 }
 
-func (u *usersRepository) GetAll() (users []*models.User, err error) {
-	cur, err := u.c.Find(u.ctx, bson.M{})
+func (u *usersRepository) GetAll(ctx context.Context) (users []*models.User, err error) {
+	cur, err := u.c.Find(ctx, bson.M{})
 	if err != nil {
 		return nil, err
 	}
 
-	for cur.Next(u.ctx) {
+	for cur.Next(ctx) {
 		var b *models.User
 		err := cur.Decode(&b)
 		if err != nil {
+			u.logger.Error(ctx, "decode document", "id", cur.Current.Lookup("_id").String(), "error", err)
 			return nil, err
 		}
 
@@ -88,17 +92,17 @@ func (u *usersRepository) GetAll() (users []*models.User, err error) {
 		return nil, err
 	}
 
-	cur.Close(u.ctx)
+	cur.Close(ctx)
 
 	if len(users) == 0 {
-		return nil, mongo.ErrNoDocuments
+		return nil, util.ErrNotFound
 	}
 	return users, err
 }
 
-func (u *usersRepository) Delete(id string) (*mongo.DeleteResult, error) {
+func (u *usersRepository) Delete(ctx context.Context, id string) (*mongo.DeleteResult, error) {
 	if ok := primitive.IsValidObjectID(id); ok == false {
-		return nil, util.ErrIdIsNotPrimitiveObjectID
+		return nil, util.ErrInvalidID
 	}
 
 	oID, err := primitive.ObjectIDFromHex(id)
@@ -106,7 +110,7 @@ func (u *usersRepository) Delete(id string) (*mongo.DeleteResult, error) {
 		return nil, err
 	}
 
-	result, err := u.c.DeleteOne(u.ctx, bson.M{"_id": oID})
+	result, err := u.c.DeleteOne(ctx, bson.M{"_id": oID})
 	if err != nil {
 		return nil, err
 	}
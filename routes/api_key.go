@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"school-notification-backend/controller"
+	"school-notification-backend/security"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type apiKeyRoutes struct {
+	apiKeyController controller.ApiKeyController
+}
+
+func NewApiKeyRoute(apiKeyController controller.ApiKeyController) Routes {
+	return &apiKeyRoutes{apiKeyController: apiKeyController}
+}
+
+func (r *apiKeyRoutes) Install(app *fiber.App) {
+	app.Get("/api-key/all", security.RequirePermission(security.PermApiKeyManage), r.apiKeyController.GetApiKeyAll)
+	app.Get("/api-key/scope/all", security.RequirePermission(security.PermApiKeyManage), r.apiKeyController.GetScopeAll)
+
+	app.Post("/api-key/create", security.RequirePermission(security.PermApiKeyManage), security.Audit("api_key.create"), r.apiKeyController.CreateApiKey)
+	app.Post("/api-key/revoke", security.RequirePermission(security.PermApiKeyManage), security.Audit("api_key.revoke"), r.apiKeyController.RevokeApiKey)
+}
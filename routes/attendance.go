@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"school-notification-backend/controller"
+	"school-notification-backend/security"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type attendanceRoutes struct {
+	attendanceController controller.AttendanceController
+}
+
+func NewAttendanceRoute(attendanceController controller.AttendanceController) Routes {
+	return &attendanceRoutes{attendanceController: attendanceController}
+}
+
+func (r *attendanceRoutes) Install(app *fiber.App) {
+	app.Get("/attendance/chronic-absence", security.RequirePermission(security.PermAttendanceReport), r.attendanceController.GetChronicAbsenceReport)
+
+	app.Post("/attendance/chronic-absence/notify", security.RequirePermission(security.PermAttendanceNotify), security.Audit("attendance.notify_chronic_absence"), r.attendanceController.NotifyChronicAbsence)
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"school-notification-backend/controller"
+	"school-notification-backend/security"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type auditLogRoutes struct {
+	auditLogController controller.AuditLogController
+}
+
+func NewAuditLogRoute(auditLogController controller.AuditLogController) Routes {
+	return &auditLogRoutes{auditLogController: auditLogController}
+}
+
+func (r *auditLogRoutes) Install(app *fiber.App) {
+	app.Get("/audit-log/all", security.RequirePermission(security.PermAuditLogRead), r.auditLogController.GetAuditLogAll)
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"school-notification-backend/controller"
+	"school-notification-backend/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -19,4 +20,21 @@ func (r *authRoutes) Install(app *fiber.App) {
 
 	// app.Post("/sign-up", r.authController.SignUp)
 	app.Post("/sign-in", r.authController.SignIn)
+	app.Post("/sign-in/two-factor", r.authController.SignInTwoFactor)
+	app.Post("/refresh", r.authController.Refresh)
+	app.Get("/.well-known/jwks.json", r.authController.GetJwks)
+	app.Post("/logout", security.Authenticated(), security.Audit("session.logout"), r.authController.Logout)
+	app.Post("/logout-all", security.Authenticated(), security.Audit("session.logout_all"), r.authController.LogoutAll)
+	app.Get("/session/all", security.Authenticated(), r.authController.GetSessionList)
+	app.Post("/session/revoke", security.RequirePermission(security.PermSessionManage), security.Audit("session.revoke"), r.authController.RevokeSession)
+	app.Post("/session/revoke-user", security.RequirePermission(security.PermSessionManage), security.Audit("session.revoke_user"), r.authController.RevokeUserSession)
+	app.Post("/password/change", security.Authenticated(), security.Audit("password.change"), r.authController.ChangePassword)
+	app.Post("/password/reset", security.RequirePermission(security.PermPasswordReset), security.Audit("password.reset"), r.authController.ResetPassword)
+	app.Post("/two-factor/enroll", security.Authenticated(), security.Audit("two_factor.enroll"), r.authController.EnrollTwoFactor)
+	app.Post("/two-factor/confirm", security.Authenticated(), security.Audit("two_factor.confirm"), r.authController.ConfirmTwoFactor)
+	app.Post("/two-factor/disable", security.Authenticated(), security.Audit("two_factor.disable"), r.authController.DisableTwoFactor)
+	app.Post("/two-factor/recovery-codes", security.Authenticated(), security.Audit("two_factor.regenerate_recovery_codes"), r.authController.RegenerateRecoveryCodes)
+	app.Post("/two-factor/reset", security.RequirePermission(security.PermTwoFactorManage), security.Audit("two_factor.reset"), r.authController.ResetTwoFactor)
+	app.Get("/two-factor/policy/all", security.RequirePermission(security.PermTwoFactorManage), r.authController.GetTwoFactorPolicyAll)
+	app.Post("/two-factor/policy", security.RequirePermission(security.PermTwoFactorManage), security.Audit("two_factor.set_policy"), r.authController.SetTwoFactorPolicy)
 }
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"school-notification-backend/controller"
+	"school-notification-backend/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,13 +16,14 @@ func NewCheckNameRoute(checkNameController controller.CheckNameController) Route
 }
 
 func (r *checkNameRoutes) Install(app *fiber.App) {
-	app.Get("/check-name/check-name-in-course", r.checkNameController.GetDateByCourseId)
-	app.Get("/check-name/check-name-data", r.checkNameController.GetCheckNameDataByCourseIdAndDate)
+	app.Get("/check-name/check-name-in-course", security.RequirePermission(security.PermCheckNameRead), r.checkNameController.GetDateByCourseId)
+	app.Get("/check-name/check-name-data", security.RequirePermission(security.PermCheckNameRead), r.checkNameController.GetCheckNameDataByCourseIdAndDate)
 	// app.Get("/CheckName/CheckName-data", r.CheckNameController.GetCheckNameDataByCourseIdAndNameSore)
 
-	app.Post("/check-name/add-date", r.checkNameController.AddDateForCheck)
-	app.Post("/check-name/student-check", r.checkNameController.CheckNameStudent)
-	app.Post("/check-name/end-date", r.checkNameController.EndDateCheckName)
+	app.Post("/check-name/add-date", security.RequirePermission(security.PermCheckNameManage), security.Audit("check_name.add_date"), r.checkNameController.AddDateForCheck)
+	app.Post("/check-name/student-check", security.RequirePermission(security.PermCheckNameMark), security.Audit("check_name.check"), r.checkNameController.CheckNameStudent)
+	app.Post("/check-name/end-date", security.RequirePermission(security.PermCheckNameManage), security.Audit("check_name.end_date"), r.checkNameController.EndDateCheckName)
+	app.Post("/check-name/override", security.RequirePermission(security.PermCheckNameManage), security.Audit("check_name.override"), r.checkNameController.OverrideCheckName)
 	// app.Post("/CheckName/add-student-CheckName", r.CheckNameController.AddStudentCheckName)
 	// app.Post("/CheckName/update-student-CheckName", r.CheckNameController.UpdateStudentCheckName)
 
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"school-notification-backend/controller"
+	"school-notification-backend/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,11 +16,11 @@ func NewClassRoute(classController controller.ClassController) Routes {
 }
 
 func (r *classRoutes) Install(app *fiber.App) {
-	app.Get("/class/all", r.classController.GetClassAllByClassYear)
-	app.Get("/class/class-year-and-room", r.classController.GetClassByClassYearAndRoom)
-	app.Get("/class/id", r.classController.GetClassById)
+	app.Get("/class/all", security.RequirePermission(security.PermClassRead), r.classController.GetClassAllByClassYear)
+	app.Get("/class/class-year-and-room", security.RequirePermission(security.PermClassRead), r.classController.GetClassByClassYearAndRoom)
+	app.Get("/class/id", security.RequirePermission(security.PermClassRead), r.classController.GetClassById)
 
-	app.Post("/class/create", r.classController.CreateClass)
-	app.Post("/class/set-advisor", r.classController.SetAdvisor)
+	app.Post("/class/create", security.RequirePermission(security.PermClassManage), security.Audit("class.create"), r.classController.CreateClass)
+	app.Post("/class/set-advisor", security.RequirePermission(security.PermClassManage), security.Audit("class.set_advisor"), r.classController.SetAdvisor)
 	// app.Post("/class/update", r.classController.UpdateClassData)
 }
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"school-notification-backend/controller"
+	"school-notification-backend/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,7 +16,7 @@ func NewConversationRoute(conversationController controller.ConversationControll
 }
 
 func (r *conversationRoutes) Install(app *fiber.App) {
-	app.Get("/conversation/user-id", r.conversationController.GetByUserId)
+	app.Get("/conversation/user-id", security.RequirePermission(security.PermConversation), r.conversationController.GetByUserId)
 
-	app.Post("/conversation/create", r.conversationController.CreateConversation)
+	app.Post("/conversation/create", security.RequirePermission(security.PermConversation), security.Audit("conversation.create"), r.conversationController.CreateConversation)
 }
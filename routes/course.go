@@ -2,6 +2,7 @@ package routes
 
 import (
 	"school-notification-backend/controller"
+	"school-notification-backend/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,15 +16,16 @@ func NewCourseRoute(courseController controller.CourseController) Routes {
 }
 
 func (r *courseRoutes) Install(app *fiber.App) {
-	app.Get("/course/year-term", r.courseController.GetCourseByYearAndTerm)
-	app.Get("/course/id", r.courseController.GetCourseById)
+	app.Get("/course/year-term", security.RequirePermission(security.PermCourseRead), r.courseController.GetCourseByYearAndTerm)
+	app.Get("/course/id", security.RequirePermission(security.PermCourseRead), r.courseController.GetCourseById)
+	app.Get("/course/lesson-dates", security.RequirePermission(security.PermCourseRead), r.courseController.GetLessonDates)
 	// app.Get("/course/score", r.courseController.GetScoreById)
 	// app.Get("/course/check-name", r.courseController.GetCheckNameById)
 
-	app.Post("/course/create", r.courseController.CreateCourse)
+	app.Post("/course/create", security.RequirePermission(security.PermCourseManage), security.Audit("course.create"), r.courseController.CreateCourse)
 	// app.Post("/course/update-data", r.courseController.UpdateCoursesData)
 	// app.Post("/course/check-name", r.courseController.ManageCheckName)
 	// app.Post("/course/score", r.courseController.ManageScore)
-	app.Post("/course/change-to-progress", r.courseController.ChangeCourseToProgress)
-	app.Post("/course/finish-course", r.courseController.FinishCourse)
+	app.Post("/course/change-to-progress", security.RequirePermission(security.PermCourseTeach), security.Audit("course.change_to_progress"), r.courseController.ChangeCourseToProgress)
+	app.Post("/course/finish-course", security.RequirePermission(security.PermCourseManage), security.Audit("course.finish"), r.courseController.FinishCourse)
 }
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"school-notification-backend/controller"
+	"school-notification-backend/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,8 +16,8 @@ func NewCourseSummaryRoute(courseSummaryController controller.CourseSummaryContr
 }
 
 func (r *courseSummaryRoutes) Install(app *fiber.App) {
-	app.Get("/course-summary", r.courseSummaryController.GetSummaryCourse)
-	app.Get("/course-summary/student", r.courseSummaryController.StudentGetSummaryCourse)
+	app.Get("/course-summary", security.RequirePermission(security.PermCourseSummaryRead), r.courseSummaryController.GetSummaryCourse)
+	app.Get("/course-summary/student", security.RequirePermission(security.PermCourseSummaryReadOwn), r.courseSummaryController.StudentGetSummaryCourse)
 
-	app.Post("/summary/course-id", r.courseSummaryController.SummaryCourse)
+	app.Post("/summary/course-id", security.RequirePermission(security.PermCourseSummaryManage), security.Audit("course_summary.summary"), r.courseSummaryController.SummaryCourse)
 }
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"school-notification-backend/controller"
+	"school-notification-backend/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,13 +16,13 @@ func NewFaceDetectionRoute(faceDetectionController controller.FaceDetectionContr
 }
 
 func (r *faceDetectionRoutes) Install(app *fiber.App) {
-	app.Get("/face-detection/open-camera", r.faceDetectionController.OpenCamera)
-	app.Get("/face-detection/id", r.faceDetectionController.GetById)
-	app.Get("/face-detection/all", r.faceDetectionController.GetAll)
-	app.Get("/face-detection/class_id", r.faceDetectionController.GetByClassId)
+	app.Get("/face-detection/open-camera", security.RequirePermission(security.PermFaceDetectionCamera), r.faceDetectionController.OpenCamera)
+	app.Get("/face-detection/id", security.RequirePermission(security.PermFaceDetectionRead), r.faceDetectionController.GetById)
+	app.Get("/face-detection/all", security.RequirePermission(security.PermFaceDetectionData), r.faceDetectionController.GetAll)
+	app.Get("/face-detection/class_id", security.RequirePermission(security.PermFaceDetectionRead), r.faceDetectionController.GetByClassId)
 
-	app.Post("/face-detection/create-data", r.faceDetectionController.CreatFaceDetectionData)
-	app.Post("/face-detection/upload-image-data", r.faceDetectionController.UploadImageData)
-	app.Post("/face-detection/trained-model", r.faceDetectionController.ModelTrained)
+	app.Post("/face-detection/create-data", security.RequirePermission(security.PermFaceDetectionCreate), security.Audit("face_detection.create"), r.faceDetectionController.CreatFaceDetectionData)
+	app.Post("/face-detection/upload-image-data", security.RequirePermission(security.PermFaceDetectionData), security.Audit("face_detection.upload_image"), r.faceDetectionController.UploadImageData)
+	app.Post("/face-detection/trained-model", security.RequirePermission(security.PermFaceDetectionData), security.Audit("face_detection.model_trained"), r.faceDetectionController.ModelTrained)
 
 }
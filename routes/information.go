@@ -2,6 +2,7 @@ package routes
 
 import (
 	"school-notification-backend/controller"
+	"school-notification-backend/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,9 +16,9 @@ func NewInformationRoutes(informationController controller.InformationController
 }
 
 func (r *newsRoutes) Install(app *fiber.App) {
-	app.Get("/information/all", r.informationController.GetInformationAll)
-	app.Get("/information/id", r.informationController.GetInformationById)
+	app.Get("/information/all", security.RequirePermission(security.PermInformationRead), r.informationController.GetInformationAll)
+	app.Get("/information/id", security.RequirePermission(security.PermInformationRead), r.informationController.GetInformationById)
 
-	app.Post("/information/create", r.informationController.CreateInformation)
-	app.Post("/information/update", r.informationController.UpdateInformation)
+	app.Post("/information/create", security.RequirePermission(security.PermInformationManage), security.Audit("information.create"), r.informationController.CreateInformation)
+	app.Post("/information/update", security.RequirePermission(security.PermInformationManage), security.Audit("information.update"), r.informationController.UpdateInformation)
 }
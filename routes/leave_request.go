@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"school-notification-backend/controller"
+	"school-notification-backend/security"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type leaveRequestRoutes struct {
+	leaveRequestController controller.LeaveRequestController
+}
+
+func NewLeaveRequestRoute(leaveRequestController controller.LeaveRequestController) Routes {
+	return &leaveRequestRoutes{leaveRequestController: leaveRequestController}
+}
+
+func (r *leaveRequestRoutes) Install(app *fiber.App) {
+	app.Get("/leave-request/all", security.RequirePermission(security.PermLeaveRequestRead), r.leaveRequestController.GetLeaveRequestList)
+	app.Get("/leave-request/id", security.RequirePermission(security.PermLeaveRequestRead), r.leaveRequestController.GetLeaveRequestById)
+	app.Get("/leave-request/file", security.RequirePermission(security.PermLeaveRequestRead), r.leaveRequestController.GetLeaveRequestFile)
+
+	app.Post("/leave-request/create", security.RequirePermission(security.PermLeaveRequestCreate), security.Audit("leave_request.create"), r.leaveRequestController.CreateLeaveRequest)
+	app.Post("/leave-request/approve", security.RequirePermission(security.PermLeaveRequestApprove), security.Audit("leave_request.approve"), r.leaveRequestController.ApproveLeaveRequest)
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"school-notification-backend/controller"
+	"school-notification-backend/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,9 +16,9 @@ func NewLocationRoute(locationController controller.LocationController) Routes {
 }
 
 func (r *locationRoutes) Install(app *fiber.App) {
-	app.Get("/location/all", r.locationController.GetLocationAll)
-	app.Get("/location/id", r.locationController.GetLocationById)
+	app.Get("/location/all", security.RequirePermission(security.PermLocationRead), r.locationController.GetLocationAll)
+	app.Get("/location/id", security.RequirePermission(security.PermLocationRead), r.locationController.GetLocationById)
 
-	app.Post("/location/create", r.locationController.CreateLocation)
+	app.Post("/location/create", security.RequirePermission(security.PermLocationManage), security.Audit("location.create"), r.locationController.CreateLocation)
 	// app.Post("/location/update", r.locationController.UpdateLocationData)
 }
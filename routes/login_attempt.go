@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"school-notification-backend/controller"
+	"school-notification-backend/security"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type loginAttemptRoutes struct {
+	loginAttemptController controller.LoginAttemptController
+}
+
+func NewLoginAttemptRoute(loginAttemptController controller.LoginAttemptController) Routes {
+	return &loginAttemptRoutes{loginAttemptController: loginAttemptController}
+}
+
+func (r *loginAttemptRoutes) Install(app *fiber.App) {
+	app.Get("/login-attempt/all", security.RequirePermission(security.PermLoginAttemptManage), r.loginAttemptController.GetLoginAttemptAll)
+	app.Get("/login-attempt/lock/all", security.RequirePermission(security.PermLoginAttemptManage), r.loginAttemptController.GetLoginLockAll)
+
+	app.Post("/login-attempt/unlock", security.RequirePermission(security.PermLoginAttemptManage), security.Audit("login_attempt.unlock"), r.loginAttemptController.UnlockLogin)
+}
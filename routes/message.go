@@ -2,6 +2,7 @@ package routes
 
 import (
 	"school-notification-backend/controller"
+	"school-notification-backend/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,7 +16,7 @@ func NewMessageRoute(messageController controller.MessageController) Routes {
 }
 
 func (r *messageRoutes) Install(app *fiber.App) {
-	app.Get("/message/conversation-id", r.messageController.GetByConversationId)
+	app.Get("/message/conversation-id", security.RequirePermission(security.PermConversation), r.messageController.GetByConversationId)
 
-	app.Post("/message/create", r.messageController.CreateMessage)
+	app.Post("/message/create", security.RequirePermission(security.PermConversation), security.Audit("message.create"), r.messageController.CreateMessage)
 }
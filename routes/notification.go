@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"school-notification-backend/controller"
+	"school-notification-backend/security"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type notificationRoutes struct {
+	notificationController controller.NotificationController
+}
+
+func NewNotificationRoute(notificationController controller.NotificationController) Routes {
+	return &notificationRoutes{notificationController: notificationController}
+}
+
+func (r *notificationRoutes) Install(app *fiber.App) {
+	app.Get("/notification/all", security.RequirePermission(security.PermNotification), r.notificationController.GetNotificationList)
+	app.Get("/notification/unread-count", security.RequirePermission(security.PermNotification), r.notificationController.GetUnreadCount)
+
+	app.Post("/notification/read", security.RequirePermission(security.PermNotification), security.Audit("notification.read"), r.notificationController.ReadNotification)
+	app.Post("/notification/read-all", security.RequirePermission(security.PermNotification), security.Audit("notification.read_all"), r.notificationController.ReadNotificationAll)
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"school-notification-backend/controller"
+	"school-notification-backend/security"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type parentRoutes struct {
+	parentController controller.ParentController
+}
+
+func NewParentRoute(parentController controller.ParentController) Routes {
+	return &parentRoutes{parentController: parentController}
+}
+
+func (r *parentRoutes) Install(app *fiber.App) {
+	app.Get("/parent/student/all", security.RequirePermission(security.PermParentStudentRead), r.parentController.GetStudentList)
+	app.Get("/parent/student/check-name", security.RequirePermission(security.PermParentStudentRead), r.parentController.GetStudentCheckName)
+	app.Get("/parent/student/score", security.RequirePermission(security.PermParentStudentRead), r.parentController.GetStudentScore)
+	app.Get("/parent/student/summary", security.RequirePermission(security.PermParentStudentRead), r.parentController.GetStudentSummary)
+
+	app.Post("/parent/add-student", security.RequirePermission(security.PermParentManage), security.Audit("parent.add_student"), r.parentController.AddStudent)
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"school-notification-backend/controller"
+	"school-notification-backend/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,12 +16,12 @@ func NewProfileRoute(profileController controller.ProfileController) Routes {
 }
 
 func (r *profileRoutes) Install(app *fiber.App) {
-	app.Get("/profile/all", r.profileController.GetProfileAllByRole)
-	app.Get("/profile/profile_id", r.profileController.GetProfileByProfileId)
-	app.Get("/profile/id", r.profileController.GetProfileById)
-	app.Get("/profile/teacher/category", r.profileController.GetProfileTeacherByCategory)
+	app.Get("/profile/all", security.RequirePermission(security.PermProfileRead), r.profileController.GetProfileAllByRole)
+	app.Get("/profile/profile_id", security.RequirePermission(security.PermProfileRead), r.profileController.GetProfileByProfileId)
+	app.Get("/profile/id", security.RequirePermission(security.PermProfileRead), r.profileController.GetProfileById)
+	app.Get("/profile/teacher/category", security.RequirePermission(security.PermProfileRead), r.profileController.GetProfileTeacherByCategory)
 
-	app.Post("/profile/create", r.profileController.CreateNewProfile)
+	app.Post("/profile/create", security.RequirePermission(security.PermProfileManage), security.Audit("profile.create"), r.profileController.CreateNewProfile)
 	// app.Post("/profile/update", r.profileController.UpdateProfile)
 
 	// app.Post("/profile/create-admin", r.profileController.CreateAdmin)
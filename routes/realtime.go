@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"school-notification-backend/controller"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+)
+
+type realtimeRoutes struct {
+	realtimeController controller.RealtimeController
+}
+
+func NewRealtimeRoute(realtimeController controller.RealtimeController) Routes {
+	return &realtimeRoutes{realtimeController: realtimeController}
+}
+
+func (r *realtimeRoutes) Install(app *fiber.App) {
+	app.Get("/ws", r.realtimeController.Upgrade, websocket.New(r.realtimeController.Connect))
+}
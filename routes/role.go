@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"school-notification-backend/controller"
+	"school-notification-backend/security"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type roleRoutes struct {
+	roleController controller.RoleController
+}
+
+func NewRoleRoute(roleController controller.RoleController) Routes {
+	return &roleRoutes{roleController: roleController}
+}
+
+func (r *roleRoutes) Install(app *fiber.App) {
+	app.Get("/role/all", security.RequirePermission(security.PermRoleManage), r.roleController.GetRoleAll)
+	app.Get("/role/permission/all", security.RequirePermission(security.PermRoleManage), r.roleController.GetPermissionAll)
+
+	app.Post("/role/create", security.RequirePermission(security.PermRoleManage), security.Audit("role.create"), r.roleController.CreateRole)
+	app.Post("/role/update", security.RequirePermission(security.PermRoleManage), security.Audit("role.update"), r.roleController.UpdateRole)
+	app.Post("/role/delete", security.RequirePermission(security.PermRoleManage), security.Audit("role.delete"), r.roleController.DeleteRole)
+	app.Post("/role/assign", security.RequirePermission(security.PermRoleManage), security.Audit("role.assign"), r.roleController.AssignRole)
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"school-notification-backend/controller"
+	"school-notification-backend/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -16,12 +17,21 @@ func NewSchoolDataRoute(schoolDataController controller.SchoolDataController) Ro
 
 func (r *schoolDataRoutes) Install(app *fiber.App) {
 	// app.Get("/school-data/all", r.schoolDataController.)
-	app.Get("/school-data/subject-category", r.schoolDataController.GetSubjectCategory)
-	app.Get("/school-data/term-year-data", r.schoolDataController.GetTermYear)
+	app.Get("/school-data/subject-category", security.RequirePermission(security.PermSchoolDataRead), r.schoolDataController.GetSubjectCategory)
+	app.Get("/school-data/term-year-data", security.RequirePermission(security.PermSchoolDataRead), r.schoolDataController.GetTermYear)
+	app.Get("/school-data/attendance-threshold", security.RequirePermission(security.PermAttendanceThresholdRead), r.schoolDataController.GetAttendanceThreshold)
+	app.Get("/school-data/holiday", security.RequirePermission(security.PermCalendarRead), r.schoolDataController.GetHoliday)
+	app.Get("/school-data/calendar", security.RequirePermission(security.PermCalendarRead), r.schoolDataController.GetCalendar)
 	// app.Get("/school-data/id", r.schoolDataController.)
 
-	app.Post("/school-data/add-year-term", r.schoolDataController.AddYearAndTerm)
-	app.Post("/school-data/add-subject-category", r.schoolDataController.AddSubjectCategory)
-	app.Post("/school-data/end-term", r.schoolDataController.EndTerm)
+	app.Post("/school-data/add-year-term", security.RequirePermission(security.PermSchoolDataManage), security.Audit("school_data.add_year_term"), r.schoolDataController.AddYearAndTerm)
+	app.Post("/school-data/add-subject-category", security.RequirePermission(security.PermSchoolDataManage), security.Audit("school_data.add_subject_category"), r.schoolDataController.AddSubjectCategory)
+	app.Post("/school-data/end-term", security.RequirePermission(security.PermSchoolDataManage), security.Audit("school_data.end_term"), r.schoolDataController.EndTerm)
+	app.Post("/school-data/set-attendance-threshold", security.RequirePermission(security.PermSchoolDataManage), security.Audit("school_data.set_attendance_threshold"), r.schoolDataController.SetAttendanceThreshold)
+	app.Post("/school-data/add-holiday", security.RequirePermission(security.PermCalendarManage), security.Audit("calendar.add_holiday"), r.schoolDataController.AddHoliday)
+	app.Post("/school-data/delete-holiday", security.RequirePermission(security.PermCalendarManage), security.Audit("calendar.delete_holiday"), r.schoolDataController.DeleteHoliday)
+	app.Post("/school-data/set-term-date", security.RequirePermission(security.PermCalendarManage), security.Audit("calendar.set_term_date"), r.schoolDataController.SetTermDate)
+	app.Post("/school-data/add-calendar-event", security.RequirePermission(security.PermCalendarManage), security.Audit("calendar.add_event"), r.schoolDataController.AddCalendarEvent)
+	app.Post("/school-data/delete-calendar-event", security.RequirePermission(security.PermCalendarManage), security.Audit("calendar.delete_event"), r.schoolDataController.DeleteCalendarEvent)
 	// app.Post("/school-data/update", r.schoolDataController.)
 }
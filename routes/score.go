@@ -2,6 +2,7 @@ package routes
 
 import (
 	"school-notification-backend/controller"
+	"school-notification-backend/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,11 +16,11 @@ func NewScoreRoute(scoreController controller.ScoreController) Routes {
 }
 
 func (r *scoreRoutes) Install(app *fiber.App) {
-	app.Get("/score/score-in-course", r.scoreController.GetScoreByCourseId)
-	app.Get("/score/score-data", r.scoreController.GetScoreDataByCourseIdAndNameSore)
+	app.Get("/score/score-in-course", security.RequirePermission(security.PermScoreRead), r.scoreController.GetScoreByCourseId)
+	app.Get("/score/score-data", security.RequirePermission(security.PermScoreRead), r.scoreController.GetScoreDataByCourseIdAndNameSore)
 
-	app.Post("/score/create", r.scoreController.CreateScore)
+	app.Post("/score/create", security.RequirePermission(security.PermScoreManage), security.Audit("score.create"), r.scoreController.CreateScore)
 	// app.Post("/score/add-student-score", r.scoreController.AddStudentScore)
-	app.Post("/score/update-student-score", r.scoreController.UpdateStudentScore)
+	app.Post("/score/update-student-score", security.RequirePermission(security.PermScoreManage), security.Audit("score.update"), r.scoreController.UpdateStudentScore)
 
 }
@@ -13,4 +13,5 @@ func NewStaticRoutes() Routes {
 func (sr *staticRoutes) Install(app *fiber.App) {
 	app.Static("/files/information", "./storage/information")
 	app.Static("/files/profile", "./storage/profile")
+	// file of leave request is read by id with permission, see leave request route
 }
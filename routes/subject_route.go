@@ -2,6 +2,7 @@ package routes
 
 import (
 	"school-notification-backend/controller"
+	"school-notification-backend/security"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,11 +16,11 @@ func NewSubjectRoute(subjectController controller.SubjectController) Routes {
 }
 
 func (r *subjectRoutes) Install(app *fiber.App) {
-	app.Get("/subject/all", r.subjectController.GetSubjectAll)
-	app.Get("/subject/category", r.subjectController.GetSubjectByCategory)
-	app.Get("/subject/id", r.subjectController.GetSubjectById)
+	app.Get("/subject/all", security.RequirePermission(security.PermSubjectRead), r.subjectController.GetSubjectAll)
+	app.Get("/subject/category", security.RequirePermission(security.PermSubjectRead), r.subjectController.GetSubjectByCategory)
+	app.Get("/subject/id", security.RequirePermission(security.PermSubjectRead), r.subjectController.GetSubjectById)
 
-	app.Post("/subject/create", r.subjectController.CreateSubject)
-	app.Post("/subject/add-instructor", r.subjectController.AddInstructor)
+	app.Post("/subject/create", security.RequirePermission(security.PermSubjectManage), security.Audit("subject.create"), r.subjectController.CreateSubject)
+	app.Post("/subject/add-instructor", security.RequirePermission(security.PermSubjectManage), security.Audit("subject.add_instructor"), r.subjectController.AddInstructor)
 	// app.Post("/subject/update", r.subjectController.UpdateSubject)
 }
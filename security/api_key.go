@@ -0,0 +1,184 @@
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"school-notification-backend/models"
+	"school-notification-backend/repository"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	ScopeAttendanceWrite = "attendance:write"
+	ScopeAttendanceRead  = "attendance:read"
+	ScopeFaceUpload      = "face:upload"
+	ScopeFaceRead        = "face:read"
+)
+
+// scope registry, permission of each scope
+// attendance write only mark student, camera key does not end or override check name
+var scopePermissions = map[string][]string{
+	ScopeAttendanceWrite: {PermCheckNameMark},
+	ScopeAttendanceRead:  {PermCheckNameRead, PermCourseRead},
+	ScopeFaceUpload:      {PermFaceDetectionData, PermFaceDetectionRead},
+	ScopeFaceRead:        {PermFaceDetectionRead},
+}
+
+const apiKeyPrefix = "ApiKey "
+
+var ErrApiKeyInvalid = errors.New("invalid api key")
+
+var apiKeyRepo repository.ApiKeyRepository
+
+func SetApiKeyRepository(repo repository.ApiKeyRepository) {
+	apiKeyRepo = repo
+}
+
+func IsScope(scope string) bool {
+	_, ok := scopePermissions[scope]
+	return ok
+}
+
+// scope list with permission, sort by name
+func ScopeList() []map[string]interface{} {
+	names := []string{}
+	for k := range scopePermissions {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	list := []map[string]interface{}{}
+	for _, v := range names {
+		list = append(list, map[string]interface{}{
+			"scope":       v,
+			"permissions": scopePermissions[v],
+		})
+	}
+
+	return list
+}
+
+// api key is "<key id>.<secret>", send as "Authorization: ApiKey <api key>"
+func NewApiKey(keyId string) (key string, hash string, err error) {
+	b := make([]byte, 32)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", "", err
+	}
+
+	secret := hex.EncodeToString(b)
+	return keyId + "." + secret, HashApiKey(secret), nil
+}
+
+func HashApiKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func IsApiKeyActive(apiKey *models.ApiKey) bool {
+	if apiKey.Revoked {
+		return false
+	}
+
+	if apiKey.ExpiresAt == nil {
+		return true
+	}
+
+	return time.Now().Before(*apiKey.ExpiresAt)
+}
+
+func ApiKeyHasPermission(apiKey *models.ApiKey, permission string) bool {
+	for _, scope := range apiKey.Scopes {
+		for _, v := range scopePermissions[scope] {
+			if v == permission {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// nil api key is allow, restriction is check only when set
+func ApiKeyAllowClass(apiKey *models.ApiKey, classId string) bool {
+	if apiKey == nil || apiKey.ClassId == nil {
+		return true
+	}
+
+	return *apiKey.ClassId == classId
+}
+
+func ApiKeyAllowCourse(apiKey *models.ApiKey, courseId string, classId string) bool {
+	if apiKey == nil {
+		return true
+	}
+
+	if apiKey.CourseId != nil && *apiKey.CourseId != courseId {
+		return false
+	}
+
+	return ApiKeyAllowClass(apiKey, classId)
+}
+
+// api key from RequirePermission, nil when authorized by user token
+func GetApiKey(c *fiber.Ctx) *models.ApiKey {
+	apiKey, _ := c.Locals("api_key").(*models.ApiKey)
+	return apiKey
+}
+
+func checkApiKey(ctx context.Context, token string) (*models.ApiKey, error) {
+	if apiKeyRepo == nil {
+		return nil, ErrApiKeyInvalid
+	}
+
+	s := strings.SplitN(token, ".", 2)
+	if len(s) != 2 || s[0] == "" || s[1] == "" {
+		return nil, ErrApiKeyInvalid
+	}
+
+	apiKey, err := apiKeyRepo.GetById(ctx, s[0])
+	if err != nil {
+		appLogger.Warn(ctx, "check api key", "error", err)
+		return nil, ErrApiKeyInvalid
+	}
+
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(HashApiKey(s[1]))) != 1 || !IsApiKeyActive(apiKey) {
+		return nil, ErrApiKeyInvalid
+	}
+
+	_, err = apiKeyRepo.UpdateLastUsed(ctx, apiKey.Id, time.Now())
+	if err != nil {
+		appLogger.Error(ctx, "check api key", "error", err)
+	}
+
+	return apiKey, nil
+}
+
+// api key act as server user with permission from its scopes
+func authenticate(c *fiber.Ctx) (*models.User, *models.ApiKey, error) {
+	authorization := c.GetReqHeaders()["Authorization"]
+	if strings.HasPrefix(authorization, apiKeyPrefix) {
+		apiKey, err := checkApiKey(c.UserContext(), strings.TrimPrefix(authorization, apiKeyPrefix))
+		if err != nil {
+			return nil, nil, err
+		}
+		appLogger.Debug(c.UserContext(), "authorized by api key", "name", apiKey.Name)
+
+		return &models.User{Username: apiKey.Name, Role: "server"}, apiKey, nil
+	}
+
+	user, err := CheckRoleFromToken(c.UserContext(), authorization, userRepo, []string{"all"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, nil, nil
+}
@@ -0,0 +1,232 @@
+package security
+
+import (
+	"context"
+	"reflect"
+	"school-notification-backend/models"
+	"school-notification-backend/repository"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const auditTargetsKey = "audit_targets"
+
+// value of these field is never keep in audit log, only that it change
+var auditRedactFields = map[string]bool{
+	"password":             true,
+	"key_hash":             true,
+	"totp_secret":          true,
+	"totp_pending_secret":  true,
+	"recovery_code_hashes": true,
+}
+
+const auditRedacted = "[redacted]"
+
+var auditLogRepo repository.AuditLogRepository
+
+func SetAuditLogRepository(repo repository.AuditLogRepository) {
+	auditLogRepo = repo
+}
+
+type auditTarget struct {
+	collection string
+	targetId   string
+	before     bson.M
+	after      bson.M
+	// after is set, target without it is not change
+	changed bool
+}
+
+// keep entity before handler change it, call it before change document in memory
+// entity that is update many time in request keep state from first call
+func AuditBefore(c *fiber.Ctx, collection string, targetId string, doc interface{}) {
+	t := getAuditTarget(c, collection, targetId)
+	if t.before == nil {
+		t.before = auditSnapshot(c.UserContext(), doc)
+	}
+}
+
+// keep entity after change, doc nil is delete
+func AuditAfter(c *fiber.Ctx, collection string, targetId string, doc interface{}) {
+	t := getAuditTarget(c, collection, targetId)
+	t.after = auditSnapshot(c.UserContext(), doc)
+	t.changed = true
+}
+
+// write that is roll back is not a change, call when unit of work is roll back
+func AuditDiscard(c *fiber.Ctx) {
+	targets, _ := c.Locals(auditTargetsKey).([]*auditTarget)
+	for _, v := range targets {
+		v.after = nil
+		v.changed = false
+	}
+}
+
+// middleware after RequirePermission or Authenticated, record request that success with its targets
+// request that fail after some entity is change is also record, so partial change is not lost
+func Audit(action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+		if err != nil {
+			// write error response before record so status code is the one that client get
+			err = c.App().ErrorHandler(c, err)
+		}
+
+		targets := []*auditTarget{}
+		all, _ := c.Locals(auditTargetsKey).([]*auditTarget)
+		for _, v := range all {
+			if v.changed {
+				targets = append(targets, v)
+			}
+		}
+
+		if (err != nil || c.Response().StatusCode() >= fiber.StatusBadRequest) && len(targets) == 0 {
+			return err
+		}
+
+		auditLog := &models.AuditLog{
+			Id:         primitive.NewObjectID(),
+			CreatedAt:  time.Now(),
+			Action:     action,
+			Method:     c.Method(),
+			Path:       c.Path(),
+			StatusCode: c.Response().StatusCode(),
+			Targets:    []models.AuditTarget{},
+			Ip:         c.IP(),
+			UserAgent:  c.Get(fiber.HeaderUserAgent),
+		}
+
+		if user := GetUser(c); user != nil {
+			auditLog.ActorId = user.Id.Hex()
+			auditLog.ActorUsername = user.Username
+			auditLog.ActorRole = user.Role
+		}
+		if apiKey := GetApiKey(c); apiKey != nil {
+			apiKeyId := apiKey.Id.Hex()
+			auditLog.ApiKeyId = &apiKeyId
+			auditLog.ActorId = ""
+		}
+
+		for _, v := range targets {
+			auditLog.Targets = append(auditLog.Targets, models.AuditTarget{
+				Collection: v.collection,
+				TargetId:   v.targetId,
+				Changes:    auditDiff(v.before, v.after),
+			})
+		}
+
+		if auditLogRepo == nil {
+			appLogger.Warn(c.UserContext(), "audit log repository is not set")
+			return err
+		}
+
+		_, insertErr := auditLogRepo.Insert(c.UserContext(), auditLog)
+		if insertErr != nil {
+			appLogger.Error(c.UserContext(), "audit log", "action", action, "error", insertErr)
+		}
+
+		return err
+	}
+}
+
+func getAuditTarget(c *fiber.Ctx, collection string, targetId string) *auditTarget {
+	targets, _ := c.Locals(auditTargetsKey).([]*auditTarget)
+	for _, v := range targets {
+		if v.collection == collection && v.targetId == targetId {
+			return v
+		}
+	}
+
+	t := &auditTarget{collection: collection, targetId: targetId}
+	c.Locals(auditTargetsKey, append(targets, t))
+	return t
+}
+
+// copy of document as bson, so change after this is not in snapshot
+func auditSnapshot(ctx context.Context, doc interface{}) bson.M {
+	if doc == nil || (reflect.ValueOf(doc).Kind() == reflect.Ptr && reflect.ValueOf(doc).IsNil()) {
+		return nil
+	}
+
+	b, err := bson.Marshal(doc)
+	if err != nil {
+		appLogger.Warn(ctx, "audit snapshot", "error", err)
+		return nil
+	}
+
+	m := bson.M{}
+	err = bson.Unmarshal(b, &m)
+	if err != nil {
+		appLogger.Warn(ctx, "audit snapshot", "error", err)
+		return nil
+	}
+
+	return m
+}
+
+// value that is different sort by field, missing side is nil
+// nested document and array of same length are compare by path like "score_information.3.score_get"
+func auditDiff(before bson.M, after bson.M) []models.AuditChange {
+	changes := []models.AuditChange{}
+	auditDiffValue(&changes, "", before, after)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+
+	for i, v := range changes {
+		if !auditRedactFields[strings.SplitN(v.Field, ".", 2)[0]] {
+			continue
+		}
+		if v.Before != nil {
+			changes[i].Before = auditRedacted
+		}
+		if v.After != nil {
+			changes[i].After = auditRedacted
+		}
+	}
+
+	return changes
+}
+
+func auditDiffValue(changes *[]models.AuditChange, path string, before interface{}, after interface{}) {
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+
+	b, okB := before.(bson.M)
+	a, okA := after.(bson.M)
+	if (okB || before == nil) && (okA || after == nil) && (okB || okA) {
+		for k, v := range b {
+			auditDiffValue(changes, auditPath(path, k), v, a[k])
+		}
+		for k, v := range a {
+			if _, ok := b[k]; !ok {
+				auditDiffValue(changes, auditPath(path, k), nil, v)
+			}
+		}
+		return
+	}
+
+	bList, okB := before.(bson.A)
+	aList, okA := after.(bson.A)
+	if okB && okA && len(bList) == len(aList) {
+		for i := range bList {
+			auditDiffValue(changes, auditPath(path, strconv.Itoa(i)), bList[i], aList[i])
+		}
+		return
+	}
+
+	*changes = append(*changes, models.AuditChange{Field: path, Before: before, After: after})
+}
+
+func auditPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
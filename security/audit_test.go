@@ -0,0 +1,159 @@
+package security
+
+import (
+	"context"
+	"reflect"
+	"school-notification-backend/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestAuditDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before bson.M
+		after  bson.M
+		want   []models.AuditChange
+	}{
+		{
+			name:   "same",
+			before: bson.M{"name": "a", "list": bson.A{"x"}},
+			after:  bson.M{"name": "a", "list": bson.A{"x"}},
+			want:   []models.AuditChange{},
+		},
+		{
+			name:   "change sort by field",
+			before: bson.M{"status": "progress", "name": "a"},
+			after:  bson.M{"status": "end", "name": "b"},
+			want: []models.AuditChange{
+				{Field: "name", Before: "a", After: "b"},
+				{Field: "status", Before: "progress", After: "end"},
+			},
+		},
+		{
+			name:   "create",
+			before: nil,
+			after:  bson.M{"name": "a"},
+			want:   []models.AuditChange{{Field: "name", Before: nil, After: "a"}},
+		},
+		{
+			name:   "delete",
+			before: bson.M{"name": "a"},
+			after:  nil,
+			want:   []models.AuditChange{{Field: "name", Before: "a", After: nil}},
+		},
+		{
+			name:   "add and remove field",
+			before: bson.M{"old": 1},
+			after:  bson.M{"new": 2},
+			want: []models.AuditChange{
+				{Field: "new", Before: nil, After: 2},
+				{Field: "old", Before: 1, After: nil},
+			},
+		},
+		{
+			name:   "nested document",
+			before: bson.M{"data": bson.M{"a": 1, "b": 2}},
+			after:  bson.M{"data": bson.M{"a": 1, "b": 3}},
+			want:   []models.AuditChange{{Field: "data.b", Before: 2, After: 3}},
+		},
+		{
+			name:   "array of same length by index",
+			before: bson.M{"score_information": bson.A{bson.M{"score_get": 1}, bson.M{"score_get": 5}}},
+			after:  bson.M{"score_information": bson.A{bson.M{"score_get": 1}, bson.M{"score_get": 7}}},
+			want:   []models.AuditChange{{Field: "score_information.1.score_get", Before: 5, After: 7}},
+		},
+		{
+			name:   "array of other length as whole",
+			before: bson.M{"student_id_list": bson.A{"s1"}},
+			after:  bson.M{"student_id_list": bson.A{"s1", "s2"}},
+			want:   []models.AuditChange{{Field: "student_id_list", Before: bson.A{"s1"}, After: bson.A{"s1", "s2"}}},
+		},
+		{
+			name:   "document replace by value",
+			before: bson.M{"note": bson.M{"a": 1}},
+			after:  bson.M{"note": "text"},
+			want:   []models.AuditChange{{Field: "note", Before: bson.M{"a": 1}, After: "text"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := auditDiff(tt.before, tt.after)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditDiffRedact(t *testing.T) {
+	tests := []struct {
+		name   string
+		before bson.M
+		after  bson.M
+		want   []models.AuditChange
+	}{
+		{
+			name:   "password change",
+			before: bson.M{"password": "old-hash"},
+			after:  bson.M{"password": "new-hash"},
+			want:   []models.AuditChange{{Field: "password", Before: auditRedacted, After: auditRedacted}},
+		},
+		{
+			name:   "secret is set",
+			before: bson.M{},
+			after:  bson.M{"totp_secret": "JBSWY3DP"},
+			want:   []models.AuditChange{{Field: "totp_secret", Before: nil, After: auditRedacted}},
+		},
+		{
+			name:   "secret is remove",
+			before: bson.M{"totp_pending_secret": "JBSWY3DP"},
+			after:  bson.M{},
+			want:   []models.AuditChange{{Field: "totp_pending_secret", Before: auditRedacted, After: nil}},
+		},
+		{
+			name:   "element of array",
+			before: bson.M{"recovery_code_hashes": bson.A{"h1", "h2"}},
+			after:  bson.M{"recovery_code_hashes": bson.A{"h1", "h3"}},
+			want:   []models.AuditChange{{Field: "recovery_code_hashes.1", Before: auditRedacted, After: auditRedacted}},
+		},
+		{
+			name:   "other field is keep",
+			before: bson.M{"key_hash": "a", "name": "old"},
+			after:  bson.M{"key_hash": "b", "name": "new"},
+			want: []models.AuditChange{
+				{Field: "key_hash", Before: auditRedacted, After: auditRedacted},
+				{Field: "name", Before: "old", After: "new"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := auditDiff(tt.before, tt.after)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// snapshot is a copy, change after snapshot is not in it
+func TestAuditSnapshot(t *testing.T) {
+	if got := auditSnapshot(context.Background(), nil); got != nil {
+		t.Errorf("got %v for nil, want nil", got)
+	}
+	var nilUser *models.User
+	if got := auditSnapshot(context.Background(), nilUser); got != nil {
+		t.Errorf("got %v for nil pointer, want nil", got)
+	}
+
+	doc := bson.M{"name": "a"}
+	snapshot := auditSnapshot(context.Background(), doc)
+	doc["name"] = "b"
+	if snapshot["name"] != "a" {
+		t.Errorf("got %v, want %v", snapshot["name"], "a")
+	}
+}
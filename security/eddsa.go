@@ -0,0 +1,52 @@
+package security
+
+import (
+	"crypto/ed25519"
+	"errors"
+
+	"github.com/form3tech-oss/jwt-go"
+)
+
+var ErrEd25519Verification = errors.New("ed25519: verification error")
+
+// jwt-go does not have EdDSA, register it for Ed25519 key
+type SigningMethodEd25519 struct{}
+
+var SigningMethodEdDSA = &SigningMethodEd25519{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+func (m *SigningMethodEd25519) Alg() string {
+	return "EdDSA"
+}
+
+func (m *SigningMethodEd25519) Verify(signingString string, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(publicKey, []byte(signingString), sig) {
+		return ErrEd25519Verification
+	}
+
+	return nil
+}
+
+func (m *SigningMethodEd25519) Sign(signingString string, key interface{}) (string, error) {
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+
+	return jwt.EncodeSegment(ed25519.Sign(privateKey, []byte(signingString))), nil
+}
@@ -0,0 +1,177 @@
+package security
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/form3tech-oss/jwt-go"
+)
+
+// sign key is nil when only public key is configured, it can verify only
+type signingKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+var keySet = map[string]*signingKey{}
+var activeKid = ""
+var allowedAlgs = map[string]bool{}
+
+// JWT_KEYS is "kid=ALG:source,..." source is secret for HS256 and pem file path for RS256 and EdDSA
+// JWT_ACTIVE_KID is key for sign new token, other keys are still valid for verify
+// JWT_ALGORITHMS is allowed algorithm, default is algorithm of all keys
+func LoadKeysFromEnv() error {
+	keys := map[string]*signingKey{}
+	for _, v := range strings.Split(os.Getenv("JWT_KEYS"), ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		key, err := parseSigningKey(v)
+		if err != nil {
+			return err
+		}
+		if _, ok := keys[key.kid]; ok {
+			return fmt.Errorf("jwt key id %s is duplicate", key.kid)
+		}
+		keys[key.kid] = key
+	}
+
+	if len(keys) == 0 {
+		return errors.New("JWT_KEYS is not set")
+	}
+
+	algs := map[string]bool{}
+	if os.Getenv("JWT_ALGORITHMS") != "" {
+		for _, v := range strings.Split(os.Getenv("JWT_ALGORITHMS"), ",") {
+			algs[strings.TrimSpace(v)] = true
+		}
+	} else {
+		for _, key := range keys {
+			algs[key.method.Alg()] = true
+		}
+	}
+
+	for _, key := range keys {
+		if !algs[key.method.Alg()] {
+			return fmt.Errorf("jwt key %s algorithm %s is not allowed", key.kid, key.method.Alg())
+		}
+	}
+
+	kid := os.Getenv("JWT_ACTIVE_KID")
+	active, ok := keys[kid]
+	if !ok {
+		return fmt.Errorf("JWT_ACTIVE_KID %s not found in JWT_KEYS", kid)
+	}
+	if active.signKey == nil {
+		return fmt.Errorf("jwt key %s does not have private key", kid)
+	}
+
+	keySet = keys
+	activeKid = kid
+	allowedAlgs = algs
+
+	return nil
+}
+
+func parseSigningKey(s string) (*signingKey, error) {
+	kidAndKey := strings.SplitN(s, "=", 2)
+	if len(kidAndKey) != 2 || kidAndKey[0] == "" {
+		return nil, fmt.Errorf("jwt key %s is invalid", s)
+	}
+	kid := kidAndKey[0]
+
+	algAndSource := strings.SplitN(kidAndKey[1], ":", 2)
+	if len(algAndSource) != 2 || algAndSource[1] == "" {
+		return nil, fmt.Errorf("jwt key %s is invalid", kid)
+	}
+	alg, source := algAndSource[0], algAndSource[1]
+
+	switch alg {
+	case jwt.SigningMethodHS256.Alg():
+		if len(source) < 32 {
+			return nil, fmt.Errorf("jwt key %s secret must be at least 32 characters", kid)
+		}
+		return &signingKey{kid: kid, method: jwt.SigningMethodHS256, signKey: []byte(source), verifyKey: []byte(source)}, nil
+	case jwt.SigningMethodRS256.Alg():
+		b, err := os.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		if privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(b); err == nil {
+			return &signingKey{kid: kid, method: jwt.SigningMethodRS256, signKey: privateKey, verifyKey: &privateKey.PublicKey}, nil
+		}
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(b)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %v", kid, err)
+		}
+		return &signingKey{kid: kid, method: jwt.SigningMethodRS256, verifyKey: publicKey}, nil
+	case SigningMethodEdDSA.Alg():
+		b, err := os.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		block, _ := pem.Decode(b)
+		if block == nil {
+			return nil, fmt.Errorf("jwt key %s is not pem", kid)
+		}
+		if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+			privateKey, ok := key.(ed25519.PrivateKey)
+			if !ok {
+				return nil, fmt.Errorf("jwt key %s is not ed25519", kid)
+			}
+			return &signingKey{kid: kid, method: SigningMethodEdDSA, signKey: privateKey, verifyKey: privateKey.Public()}, nil
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %v", kid, err)
+		}
+		publicKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("jwt key %s is not ed25519", kid)
+		}
+		return &signingKey{kid: kid, method: SigningMethodEdDSA, verifyKey: publicKey}, nil
+	}
+
+	return nil, fmt.Errorf("jwt key %s algorithm %s is not support", kid, alg)
+}
+
+// public key of RS256 and EdDSA key for other service, secret key is not include
+func JWKS() []map[string]interface{} {
+	keys := []map[string]interface{}{}
+	for _, key := range keySet {
+		switch publicKey := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, map[string]interface{}{
+				"kty": "RSA",
+				"use": "sig",
+				"kid": key.kid,
+				"alg": key.method.Alg(),
+				"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keys = append(keys, map[string]interface{}{
+				"kty": "OKP",
+				"use": "sig",
+				"kid": key.kid,
+				"alg": key.method.Alg(),
+				"crv": "Ed25519",
+				"x":   base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		}
+	}
+
+	return keys
+}
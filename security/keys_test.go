@@ -0,0 +1,177 @@
+package security
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testHmacSecret = "0123456789abcdef0123456789abcdef"
+
+func writePem(t *testing.T, dir string, name string, blockType string, b []byte) string {
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: b}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// private and public pem of rsa and ed25519 key in temp dir
+func writeTestKeys(t *testing.T) map[string]string {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPublic, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edPublicKey, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPrivate, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPublic, err := x509.MarshalPKIXPublicKey(edPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPrivate8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notPem := filepath.Join(dir, "not.pem")
+	err = os.WriteFile(notPem, []byte("not a key"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]string{
+		"rsa_private":     writePem(t, dir, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)),
+		"rsa_public":      writePem(t, dir, "rsa.pub", "PUBLIC KEY", rsaPublic),
+		"ed_private":      writePem(t, dir, "ed.pem", "PRIVATE KEY", edPrivate),
+		"ed_public":       writePem(t, dir, "ed.pub", "PUBLIC KEY", edPublic),
+		"rsa_private_pk8": writePem(t, dir, "rsa8.pem", "PRIVATE KEY", rsaPrivate8),
+		"not_pem":         notPem,
+		"missing":         filepath.Join(dir, "missing.pem"),
+	}
+}
+
+func TestParseSigningKey(t *testing.T) {
+	paths := writeTestKeys(t)
+
+	tests := []struct {
+		name      string
+		s         string
+		kid       string
+		alg       string
+		canSign   bool
+		errSubstr string
+	}{
+		{"hmac", "k1=HS256:" + testHmacSecret, "k1", "HS256", true, ""},
+		{"hmac secret with separator", "k1=HS256:" + testHmacSecret + "=:x", "k1", "HS256", true, ""},
+		{"rsa private", "r1=RS256:" + paths["rsa_private"], "r1", "RS256", true, ""},
+		{"rsa public", "r2=RS256:" + paths["rsa_public"], "r2", "RS256", false, ""},
+		{"ed25519 private", "e1=EdDSA:" + paths["ed_private"], "e1", "EdDSA", true, ""},
+		{"ed25519 public", "e2=EdDSA:" + paths["ed_public"], "e2", "EdDSA", false, ""},
+
+		{"no kid", "=HS256:" + testHmacSecret, "", "", false, "is invalid"},
+		{"no separator", "HS256:" + testHmacSecret, "", "", false, "is invalid"},
+		{"no algorithm", "k1=" + testHmacSecret, "", "", false, "is invalid"},
+		{"empty source", "k1=HS256:", "", "", false, "is invalid"},
+		{"short secret", "k1=HS256:short", "", "", false, "at least 32 characters"},
+		{"algorithm not support", "k1=HS512:" + testHmacSecret, "", "", false, "is not support"},
+		{"none algorithm", "k1=none:" + testHmacSecret, "", "", false, "is not support"},
+		{"rsa file missing", "r1=RS256:" + paths["missing"], "", "", false, "no such file"},
+		{"rsa not pem", "r1=RS256:" + paths["not_pem"], "", "", false, "r1"},
+		{"rsa with ed25519 key", "r1=RS256:" + paths["ed_public"], "", "", false, "r1"},
+		{"ed25519 not pem", "e1=EdDSA:" + paths["not_pem"], "", "", false, "is not pem"},
+		{"ed25519 with rsa private", "e1=EdDSA:" + paths["rsa_private_pk8"], "", "", false, "is not ed25519"},
+		{"ed25519 with rsa public", "e1=EdDSA:" + paths["rsa_public"], "", "", false, "is not ed25519"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := parseSigningKey(tt.s)
+			if tt.errSubstr != "" {
+				if err == nil {
+					t.Fatalf("got key %s, want error", key.kid)
+				}
+				if !strings.Contains(err.Error(), tt.errSubstr) {
+					t.Errorf("got error %q, want it to contain %q", err, tt.errSubstr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key.kid != tt.kid {
+				t.Errorf("got kid %s, want %s", key.kid, tt.kid)
+			}
+			if key.method.Alg() != tt.alg {
+				t.Errorf("got algorithm %s, want %s", key.method.Alg(), tt.alg)
+			}
+			if (key.signKey != nil) != tt.canSign {
+				t.Errorf("got sign key %v, want %v", key.signKey != nil, tt.canSign)
+			}
+			if key.verifyKey == nil {
+				t.Error("verify key is nil")
+			}
+		})
+	}
+}
+
+// secret is not leak in error of invalid key
+func TestParseSigningKeyErrorHideSecret(t *testing.T) {
+	_, err := parseSigningKey("k1=HS256:short-secret")
+	if err == nil {
+		t.Fatal("short secret is accept")
+	}
+	if strings.Contains(err.Error(), "short-secret") {
+		t.Errorf("error %q contain secret", err)
+	}
+}
+
+// signed by private key is verified by public key of the same pair
+func TestParseSigningKeySignVerify(t *testing.T) {
+	paths := writeTestKeys(t)
+
+	for _, pair := range [][2]string{
+		{"RS256:" + paths["rsa_private"], "RS256:" + paths["rsa_public"]},
+		{"EdDSA:" + paths["ed_private"], "EdDSA:" + paths["ed_public"]},
+		{"HS256:" + testHmacSecret, "HS256:" + testHmacSecret},
+	} {
+		private, err := parseSigningKey("k1=" + pair[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		public, err := parseSigningKey("k2=" + pair[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sig, err := private.method.Sign("header.payload", private.signKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = public.method.Verify("header.payload", sig, public.verifyKey)
+		if err != nil {
+			t.Errorf("%s: %v", private.method.Alg(), err)
+		}
+	}
+}
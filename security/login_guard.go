@@ -0,0 +1,111 @@
+package security
+
+import (
+	"os"
+	"school-notification-backend/models"
+	"strconv"
+	"time"
+)
+
+const (
+	LoginLockUsername = "username"
+	LoginLockIp       = "ip"
+)
+
+// LOGIN_MAX_ATTEMPTS for username, default is 5, LOGIN_IP_MAX_ATTEMPTS for ip, default is 20
+func LoginMaxAttempts(lockType string) int {
+	key, def := "LOGIN_MAX_ATTEMPTS", 5
+	if lockType == LoginLockIp {
+		key, def = "LOGIN_IP_MAX_ATTEMPTS", 20
+	}
+
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n <= 0 {
+		return def
+	}
+
+	return n
+}
+
+// LOGIN_LOCKOUT_DURATION in env, default is 15 minutes
+func LoginLockoutDuration() time.Duration {
+	d, err := time.ParseDuration(os.Getenv("LOGIN_LOCKOUT_DURATION"))
+	if err != nil || d <= 0 {
+		return 15 * time.Minute
+	}
+
+	return d
+}
+
+// LOGIN_BACKOFF_BASE in env, default is 1 second
+func LoginBackoffBase() time.Duration {
+	d, err := time.ParseDuration(os.Getenv("LOGIN_BACKOFF_BASE"))
+	if err != nil || d <= 0 {
+		return time.Second
+	}
+
+	return d
+}
+
+// wait after fail, double each fail and not over lockout duration
+func LoginBackoff(failCount int) time.Duration {
+	if failCount <= 0 {
+		return 0
+	}
+
+	d := LoginBackoffBase()
+	for i := 1; i < failCount; i++ {
+		d *= 2
+		if d >= LoginLockoutDuration() {
+			return LoginLockoutDuration()
+		}
+	}
+
+	return d
+}
+
+// time until sign in is allow again, zero is allow
+// ip is only lock when reach max attempts, so user behind same network are not slow down
+func LoginRetryAfter(lock *models.LoginLock, now time.Time) time.Duration {
+	if lock == nil {
+		return 0
+	}
+
+	if lock.LockedUntil != nil {
+		if now.Before(*lock.LockedUntil) {
+			return lock.LockedUntil.Sub(now)
+		}
+		return 0
+	}
+
+	if lock.Type == LoginLockIp {
+		return 0
+	}
+
+	wait := lock.LastFailedAt.Add(LoginBackoff(lock.FailCount)).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+
+	return wait
+}
+
+// add fail to lock, lock when fail count reach max attempts
+func AddLoginFailure(lock *models.LoginLock, now time.Time) {
+	// lock that is expired start count again
+	if lock.LockedUntil != nil {
+		if !now.Before(*lock.LockedUntil) {
+			lock.FailCount = 0
+			lock.LockedUntil = nil
+		}
+	}
+
+	lock.FailCount++
+	lock.LastFailedAt = now
+	lock.UpdatedAt = now
+
+	if lock.FailCount >= LoginMaxAttempts(lock.Type) {
+		lockedUntil := now.Add(LoginLockoutDuration())
+		lock.LockedUntil = &lockedUntil
+	}
+}
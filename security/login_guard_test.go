@@ -0,0 +1,181 @@
+package security
+
+import (
+	"school-notification-backend/models"
+	"testing"
+	"time"
+)
+
+func setLoginEnv(t *testing.T) {
+	t.Setenv("LOGIN_MAX_ATTEMPTS", "")
+	t.Setenv("LOGIN_IP_MAX_ATTEMPTS", "")
+	t.Setenv("LOGIN_LOCKOUT_DURATION", "")
+	t.Setenv("LOGIN_BACKOFF_BASE", "")
+}
+
+func TestLoginBackoff(t *testing.T) {
+	setLoginEnv(t)
+
+	tests := []struct {
+		failCount int
+		want      time.Duration
+	}{
+		{-1, 0},
+		{0, 0},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{10, 512 * time.Second},
+		// 1024s is over lockout duration
+		{11, 15 * time.Minute},
+		{100, 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := LoginBackoff(tt.failCount); got != tt.want {
+			t.Errorf("fail count %d: got %v, want %v", tt.failCount, got, tt.want)
+		}
+	}
+}
+
+func TestLoginBackoffEnv(t *testing.T) {
+	setLoginEnv(t)
+	t.Setenv("LOGIN_BACKOFF_BASE", "100ms")
+	t.Setenv("LOGIN_LOCKOUT_DURATION", "1s")
+
+	tests := []struct {
+		failCount int
+		want      time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := LoginBackoff(tt.failCount); got != tt.want {
+			t.Errorf("fail count %d: got %v, want %v", tt.failCount, got, tt.want)
+		}
+	}
+}
+
+func TestLoginGuardInvalidEnv(t *testing.T) {
+	setLoginEnv(t)
+	t.Setenv("LOGIN_BACKOFF_BASE", "-1s")
+	t.Setenv("LOGIN_LOCKOUT_DURATION", "soon")
+	t.Setenv("LOGIN_MAX_ATTEMPTS", "0")
+	t.Setenv("LOGIN_IP_MAX_ATTEMPTS", "many")
+
+	if got := LoginBackoffBase(); got != time.Second {
+		t.Errorf("backoff base got %v, want %v", got, time.Second)
+	}
+	if got := LoginLockoutDuration(); got != 15*time.Minute {
+		t.Errorf("lockout duration got %v, want %v", got, 15*time.Minute)
+	}
+	if got := LoginMaxAttempts(LoginLockUsername); got != 5 {
+		t.Errorf("max attempts of username got %d, want 5", got)
+	}
+	if got := LoginMaxAttempts(LoginLockIp); got != 20 {
+		t.Errorf("max attempts of ip got %d, want 20", got)
+	}
+}
+
+func TestAddLoginFailure(t *testing.T) {
+	setLoginEnv(t)
+	now := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	lock := &models.LoginLock{Type: LoginLockUsername}
+
+	for i := 1; i < 5; i++ {
+		AddLoginFailure(lock, now)
+		if lock.FailCount != i {
+			t.Fatalf("got fail count %d, want %d", lock.FailCount, i)
+		}
+		if lock.LockedUntil != nil {
+			t.Fatalf("lock at fail %d before max attempts", i)
+		}
+	}
+	if !lock.LastFailedAt.Equal(now) || !lock.UpdatedAt.Equal(now) {
+		t.Errorf("time of fail is not set")
+	}
+
+	AddLoginFailure(lock, now)
+	if lock.LockedUntil == nil {
+		t.Fatal("not lock when reach max attempts")
+	}
+	if want := now.Add(15 * time.Minute); !lock.LockedUntil.Equal(want) {
+		t.Errorf("locked until got %v, want %v", lock.LockedUntil, want)
+	}
+}
+
+func TestAddLoginFailureIp(t *testing.T) {
+	setLoginEnv(t)
+	now := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	lock := &models.LoginLock{Type: LoginLockIp}
+
+	for i := 0; i < 19; i++ {
+		AddLoginFailure(lock, now)
+	}
+	if lock.LockedUntil != nil {
+		t.Fatal("ip is lock before max attempts")
+	}
+
+	AddLoginFailure(lock, now)
+	if lock.LockedUntil == nil {
+		t.Fatal("ip is not lock when reach max attempts")
+	}
+}
+
+func TestAddLoginFailureAfterLock(t *testing.T) {
+	setLoginEnv(t)
+	now := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	lockedUntil := now.Add(time.Minute)
+
+	// fail during lock keep counting and extend lock
+	lock := &models.LoginLock{Type: LoginLockUsername, FailCount: 5, LockedUntil: &lockedUntil}
+	AddLoginFailure(lock, now)
+	if lock.FailCount != 6 {
+		t.Errorf("got fail count %d, want 6", lock.FailCount)
+	}
+	if want := now.Add(15 * time.Minute); lock.LockedUntil == nil || !lock.LockedUntil.Equal(want) {
+		t.Errorf("locked until got %v, want %v", lock.LockedUntil, want)
+	}
+
+	// expired lock start count again
+	lockedUntil = now.Add(-time.Second)
+	lock = &models.LoginLock{Type: LoginLockUsername, FailCount: 5, LockedUntil: &lockedUntil}
+	AddLoginFailure(lock, now)
+	if lock.FailCount != 1 {
+		t.Errorf("got fail count %d, want 1", lock.FailCount)
+	}
+	if lock.LockedUntil != nil {
+		t.Errorf("expired lock is not clear")
+	}
+}
+
+func TestLoginRetryAfter(t *testing.T) {
+	setLoginEnv(t)
+	now := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	lockedUntil := now.Add(time.Minute)
+	expired := now.Add(-time.Second)
+
+	tests := []struct {
+		name string
+		lock *models.LoginLock
+		want time.Duration
+	}{
+		{"no lock", nil, 0},
+		{"locked", &models.LoginLock{Type: LoginLockUsername, LockedUntil: &lockedUntil}, time.Minute},
+		{"lock expired", &models.LoginLock{Type: LoginLockUsername, LockedUntil: &expired}, 0},
+		{"backoff", &models.LoginLock{Type: LoginLockUsername, FailCount: 3, LastFailedAt: now.Add(-time.Second)}, 3 * time.Second},
+		{"backoff passed", &models.LoginLock{Type: LoginLockUsername, FailCount: 1, LastFailedAt: now.Add(-time.Minute)}, 0},
+		{"ip has no backoff", &models.LoginLock{Type: LoginLockIp, FailCount: 3, LastFailedAt: now}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LoginRetryAfter(tt.lock, now); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package security
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var ErrPasswordChangeRequired = errors.New("password change required")
+
+const temporaryPasswordChars = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789"
+
+// PASSWORD_MIN_LENGTH in env, default is 8
+func PasswordMinLength() int {
+	n, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH"))
+	if err != nil || n <= 0 {
+		return 8
+	}
+
+	return n
+}
+
+// password must have min length, letter and digit and must not be the username
+func CheckPasswordPolicy(password string, username string) error {
+	if len(password) < PasswordMinLength() {
+		return errors.New("password must be at least " + strconv.Itoa(PasswordMinLength()) + " characters")
+	}
+
+	if strings.EqualFold(password, username) {
+		return errors.New("password must not be the username")
+	}
+
+	letter := false
+	digit := false
+	for _, r := range password {
+		if unicode.IsLetter(r) {
+			letter = true
+		}
+		if unicode.IsDigit(r) {
+			digit = true
+		}
+	}
+	if !letter || !digit {
+		return errors.New("password must have letter and digit")
+	}
+
+	return nil
+}
+
+// temporary password pass the policy, user must change it on next sign in
+func NewTemporaryPassword() (string, error) {
+	n := PasswordMinLength()
+	if n < 12 {
+		n = 12
+	}
+
+	for {
+		b := make([]byte, n)
+		for i := range b {
+			k, err := rand.Int(rand.Reader, big.NewInt(int64(len(temporaryPasswordChars))))
+			if err != nil {
+				return "", err
+			}
+			b[i] = temporaryPasswordChars[k.Int64()]
+		}
+
+		password := string(b)
+		if CheckPasswordPolicy(password, "") == nil {
+			return password, nil
+		}
+	}
+}
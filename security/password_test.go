@@ -0,0 +1,110 @@
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	t.Setenv("PASSWORD_MIN_LENGTH", "")
+
+	tests := []struct {
+		name      string
+		password  string
+		username  string
+		errSubstr string
+	}{
+		{"valid", "abcdefg1", "student01", ""},
+		{"letter and digit anywhere", "1234567a", "student01", ""},
+		{"symbol is allow", "p@ssw0rd!", "student01", ""},
+		{"empty username", "abcdefg1", "", ""},
+		{"empty", "", "student01", "at least 8 characters"},
+		{"one short of min", "abcdef1", "student01", "at least 8 characters"},
+		{"only letter", "abcdefgh", "student01", "letter and digit"},
+		{"only digit", "12345678", "student01", "letter and digit"},
+		{"only symbol", "!@#$%^&*", "student01", "letter and digit"},
+		{"same as username", "student01", "student01", "not be the username"},
+		{"username with other case", "STUDENT01", "student01", "not be the username"},
+		{"contain username is allow", "student01x", "student01", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckPasswordPolicy(tt.password, tt.username)
+			if tt.errSubstr == "" {
+				if err != nil {
+					t.Errorf("got error %q, want nil", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("got nil, want error %q", tt.errSubstr)
+			}
+			if !strings.Contains(err.Error(), tt.errSubstr) {
+				t.Errorf("got error %q, want it to contain %q", err, tt.errSubstr)
+			}
+		})
+	}
+}
+
+func TestCheckPasswordPolicyMinLength(t *testing.T) {
+	t.Setenv("PASSWORD_MIN_LENGTH", "12")
+
+	if err := CheckPasswordPolicy("abcdefghij1", "student01"); err == nil || !strings.Contains(err.Error(), "at least 12 characters") {
+		t.Errorf("got %v, want error of min length 12", err)
+	}
+	if err := CheckPasswordPolicy("abcdefghijk1", "student01"); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+}
+
+func TestPasswordMinLength(t *testing.T) {
+	tests := []struct {
+		env  string
+		want int
+	}{
+		{"", 8},
+		{"10", 10},
+		{"0", 8},
+		{"-1", 8},
+		{"ten", 8},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("PASSWORD_MIN_LENGTH", tt.env)
+		if got := PasswordMinLength(); got != tt.want {
+			t.Errorf("env %q: got %d, want %d", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestNewTemporaryPassword(t *testing.T) {
+	tests := []struct {
+		env  string
+		want int
+	}{
+		{"", 12},
+		{"16", 16},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("PASSWORD_MIN_LENGTH", tt.env)
+
+		password, err := NewTemporaryPassword()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(password) != tt.want {
+			t.Errorf("env %q: got length %d, want %d", tt.env, len(password), tt.want)
+		}
+		if err := CheckPasswordPolicy(password, ""); err != nil {
+			t.Errorf("temporary password does not pass policy: %v", err)
+		}
+		for _, r := range password {
+			if !strings.ContainsRune(temporaryPasswordChars, r) {
+				t.Errorf("temporary password has character %q", r)
+			}
+		}
+	}
+}
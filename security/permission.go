@@ -0,0 +1,281 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"school-notification-backend/models"
+	"school-notification-backend/repository"
+	"school-notification-backend/util"
+	"sort"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	PermSchoolDataRead          = "school_data.read"
+	PermSchoolDataManage        = "school_data.manage"
+	PermAttendanceThresholdRead = "attendance_threshold.read"
+	PermCalendarRead            = "calendar.read"
+	PermCalendarManage          = "calendar.manage"
+	PermInformationRead         = "information.read"
+	PermInformationManage       = "information.manage"
+	PermSubjectRead             = "subject.read"
+	PermSubjectManage           = "subject.manage"
+	PermClassRead               = "class.read"
+	PermClassManage             = "class.manage"
+	PermLocationRead            = "location.read"
+	PermLocationManage          = "location.manage"
+	PermProfileRead             = "profile.read"
+	PermProfileManage           = "profile.manage"
+	PermCourseRead              = "course.read"
+	PermCourseManage            = "course.manage"
+	PermCourseTeach             = "course.teach"
+	PermScoreRead               = "score.read"
+	PermScoreManage             = "score.manage"
+	PermCheckNameRead           = "check_name.read"
+	PermCheckNameManage         = "check_name.manage"
+	PermCheckNameMark           = "check_name.mark"
+	PermCourseSummaryRead       = "course_summary.read"
+	PermCourseSummaryReadOwn    = "course_summary.read_own"
+	PermCourseSummaryManage     = "course_summary.manage"
+	PermFaceDetectionCamera     = "face_detection.camera"
+	PermFaceDetectionCreate     = "face_detection.create"
+	PermFaceDetectionData       = "face_detection.data"
+	PermFaceDetectionRead       = "face_detection.read"
+	PermLeaveRequestCreate      = "leave_request.create"
+	PermLeaveRequestRead        = "leave_request.read"
+	PermLeaveRequestApprove     = "leave_request.approve"
+	PermAttendanceReport        = "attendance.report"
+	PermAttendanceNotify        = "attendance.notify"
+	PermParentStudentRead       = "parent.student_read"
+	PermParentManage            = "parent.manage"
+	PermConversation            = "conversation.use"
+	PermNotification            = "notification.use"
+	PermSessionManage           = "session.manage"
+	PermRoleManage              = "role.manage"
+	PermApiKeyManage            = "api_key.manage"
+	PermPasswordReset           = "password.reset"
+	PermLoginAttemptManage      = "login_attempt.manage"
+	PermTwoFactorManage         = "two_factor.manage"
+	PermAuditLogRead            = "audit_log.read"
+)
+
+var BuiltInRoles = []string{"admin", "teacher", "student", "parent", "server"}
+
+// permission registry, built-in role of each permission
+var permissionRoles = map[string][]string{
+	PermSchoolDataRead:          {"admin", "teacher", "student", "parent", "server"},
+	PermSchoolDataManage:        {"admin"},
+	PermAttendanceThresholdRead: {"admin", "teacher"},
+	PermCalendarRead:            {"admin", "teacher", "student", "parent", "server"},
+	PermCalendarManage:          {"admin"},
+	PermInformationRead:         {"admin", "teacher", "student", "parent", "server"},
+	PermInformationManage:       {"admin"},
+	PermSubjectRead:             {"admin", "teacher", "student", "parent", "server"},
+	PermSubjectManage:           {"admin"},
+	PermClassRead:               {"admin", "teacher", "student", "parent", "server"},
+	PermClassManage:             {"admin"},
+	PermLocationRead:            {"admin", "teacher", "student", "parent", "server"},
+	PermLocationManage:          {"admin"},
+	PermProfileRead:             {"admin", "teacher", "student", "parent", "server"},
+	PermProfileManage:           {"admin"},
+	PermCourseRead:              {"admin", "teacher", "student", "parent", "server"},
+	PermCourseManage:            {"admin"},
+	PermCourseTeach:             {"admin", "teacher"},
+	PermScoreRead:               {"admin", "teacher", "student", "parent", "server"},
+	PermScoreManage:             {"admin", "teacher"},
+	PermCheckNameRead:           {"admin", "teacher", "student", "parent", "server"},
+	PermCheckNameManage:         {"admin", "teacher"},
+	PermCheckNameMark:           {"admin", "teacher"},
+	PermCourseSummaryRead:       {"admin", "teacher", "student", "parent", "server"},
+	PermCourseSummaryReadOwn:    {"student"},
+	PermCourseSummaryManage:     {"admin", "teacher"},
+	PermFaceDetectionCamera:     {"admin", "teacher"},
+	PermFaceDetectionCreate:     {"admin"},
+	PermFaceDetectionData:       {"admin", "server"},
+	PermFaceDetectionRead:       {"admin", "server", "teacher"},
+	PermLeaveRequestCreate:      {"student", "parent"},
+	PermLeaveRequestRead:        {"admin", "teacher", "student", "parent", "server"},
+	PermLeaveRequestApprove:     {"admin", "teacher"},
+	PermAttendanceReport:        {"admin", "teacher"},
+	PermAttendanceNotify:        {"admin"},
+	PermParentStudentRead:       {"parent"},
+	PermParentManage:            {"admin"},
+	PermConversation:            {"admin", "teacher", "student", "parent", "server"},
+	PermNotification:            {"admin", "teacher", "student", "parent", "server"},
+	PermSessionManage:           {"admin"},
+	PermRoleManage:              {"admin"},
+	PermApiKeyManage:            {"admin"},
+	PermPasswordReset:           {"admin"},
+	PermLoginAttemptManage:      {"admin"},
+	PermTwoFactorManage:         {"admin"},
+	PermAuditLogRead:            {"admin"},
+}
+
+var errNotPermission = errors.New("not permission")
+
+var userRepo repository.UsersRepository
+var roleRepo repository.RoleRepository
+
+func SetUserRepository(repo repository.UsersRepository) {
+	userRepo = repo
+}
+
+func SetRoleRepository(repo repository.RoleRepository) {
+	roleRepo = repo
+}
+
+func IsBuiltInRole(role string) bool {
+	for _, v := range BuiltInRoles {
+		if v == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// built-in role or custom role in roles collection
+func IsRole(ctx context.Context, name string) (bool, error) {
+	if IsBuiltInRole(name) {
+		return true, nil
+	}
+
+	if roleRepo == nil {
+		return false, nil
+	}
+
+	_, err := roleRepo.GetByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func IsPermission(permission string) bool {
+	_, ok := permissionRoles[permission]
+	return ok
+}
+
+// permission list with built-in role, sort by name
+func PermissionList() []map[string]interface{} {
+	names := []string{}
+	for k := range permissionRoles {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	list := []map[string]interface{}{}
+	for _, v := range names {
+		list = append(list, map[string]interface{}{
+			"permission": v,
+			"roles":      permissionRoles[v],
+		})
+	}
+
+	return list
+}
+
+// built-in role use the registry, other role use permission set of custom role
+func HasPermission(ctx context.Context, user *models.User, permission string) (bool, error) {
+	if IsBuiltInRole(user.Role) {
+		for _, v := range permissionRoles[permission] {
+			if v == user.Role {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if roleRepo == nil {
+		return false, nil
+	}
+
+	role, err := roleRepo.GetByName(ctx, user.Role)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, v := range role.Permissions {
+		if v == permission {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// authenticate user and keep it in locals
+func Authenticated() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, apiKey, err := authenticate(c)
+		if err != nil {
+			appLogger.Warn(c.UserContext(), "authenticated", "error", err)
+			return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
+		}
+
+		c.Locals("user", user)
+		c.Locals("api_key", apiKey)
+		return c.Next()
+	}
+}
+
+func RequirePermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, apiKey, err := authenticate(c)
+		if err != nil {
+			appLogger.Warn(c.UserContext(), "require permission", "error", err)
+			return util.ResponseNotSuccess(c, fiber.ErrUnauthorized.Code, err.Error())
+		}
+
+		// user with temporary password can only change password
+		if user.MustChangePassword {
+			appLogger.Warn(c.UserContext(), "require permission", "error", ErrPasswordChangeRequired)
+			return util.ResponseNotSuccess(c, fiber.StatusForbidden, ErrPasswordChangeRequired.Error())
+		}
+
+		if apiKey == nil {
+			err = checkTwoFactorEnrolled(c.UserContext(), user)
+			if err == ErrTwoFactorEnrollRequired {
+				appLogger.Warn(c.UserContext(), "require permission", "error", err)
+				return util.ResponseNotSuccess(c, fiber.StatusForbidden, err.Error())
+			}
+			if err != nil {
+				appLogger.Error(c.UserContext(), "require permission", "error", err)
+				return util.ResponseError(c, err)
+			}
+		}
+
+		ok := false
+		if apiKey != nil {
+			ok = ApiKeyHasPermission(apiKey, permission)
+		} else {
+			ok, err = HasPermission(c.UserContext(), user, permission)
+			if err != nil {
+				appLogger.Error(c.UserContext(), "require permission", "error", err)
+				return util.ResponseError(c, err)
+			}
+		}
+		if !ok {
+			appLogger.Warn(c.UserContext(), "not permission", "permission", permission)
+			return util.ResponseNotSuccess(c, fiber.StatusForbidden, errNotPermission.Error())
+		}
+
+		c.Locals("user", user)
+		c.Locals("api_key", apiKey)
+		return c.Next()
+	}
+}
+
+// user from Authenticated or RequirePermission
+func GetUser(c *fiber.Ctx) *models.User {
+	user, _ := c.Locals("user").(*models.User)
+	return user
+}
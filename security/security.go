@@ -1,13 +1,15 @@
 package security
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"os"
+	"school-notification-backend/logger"
 	"school-notification-backend/models"
 	"school-notification-backend/repository"
 	"strings"
+	"sync"
 
 	"time"
 
@@ -15,8 +17,12 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-var jwtSecretKey = []byte("")
-var jwtSingingMethod = jwt.SigningMethodHS256.Name
+// replace by SetLogger in main, default is for use before that
+var appLogger = logger.New(os.Stdout, logger.LevelInfo)
+
+func SetLogger(l logger.Logger) {
+	appLogger = l
+}
 
 func EncryptPassword(password string) (string, error) {
 	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -31,33 +37,61 @@ func VerifyPassword(hashed string, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password))
 }
 
-func NewToken(userId string) (string, error) {
+var dummyPasswordHash []byte
+var dummyPasswordOnce sync.Once
+
+// compare password of unknown user with hash of same cost, so it take the same time as wrong password
+func VerifyDummyPassword(password string) {
+	dummyPasswordOnce.Do(func() {
+		dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	})
+	bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+}
+
+// subject is session id
+func NewToken(userId string, sessionId string) (string, error) {
 	claims := jwt.StandardClaims{
 		Id:        userId,
 		Issuer:    userId,
+		Subject:   sessionId,
 		IssuedAt:  time.Now().Unix(),
 		ExpiresAt: time.Now().Add(time.Minute * 60).Unix(),
 	}
 
-	tokenStr := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return signClaims(claims)
+}
+
+func signClaims(claims jwt.StandardClaims) (string, error) {
+	key, ok := keySet[activeKid]
+	if !ok || key.signKey == nil {
+		return "", errors.New("signing key is not set")
+	}
+
+	tokenStr := jwt.NewWithClaims(key.method, claims)
+	tokenStr.Header["kid"] = key.kid
 
-	return tokenStr.SignedString(jwtSecretKey)
+	return tokenStr.SignedString(key.signKey)
 }
 
+// key is select by kid and algorithm must match the key and allowed algorithm
 func ValidateSignedMethod(token *jwt.Token) (interface{}, error) {
-	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := keySet[kid]
+	if !ok {
+		return nil, fmt.Errorf("Unexpected key id: %v", token.Header["kid"])
+	}
+
+	if !allowedAlgs[token.Method.Alg()] || token.Method.Alg() != key.method.Alg() {
 		return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
 	}
-	return jwtSecretKey, nil
+	return key.verifyKey, nil
 }
 
 func ParseToken(tokenStr string) (*jwt.StandardClaims, error) {
 
-	log.Println(tokenStr)
 	claims := new(jwt.StandardClaims)
 	token, err := jwt.ParseWithClaims(strings.Split(tokenStr, " ")[1], claims, ValidateSignedMethod)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
@@ -70,31 +104,34 @@ func ParseToken(tokenStr string) (*jwt.StandardClaims, error) {
 	return claims, nil
 }
 
-func CheckRoleFromToken(token string, userRepo repository.UsersRepository, rc []string) (*models.User, error) {
-	if tryApikey(token) {
-		log.Println("authorized by apikey")
-		user := models.User{
-			Role: "server",
-		}
-		return &user, nil
-	}
-
+func CheckRoleFromToken(ctx context.Context, token string, userRepo repository.UsersRepository, rc []string) (*models.User, error) {
 	if len(token) <= 7 || token[0:7] != "Bearer " {
 		return nil, errors.New("invalid auth token")
 	}
 
 	payload, err := ParseToken(token)
 	if err != nil {
-		log.Println(err)
+		appLogger.Warn(ctx, "check role from token", "error", err)
+		return nil, err
+	}
+
+	// challenge token of two factor sign in is not access token
+	if payload.VerifyAudience(twoFactorAudience, true) {
+		return nil, errors.New("invalid auth token")
+	}
+
+	err = checkSession(ctx, payload)
+	if err != nil {
+		appLogger.Warn(ctx, "check role from token", "error", err)
 		return nil, err
 	}
 	// log.Println(payload)
-	user, err := userRepo.GetById(payload.Id)
+	user, err := userRepo.GetById(ctx, payload.Id)
 	if err != nil {
-		log.Println(err)
+		appLogger.Warn(ctx, "check role from token", "error", err)
 		return nil, err
 	}
-	log.Println(user)
+	appLogger.Debug(ctx, "authorized user", "username", user.Username)
 
 	check := true
 	for _, v := range rc {
@@ -110,35 +147,3 @@ func CheckRoleFromToken(token string, userRepo repository.UsersRepository, rc []
 
 	return user, nil
 }
-
-func CheckRoleFromTokenInGet(token string, userRepo repository.UsersRepository, event string, role string) error {
-	payload, err := ParseToken(token)
-	if err != nil {
-		log.Println(err)
-		return err
-	}
-	user, err := userRepo.GetById(payload.Id)
-	if err != nil {
-		log.Println(err)
-		return err
-	}
-
-	if event == "get_all_profile" {
-		if user.Role != "admin" && user.Role != "teacher" {
-			return errors.New("You are not permission")
-		}
-	} else if event == "get_courses_id" {
-		if user.Role != "admin" {
-			if user.Role == "teacher" {
-
-			}
-		}
-	}
-	return nil
-}
-
-func tryApikey(authorization string) bool {
-	apikey := os.Getenv("APIKEY")
-
-	return authorization == apikey
-}
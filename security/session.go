@@ -0,0 +1,94 @@
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"school-notification-backend/models"
+	"school-notification-backend/repository"
+	"strings"
+	"time"
+
+	"github.com/form3tech-oss/jwt-go"
+)
+
+var ErrSessionRevoked = errors.New("session is revoked")
+var ErrRefreshTokenInvalid = errors.New("invalid refresh token")
+
+// token is not accept when session can not be check
+var errSessionRepositoryNotSet = errors.New("session repository is not set")
+
+var sessionRepo repository.SessionRepository
+
+// session of token is check in CheckRoleFromToken, token is reject until it is set
+func SetSessionRepository(repo repository.SessionRepository) {
+	sessionRepo = repo
+}
+
+// REFRESH_TOKEN_TTL in env, default is 30 days
+func RefreshTokenTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL"))
+	if err != nil || ttl <= 0 {
+		return 30 * 24 * time.Hour
+	}
+
+	return ttl
+}
+
+// refresh token is "<session id>.<secret>"
+func NewRefreshToken(sessionId string) (token string, hash string, err error) {
+	b := make([]byte, 32)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", "", err
+	}
+
+	secret := hex.EncodeToString(b)
+	return sessionId + "." + secret, HashRefreshToken(secret), nil
+}
+
+func HashRefreshToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func SplitRefreshToken(token string) (sessionId string, secret string, err error) {
+	s := strings.SplitN(token, ".", 2)
+	if len(s) != 2 || s[0] == "" || s[1] == "" {
+		return "", "", ErrRefreshTokenInvalid
+	}
+
+	return s[0], s[1], nil
+}
+
+func IsSessionActive(session *models.Session) bool {
+	if session.Revoked {
+		return false
+	}
+
+	return time.Now().Before(session.ExpiresAt)
+}
+
+func checkSession(ctx context.Context, claims *jwt.StandardClaims) error {
+	if sessionRepo == nil {
+		return errSessionRepositoryNotSet
+	}
+
+	if claims.Subject == "" {
+		return errors.New("invalid auth token")
+	}
+
+	session, err := sessionRepo.GetById(ctx, claims.Subject)
+	if err != nil {
+		return err
+	}
+
+	if session.UserId != claims.Id || !IsSessionActive(session) {
+		return ErrSessionRevoked
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	totpDigits = 6
+	totpPeriod = 30
+	// step before and after that is accept for clock drift
+	totpSkew = 1
+
+	recoveryCodeCount = 10
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTP_ISSUER in env, default is school-notification
+func TotpIssuer() string {
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		return "school-notification"
+	}
+
+	return issuer
+}
+
+// 160 bit secret in base32
+func NewTotpSecret() (string, error) {
+	b := make([]byte, 20)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// provisioning uri for authenticator app, client render it as qr code
+func TotpURI(secret string, account string) string {
+	issuer := TotpIssuer()
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprint(totpDigits))
+	v.Set("period", fmt.Sprint(totpPeriod))
+
+	return "otpauth://totp/" + url.PathEscape(issuer+":"+account) + "?" + v.Encode()
+}
+
+func totpCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	n := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, n%1000000), nil
+}
+
+// step of code that match, code of step not after lastStep is reject so it can not use again
+func VerifyTotp(secret string, code string, lastStep int64, now time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for i := -totpSkew; i <= totpSkew; i++ {
+		step := current + int64(i)
+		if step <= lastStep {
+			continue
+		}
+
+		expected, err := totpCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// recovery code is return once, keep only hash
+func NewRecoveryCodes() (codes []string, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		_, err = rand.Read(b)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(totpEncoding.EncodeToString(b))
+		code = code[:4] + "-" + code[4:]
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+func HashRecoveryCode(code string) string {
+	return HashApiKey(strings.ToLower(strings.TrimSpace(code)))
+}
+
+// index of recovery code that match, -1 is not match
+func MatchRecoveryCode(hashes []string, code string) int {
+	hash := HashRecoveryCode(code)
+	for i, v := range hashes {
+		if subtle.ConstantTimeCompare([]byte(v), []byte(hash)) == 1 {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,202 @@
+package security
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+// base32 of ascii "12345678901234567890", sha1 seed of rfc 6238 appendix b
+const rfcTotpSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// rfc 6238 give 8 digits, code is the last 6 digits of it
+func TestTotpCodeRfc6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := totpCode(rfcTotpSecret, tt.unix/totpPeriod)
+		if err != nil {
+			t.Fatalf("time %d: %v", tt.unix, err)
+		}
+		if got != tt.code {
+			t.Errorf("time %d: got %s, want %s", tt.unix, got, tt.code)
+		}
+	}
+}
+
+func TestTotpCodeLowerSecret(t *testing.T) {
+	got, err := totpCode("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "287082" {
+		t.Errorf("got %s, want %s", got, "287082")
+	}
+}
+
+func TestTotpCodeInvalidSecret(t *testing.T) {
+	if _, err := totpCode("not base32!", 1); err == nil {
+		t.Error("invalid secret is accept")
+	}
+}
+
+func TestVerifyTotpSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / totpPeriod
+
+	tests := []struct {
+		name string
+		step int64
+		ok   bool
+	}{
+		{"current step", current, true},
+		{"one step before", current - totpSkew, true},
+		{"one step after", current + totpSkew, true},
+		{"outside window before", current - totpSkew - 1, false},
+		{"outside window after", current + totpSkew + 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := totpCode(rfcTotpSecret, tt.step)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			step, ok := VerifyTotp(rfcTotpSecret, code, 0, now)
+			if ok != tt.ok {
+				t.Fatalf("got %v, want %v", ok, tt.ok)
+			}
+			if ok && step != tt.step {
+				t.Errorf("got step %d, want %d", step, tt.step)
+			}
+		})
+	}
+}
+
+// first and last second of a step have the same window
+func TestVerifyTotpStepEdge(t *testing.T) {
+	current := int64(1111111111) / totpPeriod
+	code, err := totpCode(rfcTotpSecret, current-totpSkew)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := VerifyTotp(rfcTotpSecret, code, 0, time.Unix((current+1)*totpPeriod-1, 0)); !ok {
+		t.Error("code is reject at the last second of the window")
+	}
+	if _, ok := VerifyTotp(rfcTotpSecret, code, 0, time.Unix((current+1)*totpPeriod, 0)); ok {
+		t.Error("code is accept after the window")
+	}
+}
+
+func TestVerifyTotpReplay(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / totpPeriod
+	code, err := totpCode(rfcTotpSecret, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	step, ok := VerifyTotp(rfcTotpSecret, code, 0, now)
+	if !ok {
+		t.Fatal("code is reject")
+	}
+	if _, ok := VerifyTotp(rfcTotpSecret, code, step, now); ok {
+		t.Error("code of used step is accept again")
+	}
+
+	// code of step before the used step is reject too
+	previous, err := totpCode(rfcTotpSecret, current-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := VerifyTotp(rfcTotpSecret, previous, step, now); ok {
+		t.Error("code of step before used step is accept")
+	}
+
+	next, err := totpCode(rfcTotpSecret, current+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := VerifyTotp(rfcTotpSecret, next, step, now); !ok || got != current+1 {
+		t.Errorf("code of next step got %d %v, want %d true", got, ok, current+1)
+	}
+}
+
+func TestVerifyTotpFormat(t *testing.T) {
+	now := time.Unix(59, 0)
+	tests := []struct {
+		name string
+		code string
+		ok   bool
+	}{
+		{"space is trim", " 287082 ", true},
+		{"short", "28708", false},
+		{"long", "2870820", false},
+		{"eight digits", "94287082", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := VerifyTotp(rfcTotpSecret, tt.code, 0, now); ok != tt.ok {
+				t.Errorf("got %v, want %v", ok, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRecoveryCode(t *testing.T) {
+	codes, hashes, err := NewRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("got %d codes and %d hashes, want %d", len(codes), len(hashes), recoveryCodeCount)
+	}
+
+	format := regexp.MustCompile(`^[a-z2-7]{4}-[a-z2-7]{4}$`)
+	for i, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("code %q has wrong format", code)
+		}
+		if hashes[i] == code {
+			t.Errorf("code %q is keep as plain text", code)
+		}
+		if hashes[i] != HashRecoveryCode(code) {
+			t.Errorf("hash of code %q does not match", code)
+		}
+		if got := MatchRecoveryCode(hashes, code); got != i {
+			t.Errorf("match code %q got %d, want %d", code, got, i)
+		}
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	hash := HashRecoveryCode("abcd-efgh")
+	for _, code := range []string{"ABCD-EFGH", " abcd-efgh ", "AbCd-EfGh\n"} {
+		if got := HashRecoveryCode(code); got != hash {
+			t.Errorf("hash of %q is not the same as lower code", code)
+		}
+	}
+	if HashRecoveryCode("abcd-efgi") == hash {
+		t.Error("different code has the same hash")
+	}
+	if got := MatchRecoveryCode([]string{hash}, "abcd-efgi"); got != -1 {
+		t.Errorf("got %d, want -1", got)
+	}
+	if got := MatchRecoveryCode(nil, "abcd-efgh"); got != -1 {
+		t.Errorf("got %d, want -1", got)
+	}
+}
@@ -0,0 +1,79 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"school-notification-backend/models"
+	"school-notification-backend/repository"
+	"school-notification-backend/util"
+	"time"
+
+	"github.com/form3tech-oss/jwt-go"
+)
+
+const twoFactorAudience = "two_factor"
+
+var ErrTwoFactorEnrollRequired = errors.New("two factor enrollment required")
+var ErrChallengeTokenInvalid = errors.New("invalid challenge token")
+
+var twoFactorPolicyRepo repository.TwoFactorPolicyRepository
+
+func SetTwoFactorPolicyRepository(repo repository.TwoFactorPolicyRepository) {
+	twoFactorPolicyRepo = repo
+}
+
+// role that admin enforce two factor
+func IsTwoFactorRequired(ctx context.Context, role string) (bool, error) {
+	if twoFactorPolicyRepo == nil {
+		return false, nil
+	}
+
+	policy, err := twoFactorPolicyRepo.GetByRole(ctx, role)
+	if err != nil {
+		if errors.Is(err, util.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return policy.Required, nil
+}
+
+// challenge token is valid 5 minutes and only exchange for token with totp code
+func NewChallengeToken(userId string) (string, error) {
+	return signClaims(jwt.StandardClaims{
+		Id:        userId,
+		Issuer:    userId,
+		Audience:  []string{twoFactorAudience},
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: time.Now().Add(5 * time.Minute).Unix(),
+	})
+}
+
+// user id of challenge token
+func ParseChallengeToken(tokenStr string) (string, error) {
+	claims := new(jwt.StandardClaims)
+	token, err := jwt.ParseWithClaims(tokenStr, claims, ValidateSignedMethod)
+	if err != nil || !token.Valid || !claims.VerifyAudience(twoFactorAudience, true) || claims.Id == "" {
+		return "", ErrChallengeTokenInvalid
+	}
+
+	return claims.Id, nil
+}
+
+// user of role that require two factor must enroll before use other api
+func checkTwoFactorEnrolled(ctx context.Context, user *models.User) error {
+	if user.TotpEnabled {
+		return nil
+	}
+
+	required, err := IsTwoFactorRequired(ctx, user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorEnrollRequired
+	}
+
+	return nil
+}
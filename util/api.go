@@ -7,8 +7,14 @@ import (
 )
 
 func ResponseNotSuccess(c *fiber.Ctx, code int, errMsg string) error {
+	return ResponseNotSuccessCode(c, code, CodeOfStatus(code), errMsg)
+}
+
+// errCode is more specific than the one of status, see CodeOfStatus
+func ResponseNotSuccessCode(c *fiber.Ctx, code int, errCode string, errMsg string) error {
 	return c.Status(code).JSON(fiber.Map{
 		"success": false,
+		"code":    errCode,
 		"message": errMsg,
 	})
 }
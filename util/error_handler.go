@@ -0,0 +1,82 @@
+package util
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// code in response that client can switch on, it does not change when message change
+const (
+	CodeBadRequest          = "bad_request"
+	CodeUnauthorized        = "unauthorized"
+	CodeForbidden           = "forbidden"
+	CodeNotFound            = "not_found"
+	CodeMethodNotAllowed    = "method_not_allowed"
+	CodeInvalidID           = "invalid_id"
+	CodeConflict            = "conflict"
+	CodeUnprocessableEntity = "unprocessable_entity"
+	CodeTooManyRequests     = "too_many_requests"
+	CodeUpgradeRequired     = "upgrade_required"
+	CodeInternalServerError = "internal_server_error"
+	CodeGatewayTimeout      = "gateway_timeout"
+)
+
+var statusCodes = map[int]string{
+	fiber.StatusBadRequest:          CodeBadRequest,
+	fiber.StatusUnauthorized:        CodeUnauthorized,
+	fiber.StatusForbidden:           CodeForbidden,
+	fiber.StatusNotFound:            CodeNotFound,
+	fiber.StatusMethodNotAllowed:    CodeMethodNotAllowed,
+	fiber.StatusConflict:            CodeConflict,
+	fiber.StatusUnprocessableEntity: CodeUnprocessableEntity,
+	fiber.StatusTooManyRequests:     CodeTooManyRequests,
+	fiber.StatusUpgradeRequired:     CodeUpgradeRequired,
+	fiber.StatusInternalServerError: CodeInternalServerError,
+	fiber.StatusGatewayTimeout:      CodeGatewayTimeout,
+}
+
+// code of status that is not in the list is the code of its class
+func CodeOfStatus(status int) string {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= fiber.StatusInternalServerError {
+		return CodeInternalServerError
+	}
+
+	return CodeBadRequest
+}
+
+// request deadline is reach or database does not answer in time
+func IsTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || mongo.IsTimeout(err)
+}
+
+// response for error of repository, error that is not known is 500
+func ResponseError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return ResponseNotSuccess(c, fiber.StatusNotFound, ErrNotFound.Error())
+	case errors.Is(err, ErrInvalidID):
+		return ResponseNotSuccessCode(c, fiber.StatusBadRequest, CodeInvalidID, ErrInvalidID.Error())
+	case errors.Is(err, ErrConflict):
+		return ResponseNotSuccess(c, fiber.StatusConflict, ErrConflict.Error())
+	case IsTimeout(err):
+		return ResponseNotSuccess(c, fiber.StatusGatewayTimeout, ErrGatewayTimeout.Error())
+	}
+
+	return ResponseNotSuccess(c, fiber.StatusInternalServerError, ErrInternalServerError.Error())
+}
+
+// fiber error handler, handler can return error of repository instead of write response
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return ResponseNotSuccess(c, fiberErr.Code, fiberErr.Message)
+	}
+
+	return ResponseError(c, err)
+}
@@ -3,14 +3,18 @@ package util
 import "errors"
 
 var (
-	ErrNotFound                 = errors.New("not found")
-	ErrEventInvalid             = errors.New("event invalid")
-	ErrIdIsNotPrimitiveObjectID = errors.New("Id is not primitive objectID")
-	ErrRequireParameter         = errors.New("require parameter ")
-	ErrInternalServerError      = errors.New("internal server error")
-	ErrValueAlreadyExists       = errors.New(" does already exists")
-	ErrValueNotAlreadyExists    = errors.New(" does not already exists")
-	ErrValueInvalid             = errors.New(" value is invalid")
+	// error of repository, check with errors.Is
+	ErrNotFound  = errors.New("not found")
+	ErrInvalidID = errors.New("Id is not primitive objectID")
+	ErrConflict  = errors.New("already exists")
+
+	ErrEventInvalid          = errors.New("event invalid")
+	ErrRequireParameter      = errors.New("require parameter ")
+	ErrInternalServerError   = errors.New("internal server error")
+	ErrGatewayTimeout        = errors.New("request timeout")
+	ErrValueAlreadyExists    = errors.New(" does already exists")
+	ErrValueNotAlreadyExists = errors.New(" does not already exists")
+	ErrValueInvalid          = errors.New(" value is invalid")
 	// ErrValueNotMatch             = errors.New(" value not match")
 	ErrTypeInvalid               = errors.New("type is invalid")
 	ErrProfileIdAlreadyExists    = errors.New("The profile id already exists")
@@ -0,0 +1,15 @@
+package util
+
+import "time"
+
+// date of request and response, YYYY-MM-DD
+const DateLayout = "2006-01-02"
+
+// start of the date in time.Local, date of check name is store as this time
+func ParseDate(date string) (time.Time, error) {
+	return time.ParseInLocation(DateLayout, date, time.Local)
+}
+
+func FormatDate(t time.Time) string {
+	return t.In(time.Local).Format(DateLayout)
+}
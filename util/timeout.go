@@ -0,0 +1,19 @@
+package util
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// request context is cancel after d, repository call that use it stop with context.DeadlineExceeded
+func RequestTimeout(d time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), d)
+		defer cancel()
+
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}